@@ -3,9 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/sol1corejz/goph-keeper/internal/client/otp"
 	pb "github.com/sol1corejz/goph-keeper/proto"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"time"
@@ -13,8 +14,12 @@ import (
 
 // Флаги командной строки
 var (
-	data string
-	meta string
+	data           string
+	meta           string
+	credentialType string
+	tags           []string
+	folderPath     string
+	favorite       bool
 )
 
 var addCredentialsCmd = &cobra.Command{
@@ -22,10 +27,42 @@ var addCredentialsCmd = &cobra.Command{
 	Short: "Add credentials",
 	Long:  "Добавление данных пользователя",
 	Run: func(cmd *cobra.Command, args []string) {
+		// Пароль записи генерируется и добавляется в данные
+		var secret string
+		var bits float64
+		if generate {
+			var err error
+			secret, bits, err = generateSecret()
+			if err != nil {
+				fatalf("Ошибка генерации пароля: %v", err)
+			}
+			data = withPassword(data, secret)
+		} else if otpQR != "" {
+			uri, err := readQRCode(otpQR)
+			if err != nil {
+				fatal(err)
+			}
+			data = uri
+		} else if data == "" {
+			fatal("Не указаны данные: используйте --data, --qr или --generate")
+		}
+
+		// Ключ одноразовых паролей проверяется до сохранения
+		indexData := data
+		if credentialType == otp.CredentialType {
+			key, err := otp.Parse(data)
+			if err != nil {
+				fatalf("Некорректный ключ одноразовых паролей: %v", err)
+			}
+			indexData = otpSearchData(key)
+		}
+
 		// Устанавливаем соединение с gRPC сервером
-		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(requestLogger),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 		if err != nil {
-			log.Fatalf("Ошибка подключения к gRPC: %v", err)
+			fatalf("Ошибка подключения к gRPC: %v", err)
 		}
 		defer conn.Close()
 
@@ -33,32 +70,56 @@ var addCredentialsCmd = &cobra.Command{
 		client := pb.NewKeeperClient(conn)
 
 		// Формируем контекст с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(commandContext(), time.Second*5)
 		defer cancel()
 
 		// Получение токена авторизации
 		token, err := ReadTokenFromFile()
 		if err != nil {
-			log.Fatalf("Ошибка получения токена: %v", err)
+			fatalf("Ошибка получения токена: %v", err)
 		}
 
 		credentials := &pb.Credentials{
-			Data: data,
-			Meta: meta,
+			Type:     credentialType,
+			Data:     data,
+			Meta:     meta,
+			Tags:     tags,
+			Favorite: favorite,
+		}
+
+		// Папка указывается путем, сервер принимает её идентификатор
+		if folderPath != "" {
+			folders, err := client.ListFolders(ctx, &pb.ListFoldersRequest{Token: token})
+			if err != nil {
+				fatalf("Ошибка получения папок: %v", err)
+			}
+			for _, f := range folders.Folders {
+				if f.Path == cleanFolderPath(folderPath) {
+					credentials.FolderId = f.Id
+				}
+			}
+			if credentials.FolderId == "" {
+				fatalf("Папка %q не найдена", folderPath)
+			}
 		}
 
 		payloadData := &pb.AddCredentialsRequest{
 			Token:       token,
 			Credentials: credentials,
+			SearchTerms: searchTerms(indexData, meta, tags),
 		}
 
 		_, err = client.AddCredentials(ctx, payloadData)
 		if err != nil {
-			log.Fatalf("Ошибка добавления данных: %v", err)
+			fatalf("Ошибка добавления данных: %v", err)
 		}
 
 		// Выводим ответ
 		fmt.Println("Данные успешно добавлены!")
+		if generate {
+			fmt.Printf("Сгенерированный пароль: %s\n", secret)
+			printEntropy(bits)
+		}
 		return
 
 	},
@@ -70,8 +131,14 @@ func init() {
 	// Добавляем флаги
 	addCredentialsCmd.Flags().StringVarP(&data, "data", "d", "", "Данные пользователя")
 	addCredentialsCmd.Flags().StringVarP(&meta, "meta", "m", "", "Метаданные")
+	addCredentialsCmd.Flags().StringVarP(&credentialType, "type", "t", "text", "Тип данных (login, text, binary, card, otp)")
+	addCredentialsCmd.Flags().StringSliceVar(&tags, "tag", nil, "Теги записи (можно указать несколько)")
+	addCredentialsCmd.Flags().StringVarP(&folderPath, "folder", "f", "", "Путь к папке для записи")
+	addCredentialsCmd.Flags().BoolVar(&favorite, "favorite", false, "Добавить запись в избранное")
+	addCredentialsCmd.Flags().BoolVarP(&generate, "generate", "g", false, "Сгенерировать пароль и добавить его в данные (password:...)")
+	addCredentialsCmd.Flags().StringVar(&otpQR, "qr", "", "Изображение с QR-кодом otpauth:// для записи типа otp")
+	addGeneratorFlags(addCredentialsCmd)
 
 	// Флаги обязательны
-	addCredentialsCmd.MarkFlagRequired("data")
 	addCredentialsCmd.MarkFlagRequired("meta")
 }
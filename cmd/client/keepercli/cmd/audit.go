@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/health"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+)
+
+// Флаги командной строки
+var (
+	auditFormat    string
+	auditMinScore  int
+	auditMaxAge    int
+	auditBreachDir string
+	auditFailOn    []string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check password health",
+	Long: `Проверка паролей из записей хранилища.
+
+Клиент получает свои записи и проверяет пароли (значение password:... в данных записи)
+локально: пароли и их хеши никуда не передаются. В отчет попадают записи
+со слабыми паролями (оценка zxcvbn ниже --min-score), паролями, которые используются
+в нескольких записях, записи, не изменявшиеся дольше --max-age дней, и пароли,
+найденные в локальной базе утечек (--breaches).
+
+База утечек - каталог с файлами в формате range API Have I Been Pwned: файл
+<первые 5 символов SHA-1> или <первые 5 символов SHA-1>.txt со строками
+"<остаток SHA-1>:<количество>".
+
+С флагом --fail-on команда завершается с кодом 1, если найдены проблемы указанных
+видов, что позволяет использовать ее в CI.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := health.Options{
+			MinScore: auditMinScore,
+			MaxAge:   time.Duration(auditMaxAge) * 24 * time.Hour,
+		}
+		if auditMinScore < 0 || auditMinScore > health.MaxScore {
+			fatalf("Оценка --min-score должна быть от 0 до %d", health.MaxScore)
+		}
+		for _, issue := range auditFailOn {
+			if !slices.Contains(health.Issues, issue) {
+				fatalf("Неизвестный вид проблем %q, допустимы: %s", issue, strings.Join(health.Issues, ", "))
+			}
+		}
+		if auditFormat != "table" && auditFormat != "json" {
+			fatalf("Неизвестный формат %q, допустимы: table, json", auditFormat)
+		}
+		if auditBreachDir != "" {
+			breaches, err := health.OpenBreachList(auditBreachDir)
+			if err != nil {
+				fatalf("Ошибка открытия базы утечек: %v", err)
+			}
+			opts.Breaches = breaches
+		}
+
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		// Получение всех записей пользователя потоком
+		stream, err := s.client.ListCredentials(s.ctx, &pb.GetCredentialsRequest{Token: s.token})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		var entries []health.Entry
+		for {
+			c, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				fatalf("Ошибка получения данных: %v", err)
+			}
+			var updatedAt time.Time
+			if c.UpdatedAt != nil {
+				updatedAt = c.UpdatedAt.AsTime()
+			}
+			entries = append(entries, health.NewEntry(c.Id, c.Data, c.Meta, c.Tags, updatedAt))
+		}
+
+		report, err := health.Check(entries, opts)
+		if err != nil {
+			fatalf("Ошибка проверки паролей: %v", err)
+		}
+
+		if auditFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				fatalf("Ошибка вывода отчета: %v", err)
+			}
+		} else {
+			printHealthReport(report)
+		}
+
+		var failed []string
+		for _, issue := range auditFailOn {
+			if n := report.Summary[issue]; n > 0 {
+				failed = append(failed, fmt.Sprintf("%s: %d", issue, n))
+			}
+		}
+		if len(failed) > 0 {
+			fatalf("Найдены проблемы с паролями (%s)", strings.Join(failed, ", "))
+		}
+	},
+}
+
+// printHealthReport выводит отчет о проверке паролей таблицей.
+func printHealthReport(report health.Report) {
+	fmt.Printf("Проверено записей: %d, без пароля: %d\n", report.Checked, report.Skipped)
+	for _, issue := range health.Issues {
+		fmt.Printf("  %s: %d\n", issue, report.Summary[issue])
+	}
+	if len(report.Findings) == 0 {
+		fmt.Println("Проблем не найдено")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tСАЙТ\tЛОГИН\tПРОБЛЕМЫ\tОЦЕНКА\tДНЕЙ\tУТЕЧКИ\tТОТ ЖЕ ПАРОЛЬ")
+	for _, f := range report.Findings {
+		age := ""
+		if f.AgeDays > 0 {
+			age = strconv.Itoa(f.AgeDays)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%d\t%s\n", f.ID, f.Site, f.Username,
+			strings.Join(f.Issues, ","), f.Score, health.MaxScore, age, f.BreachCount, strings.Join(f.ReusedWith, ","))
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	// Добавляем флаги
+	auditCmd.Flags().StringVar(&auditFormat, "format", "table", "Формат отчета: table или json")
+	auditCmd.Flags().IntVar(&auditMinScore, "min-score", 3, "Минимальная допустимая оценка стойкости zxcvbn (0-4)")
+	auditCmd.Flags().IntVar(&auditMaxAge, "max-age", 365, "Допустимый срок без изменения записи в днях (0 - не проверять)")
+	auditCmd.Flags().StringVar(&auditBreachDir, "breaches", "", "Каталог локальной базы утечек (range-файлы SHA-1)")
+	auditCmd.Flags().StringSliceVar(&auditFailOn, "fail-on", nil, "Завершиться с кодом 1 при проблемах: weak, reused, old, breached")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"time"
+)
+
+// session объединяет соединение с сервером, токен авторизации и контекст запроса.
+type session struct {
+	client pb.KeeperClient
+	token  string
+	ctx    context.Context
+
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+}
+
+// newSession устанавливает соединение с gRPC сервером и читает токен авторизации.
+// Вызывающий обязан закрыть сессию методом Close.
+func newSession() (*session, error) {
+	// Получение токена авторизации
+	token, err := ReadTokenFromFile()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения токена: %w", err)
+	}
+
+	// Устанавливаем соединение с gRPC сервером
+	conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(requestLogger, unaryTimeout, records.unary),
+		grpc.WithStreamInterceptor(records.stream),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к gRPC: %w", err)
+	}
+
+	// Срок ограничивает каждый унарный вызов отдельно (unaryTimeout), а не всю сессию:
+	// потоковые вызовы длятся, пока сервер передает данные
+	ctx, cancel := context.WithCancel(commandContext())
+
+	return &session{
+		client: pb.NewKeeperClient(conn),
+		token:  token,
+		ctx:    ctx,
+		conn:   conn,
+		cancel: cancel,
+	}, nil
+}
+
+// callTimeout - предельное время одного унарного вызова сервера.
+const callTimeout = 5 * time.Second
+
+// unaryTimeout ограничивает унарный вызов сроком callTimeout, если у контекста
+// вызова нет собственного срока.
+func unaryTimeout(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callTimeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// Close освобождает ресурсы сессии.
+func (s *session) Close() {
+	s.cancel()
+	s.conn.Close()
+}
+
+// folders возвращает папки пользователя.
+func (s *session) folders() ([]*pb.Folder, error) {
+	resp, err := s.client.ListFolders(s.ctx, &pb.ListFoldersRequest{Token: s.token})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Folders, nil
+}
+
+// folderByPath находит папку пользователя по пути.
+func (s *session) folderByPath(path string) (*pb.Folder, error) {
+	folders, err := s.folders()
+	if err != nil {
+		return nil, err
+	}
+	wanted := cleanFolderPath(path)
+	for _, f := range folders {
+		if f.Path == wanted {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("папка %q не найдена", path)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	"sync"
+)
+
+// cfgFile - путь к конфигурационному файлу клиента (флаг --config).
+var cfgFile string
+
+var (
+	clientConfig    *configs.ClientConfig
+	clientConfigErr error
+	clientConfigMu  sync.Once
+)
+
+// loadClientConfig загружает конфигурацию клиента один раз за запуск.
+func loadClientConfig() (*configs.ClientConfig, error) {
+	clientConfigMu.Do(func() {
+		clientConfig, clientConfigErr = configs.LoadClientConfig(cfgFile)
+	})
+	return clientConfig, clientConfigErr
+}
+
+// encryptionKey возвращает секрет клиента security.encryption_key из конфигурации.
+func encryptionKey() (string, error) {
+	cfg, err := loadClientConfig()
+	if err != nil {
+		return "", fmt.Errorf("не удалось загрузить конфигурацию клиента: %w", err)
+	}
+	if cfg.Security.EncryptionKey == "" {
+		return "", errors.New("в конфигурации клиента не задан security.encryption_key")
+	}
+	return cfg.Security.EncryptionKey, nil
+}
+
+// newIndexer создает вычислитель слепого индекса на ключе из конфигурации клиента.
+func newIndexer() (*search.Indexer, error) {
+	key, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return search.NewIndexer(key)
+}
+
+// searchTerms вычисляет термины слепого индекса для записи. Если ключ недоступен,
+// запись сохраняется без индекса и не будет находиться командой search.
+func searchTerms(data, meta string, tags []string) []string {
+	indexer, err := newIndexer()
+	if err != nil {
+		fmt.Printf("Предупреждение: запись не будет проиндексирована для поиска: %v\n", err)
+		return nil
+	}
+	return indexer.IndexTerms(search.ExtractFields(data, meta, tags))
+}
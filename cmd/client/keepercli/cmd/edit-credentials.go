@@ -1,14 +1,9 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
-	"github.com/gofiber/fiber/v2/log"
 	pb "github.com/sol1corejz/goph-keeper/proto"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"time"
 )
 
 var dataID string
@@ -18,24 +13,36 @@ var editCredentialsCmd = &cobra.Command{
 	Short: "Edit credentials",
 	Long:  "Обновление данных пользователя",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Устанавливаем соединение с gRPC сервером
-		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		s, err := newSession()
 		if err != nil {
-			log.Fatalf("Ошибка подключения к gRPC: %v", err)
+			fatal(err)
 		}
-		defer conn.Close()
+		defer s.Close()
 
-		// Создаем клиента
-		client := pb.NewKeeperClient(conn)
+		// Чужой записи (общей или из коллекции организации) среди своих нет,
+		// поэтому ошибка получения текущей записи не прерывает изменение
+		current, currentErr := fetchCredential(s.ctx, s.client, s.token, dataID)
 
-		// Формируем контекст с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
-		// Получение токена авторизации
-		token, err := ReadTokenFromFile()
-		if err != nil {
-			log.Fatalf("Ошибка получения токена: %v", err)
+		// Новый пароль заменяет пароль в переданных или текущих данных записи
+		var secret string
+		var bits float64
+		if generate {
+			if currentErr != nil && (data == "" || meta == "") {
+				fatalf("Ошибка получения данных: %v", currentErr)
+			}
+			if data == "" {
+				data = current.Data
+			}
+			if meta == "" {
+				meta = current.Meta
+			}
+			secret, bits, err = generateSecret()
+			if err != nil {
+				fatalf("Ошибка генерации пароля: %v", err)
+			}
+			data = withPassword(data, secret)
+		} else if data == "" || meta == "" {
+			fatal("Не указаны данные: используйте --data и --meta или --generate")
 		}
 
 		credentials := &pb.Credentials{
@@ -43,19 +50,32 @@ var editCredentialsCmd = &cobra.Command{
 			Meta: meta,
 		}
 
+		// Индекс строится по всем полям записи, поэтому нужны её текущие теги.
+		// Индекс чужой записи вычислен на ключе владельца и не пересчитывается,
+		// а право на изменение проверяет сервер.
+		var terms []string
+		if currentErr == nil {
+			terms = searchTerms(data, meta, current.Tags)
+		}
+
 		payloadData := &pb.EditCredentialsRequest{
 			Id:          dataID,
 			Credentials: credentials,
-			Token:       token,
+			Token:       s.token,
+			SearchTerms: terms,
 		}
 
-		_, err = client.EditCredentials(ctx, payloadData)
+		_, err = s.client.EditCredentials(s.ctx, payloadData)
 		if err != nil {
-			log.Fatalf("Ошибка обновления данных: %v", err)
+			fatalf("Ошибка обновления данных: %v", err)
 		}
 
 		// Выводим ответ
 		fmt.Println("Данные успешно обновлены!")
+		if generate {
+			fmt.Printf("Сгенерированный пароль: %s\n", secret)
+			printEntropy(bits)
+		}
 		return
 
 	},
@@ -68,9 +88,9 @@ func init() {
 	editCredentialsCmd.Flags().StringVarP(&dataID, "id", "i", "", "Идентификатор данных")
 	editCredentialsCmd.Flags().StringVarP(&data, "data", "d", "", "Данные пользователя")
 	editCredentialsCmd.Flags().StringVarP(&meta, "meta", "m", "", "Метаданные")
+	editCredentialsCmd.Flags().BoolVarP(&generate, "generate", "g", false, "Сгенерировать новый пароль записи (password:...)")
+	addGeneratorFlags(editCredentialsCmd)
 
 	// Флаги обязательны
 	editCredentialsCmd.MarkFlagRequired("id")
-	editCredentialsCmd.MarkFlagRequired("data")
-	editCredentialsCmd.MarkFlagRequired("meta")
 }
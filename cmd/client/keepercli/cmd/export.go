@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+)
+
+// passphraseEnv - переменная окружения с парольной фразой резервной копии.
+const passphraseEnv = "GOPHKEEPER_BACKUP_PASSPHRASE"
+
+// minPassphraseLength - минимальная длина парольной фразы новой резервной копии.
+const minPassphraseLength = 12
+
+// Флаги командной строки
+var (
+	exportOutput     string
+	exportPassphrase string
+	exportPlaintext  bool
+	exportFormat     string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the vault to an encrypted archive",
+	Long: `Резервная копия всех записей и папок пользователя.
+
+Архив шифруется AES-256-GCM ключом, выведенным из парольной фразы алгоритмом
+Argon2id; заголовок архива защищен от изменения вместе с содержимым.
+Парольная фраза задается флагом --passphrase или переменной окружения
+` + passphraseEnv + `. Архив восстанавливается командой restore.
+
+Выгрузка открытым текстом (--format json или csv) возможна только с флагом
+--unsafe-plaintext: такой файл содержит все пароли без защиты.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportFormat != "archive" && exportFormat != "json" && exportFormat != "csv" {
+			fatalf("Неизвестный формат %q, допустимы: archive, json, csv", exportFormat)
+		}
+		if exportFormat != "archive" && !exportPlaintext {
+			fatal("Выгрузка открытым текстом содержит все пароли без защиты; подтвердите ее флагом --unsafe-plaintext")
+		}
+		if exportFormat == "archive" && exportPlaintext {
+			fatal("Флаг --unsafe-plaintext используется только с --format json или csv")
+		}
+
+		var passphrase string
+		if exportFormat == "archive" {
+			passphrase = backupPassphrase(exportPassphrase)
+			if utf8.RuneCountInString(passphrase) < minPassphraseLength {
+				fatalf("Парольная фраза должна быть не короче %d символов", minPassphraseLength)
+			}
+		}
+
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+		ctx, cancel := context.WithTimeout(commandContext(), importTimeout)
+		defer cancel()
+
+		vault, err := fetchVault(ctx, s.client, s.token)
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOutput != "-" {
+			f, err := os.OpenFile(exportOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				fatalf("Ошибка создания файла: %v", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch exportFormat {
+		case "json":
+			err = backup.WriteJSON(out, vault)
+		case "csv":
+			err = backup.WriteCSV(out, vault)
+		default:
+			err = backup.Seal(out, vault, passphrase, backup.DefaultKDF())
+		}
+		if err != nil {
+			fatalf("Ошибка записи резервной копии: %v", err)
+		}
+		if exportOutput != "-" {
+			fmt.Fprintf(os.Stderr, "Выгружено записей: %d, папок: %d\n", len(vault.Entries), len(vault.Folders))
+		}
+	},
+}
+
+// fetchVault получает все записи и папки пользователя.
+func fetchVault(ctx context.Context, client pb.KeeperClient, token string) (backup.Vault, error) {
+	folders, err := client.ListFolders(ctx, &pb.ListFoldersRequest{Token: token})
+	if err != nil {
+		return backup.Vault{}, err
+	}
+	paths := make(map[string]string, len(folders.Folders))
+	vault := backup.Vault{Entries: []backup.Entry{}}
+	for _, f := range folders.Folders {
+		paths[f.Id] = f.Path
+		vault.Folders = append(vault.Folders, f.Path)
+	}
+	slices.Sort(vault.Folders)
+
+	creds, err := listAllCredentials(ctx, client, token)
+	if err != nil {
+		return backup.Vault{}, err
+	}
+	for _, c := range creds {
+		vault.Entries = append(vault.Entries, backup.Entry{
+			ID:        c.Id,
+			Type:      c.Type,
+			Data:      c.Data,
+			Meta:      c.Meta,
+			Tags:      c.Tags,
+			Folder:    paths[c.FolderId],
+			Favorite:  c.Favorite,
+			CreatedAt: c.CreatedAt.AsTime(),
+			UpdatedAt: c.UpdatedAt.AsTime(),
+		})
+	}
+	return vault, nil
+}
+
+// backupPassphrase возвращает парольную фразу из флага или переменной окружения.
+func backupPassphrase(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if env := os.Getenv(passphraseEnv); env != "" {
+		return env
+	}
+	fatalf("Не задана парольная фраза: используйте --passphrase или переменную окружения %s", passphraseEnv)
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	// Добавляем флаги
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Файл резервной копии (- для вывода в stdout)")
+	exportCmd.Flags().StringVar(&exportPassphrase, "passphrase", "", "Парольная фраза архива (или "+passphraseEnv+")")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "archive", "Формат: archive, json или csv")
+	exportCmd.Flags().BoolVar(&exportPlaintext, "unsafe-plaintext", false, "Разрешить выгрузку открытым текстом")
+
+	// Флаги обязательны
+	exportCmd.MarkFlagRequired("output")
+}
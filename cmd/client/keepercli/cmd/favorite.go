@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+)
+
+// Флаги командной строки
+var favoriteOff bool
+
+var favoriteCmd = &cobra.Command{
+	Use:   "favorite <идентификатор записи>",
+	Short: "Mark credentials as favorite",
+	Long:  "Добавление записи в избранное; с флагом --off — удаление из избранного",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		_, err = s.client.SetFavorite(s.ctx, &pb.SetFavoriteRequest{
+			Token:        s.token,
+			CredentialId: args[0],
+			Favorite:     !favoriteOff,
+		})
+		if err != nil {
+			fatalf("Ошибка обновления избранного: %v", err)
+		}
+
+		if favoriteOff {
+			fmt.Println("Запись удалена из избранного")
+			return
+		}
+		fmt.Println("Запись добавлена в избранное")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(favoriteCmd)
+
+	// Добавляем флаги
+	favoriteCmd.Flags().BoolVar(&favoriteOff, "off", false, "Удалить запись из избранного")
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"path"
+	"strings"
+)
+
+// Флаги командной строки
+var folderParents bool
+
+var folderCmd = &cobra.Command{
+	Use:   "folder",
+	Short: "Manage folders",
+	Long:  "Управление папками для группировки данных. Папки задаются путем, например work/databases.",
+}
+
+var folderLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List folders",
+	Long:  "Вывод дерева папок пользователя",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		folders, err := s.folders()
+		if err != nil {
+			fatalf("Ошибка получения папок: %v", err)
+		}
+		if len(folders) == 0 {
+			fmt.Println("Папок нет")
+			return
+		}
+		for _, f := range folders {
+			depth := strings.Count(f.Path, "/")
+			fmt.Printf("%s%s/  (%s)\n", strings.Repeat("  ", depth), f.Name, f.Id)
+		}
+	},
+}
+
+var folderMkCmd = &cobra.Command{
+	Use:   "mk <путь>",
+	Short: "Create folder",
+	Long:  "Создание папки. С флагом --parents создаются недостающие родительские папки.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := s.client.CreateFolder(s.ctx, &pb.CreateFolderRequest{
+			Token:   s.token,
+			Path:    args[0],
+			Parents: folderParents,
+		})
+		if err != nil {
+			fatalf("Ошибка создания папки: %v", err)
+		}
+		fmt.Printf("Папка %s создана\n", resp.Folder.Path)
+	},
+}
+
+var folderMvCmd = &cobra.Command{
+	Use:   "mv <путь> <новый путь>",
+	Short: "Move or rename folder",
+	Long: `Перемещение и/или переименование папки вместе с содержимым.
+Родительская папка нового пути должна существовать; "/" обозначает корень.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		src, err := s.folderByPath(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		dst := cleanFolderPath(args[1])
+		parentPath, name := path.Split(dst)
+		parentPath = cleanFolderPath(parentPath)
+		if name == "" {
+			fatal("Не указано имя папки")
+		}
+
+		parentID := ""
+		if parentPath != "" {
+			parent, err := s.folderByPath(parentPath)
+			if err != nil {
+				fatal(err)
+			}
+			parentID = parent.Id
+		}
+
+		if parentID != src.ParentId {
+			_, err = s.client.MoveFolder(s.ctx, &pb.MoveFolderRequest{
+				Token:    s.token,
+				FolderId: src.Id,
+				ParentId: parentID,
+			})
+			if err != nil {
+				fatalf("Ошибка перемещения папки: %v", err)
+			}
+		}
+
+		if name != src.Name {
+			_, err = s.client.RenameFolder(s.ctx, &pb.RenameFolderRequest{
+				Token:    s.token,
+				FolderId: src.Id,
+				Name:     name,
+			})
+			if err != nil {
+				fatalf("Ошибка переименования папки: %v", err)
+			}
+		}
+
+		fmt.Printf("Папка %s перемещена в %s\n", src.Path, dst)
+	},
+}
+
+var folderRmCmd = &cobra.Command{
+	Use:   "rm <путь>",
+	Short: "Delete folder",
+	Long:  "Удаление папки вместе с вложенными папками. Данные из них переходят в корень.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		folder, err := s.folderByPath(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		_, err = s.client.DeleteFolder(s.ctx, &pb.DeleteFolderRequest{Token: s.token, FolderId: folder.Id})
+		if err != nil {
+			fatalf("Ошибка удаления папки: %v", err)
+		}
+		fmt.Printf("Папка %s удалена\n", folder.Path)
+	},
+}
+
+var folderPutCmd = &cobra.Command{
+	Use:   "put <идентификатор записи> <путь>",
+	Short: "Move credentials into folder",
+	Long:  `Перемещение записи в папку; "/" перемещает запись в корень.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		folderID := ""
+		if cleanFolderPath(args[1]) != "" {
+			folder, err := s.folderByPath(args[1])
+			if err != nil {
+				fatal(err)
+			}
+			folderID = folder.Id
+		}
+
+		_, err = s.client.SetCredentialFolder(s.ctx, &pb.SetCredentialFolderRequest{
+			Token:        s.token,
+			CredentialId: args[0],
+			FolderId:     folderID,
+		})
+		if err != nil {
+			fatalf("Ошибка перемещения записи: %v", err)
+		}
+		fmt.Println("Запись перемещена")
+	},
+}
+
+// cleanFolderPath приводит путь к виду "a/b" без начального и конечного разделителя.
+func cleanFolderPath(p string) string {
+	return strings.Trim(path.Clean("/"+strings.TrimSpace(p)), "/")
+}
+
+func init() {
+	rootCmd.AddCommand(folderCmd)
+	folderCmd.AddCommand(folderLsCmd, folderMkCmd, folderMvCmd, folderRmCmd, folderPutCmd)
+
+	// Добавляем флаги
+	folderMkCmd.Flags().BoolVarP(&folderParents, "parents", "p", false, "Создать недостающие родительские папки")
+}
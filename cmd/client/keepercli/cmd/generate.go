@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	"github.com/sol1corejz/goph-keeper/internal/passgen"
+	"github.com/spf13/cobra"
+)
+
+// Флаги генератора паролей
+var (
+	generate       bool
+	genLength      int
+	genWords       int
+	genSeparator   string
+	genNoLower     bool
+	genNoUpper     bool
+	genNoDigits    bool
+	genNoSymbols   bool
+	genNoAmbiguous bool
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate password",
+	Long: `Генерация случайного пароля или парольной фразы.
+
+Пароль составляется из выбранных наборов символов и содержит хотя бы один символ
+каждого набора. С флагом --words генерируется парольная фраза diceware из слов
+словаря EFF. Случайные значения берутся из crypto/rand. Пароль выводится
+в стандартный вывод, оценка энтропии - в стандартный поток ошибок.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		secret, bits, err := generateSecret()
+		if err != nil {
+			fatalf("Ошибка генерации пароля: %v", err)
+		}
+
+		fmt.Println(secret)
+		printEntropy(bits)
+	},
+}
+
+// generateSecret генерирует пароль или парольную фразу по флагам генератора
+// и возвращает его вместе с энтропией в битах.
+func generateSecret() (string, float64, error) {
+	if genWords > 0 {
+		phrase, err := passgen.Passphrase(genWords, genSeparator)
+		return phrase, passgen.PassphraseEntropy(genWords), err
+	}
+
+	opts := passgen.Options{
+		Length:           genLength,
+		Lower:            !genNoLower,
+		Upper:            !genNoUpper,
+		Digits:           !genNoDigits,
+		Symbols:          !genNoSymbols,
+		ExcludeAmbiguous: genNoAmbiguous,
+	}
+	password, err := passgen.Generate(opts)
+	return password, opts.Entropy(), err
+}
+
+// printEntropy выводит энтропию пароля и ее словесную оценку в поток ошибок,
+// чтобы стандартный вывод содержал только пароль.
+func printEntropy(bits float64) {
+	fmt.Fprintf(os.Stderr, "Энтропия: %.0f бит (%s)\n", bits, passgen.Strength(bits))
+}
+
+// withPassword заменяет пароль в данных записи вида "login:admin, password:1234"
+// на password или добавляет его, если пароля в данных нет.
+// Запятые в пароле экранируются (см. search.FormatPair).
+func withPassword(data, password string) string {
+	field := search.FormatPair("password", password)
+	parts := search.SplitPairs(data)
+	for i, part := range parts {
+		key, _, ok := search.CutPair(part)
+		if ok && strings.EqualFold(strings.TrimSpace(key), "password") {
+			indent := part[:len(part)-len(strings.TrimLeft(part, " "))]
+			parts[i] = indent + field
+			return strings.Join(parts, ",")
+		}
+	}
+
+	if strings.TrimSpace(data) == "" {
+		return field
+	}
+	return data + ", " + field
+}
+
+// addGeneratorFlags добавляет команде cmd флаги параметров генератора паролей.
+func addGeneratorFlags(cmd *cobra.Command) {
+	defaults := passgen.DefaultOptions()
+	cmd.Flags().IntVarP(&genLength, "length", "l", defaults.Length, "Длина пароля")
+	cmd.Flags().IntVarP(&genWords, "words", "w", 0, "Сгенерировать парольную фразу из указанного количества слов")
+	cmd.Flags().StringVar(&genSeparator, "separator", "-", "Разделитель слов парольной фразы")
+	cmd.Flags().BoolVar(&genNoLower, "no-lower", false, "Без строчных букв")
+	cmd.Flags().BoolVar(&genNoUpper, "no-upper", false, "Без прописных букв")
+	cmd.Flags().BoolVar(&genNoDigits, "no-digits", false, "Без цифр")
+	cmd.Flags().BoolVar(&genNoSymbols, "no-symbols", false, "Без специальных символов")
+	cmd.Flags().BoolVar(&genNoAmbiguous, "no-ambiguous", false, "Без похожих символов ("+passgen.Ambiguous+")")
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	addGeneratorFlags(generateCmd)
+}
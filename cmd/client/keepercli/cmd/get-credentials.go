@@ -2,29 +2,46 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/gofiber/fiber/v2/log"
 	pb "github.com/sol1corejz/goph-keeper/proto"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"io"
+	"strings"
 	"time"
 )
 
 // Флаги командной строки
 var (
-	userID string
+	credentialID   string
+	listLimit      int32
+	listType       string
+	listTag        string
+	listFolder     string
+	listFavorites  bool
+	listPageToken  string
+	listSortBy     string
+	listDescending bool
 )
 
 var getCredentialsCmd = &cobra.Command{
 	Use:   "get-credentials",
 	Short: "Get credentials",
-	Long:  "Получение данных пользователя",
+	Long: `Получение данных пользователя.
+
+Без флага --limit выводятся все подходящие записи (они передаются сервером потоком).
+С флагом --limit выводится одна страница и токен для получения следующей (--page-token).`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Устанавливаем соединение с gRPC сервером
-		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(requestLogger, records.unary),
+			grpc.WithStreamInterceptor(records.stream),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 		if err != nil {
-			log.Fatalf("Ошибка подключения к gRPC: %v", err)
+			fatalf("Ошибка подключения к gRPC: %v", err)
 		}
 		defer conn.Close()
 
@@ -32,33 +49,138 @@ var getCredentialsCmd = &cobra.Command{
 		client := pb.NewKeeperClient(conn)
 
 		// Формируем контекст с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(commandContext(), time.Second*5)
 		defer cancel()
 
 		// Получение токена авторизации
 		token, err := ReadTokenFromFile()
 		if err != nil {
-			log.Fatalf("Ошибка получения токена: %v", err)
+			fatalf("Ошибка получения токена: %v", err)
 		}
 
 		payloadData := &pb.GetCredentialsRequest{
-			Token: token,
-			Id:    userID,
+			Token:      token,
+			Id:         credentialID,
+			PageSize:   listLimit,
+			PageToken:  listPageToken,
+			SortBy:     listSortBy,
+			Descending: listDescending,
+			Filter: &pb.CredentialsFilter{
+				Type:          listType,
+				Tag:           listTag,
+				Folder:        listFolder,
+				FavoritesOnly: listFavorites,
+			},
 		}
 
-		resp, err := client.GetCredentials(ctx, payloadData)
+		// Постраничный режим
+		if listLimit > 0 || listPageToken != "" {
+			resp, err := client.GetCredentials(ctx, payloadData)
+			if err != nil {
+				fatalf("Ошибка получения данных: %v", err)
+			}
+
+			fmt.Println("Данные успешно получены!")
+			printCredentials(resp.Credentials, folderPaths(ctx, client, token))
+			if resp.NextPageToken != "" {
+				fmt.Printf("Следующая страница: --page-token %s\n", resp.NextPageToken)
+			}
+			return
+		}
+
+		// Получение всех записей потоком
+		stream, err := client.ListCredentials(ctx, payloadData)
 		if err != nil {
-			log.Fatalf("Ошибка получения данных: %v", err)
+			fatalf("Ошибка получения данных: %v", err)
+		}
+
+		credentials := make([]*pb.Credentials, 0)
+		for {
+			credential, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				fatalf("Ошибка получения данных: %v", err)
+			}
+			credentials = append(credentials, credential)
 		}
 
 		// Выводим ответ
 		fmt.Println("Данные успешно получены!")
-		fmt.Println(resp.Credentials)
-		return
-
+		printCredentials(credentials, folderPaths(ctx, client, token))
 	},
 }
 
+// fetchCredential получает одну запись пользователя по идентификатору.
+func fetchCredential(ctx context.Context, client pb.KeeperClient, token, id string) (*pb.Credentials, error) {
+	resp, err := client.GetCredentials(ctx, &pb.GetCredentialsRequest{
+		Token:    token,
+		Id:       id,
+		PageSize: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Credentials) == 0 {
+		return nil, fmt.Errorf("запись %s не найдена", id)
+	}
+	return resp.Credentials[0], nil
+}
+
+// folderPaths возвращает соответствие идентификаторов папок их путям.
+// При ошибке возвращается пустое соответствие: папки в выводе не критичны.
+func folderPaths(ctx context.Context, client pb.KeeperClient, token string) map[string]string {
+	paths := make(map[string]string)
+	resp, err := client.ListFolders(ctx, &pb.ListFoldersRequest{Token: token})
+	if err != nil {
+		return paths
+	}
+	for _, f := range resp.Folders {
+		paths[f.Id] = f.Path
+	}
+	return paths
+}
+
+// printCredentials выводит учетные данные в читаемом виде.
+// folders сопоставляет идентификаторы папок их путям.
+func printCredentials(credentials []*pb.Credentials, folders map[string]string) {
+	if len(credentials) == 0 {
+		fmt.Println("Записей не найдено")
+		return
+	}
+	for _, c := range credentials {
+		favorite := ""
+		if c.Favorite {
+			favorite = " ★"
+		}
+		fmt.Printf("ID: %s%s\n", c.Id, favorite)
+		fmt.Printf("  Тип: %s\n", c.Type)
+		fmt.Printf("  Данные: %s\n", c.Data)
+		fmt.Printf("  Метаданные: %s\n", c.Meta)
+		if c.FolderId != "" {
+			fmt.Printf("  Папка: %s\n", folders[c.FolderId])
+		}
+		if len(c.Tags) > 0 {
+			fmt.Printf("  Теги: %s\n", strings.Join(c.Tags, ", "))
+		}
+		if c.UpdatedAt != nil {
+			fmt.Printf("  Изменено: %s\n", c.UpdatedAt.AsTime().Local().Format(time.DateTime))
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(getCredentialsCmd)
+
+	// Добавляем флаги
+	getCredentialsCmd.Flags().StringVarP(&credentialID, "id", "i", "", "Идентификатор записи")
+	getCredentialsCmd.Flags().Int32VarP(&listLimit, "limit", "l", 0, "Количество записей на странице (0 — все записи)")
+	getCredentialsCmd.Flags().StringVar(&listPageToken, "page-token", "", "Токен следующей страницы")
+	getCredentialsCmd.Flags().StringVarP(&listType, "type", "t", "", "Фильтр по типу (login, text, binary, card, otp)")
+	getCredentialsCmd.Flags().StringVar(&listTag, "tag", "", "Фильтр по тегу")
+	getCredentialsCmd.Flags().StringVarP(&listFolder, "folder", "f", "", "Фильтр по пути к папке")
+	getCredentialsCmd.Flags().BoolVar(&listFavorites, "favorites", false, "Только избранные записи")
+	getCredentialsCmd.Flags().StringVar(&listSortBy, "sort", "created_at", "Поле сортировки (created_at, updated_at)")
+	getCredentialsCmd.Flags().BoolVar(&listDescending, "desc", false, "Сортировка по убыванию")
 }
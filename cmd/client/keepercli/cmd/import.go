@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/importer"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Ограничения одного сообщения потока BatchAddCredentials. Количество записей
+// совпадает с ограничением сервера, а размер оставляет запас до ограничения gRPC
+// на размер принимаемого сервером сообщения по умолчанию (4 МБ).
+const (
+	importBatchSize  = 5000
+	importBatchBytes = 3 << 20
+)
+
+// importTimeout - время на загрузку существующих записей и сохранение импорта.
+const importTimeout = 2 * time.Minute
+
+// Флаги командной строки
+var (
+	importFormat          string
+	importMapping         string
+	importDryRun          bool
+	importAllowDuplicates bool
+	importFolder          string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <файл>",
+	Short: "Import credentials from other password managers",
+	Long: `Импорт записей из других менеджеров паролей.
+
+Поддерживаемые форматы (--format):
+  keepass    XML-экспорт KeePass 2.x и KeePassXC
+  bitwarden  незашифрованный JSON-экспорт Bitwarden
+  1password  CSV-экспорт 1Password
+  chrome     CSV-экспорт паролей Chrome
+  firefox    CSV-экспорт паролей Firefox
+  csv        CSV с заголовком; столбцы распознаются по названиям (title, url,
+             username, password, notes, tags, folder...) или задаются --map
+
+Записи, совпадающие с уже сохраненными (тот же тип, сайт и логин), пропускаются.
+Импорт выполняется одной транзакцией вместе с созданием недостающих папок:
+при ошибке не сохраняется ни одна запись.
+С флагом --dry-run выводится только план импорта.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var mapping importer.Mapping
+		if importMapping != "" {
+			if importFormat != importer.FormatCSV {
+				fatal("Флаг --map используется только с --format csv")
+			}
+			var err error
+			if mapping, err = importer.ParseMapping(importMapping); err != nil {
+				fatalf("Некорректное сопоставление столбцов: %v", err)
+			}
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			fatalf("Ошибка открытия файла: %v", err)
+		}
+		records, err := importer.Parse(importFormat, f, mapping)
+		f.Close()
+		if err != nil {
+			fatalf("Ошибка чтения файла: %v", err)
+		}
+		for i := range records {
+			records[i].Folder = cleanFolderPath(path.Join(importFolder, records[i].Folder))
+		}
+
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+		ctx, cancel := context.WithTimeout(commandContext(), importTimeout)
+		defer cancel()
+
+		fresh, duplicates := records, []importer.Record(nil)
+		if !importAllowDuplicates {
+			creds, err := listAllCredentials(ctx, s.client, s.token)
+			if err != nil {
+				fatalf("Ошибка получения данных: %v", err)
+			}
+			existing := make([]string, 0, len(creds))
+			for _, c := range creds {
+				existing = append(existing, importer.Key(c.Type, c.Data, c.Meta))
+			}
+			fresh, duplicates = importer.Dedupe(records, existing)
+		}
+
+		printImportPlan(fresh, duplicates)
+		if importDryRun || len(fresh) == 0 {
+			return
+		}
+
+		indexer, err := newIndexer()
+		if err != nil {
+			fmt.Printf("Предупреждение: записи не будут проиндексированы для поиска: %v\n", err)
+		}
+
+		items := make([]*pb.NewCredentials, 0, len(fresh))
+		for _, r := range fresh {
+			data, meta := r.Data(), r.Meta()
+			items = append(items, &pb.NewCredentials{
+				Credentials: &pb.Credentials{
+					Type:     r.Type,
+					Data:     data,
+					Meta:     meta,
+					Tags:     r.Tags,
+					Favorite: r.Favorite,
+				},
+				SearchTerms: indexTerms(indexer, data, meta, r.Tags),
+				FolderPath:  r.Folder,
+			})
+		}
+
+		ids, err := addCredentials(ctx, s.client, s.token, items)
+		if err != nil {
+			fatalf("Ошибка импорта, записи не сохранены: %v", err)
+		}
+		fmt.Printf("Импортировано записей: %d\n", len(ids))
+	},
+}
+
+// listAllCredentials возвращает все записи пользователя, полученные потоком.
+func listAllCredentials(ctx context.Context, client pb.KeeperClient, token string) ([]*pb.Credentials, error) {
+	stream, err := client.ListCredentials(ctx, &pb.GetCredentialsRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	var creds []*pb.Credentials
+	for {
+		c, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return creds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, c)
+	}
+}
+
+// ensureFolders создает недостающие папки с путями paths и возвращает соответствие
+// путей всех папок пользователя их идентификаторам. Пути создаются по порядку,
+// так что родительская папка создается раньше вложенных и не создается повторно.
+func ensureFolders(ctx context.Context, client pb.KeeperClient, token string, paths []string) (map[string]string, error) {
+	resp, err := client.ListFolders(ctx, &pb.ListFoldersRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(resp.Folders))
+	for _, f := range resp.Folders {
+		ids[f.Path] = f.Id
+	}
+
+	// Путь родителя - префикс путей вложенных папок, поэтому сортировка ставит его раньше
+	paths = slices.Clone(paths)
+	slices.Sort(paths)
+	for _, p := range slices.Compact(paths) {
+		if p == "" {
+			continue
+		}
+		if _, ok := ids[p]; ok {
+			continue
+		}
+		created, err := client.CreateFolder(ctx, &pb.CreateFolderRequest{Token: token, Path: p, Parents: true})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		ids[p] = created.Folder.Id
+	}
+	return ids, nil
+}
+
+// addCredentials сохраняет записи одним потоком BatchAddCredentials, разбитым
+// на сообщения функцией importBatches, и возвращает идентификаторы сохраненных
+// записей. Сервер сохраняет поток одной транзакцией, поэтому при ошибке
+// не сохраняется ни одна запись.
+func addCredentials(ctx context.Context, client pb.KeeperClient, token string, items []*pb.NewCredentials) ([]string, error) {
+	stream, err := client.BatchAddCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, batch := range importBatches(items) {
+		req := &pb.BatchAddCredentialsRequest{Items: batch}
+		if i == 0 {
+			req.Token = token
+		}
+		// Если сервер прервал поток, причина возвращается из CloseAndRecv
+		if err = stream.Send(req); err != nil {
+			break
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ids, nil
+}
+
+// importBatches делит записи на пакеты не более importBatchSize записей
+// и importBatchBytes байт. Запись больше importBatchBytes отправляется отдельно.
+func importBatches(items []*pb.NewCredentials) [][]*pb.NewCredentials {
+	var (
+		batches [][]*pb.NewCredentials
+		start   int
+		size    int
+	)
+	for i, item := range items {
+		// Размер поля repeated в сообщении запроса с учетом тега и длины
+		itemSize := protowire.SizeBytes(proto.Size(item)) + 1
+		if i > start && (i-start == importBatchSize || size+itemSize > importBatchBytes) {
+			batches = append(batches, items[start:i])
+			start, size = i, 0
+		}
+		size += itemSize
+	}
+	if start < len(items) {
+		batches = append(batches, items[start:])
+	}
+	return batches
+}
+
+// indexTerms вычисляет термины слепого индекса записи; без ключа индекса (indexer == nil)
+// возвращает nil.
+func indexTerms(indexer *search.Indexer, data, meta string, tags []string) []string {
+	if indexer == nil {
+		return nil
+	}
+	return indexer.IndexTerms(search.ExtractFields(data, meta, tags))
+}
+
+// printImportPlan выводит импортируемые записи и пропускаемые дубликаты.
+func printImportPlan(fresh, duplicates []importer.Record) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ДЕЙСТВИЕ\tТИП\tНАЗВАНИЕ\tСАЙТ\tЛОГИН\tПАПКА")
+	row := func(action string, r importer.Record) {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", action, r.Type, r.Title, r.URL, r.Username, r.Folder)
+	}
+	for _, r := range fresh {
+		row("добавить", r)
+	}
+	for _, r := range duplicates {
+		row("пропустить", r)
+	}
+	w.Flush()
+	fmt.Printf("Новых записей: %d, дубликатов: %d\n", len(fresh), len(duplicates))
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	// Добавляем флаги
+	importCmd.Flags().StringVar(&importFormat, "format", "", "Формат файла: "+strings.Join(importer.Formats, ", "))
+	importCmd.Flags().StringVar(&importMapping, "map", "", "Сопоставление столбцов CSV, например title=Name,password=Pass")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Только показать план импорта")
+	importCmd.Flags().BoolVar(&importAllowDuplicates, "allow-duplicates", false, "Не пропускать записи, совпадающие с сохраненными")
+	importCmd.Flags().StringVarP(&importFolder, "folder", "f", "", "Папка, в которую помещаются импортированные записи")
+
+	// Флаги обязательны
+	importCmd.MarkFlagRequired("format")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// logCloser закрывает файл логов клиента при завершении команды.
+var logCloser io.Closer
+
+// setupLogging настраивает логгер клиента по разделу logging конфигурации.
+// Без файла логов записи не выводятся, чтобы не смешиваться с выводом команд.
+// Ошибки загрузки конфигурации здесь не обрабатываются: о них сообщат команды,
+// которым конфигурация нужна.
+func setupLogging() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cfg, err := loadClientConfig()
+	if err != nil || cfg.Logging.File == "" {
+		return
+	}
+
+	logger, closer, err := logging.New(logging.Options{
+		Level:      cfg.Logging.Level,
+		File:       cfg.Logging.File,
+		MaxSizeMB:  cfg.Logging.MaxSizeMB,
+		MaxBackups: cfg.Logging.MaxBackups,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Предупреждение: логирование отключено: %v\n", err)
+		return
+	}
+	slog.SetDefault(logger)
+	logCloser = closer
+}
+
+// closeLogging закрывает файл логов клиента.
+func closeLogging() {
+	if logCloser != nil {
+		logCloser.Close()
+	}
+}
+
+// fatal выводит сообщение об ошибке, записывает его в лог и завершает команду.
+func fatal(args ...any) {
+	exit(fmt.Sprint(args...))
+}
+
+// fatalf форматирует сообщение об ошибке, записывает его в лог и завершает команду.
+func fatalf(format string, args ...any) {
+	exit(fmt.Sprintf(format, args...))
+}
+
+// exit записывает сообщение в лог и поток ошибок и завершает процесс с кодом 1.
+func exit(msg string) {
+	slog.ErrorContext(commandContext(), msg)
+	endTracing()
+	closeLogging()
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+
+// requestLogger передает серверу идентификатор запроса и записывает в лог итог вызова.
+// Идентификатор запроса позволяет найти запись о вызове в логах сервера.
+func requestLogger(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	requestID := logging.NewRequestID()
+	ctx = metadata.AppendToOutgoingContext(ctx, logging.RequestIDHeader, requestID)
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	slog.Log(ctx, level, "rpc call",
+		slog.String(logging.KeyRequestID, requestID),
+		slog.String(logging.KeyMethod, method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return err
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
 	"strings"
 	"time"
 
 	pb "github.com/sol1corejz/goph-keeper/proto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 
 	"github.com/spf13/cobra"
@@ -20,9 +20,11 @@ var loginCmd = &cobra.Command{
 	Short: "Авторизация пользователя через gRPC",
 	Run: func(cmd *cobra.Command, args []string) {
 		// Устанавливаем соединение с gRPC сервером
-		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(requestLogger),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 		if err != nil {
-			log.Fatalf("Ошибка подключения к gRPC: %v", err)
+			fatalf("Ошибка подключения к gRPC: %v", err)
 		}
 		defer conn.Close()
 
@@ -30,7 +32,7 @@ var loginCmd = &cobra.Command{
 		client := pb.NewKeeperClient(conn)
 
 		// Формируем контекст с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(commandContext(), time.Second*5)
 		defer cancel()
 
 		userData := &pb.User{
@@ -46,13 +48,18 @@ var loginCmd = &cobra.Command{
 				fmt.Println("Неправильный логин или пароль!")
 				return
 			}
-			log.Fatalf("Ошибка авторизации: %v", err)
+			fatalf("Ошибка авторизации: %v", err)
 		}
 
 		// Сохраняем токен
 		err = SaveTokenToFile(resp.Token)
 		if err != nil {
-			log.Fatalf("Ошибка сохранения токена: %v", err)
+			fatalf("Ошибка сохранения токена: %v", err)
+		}
+
+		// Публикуем открытый ключ, чтобы другие пользователи могли выдавать доступ к записям
+		if err = publishKey(ctx, client, resp.Token); err != nil {
+			fmt.Printf("Предупреждение: открытый ключ не опубликован, доступ к записям вам выдать не смогут: %v\n", err)
 		}
 
 		// Выводим ответ
@@ -0,0 +1,475 @@
+package cmd
+
+import (
+	"fmt"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// Флаги командной строки
+var (
+	orgRole        string
+	collectionTeam []string
+)
+
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Manage organizations",
+	Long:  "Управление организациями, их участниками, командами и коллекциями",
+}
+
+var orgCreateCmd = &cobra.Command{
+	Use:   "create <организация>",
+	Short: "Create organization",
+	Long:  "Создание организации; создатель становится её владельцем",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		_, err = s.client.CreateOrganization(s.ctx, &pb.CreateOrganizationRequest{Token: s.token, Name: args[0]})
+		if err != nil {
+			fatalf("Ошибка создания организации: %v", err)
+		}
+		fmt.Printf("Организация %s создана\n", args[0])
+	},
+}
+
+var orgLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List organizations",
+	Long:  "Вывод организаций, в которых вы состоите",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		orgs, err := s.organizations()
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		if len(orgs) == 0 {
+			fmt.Println("Организаций нет")
+			return
+		}
+		for _, org := range orgs {
+			fmt.Printf("%s\t%s\n", org.Name, org.Role)
+		}
+	},
+}
+
+var orgMembersCmd = &cobra.Command{
+	Use:   "members <организация>",
+	Short: "List organization members",
+	Long:  "Вывод участников организации и их ролей",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		resp, err := s.client.ListMembers(s.ctx, &pb.ListMembersRequest{Token: s.token, OrgId: org.Id})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		for _, member := range resp.Members {
+			fmt.Printf("%s\t%s\n", member.Username, member.Role)
+		}
+	},
+}
+
+var orgInviteCmd = &cobra.Command{
+	Use:   "invite <организация> <пользователь>",
+	Short: "Invite user to organization",
+	Long:  "Приглашение пользователя в организацию с ролью owner, admin, member или read-only",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		_, err = s.client.InviteMember(s.ctx, &pb.InviteMemberRequest{
+			Token:    s.token,
+			OrgId:    org.Id,
+			Username: args[1],
+			Role:     orgRole,
+		})
+		if err != nil {
+			fatalf("Ошибка приглашения: %v", err)
+		}
+		fmt.Printf("Пользователь %s приглашен в %s\n", args[1], org.Name)
+	},
+}
+
+var orgInvitesCmd = &cobra.Command{
+	Use:   "invites",
+	Short: "List pending invites",
+	Long:  "Вывод приглашений в организации",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := s.client.ListInvites(s.ctx, &pb.ListInvitesRequest{Token: s.token})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		if len(resp.Invites) == 0 {
+			fmt.Println("Приглашений нет")
+			return
+		}
+		for _, invite := range resp.Invites {
+			fmt.Printf("%s\t%s\n", invite.OrgName, invite.Role)
+		}
+	},
+}
+
+var orgAcceptCmd = &cobra.Command{
+	Use:   "accept <организация>",
+	Short: "Accept invite",
+	Long:  "Принятие приглашения в организацию",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := s.client.ListInvites(s.ctx, &pb.ListInvitesRequest{Token: s.token})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+
+		for _, invite := range resp.Invites {
+			if invite.OrgName != args[0] {
+				continue
+			}
+			_, err = s.client.AcceptInvite(s.ctx, &pb.AcceptInviteRequest{Token: s.token, OrgId: invite.OrgId})
+			if err != nil {
+				fatalf("Ошибка принятия приглашения: %v", err)
+			}
+			fmt.Printf("Вы вступили в %s с ролью %s\n", invite.OrgName, invite.Role)
+			return
+		}
+		fatalf("Приглашение в %s не найдено", args[0])
+	},
+}
+
+var orgRemoveCmd = &cobra.Command{
+	Use:   "remove <организация> <пользователь>",
+	Short: "Remove member from organization",
+	Long:  "Удаление участника из организации; доступные ему записи отмечаются для смены",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		resp, err := s.client.RemoveMember(s.ctx, &pb.RemoveMemberRequest{
+			Token:    s.token,
+			OrgId:    org.Id,
+			Username: args[1],
+		})
+		if err != nil {
+			fatalf("Ошибка удаления участника: %v", err)
+		}
+		fmt.Printf("Участник %s удален, записей отмечено для смены: %d\n", args[1], resp.FlaggedForRotation)
+	},
+}
+
+var orgTeamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Manage organization teams",
+	Long:  "Управление командами организации",
+}
+
+var orgTeamCreateCmd = &cobra.Command{
+	Use:   "create <организация> <команда>",
+	Short: "Create team",
+	Long:  "Создание команды организации",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		_, err = s.client.CreateTeam(s.ctx, &pb.CreateTeamRequest{Token: s.token, OrgId: org.Id, Name: args[1]})
+		if err != nil {
+			fatalf("Ошибка создания команды: %v", err)
+		}
+		fmt.Printf("Команда %s создана\n", args[1])
+	},
+}
+
+var orgTeamAddCmd = &cobra.Command{
+	Use:   "add <организация> <команда> <пользователь>",
+	Short: "Add member to team",
+	Long:  "Добавление участника организации в команду",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		_, err = s.client.AddTeamMember(s.ctx, &pb.AddTeamMemberRequest{
+			Token:    s.token,
+			OrgId:    org.Id,
+			Team:     args[1],
+			Username: args[2],
+		})
+		if err != nil {
+			fatalf("Ошибка добавления в команду: %v", err)
+		}
+		fmt.Printf("Пользователь %s добавлен в команду %s\n", args[2], args[1])
+	},
+}
+
+var orgCollectionCmd = &cobra.Command{
+	Use:   "collection",
+	Short: "Manage organization collections",
+	Long:  "Управление коллекциями записей организации",
+}
+
+var orgCollectionCreateCmd = &cobra.Command{
+	Use:   "create <организация> <коллекция>",
+	Short: "Create collection",
+	Long:  "Создание коллекции; с флагом --team коллекция доступна только участникам указанных команд",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		_, err = s.client.CreateCollection(s.ctx, &pb.CreateCollectionRequest{
+			Token: s.token,
+			OrgId: org.Id,
+			Name:  args[1],
+			Teams: collectionTeam,
+		})
+		if err != nil {
+			fatalf("Ошибка создания коллекции: %v", err)
+		}
+		fmt.Printf("Коллекция %s создана\n", args[1])
+	},
+}
+
+var orgCollectionLsCmd = &cobra.Command{
+	Use:   "ls <организация>",
+	Short: "List collections",
+	Long:  "Вывод доступных вам коллекций организации",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		resp, err := s.client.ListCollections(s.ctx, &pb.ListCollectionsRequest{Token: s.token, OrgId: org.Id})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		for _, c := range resp.Collections {
+			teams := "все участники"
+			if len(c.Teams) > 0 {
+				teams = strings.Join(c.Teams, ", ")
+			}
+			fmt.Printf("%s\t%s\n", c.Name, teams)
+		}
+	},
+}
+
+var orgPutCmd = &cobra.Command{
+	Use:   "put <идентификатор записи> <организация> <коллекция>",
+	Short: "Move credentials into organization collection",
+	Long: `Помещение записи в коллекцию организации.
+
+Ваша запись переходит во владение организации: она пропадает из личного списка,
+а её папка, отметка «избранное» и выданные доступы удаляются. Данные записи,
+зашифрованные ключом записи при выдаче доступа, передаются организации
+расшифрованными. Запись организации перемещается в другую коллекцию.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[1])
+		if err != nil {
+			fatal(err)
+		}
+
+		data, err := unsealedData(s, args[0])
+		if err != nil {
+			fatalf("Ошибка расшифровки записи: %v", err)
+		}
+
+		_, err = s.client.AddToCollection(s.ctx, &pb.AddToCollectionRequest{
+			Token:        s.token,
+			CredentialId: args[0],
+			OrgId:        org.Id,
+			Collection:   args[2],
+			Data:         data,
+		})
+		if err != nil {
+			fatalf("Ошибка перемещения записи: %v", err)
+		}
+		fmt.Println("Запись перемещена в коллекцию")
+	},
+}
+
+// unsealedData возвращает расшифрованные данные своей записи id, если они
+// зашифрованы ключом записи. Для остальных записей, в том числе записей
+// организации, возвращается пустая строка.
+func unsealedData(s *session, id string) (string, error) {
+	current, err := fetchCredential(s.ctx, s.client, s.token, id)
+	if err != nil {
+		// Записи организации нет среди личных; зашифрованную запись без данных
+		// сервер всё равно не примет
+		return "", nil
+	}
+	if _, sealed, err := records.key(id); !sealed || err != nil {
+		return "", err
+	}
+	return current.Data, nil
+}
+
+var orgSecretsCmd = &cobra.Command{
+	Use:   "secrets <организация>",
+	Short: "List organization credentials",
+	Long:  "Вывод доступных вам записей организации",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		org, err := s.organizationByName(args[0])
+		if err != nil {
+			fatal(err)
+		}
+
+		collections, err := s.client.ListCollections(s.ctx, &pb.ListCollectionsRequest{Token: s.token, OrgId: org.Id})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		names := make(map[string]string, len(collections.Collections))
+		for _, c := range collections.Collections {
+			names[c.Id] = c.Name
+		}
+
+		resp, err := s.client.ListOrgCredentials(s.ctx, &pb.ListOrgCredentialsRequest{Token: s.token, OrgId: org.Id})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		if len(resp.Credentials) == 0 {
+			fmt.Println("Записей не найдено")
+			return
+		}
+		for _, cred := range resp.Credentials {
+			rotation := ""
+			if cred.RotationRequired {
+				rotation = " (требуется смена)"
+			}
+			fmt.Printf("Коллекция: %s%s\n", names[cred.CollectionId], rotation)
+			printCredentials([]*pb.Credentials{cred.Credentials}, nil)
+		}
+	},
+}
+
+// organizations возвращает организации пользователя.
+func (s *session) organizations() ([]*pb.Organization, error) {
+	resp, err := s.client.ListOrganizations(s.ctx, &pb.ListOrganizationsRequest{Token: s.token})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Organizations, nil
+}
+
+// organizationByName находит организацию пользователя по имени.
+func (s *session) organizationByName(name string) (*pb.Organization, error) {
+	orgs, err := s.organizations()
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range orgs {
+		if org.Name == name {
+			return org, nil
+		}
+	}
+	return nil, fmt.Errorf("организация %q не найдена", name)
+}
+
+func init() {
+	rootCmd.AddCommand(orgCmd)
+	orgCmd.AddCommand(orgCreateCmd, orgLsCmd, orgMembersCmd, orgInviteCmd, orgInvitesCmd, orgAcceptCmd,
+		orgRemoveCmd, orgTeamCmd, orgCollectionCmd, orgPutCmd, orgSecretsCmd)
+	orgTeamCmd.AddCommand(orgTeamCreateCmd, orgTeamAddCmd)
+	orgCollectionCmd.AddCommand(orgCollectionCreateCmd, orgCollectionLsCmd)
+
+	// Добавляем флаги
+	orgInviteCmd.Flags().StringVar(&orgRole, "role", "member", "Роль участника (owner, admin, member, read-only)")
+	orgCollectionCreateCmd.Flags().StringSliceVar(&collectionTeam, "team", nil, "Команда с доступом к коллекции")
+}
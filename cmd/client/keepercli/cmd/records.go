@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/sharing"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// records расшифровывает данные общих записей в ответах сервера и шифрует их
+// при изменении. Данные записи шифруются ключом записи перед выдачей доступа
+// (share add), остальные записи хранятся как есть.
+var records = &recordCipher{keys: make(map[string]*recordKey), checked: make(map[string]bool)}
+
+// recordCipher хранит ключи зашифрованных записей, встреченных за время работы клиента.
+type recordCipher struct {
+	once     sync.Once
+	identity *ecdh.PrivateKey
+
+	mu sync.Mutex
+	// keys - ключи записей по идентификатору. Для зашифрованной записи без
+	// доступного ключа хранится nil, для незашифрованной ключа нет.
+	keys map[string]*recordKey
+	// checked - записи, полученные с сервера
+	checked map[string]bool
+}
+
+// recordKey - ключ зашифрованной записи.
+type recordKey struct {
+	key   []byte // Ключ записи
+	owner []byte // Ключ записи, зашифрованный для владельца, из заголовка её данных
+}
+
+// load читает секрет клиента при первом обращении. Без секрета данные общих
+// записей остаются зашифрованными.
+func (c *recordCipher) load() {
+	c.once.Do(func() {
+		secret, err := encryptionKey()
+		if err == nil {
+			c.identity, err = sharing.Identity(secret)
+		}
+		if err != nil {
+			slog.Warn("shared records will not be decrypted", slog.String("error", err.Error()))
+		}
+	})
+}
+
+// unwrap расшифровывает ключ записи wrapped закрытым ключом пользователя.
+func (c *recordCipher) unwrap(wrapped []byte) []byte {
+	if c.load(); c.identity == nil {
+		return nil
+	}
+	key, _ := sharing.Unwrap(c.identity, wrapped)
+	return key
+}
+
+// unary расшифровывает общие записи в ответах унарных вызовов и шифрует данные
+// изменяемой общей записи.
+func (c *recordCipher) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if in, ok := req.(*pb.EditCredentialsRequest); ok {
+		sealed, err := c.sealEdit(ctx, cc, in)
+		if err != nil {
+			return err
+		}
+		req = sealed
+	}
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return err
+	}
+	if m, ok := reply.(proto.Message); ok {
+		c.open(m.ProtoReflect())
+	}
+	return nil
+}
+
+// stream расшифровывает общие записи в сообщениях потоков сервера.
+func (c *recordCipher) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &openStream{ClientStream: s, records: c}, nil
+}
+
+// openStream расшифровывает общие записи в каждом полученном сообщении.
+type openStream struct {
+	grpc.ClientStream
+	records *recordCipher
+}
+
+func (s *openStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(proto.Message); ok {
+		s.records.open(msg.ProtoReflect())
+	}
+	return nil
+}
+
+// open расшифровывает данные всех записей в сообщении m.
+func (c *recordCipher) open(m protoreflect.Message) {
+	switch v := m.Interface().(type) {
+	case *pb.SharedCredentials:
+		// Ключ чужой записи передан зашифрованным на открытом ключе пользователя
+		c.openCredentials(v.Credentials, c.unwrap(v.EncryptedKey))
+		return
+	case *pb.Credentials:
+		// Ключ своей записи хранится в заголовке её данных
+		var key []byte
+		if owner, err := sharing.OwnerKey(v.Data); err == nil {
+			key = c.unwrap(owner)
+		}
+		c.openCredentials(v, key)
+		return
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				c.open(v.List().Get(i).Message())
+			}
+		default:
+			c.open(v.Message())
+		}
+		return true
+	})
+}
+
+// openCredentials расшифровывает данные записи cred ключом key и запоминает ключ
+// для её изменения. Если ключ не подходит, данные остаются зашифрованными.
+func (c *recordCipher) openCredentials(cred *pb.Credentials, key []byte) {
+	if cred == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked[cred.Id] = true
+	if !sharing.Sealed(cred.Data) {
+		delete(c.keys, cred.Id)
+		return
+	}
+
+	owner, _ := sharing.OwnerKey(cred.Data)
+	data, err := sharing.Open(key, cred.Id, cred.Data)
+	if err != nil {
+		c.keys[cred.Id] = nil
+		return
+	}
+	cred.Data = data
+	c.keys[cred.Id] = &recordKey{key: key, owner: owner}
+}
+
+// key возвращает ключ полученной ранее записи id и признак того, что её данные
+// зашифрованы. Для зашифрованной записи без доступного ключа возвращается errSealedKey.
+func (c *recordCipher) key(id string) (*recordKey, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[id]
+	if ok && key == nil {
+		return nil, true, errSealedKey
+	}
+	return key, ok, nil
+}
+
+// errSealedKey - данные общей записи нельзя изменить без её ключа.
+var errSealedKey = errors.New("данные записи зашифрованы, а её ключ недоступен: проверьте security.encryption_key")
+
+// sealEdit возвращает запрос изменения, в котором новые данные общей записи
+// зашифрованы её ключом. Если запись ещё не встречалась, она запрашивается среди
+// своих записей, а затем среди общих.
+func (c *recordCipher) sealEdit(ctx context.Context, cc *grpc.ClientConn, in *pb.EditCredentialsRequest) (*pb.EditCredentialsRequest, error) {
+	data := in.GetCredentials().GetData()
+	if data == "" || sharing.Sealed(data) {
+		return in, nil
+	}
+
+	c.mu.Lock()
+	checked := c.checked[in.Id]
+	c.mu.Unlock()
+	if !checked {
+		own := &pb.GetCredentialsResponse{}
+		err := cc.Invoke(ctx, pb.Keeper_GetCredentials_FullMethodName,
+			&pb.GetCredentialsRequest{Token: in.Token, Id: in.Id, PageSize: 1}, own)
+		if err != nil || len(own.Credentials) == 0 {
+			shared := &pb.ListSharedWithMeResponse{}
+			if err = cc.Invoke(ctx, pb.Keeper_ListSharedWithMe_FullMethodName,
+				&pb.ListSharedWithMeRequest{Token: in.Token}, shared); err != nil {
+				return nil, fmt.Errorf("не удалось проверить шифрование записи: %w", err)
+			}
+		}
+	}
+
+	key, ok, err := c.key(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return in, nil
+	}
+	sealed, err := sharing.Seal(key.key, key.owner, in.Id, data)
+	if err != nil {
+		return nil, err
+	}
+	out := proto.Clone(in).(*pb.EditCredentialsRequest)
+	out.Credentials.Data = sealed
+	return out, nil
+}
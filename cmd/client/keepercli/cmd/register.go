@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
 	"strings"
 	"time"
 
 	pb "github.com/sol1corejz/goph-keeper/proto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 
 	"github.com/spf13/cobra"
@@ -26,9 +26,11 @@ var registerCmd = &cobra.Command{
 	Short: "Регистрация нового пользователя через gRPC",
 	Run: func(cmd *cobra.Command, args []string) {
 		// Устанавливаем соединение с gRPC сервером
-		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(requestLogger),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 		if err != nil {
-			log.Fatalf("Ошибка подключения к gRPC: %v", err)
+			fatalf("Ошибка подключения к gRPC: %v", err)
 		}
 		defer conn.Close()
 
@@ -36,30 +38,39 @@ var registerCmd = &cobra.Command{
 		client := pb.NewKeeperClient(conn)
 
 		// Формируем контекст с таймаутом
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		ctx, cancel := context.WithTimeout(commandContext(), time.Second*5)
 		defer cancel()
 
 		userData := &pb.User{
 			Username: username,
 			Password: password,
 		}
+
+		// Открытый ключ сохраняется вместе с пользователем, чтобы другие
+		// пользователи сразу могли выдавать доступ к записям
+		key, err := publicKey()
+		if err != nil {
+			fmt.Printf("Предупреждение: открытый ключ не будет опубликован, доступ к записям вам выдать не смогут: %v\n", err)
+		}
+
 		// Отправляем запрос
 		resp, err := client.Register(ctx, &pb.RegisterRequest{
-			UserData: userData,
+			UserData:  userData,
+			PublicKey: key,
 		})
 		if err != nil {
 			if err != nil && strings.Contains(err.Error(), "already exists") {
 				fmt.Println("Пользователь уже зарегестрирован!")
 				return
 			}
-			log.Fatalf("Ошибка регистрации: %v", err)
+			fatalf("Ошибка регистрации: %v", err)
 			return
 		}
 
 		// Сохраняем токен
 		err = SaveTokenToFile(resp.Token)
 		if err != nil {
-			log.Fatalf("Ошибка сохранения токена: %v", err)
+			fatalf("Ошибка сохранения токена: %v", err)
 		}
 
 		// Выводим ответ
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+)
+
+// Флаги командной строки
+var (
+	restorePassphrase string
+	restoreMerge      string
+	restoreDryRun     bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <архив>",
+	Short: "Restore the vault from an encrypted archive",
+	Long: `Восстановление записей и папок из архива, созданного командой export.
+
+Архив можно восстановить в пустой или существующий аккаунт. Запись архива
+совпадает с записью аккаунта с тем же идентификатором, а при восстановлении
+в другой аккаунт - с записью того же типа с тем же сайтом и логином.
+Правило объединения (--merge):
+  skip       совпадающие записи не изменяются (по умолчанию)
+  overwrite  данные и метаданные совпадающих записей заменяются данными архива
+  duplicate  все записи архива добавляются как новые`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains(backup.MergeModes, restoreMerge) {
+			fatalf("Неизвестное правило %q, допустимы: %s", restoreMerge, strings.Join(backup.MergeModes, ", "))
+		}
+		passphrase := backupPassphrase(restorePassphrase)
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			fatalf("Ошибка открытия архива: %v", err)
+		}
+		vault, header, err := backup.Open(f, passphrase)
+		f.Close()
+		if errors.Is(err, backup.ErrDecrypt) {
+			fatal("Неверная парольная фраза или архив поврежден")
+		}
+		if err != nil {
+			fatalf("Ошибка чтения архива: %v", err)
+		}
+		fmt.Printf("Архив от %s: записей %d, папок %d\n", header.CreatedAt.Local().Format("2006-01-02 15:04"),
+			len(vault.Entries), len(vault.Folders))
+
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+		ctx, cancel := context.WithTimeout(commandContext(), importTimeout)
+		defer cancel()
+
+		creds, err := listAllCredentials(ctx, s.client, s.token)
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+		existing := make([]backup.Existing, 0, len(creds))
+		for _, c := range creds {
+			existing = append(existing, backup.Existing{ID: c.Id, Type: c.Type, Data: c.Data, Meta: c.Meta, Tags: c.Tags})
+		}
+
+		plan, err := backup.Merge(vault, existing, restoreMerge)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Printf("Будет добавлено: %d, изменено: %d, пропущено: %d\n", len(plan.Add), len(plan.Update), len(plan.Skip))
+		if restoreDryRun {
+			return
+		}
+
+		indexer, err := newIndexer()
+		if err != nil {
+			fmt.Printf("Предупреждение: записи не будут проиндексированы для поиска: %v\n", err)
+		}
+
+		// Папки новых записей сервер создает в той же транзакции, что и записи,
+		// поэтому при ошибке не остается ни записей, ни папок
+		items := make([]*pb.NewCredentials, 0, len(plan.Add))
+		for _, e := range plan.Add {
+			items = append(items, &pb.NewCredentials{
+				Credentials: &pb.Credentials{
+					Type:     e.Type,
+					Data:     e.Data,
+					Meta:     e.Meta,
+					Tags:     e.Tags,
+					Favorite: e.Favorite,
+				},
+				FolderPath:  e.Folder,
+				SearchTerms: indexTerms(indexer, e.Data, e.Meta, e.Tags),
+			})
+		}
+		ids, err := addCredentials(ctx, s.client, s.token, items)
+		if err != nil {
+			fatalf("Ошибка восстановления, записи и папки не добавлены: %v", err)
+		}
+		added := len(ids)
+
+		// Пустые папки архива создаются только после сохранения записей
+		if _, err = ensureFolders(ctx, s.client, s.token, vault.Folders); err != nil {
+			fatalf("Ошибка создания пустых папок (добавлено записей: %d): %v", added, err)
+		}
+
+		for i, u := range plan.Update {
+			_, err = s.client.EditCredentials(ctx, &pb.EditCredentialsRequest{
+				Token:       s.token,
+				Id:          u.ID,
+				Credentials: &pb.Credentials{Data: u.Entry.Data, Meta: u.Entry.Meta},
+				SearchTerms: indexTerms(indexer, u.Entry.Data, u.Entry.Meta, u.Tags),
+			})
+			if err != nil {
+				fatalf("Ошибка изменения записи %s (добавлено: %d, изменено: %d): %v", u.ID, added, i, err)
+			}
+		}
+		fmt.Printf("Добавлено записей: %d, изменено: %d\n", added, len(plan.Update))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	// Добавляем флаги
+	restoreCmd.Flags().StringVar(&restorePassphrase, "passphrase", "", "Парольная фраза архива (или "+passphraseEnv+")")
+	restoreCmd.Flags().StringVar(&restoreMerge, "merge", backup.MergeSkip, "Правило объединения: "+strings.Join(backup.MergeModes, ", "))
+	restoreCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "Только показать, что будет восстановлено")
+}
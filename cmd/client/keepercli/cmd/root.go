@@ -19,6 +19,14 @@ examples and usage of using your application. For example:
 Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupLogging()
+		setupTracing(cmd)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		endTracing()
+		closeLogging()
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -38,7 +46,7 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.client.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "configs/client_config.yaml", "Путь к конфигурационному файлу клиента")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
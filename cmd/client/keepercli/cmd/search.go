@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Флаги командной строки
+var searchLimit int32
+
+var searchCmd = &cobra.Command{
+	Use:   "search <запрос>",
+	Short: "Search credentials",
+	Long: `Поиск данных пользователя по сайту, логину и тегам.
+
+Запрос не передается на сервер в открытом виде: клиент вычисляет HMAC слов запроса
+на ключе из конфигурации (security.encryption_key), сервер возвращает записи
+с совпавшими терминами, а окончательное ранжирование выполняется локально.
+Поддерживается поиск по началу слова (не короче 3 символов).`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+
+		// Вычисляем термины запроса
+		indexer, err := newIndexer()
+		if err != nil {
+			fatalf("Ошибка подготовки поиска: %v", err)
+		}
+		terms := indexer.QueryTerms(query)
+		if len(terms) == 0 {
+			fmt.Println("Пустой поисковый запрос")
+			return
+		}
+
+		// Устанавливаем соединение с gRPC сервером
+		conn, err := grpc.NewClient("localhost:3200", grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(requestLogger, records.unary),
+			grpc.WithStreamInterceptor(records.stream),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+		if err != nil {
+			fatalf("Ошибка подключения к gRPC: %v", err)
+		}
+		defer conn.Close()
+
+		// Создаем клиента
+		client := pb.NewKeeperClient(conn)
+
+		// Формируем контекст с таймаутом
+		ctx, cancel := context.WithTimeout(commandContext(), time.Second*5)
+		defer cancel()
+
+		// Получение токена авторизации
+		token, err := ReadTokenFromFile()
+		if err != nil {
+			fatalf("Ошибка получения токена: %v", err)
+		}
+
+		resp, err := client.SearchCredentials(ctx, &pb.SearchCredentialsRequest{
+			Token: token,
+			Terms: terms,
+			Limit: searchLimit,
+		})
+		if err != nil {
+			fatalf("Ошибка поиска: %v", err)
+		}
+
+		// Ранжируем результаты по открытым значениям полей
+		type result struct {
+			credential *pb.Credentials
+			score      int
+		}
+		results := make([]result, 0, len(resp.Credentials))
+		for _, c := range resp.Credentials {
+			score := search.Score(query, search.ExtractFields(c.Data, c.Meta, c.Tags))
+			if score == 0 {
+				continue
+			}
+			results = append(results, result{credential: c, score: score})
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].score > results[j].score
+		})
+
+		credentials := make([]*pb.Credentials, 0, len(results))
+		for _, r := range results {
+			credentials = append(credentials, r.credential)
+		}
+
+		// Выводим ответ
+		fmt.Printf("Найдено записей: %d\n", len(credentials))
+		printCredentials(credentials, folderPaths(ctx, client, token))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	// Добавляем флаги
+	searchCmd.Flags().Int32VarP(&searchLimit, "limit", "l", 50, "Максимальное количество записей, запрашиваемых у сервера")
+}
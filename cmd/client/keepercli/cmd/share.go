@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/sol1corejz/goph-keeper/internal/client/sharing"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// Флаги командной строки
+var shareWrite bool
+
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Share credentials with other users",
+	Long:  "Управление доступом других пользователей к записям",
+}
+
+var shareAddCmd = &cobra.Command{
+	Use:   "add <идентификатор записи> <пользователь>",
+	Short: "Grant access to credentials",
+	Long: `Выдача пользователю доступа к записи на чтение; с флагом --write — на чтение и изменение.
+
+Перед первой выдачей доступа данные записи шифруются случайным ключом записи,
+поэтому сервер хранит их в виде шифртекста. Ключ записи шифруется на вашем
+открытом ключе и на открытом ключе получателя, поэтому получатель должен заранее
+опубликовать свой ключ командой share key (это делает и команда login).`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		permission := "read"
+		if shareWrite {
+			permission = "write"
+		}
+
+		// Ключ записи шифруется на открытом ключе получателя
+		keyResp, err := s.client.GetPublicKey(s.ctx, &pb.GetPublicKeyRequest{Token: s.token, Username: args[1]})
+		if status.Code(err) == codes.FailedPrecondition {
+			fatalf("Пользователь %s не опубликовал открытый ключ: ему нужно выполнить keepercli share key", args[1])
+		}
+		if err != nil {
+			fatalf("Ошибка получения открытого ключа: %v", err)
+		}
+
+		// Данные записи шифруются ключом записи до выдачи доступа
+		key, err := sealCredential(s, args[0])
+		if err != nil {
+			fatalf("Ошибка шифрования записи: %v", err)
+		}
+		wrapped, err := sharing.Wrap(keyResp.PublicKey, key)
+		if err != nil {
+			fatalf("Ошибка шифрования ключа записи: %v", err)
+		}
+
+		_, err = s.client.ShareCredential(s.ctx, &pb.ShareCredentialRequest{
+			Token:        s.token,
+			CredentialId: args[0],
+			Username:     args[1],
+			Permission:   permission,
+			EncryptedKey: wrapped,
+		})
+		if err != nil {
+			fatalf("Ошибка выдачи доступа: %v", err)
+		}
+		fmt.Printf("Пользователю %s выдан доступ (%s)\n", args[1], permission)
+	},
+}
+
+// sealCredential возвращает ключ своей записи id. Если данные записи ещё не
+// зашифрованы, они шифруются новым ключом записи, а индекс поиска пересчитывается
+// по открытым данным.
+func sealCredential(s *session, id string) ([]byte, error) {
+	current, err := fetchCredential(s.ctx, s.client, s.token, id)
+	if err != nil {
+		return nil, err
+	}
+	key, sealed, err := records.key(id)
+	if err != nil {
+		return nil, err
+	}
+	if sealed {
+		return key.key, nil
+	}
+
+	newKey, data, err := sealWithNewKey(id, current.Data)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.client.EditCredentials(s.ctx, &pb.EditCredentialsRequest{
+		Token:       s.token,
+		Id:          id,
+		Credentials: &pb.Credentials{Data: data, Meta: current.Meta},
+		SearchTerms: searchTerms(current.Data, current.Meta, current.Tags),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newKey, nil
+}
+
+// sealWithNewKey шифрует данные своей записи id новым ключом записи. Ключ,
+// зашифрованный на открытом ключе пользователя, хранится в заголовке данных.
+func sealWithNewKey(id, data string) ([]byte, string, error) {
+	pub, err := publicKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := sharing.NewKey()
+	if err != nil {
+		return nil, "", err
+	}
+	owner, err := sharing.Wrap(pub, key)
+	if err != nil {
+		return nil, "", err
+	}
+	sealed, err := sharing.Seal(key, owner, id, data)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, sealed, nil
+}
+
+var shareRmCmd = &cobra.Command{
+	Use:   "rm <идентификатор записи> <пользователь>",
+	Short: "Revoke access to credentials",
+	Long: `Отзыв доступа пользователя к записи.
+
+Зашифрованные данные записи одновременно шифруются новым ключом записи, который
+передается только оставшимся получателям доступа, поэтому прежний ключ,
+сохраненный отозванным пользователем, новые данные не открывает.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		req := &pb.RevokeShareRequest{
+			Token:        s.token,
+			CredentialId: args[0],
+			Username:     args[1],
+		}
+		if err = rekeyCredential(s, req); err != nil {
+			fatalf("Ошибка смены ключа записи: %v", err)
+		}
+
+		_, err = s.client.RevokeShare(s.ctx, req)
+		if err != nil {
+			fatalf("Ошибка отзыва доступа: %v", err)
+		}
+		fmt.Printf("Доступ пользователя %s отозван\n", args[1])
+	},
+}
+
+// rekeyCredential дополняет запрос отзыва доступа req данными записи, зашифрованными
+// новым ключом записи, и этим ключом, зашифрованным для остальных получателей.
+// Незашифрованная запись отзывается без смены ключа.
+func rekeyCredential(s *session, req *pb.RevokeShareRequest) error {
+	current, err := fetchCredential(s.ctx, s.client, s.token, req.CredentialId)
+	if err != nil {
+		return err
+	}
+	if _, sealed, err := records.key(req.CredentialId); !sealed || err != nil {
+		return err
+	}
+
+	shares, err := s.client.ListShares(s.ctx, &pb.ListSharesRequest{Token: s.token, CredentialId: req.CredentialId})
+	if err != nil {
+		return err
+	}
+	key, data, err := sealWithNewKey(req.CredentialId, current.Data)
+	if err != nil {
+		return err
+	}
+
+	req.Data = data
+	req.EncryptedKeys = make(map[string][]byte, len(shares.Shares))
+	for _, share := range shares.Shares {
+		if share.Username == req.Username {
+			continue
+		}
+		keyResp, err := s.client.GetPublicKey(s.ctx, &pb.GetPublicKeyRequest{Token: s.token, Username: share.Username})
+		if err != nil {
+			return fmt.Errorf("открытый ключ пользователя %s: %w", share.Username, err)
+		}
+		if req.EncryptedKeys[share.Username], err = sharing.Wrap(keyResp.PublicKey, key); err != nil {
+			return fmt.Errorf("ключ записи для пользователя %s: %w", share.Username, err)
+		}
+	}
+	return nil
+}
+
+var shareLsCmd = &cobra.Command{
+	Use:   "ls <идентификатор записи>",
+	Short: "List users with access to credentials",
+	Long:  "Вывод пользователей, которым выдан доступ к записи",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := s.client.ListShares(s.ctx, &pb.ListSharesRequest{Token: s.token, CredentialId: args[0]})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+
+		if len(resp.Shares) == 0 {
+			fmt.Println("Доступ никому не выдан")
+			return
+		}
+		for _, share := range resp.Shares {
+			fmt.Printf("%s\t%s\t%s\n", share.Username, share.Permission,
+				share.CreatedAt.AsTime().Local().Format(time.DateTime))
+		}
+	},
+}
+
+var shareKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Publish my public key",
+	Long: `Публикация открытого ключа, на котором другие пользователи шифруют ключи
+записей при выдаче вам доступа. Ключ выводится из security.encryption_key
+конфигурации клиента; закрытый ключ на сервер не передается.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		if err = publishKey(s.ctx, s.client, s.token); err != nil {
+			fatalf("Ошибка публикации ключа: %v", err)
+		}
+		fmt.Println("Открытый ключ опубликован")
+	},
+}
+
+// publishKey публикует открытый ключ пользователя, выведенный из security.encryption_key.
+func publishKey(ctx context.Context, client pb.KeeperClient, token string) error {
+	key, err := publicKey()
+	if err != nil {
+		return err
+	}
+	_, err = client.SetPublicKey(ctx, &pb.SetPublicKeyRequest{Token: token, PublicKey: key})
+	return err
+}
+
+// publicKey возвращает открытый ключ пользователя, выведенный из security.encryption_key.
+func publicKey() ([]byte, error) {
+	secret, err := encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := sharing.Identity(secret)
+	if err != nil {
+		return nil, err
+	}
+	return identity.PublicKey().Bytes(), nil
+}
+
+var sharedCmd = &cobra.Command{
+	Use:   "shared",
+	Short: "List credentials shared with me",
+	Long:  "Вывод записей других пользователей, к которым вам выдан доступ",
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newSession()
+		if err != nil {
+			fatal(err)
+		}
+		defer s.Close()
+
+		resp, err := s.client.ListSharedWithMe(s.ctx, &pb.ListSharedWithMeRequest{Token: s.token})
+		if err != nil {
+			fatalf("Ошибка получения данных: %v", err)
+		}
+
+		if len(resp.Credentials) == 0 {
+			fmt.Println("Записей не найдено")
+			return
+		}
+
+		// Данные записей расшифрованы при получении ключами, которые расшифровываются
+		// закрытым ключом пользователя (records)
+		if _, err = encryptionKey(); err != nil {
+			fmt.Printf("Предупреждение: записи не будут расшифрованы: %v\n", err)
+		}
+
+		for _, shared := range resp.Credentials {
+			fmt.Printf("Владелец: %s (%s)\n", shared.Owner, shared.Permission)
+			if sharing.Sealed(shared.Credentials.GetData()) {
+				fmt.Println("Запись не расшифрована: доступ выдан на другой открытый ключ, попросите владельца выдать его повторно")
+			}
+			printCredentials([]*pb.Credentials{shared.Credentials}, nil)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd, sharedCmd)
+	shareCmd.AddCommand(shareAddCmd, shareRmCmd, shareLsCmd, shareKeyCmd)
+
+	// Добавляем флаги
+	shareAddCmd.Flags().BoolVar(&shareWrite, "write", false, "Разрешить изменение записи")
+}
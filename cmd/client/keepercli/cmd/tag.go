@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags",
+	Long:  "Управление тегами записи",
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <идентификатор записи> <тег>...",
+	Short: "Add tags",
+	Long:  "Добавление тегов к записи",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateTags(args[0], args[1:], nil)
+		fmt.Println("Теги добавлены")
+	},
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:   "rm <идентификатор записи> <тег>...",
+	Short: "Remove tags",
+	Long:  "Удаление тегов записи",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		updateTags(args[0], nil, args[1:])
+		fmt.Println("Теги удалены")
+	},
+}
+
+// updateTags изменяет теги записи и перестраивает её слепой индекс,
+// так как теги входят в индексируемые поля.
+func updateTags(id string, add, remove []string) {
+	s, err := newSession()
+	if err != nil {
+		fatal(err)
+	}
+	defer s.Close()
+
+	current, err := fetchCredential(s.ctx, s.client, s.token, id)
+	if err != nil {
+		fatalf("Ошибка получения данных: %v", err)
+	}
+
+	// Итоговый набор тегов для индекса
+	removed := make(map[string]bool, len(remove))
+	for _, t := range remove {
+		removed[strings.TrimSpace(t)] = true
+	}
+	tags := make([]string, 0, len(current.Tags)+len(add))
+	for _, t := range current.Tags {
+		if !removed[t] {
+			tags = append(tags, t)
+		}
+	}
+	tags = append(tags, add...)
+
+	_, err = s.client.UpdateTags(s.ctx, &pb.UpdateTagsRequest{
+		Token:        s.token,
+		CredentialId: id,
+		Add:          add,
+		Remove:       remove,
+		SearchTerms:  searchTerms(current.Data, current.Meta, tags),
+	})
+	if err != nil {
+		fatalf("Ошибка обновления тегов: %v", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd, tagRmCmd)
+}
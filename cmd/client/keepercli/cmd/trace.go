@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/sol1corejz/goph-keeper/internal/tracing"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// commandCtx - контекст выполняемой команды с ее корневым участком трассировки.
+	commandCtx = context.Background()
+
+	// commandSpan - корневой участок трассировки выполняемой команды.
+	commandSpan trace.Span
+)
+
+// setupTracing начинает трассу для команды cmd. Участки клиента не экспортируются:
+// контекст трассы передается серверу в заголовке traceparent каждого вызова,
+// поэтому все вызовы одной команды попадают на сервере в одну трассу, а ее
+// идентификатор записывается в логи клиента.
+func setupTracing(cmd *cobra.Command) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	provider := tracing.NewProvider(tracing.Options{ServiceName: "keepercli"})
+	otel.SetTracerProvider(provider)
+
+	commandCtx, commandSpan = provider.Tracer("keepercli").Start(context.Background(), cmd.CommandPath())
+}
+
+// commandContext возвращает контекст выполняемой команды для вызовов сервера.
+func commandContext() context.Context {
+	return commandCtx
+}
+
+// endTracing завершает корневой участок трассировки команды.
+func endTracing() {
+	if commandSpan != nil {
+		commandSpan.End()
+	}
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	"github.com/sol1corejz/goph-keeper/internal/client/tui"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Флаги командной строки
+var (
+	tuiArchive        string
+	tuiCache          string
+	tuiOffline        bool
+	tuiPassphrase     string
+	tuiClearClipboard time.Duration
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Interactive terminal UI",
+	Long: `Полноэкранный интерфейс для просмотра и изменения записей.
+
+Слева - дерево папок, в центре - список записей выбранной папки, справа -
+карточка записи. Пароли и другие секреты скрыты, пока их не показать клавишей r;
+клавиша c копирует пароль (код TOTP для записей otp) в буфер обмена, u - логин.
+Буфер очищается через --clear-clipboard, если в нем осталось скопированное значение.
+Клавиша / включает нечеткий поиск по названию, логину, тегам и папке записей,
+n и e открывают форму новой записи и изменения выбранной, f - избранное.
+
+После каждой загрузки записей с сервера интерфейс сохраняет их в локальный кэш
+(--cache), зашифрованный ключом security.encryption_key из конфигурации клиента
+так же, как архив export. Если сервер недоступен или указан флаг --offline,
+записи открываются только для просмотра из архива --archive, а без него - из кэша.
+Парольная фраза архива задается флагом --passphrase или переменной окружения
+` + passphraseEnv + `.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		backend := &vaultBackend{archive: tuiArchive, offline: tuiOffline}
+		if tuiArchive != "" {
+			backend.passphrase = backupPassphrase(tuiPassphrase)
+		}
+		if tuiCache != "" {
+			key, err := encryptionKey()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Предупреждение: локальный кэш записей отключен: %v\n", err)
+			} else {
+				backend.cache, backend.cacheKey = tuiCache, key
+			}
+		}
+		if tuiOffline && tuiArchive == "" && backend.cache == "" {
+			fatal("Для работы без подключения укажите архив флагом --archive или настройте локальный кэш")
+		}
+
+		if !tuiOffline {
+			s, err := newSession()
+			if err != nil {
+				fatal(err)
+			}
+			defer s.Close()
+			backend.client, backend.token = s.client, s.token
+
+			indexer, err := newIndexer()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Предупреждение: записи не будут проиндексированы для поиска: %v\n", err)
+			}
+			backend.indexer = indexer
+		}
+
+		err := tui.Run(commandContext(), backend, tui.Options{
+			Clipboard:        systemClipboard{},
+			ClipboardTimeout: tuiClearClipboard,
+			Timeout:          importTimeout,
+		})
+		if err != nil {
+			fatalf("Ошибка интерфейса: %v", err)
+		}
+	},
+}
+
+// vaultBackend загружает и сохраняет записи на сервере, а при недоступности
+// сервера читает записи из архива или локального кэша.
+type vaultBackend struct {
+	client  pb.KeeperClient
+	token   string
+	indexer *search.Indexer
+
+	archive    string
+	passphrase string
+	cache      string // файл локального кэша; пусто - кэш отключен
+	cacheKey   string
+	offline    bool
+}
+
+// Load получает записи с сервера и обновляет локальный кэш. Если сервер недоступен,
+// записи читаются из архива, а без него - из кэша.
+func (b *vaultBackend) Load(ctx context.Context) (backup.Vault, error) {
+	if !b.offline {
+		v, err := fetchVault(ctx, b.client, b.token)
+		if err == nil {
+			// Ошибка кэша не мешает работе с сервером; вывод в терминал испортил бы экран
+			if err := b.saveCache(v); err != nil {
+				slog.Warn("failed to update vault cache", "path", b.cache, "error", err)
+			}
+			return v, nil
+		}
+		if (b.archive == "" && b.cache == "") || !unavailable(err) {
+			return v, err
+		}
+		b.offline = true
+	}
+
+	if b.archive != "" {
+		return openVault(b.archive, b.passphrase, "неверная парольная фраза или архив поврежден")
+	}
+	v, err := openVault(b.cache, b.cacheKey, "кэш записей поврежден или зашифрован другим ключом")
+	if errors.Is(err, os.ErrNotExist) {
+		return v, errors.New("локальный кэш записей еще не создан: запустите интерфейс с подключением к серверу")
+	}
+	return v, err
+}
+
+// saveCache заменяет локальный кэш записями v. Файл записывается целиком во временный
+// файл рядом с кэшем и переименовывается, поэтому прерванная запись не портит кэш.
+func (b *vaultBackend) saveCache(v backup.Vault) error {
+	if b.cache == "" {
+		return nil
+	}
+	dir := filepath.Dir(b.cache)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	// CreateTemp создает файл с правами 0600
+	f, err := os.CreateTemp(dir, filepath.Base(b.cache)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := backup.Seal(f, v, b.cacheKey, backup.DefaultKDF()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), b.cache)
+}
+
+// openVault читает архив path и расшифровывает его ключом passphrase. При неверном
+// ключе возвращается ошибка с сообщением decryptErr.
+func openVault(path, passphrase, decryptErr string) (backup.Vault, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return backup.Vault{}, err
+	}
+	defer f.Close()
+	v, _, err := backup.Open(f, passphrase)
+	if errors.Is(err, backup.ErrDecrypt) {
+		return v, errors.New(decryptErr)
+	}
+	return v, err
+}
+
+// Save добавляет или изменяет запись на сервере. Изменение отправляет только
+// измененные части записи: данные, теги, папку и отметку «избранное».
+func (b *vaultBackend) Save(ctx context.Context, e, prev backup.Entry) (backup.Entry, error) {
+	if b.offline {
+		return e, tui.ErrReadOnly
+	}
+	folders, err := ensureFolders(ctx, b.client, b.token, []string{e.Folder})
+	if err != nil {
+		return e, err
+	}
+	terms := indexTerms(b.indexer, e.Data, e.Meta, e.Tags)
+	now := time.Now()
+
+	if e.ID == "" {
+		// Пакетный метод возвращает идентификатор новой записи
+		ids, err := addCredentials(ctx, b.client, b.token, []*pb.NewCredentials{{
+			Credentials: &pb.Credentials{
+				Type:     e.Type,
+				Data:     e.Data,
+				Meta:     e.Meta,
+				Tags:     e.Tags,
+				FolderId: folders[e.Folder],
+				Favorite: e.Favorite,
+			},
+			SearchTerms: terms,
+		}})
+		if err != nil {
+			return e, err
+		}
+		if len(ids) != 1 {
+			return e, errors.New("сервер не вернул идентификатор записи")
+		}
+		e.ID, e.CreatedAt, e.UpdatedAt = ids[0], now, now
+		return e, nil
+	}
+
+	if e.Data != prev.Data || e.Meta != prev.Meta {
+		_, err = b.client.EditCredentials(ctx, &pb.EditCredentialsRequest{
+			Token:       b.token,
+			Id:          e.ID,
+			Credentials: &pb.Credentials{Data: e.Data, Meta: e.Meta},
+			SearchTerms: terms,
+		})
+		if err != nil {
+			return e, err
+		}
+	}
+
+	var add, remove []string
+	for _, t := range e.Tags {
+		if !slices.Contains(prev.Tags, t) {
+			add = append(add, t)
+		}
+	}
+	for _, t := range prev.Tags {
+		if !slices.Contains(e.Tags, t) {
+			remove = append(remove, t)
+		}
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		_, err = b.client.UpdateTags(ctx, &pb.UpdateTagsRequest{
+			Token:        b.token,
+			CredentialId: e.ID,
+			Add:          add,
+			Remove:       remove,
+			SearchTerms:  terms,
+		})
+		if err != nil {
+			return e, err
+		}
+	}
+
+	if e.Folder != prev.Folder {
+		_, err = b.client.SetCredentialFolder(ctx, &pb.SetCredentialFolderRequest{
+			Token:        b.token,
+			CredentialId: e.ID,
+			FolderId:     folders[e.Folder],
+		})
+		if err != nil {
+			return e, err
+		}
+	}
+
+	if e.Favorite != prev.Favorite {
+		_, err = b.client.SetFavorite(ctx, &pb.SetFavoriteRequest{
+			Token:        b.token,
+			CredentialId: e.ID,
+			Favorite:     e.Favorite,
+		})
+		if err != nil {
+			return e, err
+		}
+	}
+
+	e.UpdatedAt = now
+	return e, nil
+}
+
+// Offline сообщает, что записи загружены из архива или кэша.
+func (b *vaultBackend) Offline() bool {
+	return b.offline
+}
+
+// unavailable сообщает, что сервер недоступен.
+func unavailable(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// systemClipboard - системный буфер обмена (xclip, xsel или wl-clipboard в Linux).
+type systemClipboard struct{}
+
+func (systemClipboard) ReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+func (systemClipboard) WriteAll(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// defaultCachePath возвращает путь локального кэша записей в каталоге кэша пользователя.
+// Если каталог не определен, кэш по умолчанию отключен.
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goph-keeper", "vault.cache")
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	// Добавляем флаги
+	tuiCmd.Flags().StringVar(&tuiArchive, "archive", "", "Архив export для работы без подключения к серверу")
+	tuiCmd.Flags().StringVar(&tuiCache, "cache", defaultCachePath(), "Файл локального кэша записей (пусто - не сохранять)")
+	tuiCmd.Flags().BoolVar(&tuiOffline, "offline", false, "Открыть архив --archive или кэш, не обращаясь к серверу")
+	tuiCmd.Flags().StringVar(&tuiPassphrase, "passphrase", "", "Парольная фраза архива (или "+passphraseEnv+")")
+	tuiCmd.Flags().DurationVar(&tuiClearClipboard, "clear-clipboard", 30*time.Second, "Время до очистки буфера обмена (0 - не очищать)")
+}
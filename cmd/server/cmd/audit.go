@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd объединяет команды для работы с журналом аудита.
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Работа с журналом аудита",
+}
+
+// auditVerifyCmd проверяет целостность хэш-цепочки журнала аудита без запуска серверов.
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Проверка целостности журнала аудита",
+	Long: `Проверяет хэш-цепочку журнала аудита и выводит количество записей и хэш
+последней записи. При повреждении журнала завершается с кодом 1.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		if err := initDatabase(); err != nil {
+			return err
+		}
+		defer storage.DBStorage.DB.Close()
+
+		return verifyAudit(cmd)
+	},
+}
+
+func init() {
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+// verifyAudit проверяет хэш-цепочку журнала аудита.
+func verifyAudit(cmd *cobra.Command) error {
+	v, err := storage.DBStorage.VerifyAudit(context.Background(), storage.MaxPageSize)
+	if err != nil {
+		return fmt.Errorf("журнал аудита поврежден: %w (проверено записей: %d)", err, v.Count())
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Журнал аудита цел, записей: %d, хэш последней записи: %s\n", v.Count(), v.Head())
+	return nil
+}
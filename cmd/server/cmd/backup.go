@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/backup"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/spf13/cobra"
+)
+
+// Значения флагов команд резервного копирования.
+var (
+	backupOutput   string
+	restoreAt      string
+	restoreReplace bool
+)
+
+// backupCmd снимает резервную копию хранилища.
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Резервное копирование хранилища",
+	Long: `Снимает согласованную резервную копию хранилища, сжимает ее и шифрует ключом
+backup.encryption_key. Без --output копия сохраняется в каталог backup.dir,
+после чего из него удаляются копии сверх backup.retention и старше backup.max_age.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		key, err := backupKey()
+		if err != nil {
+			return err
+		}
+		if err := initDatabase(); err != nil {
+			return err
+		}
+		defer storage.DBStorage.DB.Close()
+
+		ctx := context.Background()
+		if backupOutput == "" {
+			path, h, err := backup.WriteFile(ctx, config.Backup.Dir, &storage.DBStorage, key)
+			if err != nil {
+				return fmt.Errorf("не удалось создать резервную копию: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Резервная копия создана: %s, строк: %d\n", path, h.Rows())
+
+			removed, err := backup.Prune(config.Backup.Dir, config.Backup.Retention, config.Backup.MaxAge, time.Now())
+			for _, p := range removed {
+				fmt.Fprintf(cmd.OutOrStdout(), "Удалена устаревшая копия: %s\n", p)
+			}
+			return err
+		}
+
+		var w io.Writer = cmd.OutOrStdout()
+		if backupOutput != "-" {
+			f, err := os.OpenFile(backupOutput, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		h, err := backup.Write(ctx, w, &storage.DBStorage, key)
+		if err != nil {
+			// Недописанная копия удаляется
+			if backupOutput != "-" {
+				os.Remove(backupOutput)
+			}
+			return fmt.Errorf("не удалось создать резервную копию: %w", err)
+		}
+		if backupOutput != "-" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Резервная копия создана: %s, строк: %d\n", backupOutput, h.Rows())
+		}
+		return nil
+	},
+}
+
+// backupVerifyCmd проверяет резервную копию без подключения к базе данных.
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify [file]",
+	Short: "Проверка резервной копии",
+	Long: `Расшифровывает резервную копию, восстанавливает ее во временное хранилище
+в памяти и сверяет количество строк и контрольные суммы таблиц с заголовком копии.
+Временное хранилище проверяет строки по схеме базы данных: набор столбцов, типы
+значений, NOT NULL, ограничения CHECK, первичные, уникальные и внешние ключи, поэтому прошедшая проверку копия
+восстанавливается в базу данных этой версии сервера. Без аргумента проверяется последняя копия из каталога backup.dir. При повреждении
+копии завершается с кодом 1.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		key, err := backupKey()
+		if err != nil {
+			return err
+		}
+		path, err := backupFile(args, time.Time{})
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h, err := backup.Verify(context.Background(), f, key)
+		if errors.Is(err, backup.ErrSchema) {
+			return fmt.Errorf("резервная копия %s не соответствует схеме базы данных: %w", path, err)
+		}
+		if err != nil {
+			return fmt.Errorf("резервная копия %s повреждена: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Резервная копия %s цела, снята %s\n", path, h.CreatedAt.Format(time.RFC3339))
+		printTables(cmd.OutOrStdout(), h)
+		return nil
+	},
+}
+
+// backupListCmd выводит резервные копии каталога backup.dir.
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список резервных копий",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		files, err := backup.List(config.Backup.Dir)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ФАЙЛ\tСНЯТА\tСТРОК")
+		for _, file := range files {
+			rows := "?"
+			if f, err := os.Open(file.Path); err == nil {
+				if h, err := backup.ReadHeader(f); err == nil {
+					rows = fmt.Sprint(h.Rows())
+				}
+				f.Close()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", file.Path, file.CreatedAt.Format(time.RFC3339), rows)
+		}
+		return w.Flush()
+	},
+}
+
+// restoreCmd восстанавливает хранилище из резервной копии.
+var restoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Восстановление хранилища из резервной копии",
+	Long: `Восстанавливает хранилище из резервной копии одной транзакцией. Без аргумента
+используется последняя копия из каталога backup.dir, а с флагом --at - последняя
+копия, снятая не позже указанного времени. Точность восстановления на момент
+времени равна периоду резервного копирования backup.interval.
+
+По умолчанию хранилище должно быть пустым; флаг --replace удаляет текущие данные.
+Сервер на время восстановления нужно остановить.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var at time.Time
+		if restoreAt != "" {
+			if len(args) > 0 {
+				return errors.New("нельзя указать одновременно файл и --at")
+			}
+			var err error
+			if at, err = time.Parse(time.RFC3339, restoreAt); err != nil {
+				return fmt.Errorf("неверное время --at, ожидается RFC 3339: %w", err)
+			}
+		}
+
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		key, err := backupKey()
+		if err != nil {
+			return err
+		}
+		path, err := backupFile(args, at)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := initDatabase(); err != nil {
+			return err
+		}
+		defer storage.DBStorage.DB.Close()
+
+		h, err := backup.Restore(context.Background(), f, &storage.DBStorage, key, restoreReplace)
+		if errors.Is(err, storage.ErrNotEmpty) {
+			return errors.New("хранилище не пустое, используйте --replace для замены данных")
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось восстановить %s: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Хранилище восстановлено из %s (снята %s)\n", path, h.CreatedAt.Format(time.RFC3339))
+		printTables(cmd.OutOrStdout(), h)
+		return nil
+	},
+}
+
+func init() {
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", `Файл копии вместо каталога backup.dir ("-" - стандартный вывод)`)
+	restoreCmd.Flags().StringVar(&restoreAt, "at", "", "Восстановить последнюю копию, снятую не позже времени RFC 3339")
+	restoreCmd.Flags().BoolVar(&restoreReplace, "replace", false, "Удалить текущие данные хранилища перед восстановлением")
+
+	backupCmd.AddCommand(backupVerifyCmd)
+	backupCmd.AddCommand(backupListCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// backupKey возвращает ключ шифрования резервных копий из конфигурации.
+func backupKey() (string, error) {
+	if config.Backup.EncryptionKey == "" {
+		return "", errors.New("не задан ключ шифрования резервных копий backup.encryption_key")
+	}
+	return config.Backup.EncryptionKey, nil
+}
+
+// backupFile возвращает путь к копии из аргументов команды или последнюю копию
+// каталога backup.dir, снятую не позже at.
+func backupFile(args []string, at time.Time) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	f, err := backup.Latest(config.Backup.Dir, at)
+	if err != nil {
+		return "", fmt.Errorf("резервная копия в каталоге %s не найдена: %w", config.Backup.Dir, err)
+	}
+	return f.Path, nil
+}
+
+// backupSchedule возвращает параметры резервного копирования по расписанию.
+func backupSchedule() backup.Schedule {
+	return backup.Schedule{
+		Dir:       config.Backup.Dir,
+		Interval:  config.Backup.Interval,
+		Retention: config.Backup.Retention,
+		MaxAge:    config.Backup.MaxAge,
+		Key:       config.Backup.EncryptionKey,
+	}
+}
+
+// printTables выводит количество строк и контрольные суммы таблиц копии.
+func printTables(out io.Writer, h backup.Header) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ТАБЛИЦА\tСТРОК\tSHA-256")
+	for _, t := range h.Tables {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", t.Name, t.Rows, t.SHA256)
+	}
+	w.Flush()
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/cert"
+	"github.com/spf13/cobra"
+)
+
+// forceCert разрешает gen-cert заменить существующий сертификат.
+var forceCert bool
+
+// genCertCmd создает самоподписанный TLS-сертификат и приватный ключ.
+var genCertCmd = &cobra.Command{
+	Use:   "gen-cert",
+	Short: "Создание самоподписанного TLS-сертификата",
+	Long: fmt.Sprintf(`Создает самоподписанный TLS-сертификат и приватный ключ в файлах %s и %s.
+Существующие файлы заменяются только с флагом --force.`, cert.CertificateFilePath, cert.KeyFilePath),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cert.CertExists() && !forceCert {
+			return fmt.Errorf("сертификат %s уже существует, используйте --force для замены", cert.CertificateFilePath)
+		}
+
+		certPEM, keyPEM := cert.GenerateCert()
+		if err := cert.SaveCert(certPEM, keyPEM); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Сертификат сохранен в %s, ключ - в %s\n", cert.CertificateFilePath, cert.KeyFilePath)
+		return nil
+	},
+}
+
+func init() {
+	genCertCmd.Flags().BoolVar(&forceCert, "force", false, "Заменить существующий сертификат")
+	rootCmd.AddCommand(genCertCmd)
+}
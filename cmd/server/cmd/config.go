@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd объединяет команды для работы с конфигурацией.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Работа с конфигурацией сервера",
+}
+
+// configPrintCmd выводит итоговую конфигурацию с секретами, замененными на [REDACTED].
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Вывод итоговой конфигурации",
+	Long: `Выводит конфигурацию, с которой запустится сервер: значения из файла,
+переменных окружения GOPHKEEPER_ и флагов. Секреты заменяются на [REDACTED],
+в строке подключения к базе данных скрывается пароль.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := LoadServerConfig(cfgFile)
+		if err != nil {
+			return err
+		}
+		return cfg.WriteYAML(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd применяет миграции базы данных без запуска серверов.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Применение миграций базы данных",
+	Long: `Создает недостающие таблицы и применяет изменения схемы базы данных.
+Команда serve выполняет то же самое при запуске; migrate позволяет обновить
+схему заранее, например отдельным шагом развертывания.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		closeLog, err := setup()
+		if err != nil {
+			return err
+		}
+		defer closeLog.Close()
+
+		if err := initDatabase(); err != nil {
+			return err
+		}
+		defer storage.DBStorage.DB.Close()
+
+		fmt.Fprintln(cmd.OutOrStdout(), "Миграции применены")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	internal "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	"github.com/spf13/cobra"
+)
+
+// openapiCmd выводит документ OpenAPI REST API, который сервер отдает
+// по адресу /api/v1/openapi.json. Конфигурация для этого не нужна.
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Документ OpenAPI REST API",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(internal.OpenAPI())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+}
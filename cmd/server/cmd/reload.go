@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"github.com/sol1corejz/goph-keeper/internal/server/cert"
+)
+
+// reloadDelay - задержка перед перезагрузкой после изменения файла. Редакторы и
+// системы развертывания записывают файл в несколько шагов, и перезагрузка
+// выполняется один раз после последнего из них.
+const reloadDelay = 500 * time.Millisecond
+
+// watchReload перезагружает конфигурацию и TLS-сертификат по сигналу SIGHUP
+// и при изменении конфигурационного файла или файлов сертификата.
+// Завершается при отмене ctx.
+func watchReload(ctx context.Context, store *configs.Store, keyPair *cert.KeyPair) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Отслеживаются каталоги, а не файлы: при замене файла переименованием
+	// наблюдение за самим файлом прекращается
+	files := map[string]bool{}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("failed to watch config file, reload only on SIGHUP", "error", err)
+	} else {
+		defer watcher.Close()
+		for _, path := range []string{cfgFile, cert.CertificateFilePath, cert.KeyFilePath} {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				continue
+			}
+			files[abs] = true
+			if err := watcher.Add(filepath.Dir(abs)); err != nil {
+				slog.Error("failed to watch directory", "path", filepath.Dir(abs), "error", err)
+			}
+		}
+	}
+
+	var (
+		events      <-chan fsnotify.Event
+		watchErrors <-chan error
+	)
+	if watcher != nil {
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("received SIGHUP, reloading configuration")
+			reload(store, keyPair)
+		case event := <-events:
+			if abs, err := filepath.Abs(event.Name); err == nil && files[abs] &&
+				event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				timer.Reset(reloadDelay)
+			}
+		case err := <-watchErrors:
+			slog.Error("config file watch error", "error", err)
+		case <-timer.C:
+			slog.Info("configuration files changed, reloading configuration")
+			reload(store, keyPair)
+		}
+	}
+}
+
+// reload перечитывает конфигурацию, применяет изменения параметров, которые
+// не требуют перезапуска, и перечитывает TLS-сертификат. При ошибке продолжают
+// действовать прежние конфигурация и сертификат.
+func reload(store *configs.Store, keyPair *cert.KeyPair) {
+	if err := keyPair.Reload(); err != nil {
+		slog.Error("failed to reload TLS certificate", "error", err)
+	}
+
+	next, err := configs.LoadServerConfig(cfgFile, flagOverrides()...)
+	if err != nil {
+		slog.Error("failed to reload configuration, keeping current settings", "error", err)
+		return
+	}
+
+	result := configs.Reload(store.Load(), next)
+	if len(result.Applied) == 0 && len(result.RestartRequired) == 0 {
+		slog.Info("configuration unchanged")
+		return
+	}
+
+	if err := logging.SetLevel(result.Config.Logging.Level); err != nil {
+		slog.Error("failed to apply log level", "error", err)
+	}
+	store.Swap(result.Config)
+
+	slog.Info("configuration reloaded",
+		"applied", result.Applied,
+		"restart_required", result.RestartRequired,
+	)
+}
@@ -0,0 +1,117 @@
+// Package cmd содержит команды сервера goph-keeper: запуск серверов, применение
+// миграций, вывод версии и конфигурации, создание TLS-сертификата, проверку
+// журнала аудита, резервное копирование и восстановление хранилища.
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// DefaultConfigPath - путь к конфигурационному файлу сервера по умолчанию.
+const DefaultConfigPath = "configs/server_config.yaml"
+
+var (
+	config *configs.ServerConfig
+	once   sync.Once
+)
+
+var (
+	buildVersion = "N/A"
+	buildDate    = "N/A"
+)
+
+// Значения флагов командной строки.
+var (
+	cfgFile  string
+	httpAddr string
+	grpcAddr string
+)
+
+// rootCmd - корневая команда сервера. Без подкоманды запускает сервер, как serve.
+var rootCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Сервер goph-keeper",
+	Long: `Сервер goph-keeper хранит учетные данные пользователей и обслуживает
+клиентов по gRPC и HTTP. Без подкоманды запускает сервер, как serve.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+}
+
+// SetBuildInfo задает версию и дату сборки, которые выводят команды version и serve.
+func SetBuildInfo(version, date string) {
+	buildVersion = version
+	buildDate = date
+}
+
+// Execute выполняет команду, указанную в аргументах командной строки.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", DefaultConfigPath, "Путь к конфигурационному файлу сервера")
+	rootCmd.PersistentFlags().StringVar(&httpAddr, "http-addr", "", "Адрес HTTP-сервера (заменяет server.address)")
+	rootCmd.PersistentFlags().StringVar(&grpcAddr, "grpc-addr", "", "Адрес gRPC-сервера (заменяет grpc.address)")
+}
+
+// flagOverrides возвращает параметры конфигурации, заданные флагами командной строки.
+// Флаги имеют приоритет над конфигурационным файлом и переменными окружения.
+func flagOverrides() []configs.Override {
+	var overrides []configs.Override
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("http-addr") {
+		overrides = append(overrides, configs.Override{Key: "server.address", Value: httpAddr})
+	}
+	if flags.Changed("grpc-addr") {
+		overrides = append(overrides, configs.Override{Key: "grpc.address", Value: grpcAddr})
+	}
+	return overrides
+}
+
+// LoadServerConfig - Функция загрузки конфигурации из файла с конфигурациями
+func LoadServerConfig(filePath string) (*configs.ServerConfig, error) {
+	var err error
+	once.Do(func() {
+		config, err = configs.LoadServerConfig(filePath, flagOverrides()...)
+	})
+	return config, err
+}
+
+// setup загружает конфигурацию и настраивает логирование.
+// Возвращаемый io.Closer закрывает файл логов.
+func setup() (io.Closer, error) {
+	if _, err := LoadServerConfig(cfgFile); err != nil {
+		return nil, err
+	}
+	return initLogger()
+}
+
+// initLogger настраивает логгер по умолчанию по разделу logging конфигурации.
+func initLogger() (io.Closer, error) {
+	logger, closer, err := logging.New(logging.Options{
+		Level:      config.Logging.Level,
+		File:       config.Logging.File,
+		MaxSizeMB:  config.Logging.MaxSizeMB,
+		MaxBackups: config.Logging.MaxBackups,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closer, nil
+}
+
+// fatal записывает ошибку запуска в лог и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
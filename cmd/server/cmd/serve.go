@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/cert"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	"github.com/sol1corejz/goph-keeper/internal/server/health"
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/sol1corejz/goph-keeper/internal/tracing"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serveCmd запускает HTTP- и gRPC-серверы и сервер метрик.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Запуск сервера",
+	Long: `Запускает HTTP- и gRPC-серверы и сервер метрик и работает до получения
+сигнала SIGINT или SIGTERM. Перед запуском применяются миграции базы данных.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		serve()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	// Запуск без подкоманды сохраняет прежнее поведение и запускает сервер
+	rootCmd.Run = serveCmd.Run
+}
+
+// serve запускает серверы и ожидает сигнала завершения.
+func serve() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+
+	closeLog, err := setup()
+	if err != nil {
+		fatal("Failed to load server config", err)
+	}
+	defer closeLog.Close()
+	slog.Info("Запуск сервера", "version", buildVersion, "date", buildDate)
+
+	shutdownTracing, err := initTracing(ctx)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
+	if err := initDatabase(); err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	if err := metrics.RegisterDB(storage.DBStorage.DB); err != nil {
+		slog.Error("failed to register database metrics", "error", err)
+	}
+
+	keyPair, err := loadCertificate()
+	if err != nil {
+		fatal("Failed to load TLS certificate", err)
+	}
+
+	// Текущая конфигурация, которую обработчики читают при каждом запросе,
+	// и ее перезагрузка по SIGHUP и изменению файла
+	store := configs.NewStore(config)
+	go watchReload(ctx, store, keyPair)
+
+	checker := health.NewChecker(storage.DBStorage.DB, cert.CertificateFilePath)
+	app := setupServer(store, checker)
+
+	// Запускаем HTTP сервер в отдельной горутине
+	go startServer(app, keyPair)
+
+	// Запускаем gRPC сервер в отдельной горутине
+	grpcClosed := make(chan struct{})
+	go grpcStart(ctx, grpcClosed, store, checker)
+
+	// Запускаем сервер метрик на отдельном адресе
+	metricsClosed := make(chan struct{})
+	go metricsStart(ctx, metricsClosed)
+
+	// Резервное копирование по расписанию
+	if config.Backup.Interval > 0 {
+		go backupSchedule().Run(ctx, &storage.DBStorage)
+	}
+
+	// Ожидание сигнала завершения
+	<-sigint
+	slog.Info("Получен сигнал завершения, останавливаем серверы...")
+
+	cancel() // Отправляем сигнал завершения контексту
+
+	// Завершаем Fiber
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		slog.Error("Ошибка при завершении HTTP сервера", "error", err)
+	}
+
+	// Ждём завершения gRPC-сервера и сервера метрик
+	<-grpcClosed
+	<-metricsClosed
+	slog.Info("Сервер полностью завершён")
+}
+
+// initTracing настраивает трассировку OpenTelemetry по разделу tracing конфигурации.
+func initTracing(ctx context.Context) (tracing.Shutdown, error) {
+	return tracing.Setup(ctx, tracing.Options{
+		ServiceName:    "goph-keeper-server",
+		ServiceVersion: buildVersion,
+		Exporter:       config.Tracing.Exporter,
+		Endpoint:       config.Tracing.Endpoint,
+		Insecure:       config.Tracing.Insecure,
+		SampleRatio:    config.Tracing.SampleRatio,
+	})
+}
+
+// initDatabase подключается к базе данных и применяет миграции.
+func initDatabase() error {
+	return storage.DBStorage.ConnectDB(config)
+}
+
+func setupServer(store *configs.Store, checker *health.Checker) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  config.Server.ReadTimeout,
+		WriteTimeout: config.Server.WriteTimeout,
+		IdleTimeout:  config.Server.IdleTimeout,
+	})
+
+	// Проверки состояния регистрируются до остальных middleware, чтобы частые
+	// запросы оркестратора не попадали в журнал аудита, логи и трассы
+	app.Get("/healthz", internal.LivenessHandler)
+	app.Get("/readyz", internal.NewReadinessHandler(checker))
+
+	// Участок трассировки для каждого HTTP-запроса
+	app.Use(internal.TracingMiddleware)
+
+	// Middleware для добавления текущей конфигурации в контекст
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", store.Load())
+		return c.Next()
+	})
+
+	// Заголовки безопасности и CORS для браузерных клиентов. Предварительные
+	// запросы CORS завершаются здесь и не попадают в журналы и метрики
+	app.Use(internal.SecurityHeaders)
+	app.Use(internal.CORSMiddleware)
+
+	// Поля запроса в логах и итоговая запись о каждом HTTP-запросе
+	app.Use(internal.RequestLogger)
+
+	// Метрики HTTP-запросов
+	app.Use(internal.MetricsMiddleware)
+
+	// Журнал аудита всех HTTP-запросов
+	app.Use(internal.AuditMiddleware)
+
+	// Проверка CSRF-токена изменяющих запросов с cookie сессии
+	app.Use(internal.CSRFMiddleware)
+
+	setupRoutes(app)
+	return app
+}
+
+func setupRoutes(app *fiber.App) {
+	// REST API версии 1 и его документ OpenAPI
+	internal.RegisterAPI(app)
+
+	// Маршруты без версии сохранены для совместимости со старыми клиентами
+	app.Post("/register", internal.Deprecated("/users"), internal.RegisterHandler)
+	app.Post("/login", internal.Deprecated("/sessions"), internal.LoginHandler)
+	app.Post("/credentials", internal.Deprecated("/credentials"), internal.AddCredentials)
+	app.Post("/edit-credentials", internal.Deprecated("/credentials"), internal.EditCredentials)
+	app.Get("/credentials", internal.Deprecated("/credentials"), internal.GetCredentials)
+
+	// Все методы gRPC-сервиса Keeper по HTTP/JSON
+	app.Post("/rpc/:method", internal.RPCHandler)
+}
+
+// loadCertificate загружает TLS-сертификат HTTP-сервера, предварительно создав
+// самоподписанный сертификат, если его нет.
+func loadCertificate() (*cert.KeyPair, error) {
+	// Создание сертификата
+	if !cert.CertExists() {
+		slog.Info("Generating new TLS certificate")
+		certPEM, keyPEM := cert.GenerateCert()
+		if err := cert.SaveCert(certPEM, keyPEM); err != nil {
+			slog.Error("failed to save TLS certificate", "error", err)
+		}
+	}
+
+	slog.Info("Loading existing TLS certificate")
+	return cert.LoadKeyPair(cert.CertificateFilePath, cert.KeyFilePath)
+}
+
+// startServer запускает HTTP-сервер с TLS. Сертификат берется из keyPair
+// для каждого нового соединения, поэтому его можно заменить без перезапуска.
+func startServer(app *fiber.App, keyPair *cert.KeyPair) {
+	ln, err := net.Listen("tcp", config.Server.Address)
+	if err != nil {
+		fatal("Ошибка запуска HTTP сервера", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: keyPair.GetCertificate,
+	}
+	if err := app.Listener(tls.NewListener(ln, tlsConfig)); err != nil {
+		fatal("Ошибка запуска HTTP сервера", err)
+	}
+}
+
+func grpcStart(ctx context.Context, closed chan struct{}, store *configs.Store, checker *health.Checker) {
+	listen, err := net.Listen("tcp", config.GRPC.Address)
+	if err != nil {
+		slog.Error("Ошибка при запуске gRPC сервера", "error", err)
+		close(closed)
+		return
+	}
+
+	keeper := &internal.KeeperServer{Config: config, Store: store}
+	opts := append(grpcServerOptions(),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			keeper.TokenUnaryInterceptor,
+			keeper.LoggingUnaryInterceptor,
+			keeper.MetricsUnaryInterceptor,
+			keeper.AuditUnaryInterceptor,
+			keeper.StatusUnaryInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			keeper.TokenStreamInterceptor,
+			keeper.LoggingStreamInterceptor,
+			keeper.MetricsStreamInterceptor,
+			keeper.AuditStreamInterceptor,
+			keeper.StatusStreamInterceptor,
+		),
+	)
+	s := grpc.NewServer(opts...)
+	pb.RegisterKeeperServer(s, keeper)
+
+	// Статус grpc.health.v1 обновляется по доступности базы данных и сертификата
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	go checker.Watch(ctx, healthServer, health.DefaultInterval, "", pb.Keeper_ServiceDesc.ServiceName)
+
+	if config.GRPC.Reflection {
+		reflection.Register(s)
+	}
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("Останавливаем gRPC сервер...")
+		healthServer.Shutdown()
+		s.GracefulStop()
+		close(closed)
+	}()
+
+	slog.Info("gRPC сервер запущен", "address", listen.Addr().String())
+	if err := s.Serve(listen); err != nil {
+		slog.Error("Ошибка работы gRPC сервера", "error", err)
+	}
+}
+
+// grpcServerOptions возвращает параметры gRPC-сервера из раздела grpc конфигурации.
+// Нулевые значения не передаются, и для них действуют значения gRPC по умолчанию.
+func grpcServerOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if config.GRPC.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(config.GRPC.ConnectionTimeout))
+	}
+	if config.GRPC.MaxRecvMsgSizeMB > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(config.GRPC.MaxRecvMsgSizeMB<<20))
+	}
+	if config.GRPC.MaxSendMsgSizeMB > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(config.GRPC.MaxSendMsgSizeMB<<20))
+	}
+
+	ka := config.GRPC.Keepalive
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     ka.MaxConnectionIdle,
+			MaxConnectionAge:      ka.MaxConnectionAge,
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+			Time:                  ka.Time,
+			Timeout:               ka.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}),
+	)
+
+	return opts
+}
+
+// metricsStart запускает HTTP-сервер метрик Prometheus, если задан metrics.address.
+// Метрики отдаются на отдельном адресе, чтобы не публиковать их вместе с API.
+func metricsStart(ctx context.Context, closed chan struct{}) {
+	defer close(closed)
+
+	if config.Metrics.Address == "" {
+		slog.Info("Сервер метрик отключен")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{
+		Addr:              config.Metrics.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("Останавливаем сервер метрик...")
+		if err := srv.Shutdown(context.Background()); err != nil {
+			slog.Error("Ошибка при завершении сервера метрик", "error", err)
+		}
+	}()
+
+	slog.Info("Сервер метрик запущен", "address", config.Metrics.Address)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Ошибка работы сервера метрик", "error", err)
+	}
+	<-ctx.Done()
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// versionCmd выводит версию и дату сборки сервера.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Версия сервера",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Build version: %s\nBuild date: %s\n", buildVersion, buildDate)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
@@ -37,6 +37,12 @@ type clientLoggingConfig struct {
 
 	// File — путь к файлу, в который будут записываться логи.
 	File string `mapstructure:"file"`
+
+	// MaxSizeMB — размер файла логов в мегабайтах, при достижении которого он ротируется.
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+
+	// MaxBackups — количество хранимых ротированных файлов логов.
+	MaxBackups int `mapstructure:"max_backups"`
 }
 
 // ClientConfig объединяет настройки клиента, безопасности и логирования.
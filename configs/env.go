@@ -0,0 +1,76 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix - префикс переменных окружения, переопределяющих конфигурацию сервера.
+// Параметр security.jwt_secret задается переменной GOPHKEEPER_SECURITY_JWT_SECRET.
+const EnvPrefix = "GOPHKEEPER"
+
+// bindEnv связывает с переменными окружения все параметры структуры t, включая
+// вложенные. Без явной привязки viper читает из окружения только параметры,
+// которые уже есть в конфигурационном файле.
+func bindEnv(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+
+		key := prefix + name
+		if field.Type.Kind() == reflect.Struct {
+			bindEnv(v, field.Type, key+".")
+			continue
+		}
+		// Ошибка возможна только при пустом ключе
+		_ = v.BindEnv(key)
+	}
+}
+
+// secretFile - секрет, значение которого можно прочитать из файла.
+type secretFile struct {
+	// name - имя параметра с путем к файлу.
+	name string
+
+	// path - путь к файлу с секретом.
+	path string
+
+	// value - параметр, в который записывается содержимое файла.
+	value *string
+}
+
+// loadSecretFiles заменяет значения секретов содержимым файлов из параметров *_file,
+// например смонтированных секретов Docker или Kubernetes. Перевод строки в конце
+// файла отбрасывается.
+func (c *ServerConfig) loadSecretFiles() error {
+	secrets := []secretFile{
+		{"storage.connection_string_file", c.Storage.ConnectionStringFile, &c.Storage.ConnectionString},
+		{"security.jwt_secret_file", c.Security.JWTSecretFile, &c.Security.JWTSecret},
+		{"security.encryption_key_file", c.Security.EncryptionKeyFile, &c.Security.EncryptionKey},
+		{"backup.encryption_key_file", c.Backup.EncryptionKeyFile, &c.Backup.EncryptionKey},
+	}
+
+	for _, secret := range secrets {
+		if secret.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(secret.path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", secret.name, err)
+		}
+		value := strings.TrimRight(string(data), "\r\n")
+		if value == "" {
+			return fmt.Errorf("%s: file %s is empty", secret.name, secret.path)
+		}
+		*secret.value = value
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package configs
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskedValue - значение, которым заменяются секреты при выводе конфигурации.
+const MaskedValue = "[REDACTED]"
+
+// Masked возвращает копию конфигурации, в которой секреты заменены на MaskedValue.
+// В строке подключения к базе данных пароль заменяется на xxxxx, остальные части сохраняются.
+func (c ServerConfig) Masked() ServerConfig {
+	for _, secret := range []*string{&c.Security.JWTSecret, &c.Security.EncryptionKey, &c.Backup.EncryptionKey} {
+		if *secret != "" {
+			*secret = MaskedValue
+		}
+	}
+
+	if c.Storage.ConnectionString != "" {
+		u, err := url.Parse(c.Storage.ConnectionString)
+		if err == nil && u.Scheme != "" {
+			c.Storage.ConnectionString = u.Redacted()
+		} else {
+			c.Storage.ConnectionString = MaskedValue
+		}
+	}
+
+	return c
+}
+
+// WriteYAML записывает конфигурацию в w в формате конфигурационного файла
+// с секретами, замененными на MaskedValue.
+func (c ServerConfig) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(reflect.ValueOf(c.Masked()))); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlNode строит узел YAML для значения v, сохраняя порядок полей структуры
+// и используя имена параметров из тегов mapstructure.
+func yamlNode(v reflect.Value) *yaml.Node {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: d.String()}
+	}
+
+	if v.Kind() != reflect.Struct {
+		node := &yaml.Node{}
+		// Скалярные значения конфигурации кодируются без ошибок
+		_ = node.Encode(v.Interface())
+		return node
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+			yamlNode(v.Field(i)),
+		)
+	}
+	return node
+}
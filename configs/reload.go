@@ -0,0 +1,99 @@
+package configs
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// reloadable - параметры, изменения которых применяются без перезапуска сервера.
+// Изменения остальных параметров при перезагрузке конфигурации игнорируются
+// до перезапуска.
+var reloadable = map[string]bool{
+	"logging.level": true,
+
+	"server.cors.allowed_origins":    true,
+	"server.cors.allowed_methods":    true,
+	"server.cors.allowed_headers":    true,
+	"server.cors.allow_credentials":  true,
+	"server.cors.max_age":            true,
+	"server.hsts_max_age":            true,
+	"server.content_security_policy": true,
+
+	"security.min_password_entropy": true,
+}
+
+// IsReloadable сообщает, применяется ли изменение параметра key без перезапуска.
+func IsReloadable(key string) bool {
+	return reloadable[key]
+}
+
+// Store хранит текущую конфигурацию сервера. Обработчики читают ее при каждом
+// запросе, поэтому замена конфигурации при перезагрузке атомарна для них.
+type Store struct {
+	current atomic.Pointer[ServerConfig]
+}
+
+// NewStore создает хранилище с конфигурацией cfg.
+func NewStore(cfg *ServerConfig) *Store {
+	s := &Store{}
+	s.current.Store(cfg)
+	return s
+}
+
+// Load возвращает текущую конфигурацию. Возвращаемое значение нельзя изменять.
+func (s *Store) Load() *ServerConfig {
+	return s.current.Load()
+}
+
+// Swap заменяет текущую конфигурацию на cfg и возвращает прежнюю.
+func (s *Store) Swap(cfg *ServerConfig) *ServerConfig {
+	return s.current.Swap(cfg)
+}
+
+// ReloadResult - результат сравнения текущей и перечитанной конфигурации.
+type ReloadResult struct {
+	// Config — новая конфигурация: текущая с примененными изменениями из Applied.
+	Config *ServerConfig
+
+	// Applied — измененные параметры, которые применяются без перезапуска.
+	Applied []string
+
+	// RestartRequired — измененные параметры, которые вступят в силу после перезапуска.
+	RestartRequired []string
+}
+
+// Reload сравнивает текущую конфигурацию current с перечитанной next и возвращает
+// копию current, в которую перенесены изменения параметров, применяемых без перезапуска.
+func Reload(current, next *ServerConfig) ReloadResult {
+	merged := *current
+	result := ReloadResult{Config: &merged}
+	diff(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(next).Elem(), "", &result)
+	return result
+}
+
+// diff сравнивает поля структур cur и next и переносит в cur изменения
+// параметров из reloadable. prefix - имя родительского раздела конфигурации.
+func diff(cur, next reflect.Value, prefix string, result *ReloadResult) {
+	for i := 0; i < cur.NumField(); i++ {
+		name := cur.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+
+		if cur.Field(i).Kind() == reflect.Struct {
+			diff(cur.Field(i), next.Field(i), key+".", result)
+			continue
+		}
+		if reflect.DeepEqual(cur.Field(i).Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		if IsReloadable(key) {
+			cur.Field(i).Set(next.Field(i))
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package configs
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -11,13 +16,89 @@ type serverConfig struct {
 	Address string `mapstructure:"address"`
 
 	// ReadTimeout — таймаут для чтения данных от клиента.
-	ReadTimeout string `mapstructure:"read_timeout"`
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
 
 	// WriteTimeout — таймаут для записи данных на клиента.
-	WriteTimeout string `mapstructure:"write_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 
 	// IdleTimeout — таймаут простоя, после которого соединение с клиентом закрывается.
-	IdleTimeout string `mapstructure:"idle_timeout"`
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+
+	// CORS — настройки CORS для браузерных клиентов с других доменов.
+	CORS serverCORSConfig `mapstructure:"cors"`
+
+	// HSTSMaxAge — срок, на который браузер запоминает, что сервер доступен только
+	// по HTTPS (заголовок Strict-Transport-Security). Ноль отключает заголовок.
+	HSTSMaxAge time.Duration `mapstructure:"hsts_max_age"`
+
+	// ContentSecurityPolicy — значение заголовка Content-Security-Policy.
+	// Пустое значение означает политику по умолчанию, запрещающую любые ресурсы.
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+}
+
+// serverCORSConfig содержит настройки CORS HTTP-сервера.
+type serverCORSConfig struct {
+	// AllowedOrigins — источники (схема, хост и порт), которым разрешены запросы,
+	// или "*" для любых источников. Пустой список отключает CORS.
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+
+	// AllowedMethods — методы, разрешенные в запросах с других источников.
+	AllowedMethods []string `mapstructure:"allowed_methods"`
+
+	// AllowedHeaders — заголовки, разрешенные в запросах с других источников.
+	AllowedHeaders []string `mapstructure:"allowed_headers"`
+
+	// AllowCredentials разрешает запросы с cookie. Несовместимо с источником "*".
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+
+	// MaxAge — время, на которое браузер кэширует результат предварительного запроса.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+// serverGRPCConfig содержит настройки gRPC-сервера.
+type serverGRPCConfig struct {
+	// Address — адрес, на котором работает gRPC-сервер.
+	Address string `mapstructure:"address"`
+
+	// Reflection включает сервис reflection для отладки клиентами вроде grpcurl.
+	Reflection bool `mapstructure:"reflection"`
+
+	// ConnectionTimeout — время на установку соединения, включая TLS-рукопожатие.
+	ConnectionTimeout time.Duration `mapstructure:"connection_timeout"`
+
+	// MaxRecvMsgSizeMB — максимальный размер принимаемого сообщения в мегабайтах.
+	MaxRecvMsgSizeMB int `mapstructure:"max_recv_msg_size_mb"`
+
+	// MaxSendMsgSizeMB — максимальный размер отправляемого сообщения в мегабайтах.
+	MaxSendMsgSizeMB int `mapstructure:"max_send_msg_size_mb"`
+
+	// Keepalive — параметры проверки активности соединений.
+	Keepalive serverGRPCKeepaliveConfig `mapstructure:"keepalive"`
+}
+
+// serverGRPCKeepaliveConfig содержит параметры keepalive gRPC-сервера.
+// Нулевые значения означают значения gRPC по умолчанию.
+type serverGRPCKeepaliveConfig struct {
+	// Time — период простоя, после которого сервер проверяет соединение ping-запросом.
+	Time time.Duration `mapstructure:"time"`
+
+	// Timeout — время ожидания ответа на ping, после которого соединение закрывается.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxConnectionIdle — время простоя, после которого соединение закрывается.
+	MaxConnectionIdle time.Duration `mapstructure:"max_connection_idle"`
+
+	// MaxConnectionAge — максимальное время жизни соединения.
+	MaxConnectionAge time.Duration `mapstructure:"max_connection_age"`
+
+	// MaxConnectionAgeGrace — время на завершение вызовов после MaxConnectionAge.
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"`
+
+	// MinTime — минимальный интервал между ping-запросами клиента.
+	MinTime time.Duration `mapstructure:"min_time"`
+
+	// PermitWithoutStream разрешает клиентам ping-запросы без активных вызовов.
+	PermitWithoutStream bool `mapstructure:"permit_without_stream"`
 }
 
 // serverStorageConfig содержит настройки хранилища данных для сервера,
@@ -29,6 +110,9 @@ type serverStorageConfig struct {
 	// ConnectionString — строка подключения к хранилищу данных.
 	ConnectionString string `mapstructure:"connection_string"`
 
+	// ConnectionStringFile — файл, из которого читается строка подключения.
+	ConnectionStringFile string `mapstructure:"connection_string_file"`
+
 	// FilePath — путь к файлу для хранения данных, если используется файловое хранилище.
 	FilePath string `mapstructure:"file_path"`
 }
@@ -39,8 +123,18 @@ type serverSecurityConfig struct {
 	// JWTSecret — секретный ключ для подписания JWT.
 	JWTSecret string `mapstructure:"jwt_secret"`
 
+	// JWTSecretFile — файл, из которого читается секретный ключ для подписания JWT.
+	JWTSecretFile string `mapstructure:"jwt_secret_file"`
+
 	// EncryptionKey — ключ для шифрования данных.
 	EncryptionKey string `mapstructure:"encryption_key"`
+
+	// EncryptionKeyFile — файл, из которого читается ключ для шифрования данных.
+	EncryptionKeyFile string `mapstructure:"encryption_key_file"`
+
+	// MinPasswordEntropy — минимальная оценка энтропии пароля учетной записи в битах
+	// по алгоритму zxcvbn, проверяемая при регистрации. Ноль отключает проверку.
+	MinPasswordEntropy float64 `mapstructure:"min_password_entropy"`
 }
 
 // serverLoggingConfig содержит настройки логирования для сервера,
@@ -51,13 +145,74 @@ type serverLoggingConfig struct {
 
 	// File — путь к файлу, в который будут записываться логи.
 	File string `mapstructure:"file"`
+
+	// MaxSizeMB — размер файла логов в мегабайтах, при достижении которого он ротируется.
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+
+	// MaxBackups — количество хранимых ротированных файлов логов.
+	MaxBackups int `mapstructure:"max_backups"`
 }
 
+// serverMetricsConfig содержит настройки сервера метрик Prometheus.
+type serverMetricsConfig struct {
+	// Address — адрес отдельного HTTP-сервера с метриками. Пустое значение отключает метрики.
+	Address string `mapstructure:"address"`
+}
+
+// serverTracingConfig содержит настройки трассировки OpenTelemetry.
+type serverTracingConfig struct {
+	// Exporter — экспортер участков трассировки: none, otlp или stdout.
+	Exporter string `mapstructure:"exporter"`
+
+	// Endpoint — адрес OTLP-коллектора (gRPC).
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Insecure отключает TLS при подключении к OTLP-коллектору.
+	Insecure bool `mapstructure:"insecure"`
+
+	// SampleRatio — доля записываемых трасс от 0 до 1.
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// serverBackupConfig содержит настройки резервного копирования хранилища.
+type serverBackupConfig struct {
+	// Dir — каталог, в котором хранятся резервные копии.
+	Dir string `mapstructure:"dir"`
+
+	// Interval — период резервного копирования по расписанию. Ноль отключает расписание.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Retention — количество хранимых копий, более старые удаляются. Ноль — хранить все.
+	Retention int `mapstructure:"retention"`
+
+	// MaxAge — срок хранения копий. Ноль — без ограничения.
+	MaxAge time.Duration `mapstructure:"max_age"`
+
+	// EncryptionKey — ключ шифрования резервных копий.
+	EncryptionKey string `mapstructure:"encryption_key"`
+
+	// EncryptionKeyFile — файл, из которого читается ключ шифрования резервных копий.
+	EncryptionKeyFile string `mapstructure:"encryption_key_file"`
+}
+
+// Режимы работы сервера.
+const (
+	ModeDevelopment = "development"
+	ModeProduction  = "production"
+)
+
 // ServerConfig объединяет все настройки сервера, хранилища, безопасности и логирования.
 type ServerConfig struct {
+	// Mode — режим работы: development (по умолчанию) или production.
+	// В режиме production сервер не запускается со стандартными и слабыми секретами.
+	Mode string `mapstructure:"mode"`
+
 	// Server — настройки сервера.
 	Server serverConfig `mapstructure:"server"`
 
+	// GRPC — настройки gRPC-сервера.
+	GRPC serverGRPCConfig `mapstructure:"grpc"`
+
 	// Storage — настройки хранилища данных.
 	Storage serverStorageConfig `mapstructure:"storage"`
 
@@ -66,25 +221,64 @@ type ServerConfig struct {
 
 	// Logging — настройки логирования.
 	Logging serverLoggingConfig `mapstructure:"logging"`
+
+	// Metrics — настройки сервера метрик.
+	Metrics serverMetricsConfig `mapstructure:"metrics"`
+
+	// Tracing — настройки трассировки.
+	Tracing serverTracingConfig `mapstructure:"tracing"`
+
+	// Backup — настройки резервного копирования.
+	Backup serverBackupConfig `mapstructure:"backup"`
+}
+
+// Override - значение параметра конфигурации, заданное вне файла, например флагом
+// командной строки. Имеет приоритет над файлом и переменными окружения.
+type Override struct {
+	// Key — имя параметра, например grpc.address.
+	Key string
+
+	// Value — значение параметра.
+	Value any
 }
 
 // LoadServerConfig загружает конфигурацию из файла по указанному пути и
-// возвращает объект ServerConfig. В случае ошибки возвращает ошибку.
-func LoadServerConfig(path string) (*ServerConfig, error) {
-	// Устанавливаем файл конфигурации и активируем автоматическое считывание
-	// переменных окружения.
-	viper.SetConfigFile(path)
-	viper.AutomaticEnv()
+// возвращает объект ServerConfig. Значения из файла переопределяются переменными
+// окружения с префиксом GOPHKEEPER_ и затем значениями overrides, секреты могут
+// читаться из файлов параметров *_file.
+// Конфигурация с неизвестными параметрами или неверными значениями отклоняется.
+func LoadServerConfig(path string, overrides ...Override) (*ServerConfig, error) {
+	// Устанавливаем файл конфигурации и связываем параметры с переменными окружения:
+	// security.jwt_secret переопределяется переменной GOPHKEEPER_SECURITY_JWT_SECRET.
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	bindEnv(v, reflect.TypeOf(ServerConfig{}), "")
 
 	// Чтение конфигурации из файла.
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
+	for _, o := range overrides {
+		v.Set(o.Key, o.Value)
+	}
 
-	// Разбор конфигурации в структуру ServerConfig.
+	// Разбор конфигурации в структуру ServerConfig. Неизвестные параметры, например
+	// с опечаткой в имени, считаются ошибкой.
 	var config ServerConfig
-	if err := viper.Unmarshal(&config); err != nil {
-		return nil, err
+	if err := v.UnmarshalExact(&config); err != nil {
+		return nil, fmt.Errorf("invalid server config %s: %w", path, err)
+	}
+
+	// Чтение секретов из файлов.
+	if err := config.loadSecretFiles(); err != nil {
+		return nil, fmt.Errorf("invalid server config %s: %w", path, err)
+	}
+
+	// Проверка значений, чтобы сервер не запустился с неверными настройками.
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid server config %s: %w", path, err)
 	}
 
 	// Возвращаем структуру с конфигурацией.
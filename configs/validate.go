@@ -0,0 +1,206 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"github.com/sol1corejz/goph-keeper/internal/tracing"
+)
+
+// MaxMsgSizeMB - наибольший допустимый размер сообщения gRPC в мегабайтах.
+const MaxMsgSizeMB = 2047
+
+// MinBackupInterval - наименьший период резервного копирования по расписанию.
+const MinBackupInterval = time.Minute
+
+// MinSecretLength - наименьшая длина секретов в режиме production.
+const MinSecretLength = 32
+
+// defaultSecrets - значения секретов из примеров конфигурации и другие заведомо
+// известные значения, с которыми сервер не запускается в режиме production.
+var defaultSecrets = []string{"secret-key", "encryption-key", "secret", "changeme", "password"}
+
+// Validate проверяет значения конфигурации сервера и возвращает все найденные ошибки.
+// Каждая ошибка содержит имя параметра в конфигурационном файле.
+func (c *ServerConfig) Validate() error {
+	var errs []error
+
+	switch c.Mode {
+	case "", ModeDevelopment, ModeProduction:
+	default:
+		errs = append(errs, fmt.Errorf("mode must be %s or %s, got %q", ModeDevelopment, ModeProduction, c.Mode))
+	}
+
+	errs = append(errs, validateAddress("server.address", c.Server.Address, true))
+	errs = append(errs, validateAddress("grpc.address", c.GRPC.Address, true))
+	errs = append(errs, validateAddress("metrics.address", c.Metrics.Address, false))
+	if c.Server.Address != "" && c.Server.Address == c.GRPC.Address {
+		errs = append(errs, fmt.Errorf("grpc.address must differ from server.address %q", c.Server.Address))
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.hsts_max_age", c.Server.HSTSMaxAge},
+		{"server.cors.max_age", c.Server.CORS.MaxAge},
+		{"grpc.connection_timeout", c.GRPC.ConnectionTimeout},
+		{"grpc.keepalive.time", c.GRPC.Keepalive.Time},
+		{"grpc.keepalive.timeout", c.GRPC.Keepalive.Timeout},
+		{"grpc.keepalive.max_connection_idle", c.GRPC.Keepalive.MaxConnectionIdle},
+		{"grpc.keepalive.max_connection_age", c.GRPC.Keepalive.MaxConnectionAge},
+		{"grpc.keepalive.max_connection_age_grace", c.GRPC.Keepalive.MaxConnectionAgeGrace},
+		{"grpc.keepalive.min_time", c.GRPC.Keepalive.MinTime},
+		{"backup.interval", c.Backup.Interval},
+		{"backup.max_age", c.Backup.MaxAge},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", d.name, d.value))
+		}
+	}
+
+	sizes := []struct {
+		name  string
+		value int
+	}{
+		{"grpc.max_recv_msg_size_mb", c.GRPC.MaxRecvMsgSizeMB},
+		{"grpc.max_send_msg_size_mb", c.GRPC.MaxSendMsgSizeMB},
+	}
+	for _, size := range sizes {
+		if size.value < 0 || size.value > MaxMsgSizeMB {
+			errs = append(errs, fmt.Errorf("%s must be between 0 and %d, got %d", size.name, MaxMsgSizeMB, size.value))
+		}
+	}
+
+	errs = append(errs, c.Server.CORS.validate())
+
+	if c.Storage.Type != "" && c.Storage.Type != "postgres" {
+		errs = append(errs, fmt.Errorf("storage.type: unsupported storage %q, only postgres is available", c.Storage.Type))
+	}
+	if c.Storage.ConnectionString == "" {
+		errs = append(errs, errors.New("storage.connection_string is required"))
+	}
+	if c.Security.JWTSecret == "" {
+		errs = append(errs, errors.New("security.jwt_secret is required"))
+	}
+	if c.Security.MinPasswordEntropy < 0 || c.Security.MinPasswordEntropy > 256 {
+		errs = append(errs, fmt.Errorf("security.min_password_entropy must be between 0 and 256, got %g", c.Security.MinPasswordEntropy))
+	}
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	if c.Logging.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("logging.max_size_mb must not be negative, got %d", c.Logging.MaxSizeMB))
+	}
+	if c.Logging.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("logging.max_backups must not be negative, got %d", c.Logging.MaxBackups))
+	}
+
+	switch strings.ToLower(c.Tracing.Exporter) {
+	case "", tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		errs = append(errs, validateAddress("tracing.endpoint", c.Tracing.Endpoint, true))
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be %s, %s or %s, got %q",
+			tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+
+	if c.Backup.Interval > 0 {
+		if c.Backup.Interval < MinBackupInterval {
+			errs = append(errs, fmt.Errorf("backup.interval must be at least %s, got %s", MinBackupInterval, c.Backup.Interval))
+		}
+		if c.Backup.Dir == "" {
+			errs = append(errs, errors.New("backup.dir is required when backup.interval is set"))
+		}
+		if c.Backup.EncryptionKey == "" {
+			errs = append(errs, errors.New("backup.encryption_key is required when backup.interval is set"))
+		}
+	}
+	if c.Backup.Retention < 0 {
+		errs = append(errs, fmt.Errorf("backup.retention must not be negative, got %d", c.Backup.Retention))
+	}
+
+	if c.Mode == ModeProduction {
+		errs = append(errs, validateSecret("security.jwt_secret", c.Security.JWTSecret))
+		if c.Security.EncryptionKey != "" {
+			errs = append(errs, validateSecret("security.encryption_key", c.Security.EncryptionKey))
+		}
+		if c.Backup.EncryptionKey != "" {
+			errs = append(errs, validateSecret("backup.encryption_key", c.Backup.EncryptionKey))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate проверяет источники и методы CORS.
+func (c serverCORSConfig) validate() error {
+	var errs []error
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errs = append(errs, errors.New(`server.cors.allowed_origins: "*" cannot be used with allow_credentials`))
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.User != nil {
+			errs = append(errs, fmt.Errorf("server.cors.allowed_origins: invalid origin %q, want scheme://host[:port]", origin))
+		}
+	}
+	for _, method := range c.AllowedMethods {
+		if method == "" || method != strings.ToUpper(method) || strings.ContainsAny(method, " ,") {
+			errs = append(errs, fmt.Errorf("server.cors.allowed_methods: invalid method %q", method))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateSecret проверяет, что секрет name не совпадает с известными значениями
+// и не короче MinSecretLength. Значение секрета в ошибку не попадает.
+func validateSecret(name, value string) error {
+	for _, weak := range defaultSecrets {
+		if strings.EqualFold(value, weak) {
+			return fmt.Errorf("%s uses a default value, set a random secret in production mode", name)
+		}
+	}
+	if len(value) < MinSecretLength {
+		return fmt.Errorf("%s must be at least %d characters in production mode, got %d", name, MinSecretLength, len(value))
+	}
+	return nil
+}
+
+// validateAddress проверяет, что value - адрес вида host:port. Пустое значение
+// допустимо, только если параметр name необязателен.
+func validateAddress(name, value string, required bool) error {
+	if value == "" {
+		if required {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("%s: invalid port %q", name, port)
+	}
+	return nil
+}
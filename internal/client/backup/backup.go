@@ -0,0 +1,201 @@
+// Package backup реализует формат резервной копии хранилища пользователя.
+//
+// Архив - JSON-документ с открытым заголовком (формат, версия, параметры
+// Argon2id и шифра) и зашифрованным содержимым. Ключ выводится из парольной фразы
+// алгоритмом Argon2id, содержимое шифруется AES-256-GCM. Заголовок передается
+// шифру как связанные данные, поэтому тег GCM служит кодом целостности всего
+// архива: изменение заголовка или содержимого обнаруживается при открытии.
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры формата.
+const (
+	FormatName      = "goph-keeper-vault"
+	Version         = 1
+	KDFArgon2id     = "argon2id"
+	CipherAES256GCM = "aes-256-gcm"
+
+	keyLen  = 32
+	saltLen = 16
+)
+
+// Ограничения параметров Argon2id при открытии архива. Не дают архиву
+// из недоверенного источника занять всю память или процессор.
+const (
+	maxKDFTime      = 64
+	maxKDFMemoryKiB = 4 * 1024 * 1024
+)
+
+var (
+	// ErrNotArchive - файл не является архивом goph-keeper.
+	ErrNotArchive = errors.New("not a goph-keeper vault archive")
+	// ErrDecrypt - неверная парольная фраза или архив поврежден.
+	ErrDecrypt = errors.New("wrong passphrase or corrupted archive")
+)
+
+// KDF - параметры вывода ключа из парольной фразы.
+type KDF struct {
+	Name      string `json:"name"`
+	Salt      []byte `json:"salt"`
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memory_kib"`
+	Threads   uint8  `json:"threads"`
+}
+
+// DefaultKDF возвращает рекомендуемые параметры Argon2id (RFC 9106) без соли.
+func DefaultKDF() KDF {
+	return KDF{Name: KDFArgon2id, Time: 3, MemoryKiB: 64 * 1024, Threads: 4}
+}
+
+// Header - открытый заголовок архива.
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	KDF       KDF       `json:"kdf"`
+	Cipher    string    `json:"cipher"`
+	Nonce     []byte    `json:"nonce"`
+}
+
+// archive - документ архива.
+type archive struct {
+	Header     Header `json:"header"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Entry - запись хранилища.
+type Entry struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Data      string    `json:"data"`
+	Meta      string    `json:"meta"`
+	Tags      []string  `json:"tags,omitempty"`
+	Folder    string    `json:"folder,omitempty"` // путь папки
+	Favorite  bool      `json:"favorite,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Vault - содержимое резервной копии.
+type Vault struct {
+	Entries []Entry  `json:"entries"`
+	Folders []string `json:"folders,omitempty"` // пути всех папок, включая пустые
+}
+
+// Seal шифрует v парольной фразой passphrase с параметрами kdf и записывает архив в w.
+// Соль генерируется заново при каждом вызове.
+func Seal(w io.Writer, v Vault, passphrase string, kdf KDF) error {
+	kdf.Name = KDFArgon2id
+	kdf.Salt = make([]byte, saltLen)
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return err
+	}
+
+	h := Header{
+		Format:    FormatName,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		KDF:       kdf,
+		Cipher:    CipherAES256GCM,
+	}
+	aead, err := newAEAD(passphrase, h.KDF)
+	if err != nil {
+		return err
+	}
+	h.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(h.Nonce); err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ad, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archive{Header: h, Ciphertext: aead.Seal(nil, h.Nonce, plaintext, ad)})
+}
+
+// Open читает архив из r и расшифровывает его парольной фразой passphrase.
+func Open(r io.Reader, passphrase string) (Vault, Header, error) {
+	var a archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil || a.Header.Format != FormatName {
+		return Vault{}, Header{}, ErrNotArchive
+	}
+	h := a.Header
+	if h.Version != Version {
+		return Vault{}, h, fmt.Errorf("unsupported archive version %d", h.Version)
+	}
+	if h.Cipher != CipherAES256GCM {
+		return Vault{}, h, fmt.Errorf("unsupported cipher %q", h.Cipher)
+	}
+	if err := h.KDF.validate(); err != nil {
+		return Vault{}, h, err
+	}
+
+	aead, err := newAEAD(passphrase, h.KDF)
+	if err != nil {
+		return Vault{}, h, err
+	}
+	if len(h.Nonce) != aead.NonceSize() {
+		return Vault{}, h, ErrDecrypt
+	}
+	ad, err := json.Marshal(h)
+	if err != nil {
+		return Vault{}, h, err
+	}
+	plaintext, err := aead.Open(nil, h.Nonce, a.Ciphertext, ad)
+	if err != nil {
+		return Vault{}, h, ErrDecrypt
+	}
+
+	var v Vault
+	if err = json.Unmarshal(plaintext, &v); err != nil {
+		return Vault{}, h, fmt.Errorf("invalid archive contents: %w", err)
+	}
+	return v, h, nil
+}
+
+// validate проверяет параметры вывода ключа из заголовка архива.
+func (k KDF) validate() error {
+	switch {
+	case k.Name != KDFArgon2id:
+		return fmt.Errorf("unsupported key derivation %q", k.Name)
+	case len(k.Salt) < saltLen:
+		return errors.New("key derivation salt is too short")
+	case k.Time == 0 || k.Time > maxKDFTime:
+		return fmt.Errorf("argon2id time must be between 1 and %d, got %d", maxKDFTime, k.Time)
+	case k.MemoryKiB < 8*uint32(k.Threads) || k.MemoryKiB > maxKDFMemoryKiB:
+		return fmt.Errorf("argon2id memory %d KiB is out of range", k.MemoryKiB)
+	case k.Threads == 0:
+		return errors.New("argon2id threads must be positive")
+	}
+	return nil
+}
+
+// newAEAD выводит ключ из парольной фразы и создает шифр AES-256-GCM.
+func newAEAD(passphrase string, kdf KDF) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), kdf.Salt, kdf.Time, kdf.MemoryKiB, kdf.Threads, keyLen)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,131 @@
+package backup_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKDF - облегченные параметры Argon2id, чтобы тесты выполнялись быстро.
+var testKDF = backup.KDF{Time: 1, MemoryKiB: 64, Threads: 1}
+
+var testVault = backup.Vault{
+	Entries: []backup.Entry{{
+		ID:        "1",
+		Type:      "login",
+		Data:      "login:admin, password:1234",
+		Meta:      "website:example.com",
+		Tags:      []string{"work"},
+		Folder:    "work/db",
+		Favorite:  true,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC),
+	}},
+	Folders: []string{"work", "work/db", "empty"},
+}
+
+func TestSealOpen(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, backup.Seal(&buf, testVault, "correct horse battery staple", testKDF))
+	assert.NotContains(t, buf.String(), "1234")
+
+	v, h, err := backup.Open(bytes.NewReader(buf.Bytes()), "correct horse battery staple")
+	require.NoError(t, err)
+	assert.Equal(t, testVault, v)
+	assert.Equal(t, backup.FormatName, h.Format)
+	assert.Equal(t, backup.Version, h.Version)
+	assert.Equal(t, backup.KDFArgon2id, h.KDF.Name)
+	assert.Len(t, h.KDF.Salt, 16)
+
+	_, _, err = backup.Open(bytes.NewReader(buf.Bytes()), "wrong passphrase")
+	assert.ErrorIs(t, err, backup.ErrDecrypt)
+}
+
+func TestOpenDetectsTampering(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, backup.Seal(&buf, testVault, "passphrase", testKDF))
+
+	tests := map[string]func(header map[string]any){
+		// Заголовок защищен кодом целостности вместе с содержимым
+		"header": func(header map[string]any) { header["created_at"] = "2020-01-01T00:00:00Z" },
+		"kdf":    func(header map[string]any) { header["kdf"].(map[string]any)["time"] = 2 },
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+			tamper(doc["header"].(map[string]any))
+			data, err := json.Marshal(doc)
+			require.NoError(t, err)
+
+			_, _, err = backup.Open(bytes.NewReader(data), "passphrase")
+			assert.ErrorIs(t, err, backup.ErrDecrypt)
+		})
+	}
+
+	// Изменение зашифрованного содержимого
+	data := []byte(strings.Replace(buf.String(), `"ciphertext": "`, `"ciphertext": "AAAA`, 1))
+	_, _, err := backup.Open(bytes.NewReader(data), "passphrase")
+	assert.ErrorIs(t, err, backup.ErrDecrypt)
+}
+
+func TestOpenRejectsUnsupported(t *testing.T) {
+	_, _, err := backup.Open(strings.NewReader(`{"entries": []}`), "passphrase")
+	assert.ErrorIs(t, err, backup.ErrNotArchive)
+
+	_, _, err = backup.Open(strings.NewReader(`{"header": {"format": "goph-keeper-vault", "version": 99}}`), "passphrase")
+	assert.ErrorContains(t, err, "unsupported archive version")
+
+	// Параметры Argon2id, требующие слишком много памяти, отклоняются до вывода ключа
+	doc := `{"header": {"format": "goph-keeper-vault", "version": 1, "cipher": "aes-256-gcm",
+		"kdf": {"name": "argon2id", "salt": "AAAAAAAAAAAAAAAAAAAAAA==", "time": 1, "memory_kib": 4294967295, "threads": 1}}}`
+	_, _, err = backup.Open(strings.NewReader(doc), "passphrase")
+	assert.ErrorContains(t, err, "out of range")
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, backup.WriteCSV(&buf, testVault))
+	assert.Equal(t, "id,type,data,meta,tags,folder,favorite,created_at,updated_at\n"+
+		`1,login,"login:admin, password:1234",website:example.com,work,work/db,true,2026-01-02T03:04:05Z,2026-02-03T04:05:06Z`+"\n",
+		buf.String())
+}
+
+func TestMerge(t *testing.T) {
+	v := backup.Vault{Entries: []backup.Entry{
+		{ID: "same", Type: "text", Data: "unchanged"},
+		{ID: "edited", Type: "text", Data: "new data"},
+		{ID: "other-account", Type: "login", Data: "login:admin, password:new", Meta: "website:example.com"},
+		{ID: "fresh", Type: "text", Data: "fresh"},
+	}}
+	existing := []backup.Existing{
+		{ID: "same", Type: "text", Data: "unchanged"},
+		{ID: "edited", Type: "text", Data: "old data"},
+		{ID: "x", Type: "login", Data: "login:admin, password:old", Meta: "website:example.com"},
+	}
+
+	plan, err := backup.Merge(v, existing, backup.MergeSkip)
+	require.NoError(t, err)
+	assert.Equal(t, []backup.Entry{v.Entries[3]}, plan.Add)
+	assert.Empty(t, plan.Update)
+	assert.Len(t, plan.Skip, 3)
+
+	plan, err = backup.Merge(v, existing, backup.MergeOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, []backup.Entry{v.Entries[3]}, plan.Add)
+	assert.Equal(t, []backup.Update{{ID: "edited", Entry: v.Entries[1]}, {ID: "x", Entry: v.Entries[2]}}, plan.Update)
+	assert.Equal(t, []backup.Entry{v.Entries[0]}, plan.Skip)
+
+	plan, err = backup.Merge(v, existing, backup.MergeDuplicate)
+	require.NoError(t, err)
+	assert.Equal(t, v.Entries, plan.Add)
+
+	_, err = backup.Merge(v, existing, "replace")
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/importer"
+)
+
+// Правила объединения резервной копии с записями аккаунта.
+const (
+	MergeSkip      = "skip"      // существующие записи не изменяются
+	MergeOverwrite = "overwrite" // существующие записи заменяются данными из копии
+	MergeDuplicate = "duplicate" // все записи копии добавляются как новые
+)
+
+// MergeModes - все правила объединения.
+var MergeModes = []string{MergeSkip, MergeOverwrite, MergeDuplicate}
+
+// Existing - запись, уже сохраненная в аккаунте.
+type Existing struct {
+	ID   string
+	Type string
+	Data string
+	Meta string
+	Tags []string
+}
+
+// Update - замена данных существующей записи данными записи копии.
+type Update struct {
+	ID    string
+	Tags  []string // теги существующей записи; не изменяются
+	Entry Entry
+}
+
+// Plan - результат сопоставления копии с аккаунтом.
+type Plan struct {
+	Add    []Entry  // новые записи
+	Update []Update // записи, данные которых заменяются
+	Skip   []Entry  // записи, уже сохраненные в аккаунте
+}
+
+// Merge сопоставляет записи копии v с записями аккаунта existing по правилу mode.
+// Запись копии соответствует записи аккаунта с тем же идентификатором (восстановление
+// в тот же аккаунт), а если такой нет - записи с тем же ключом дубликатов
+// (см. importer.Key). Совпадающие записи с одинаковыми данными пропускаются
+// при любом правиле, кроме MergeDuplicate.
+func Merge(v Vault, existing []Existing, mode string) (Plan, error) {
+	var plan Plan
+	switch mode {
+	case MergeDuplicate:
+		plan.Add = v.Entries
+		return plan, nil
+	case MergeSkip, MergeOverwrite:
+	default:
+		return plan, fmt.Errorf("unknown merge mode %q", mode)
+	}
+
+	byID := make(map[string]Existing, len(existing))
+	byKey := make(map[string]Existing, len(existing))
+	for _, e := range existing {
+		byID[e.ID] = e
+		byKey[importer.Key(e.Type, e.Data, e.Meta)] = e
+	}
+
+	for _, entry := range v.Entries {
+		match, ok := byID[entry.ID]
+		if !ok {
+			match, ok = byKey[importer.Key(entry.Type, entry.Data, entry.Meta)]
+		}
+		switch {
+		case !ok:
+			plan.Add = append(plan.Add, entry)
+			// Дубликаты внутри копии сопоставляются с первой из них
+			byKey[importer.Key(entry.Type, entry.Data, entry.Meta)] = Existing{Type: entry.Type, Data: entry.Data, Meta: entry.Meta}
+		case mode == MergeOverwrite && match.ID != "" && (match.Data != entry.Data || match.Meta != entry.Meta):
+			plan.Update = append(plan.Update, Update{ID: match.ID, Tags: match.Tags, Entry: entry})
+		default:
+			plan.Skip = append(plan.Skip, entry)
+		}
+	}
+	return plan, nil
+}
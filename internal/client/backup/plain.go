@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader - столбцы открытой выгрузки в CSV.
+var csvHeader = []string{"id", "type", "data", "meta", "tags", "folder", "favorite", "created_at", "updated_at"}
+
+// WriteJSON записывает содержимое хранилища в w открытым текстом в формате JSON.
+func WriteJSON(w io.Writer, v Vault) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// WriteCSV записывает записи хранилища в w открытым текстом в формате CSV.
+// Теги разделяются точкой с запятой.
+func WriteCSV(w io.Writer, v Vault) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range v.Entries {
+		err := writer.Write([]string{
+			e.ID, e.Type, e.Data, e.Meta, strings.Join(e.Tags, ";"), e.Folder,
+			strconv.FormatBool(e.Favorite), e.CreatedAt.Format(time.RFC3339), e.UpdatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
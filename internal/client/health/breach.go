@@ -0,0 +1,95 @@
+package health
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PrefixLen - длина префикса SHA-1, по которому хеши разбиты на файлы.
+const PrefixLen = 5
+
+// BreachList - локальная копия базы хешей паролей из утечек в формате range API
+// Have I Been Pwned. Хеши SHA-1 разбиты на файлы по первым PrefixLen шестнадцатеричным
+// символам: файл <каталог>/<ПРЕФИКС> или <каталог>/<ПРЕФИКС>.txt содержит строки
+// "<ОСТАТОК ХЕША>:<КОЛИЧЕСТВО>". Отсутствующий файл означает, что хешей с таким
+// префиксом в базе нет.
+type BreachList struct {
+	dir    string
+	ranges map[string]map[string]int // прочитанные файлы по префиксу
+}
+
+// OpenBreachList открывает базу утечек в каталоге dir.
+func OpenBreachList(dir string) (*BreachList, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &BreachList{dir: dir, ranges: make(map[string]map[string]int)}, nil
+}
+
+// Count возвращает, сколько раз пароль password встречался в утечках.
+func (b *BreachList) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:PrefixLen], hash[PrefixLen:]
+
+	counts, ok := b.ranges[prefix]
+	if !ok {
+		var err error
+		if counts, err = b.readRange(prefix); err != nil {
+			return 0, err
+		}
+		b.ranges[prefix] = counts
+	}
+	return counts[suffix], nil
+}
+
+// readRange читает файл хешей с префиксом prefix.
+func (b *BreachList) readRange(prefix string) (map[string]int, error) {
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		f, err := os.Open(filepath.Join(b.dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		counts, err := parseRange(f)
+		f.Close()
+		return counts, err
+	}
+	return make(map[string]int), nil
+}
+
+// parseRange разбирает строки "<ОСТАТОК ХЕША>:<КОЛИЧЕСТВО>" файла хешей f.
+func parseRange(f *os.File) (map[string]int, error) {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		suffix, count, ok := strings.Cut(text, ":")
+		n, err := strconv.Atoi(count)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid line %q", f.Name(), line, text)
+		}
+		counts[strings.ToUpper(suffix)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
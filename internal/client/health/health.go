@@ -0,0 +1,163 @@
+// Package health проверяет пароли из записей хранилища на клиенте: находит слабые,
+// повторно используемые, давно не менявшиеся и встречавшиеся в утечках пароли.
+//
+// Проверка выполняется локально и не передает пароли или их хеши по сети.
+// Стойкость оценивается алгоритмом zxcvbn, утечки ищутся в локальной копии
+// базы хешей (см. BreachList).
+package health
+
+import (
+	"time"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+)
+
+// Виды проблем пароля.
+const (
+	IssueWeak     = "weak"     // оценка стойкости ниже минимальной
+	IssueReused   = "reused"   // пароль используется в нескольких записях
+	IssueOld      = "old"      // запись не изменялась дольше допустимого срока
+	IssueBreached = "breached" // пароль найден в базе утечек
+)
+
+// Issues - все виды проблем в порядке вывода.
+var Issues = []string{IssueWeak, IssueReused, IssueOld, IssueBreached}
+
+// MaxScore - максимальная оценка стойкости zxcvbn.
+const MaxScore = 4
+
+// passwordKeys - ключи, под которыми в данных записи указывается пароль.
+var passwordKeys = []string{"password", "pass", "pwd"}
+
+// Entry - запись хранилища с паролем.
+type Entry struct {
+	ID        string
+	Site      string
+	Username  string
+	Password  string
+	UpdatedAt time.Time // нулевое значение - время изменения неизвестно
+}
+
+// NewEntry создает Entry из данных и метаданных записи вида "login:admin, password:1234".
+func NewEntry(id, data, meta string, tags []string, updatedAt time.Time) Entry {
+	fields := search.ExtractFields(data, meta, tags)
+	pairs := search.ParsePairs(data)
+	var password string
+	for _, key := range passwordKeys {
+		if v, ok := pairs[key]; ok {
+			password = v
+			break
+		}
+	}
+	return Entry{
+		ID:        id,
+		Site:      fields.Site,
+		Username:  fields.Username,
+		Password:  password,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// Options - параметры проверки.
+type Options struct {
+	MinScore int           // минимальная допустимая оценка стойкости от 0 до MaxScore
+	MaxAge   time.Duration // допустимый срок без изменения записи; 0 - не проверять
+	Breaches *BreachList   // база утечек; nil - не проверять
+	Now      time.Time     // момент проверки; нулевое значение - текущее время
+}
+
+// Finding - проблемы пароля одной записи.
+type Finding struct {
+	ID          string   `json:"id"`
+	Site        string   `json:"site,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	Issues      []string `json:"issues"`
+	Score       int      `json:"score"`
+	ReusedWith  []string `json:"reused_with,omitempty"`
+	AgeDays     int      `json:"age_days,omitempty"`
+	BreachCount int      `json:"breach_count,omitempty"`
+}
+
+// Report - результат проверки.
+type Report struct {
+	Checked  int            `json:"checked"` // записи с паролем
+	Skipped  int            `json:"skipped"` // записи без пароля
+	Summary  map[string]int `json:"summary"` // количество записей с каждым видом проблем
+	Findings []Finding      `json:"findings"`
+}
+
+// Check проверяет пароли записей entries. Записи без пароля пропускаются.
+// Находки возвращаются в порядке записей.
+func Check(entries []Entry, opts Options) (Report, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	report := Report{Summary: make(map[string]int, len(Issues)), Findings: []Finding{}}
+	for _, issue := range Issues {
+		report.Summary[issue] = 0
+	}
+
+	// Записи с одинаковыми паролями
+	byPassword := make(map[string][]string)
+	for _, e := range entries {
+		if e.Password != "" {
+			byPassword[e.Password] = append(byPassword[e.Password], e.ID)
+		}
+	}
+
+	for _, e := range entries {
+		if e.Password == "" {
+			report.Skipped++
+			continue
+		}
+		report.Checked++
+
+		f := Finding{ID: e.ID, Site: e.Site, Username: e.Username}
+
+		// Логин и сайт записи считаются известными атакующему
+		f.Score = zxcvbn.PasswordStrength(e.Password, []string{e.Site, e.Username}).Score
+		if f.Score < opts.MinScore {
+			f.Issues = append(f.Issues, IssueWeak)
+		}
+
+		for _, id := range byPassword[e.Password] {
+			if id != e.ID {
+				f.ReusedWith = append(f.ReusedWith, id)
+			}
+		}
+		if len(f.ReusedWith) > 0 {
+			f.Issues = append(f.Issues, IssueReused)
+		}
+
+		if !e.UpdatedAt.IsZero() {
+			age := now.Sub(e.UpdatedAt)
+			f.AgeDays = int(age.Hours() / 24)
+			if opts.MaxAge > 0 && age > opts.MaxAge {
+				f.Issues = append(f.Issues, IssueOld)
+			}
+		}
+
+		if opts.Breaches != nil {
+			count, err := opts.Breaches.Count(e.Password)
+			if err != nil {
+				return Report{}, err
+			}
+			f.BreachCount = count
+			if count > 0 {
+				f.Issues = append(f.Issues, IssueBreached)
+			}
+		}
+
+		if len(f.Issues) == 0 {
+			continue
+		}
+		for _, issue := range f.Issues {
+			report.Summary[issue]++
+		}
+		report.Findings = append(report.Findings, f)
+	}
+	return report, nil
+}
@@ -0,0 +1,81 @@
+package health_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	e := health.NewEntry("1", "login:admin, password:1234", "website:example.com", nil, updated)
+	assert.Equal(t, health.Entry{ID: "1", Site: "example.com", Username: "admin", Password: "1234", UpdatedAt: updated}, e)
+
+	assert.Empty(t, health.NewEntry("2", "note", "", nil, updated).Password)
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	strong := "vK7#qL2!mZ9@xR4$wT6%"
+	entries := []health.Entry{
+		{ID: "weak", Site: "example.com", Username: "admin", Password: "password1", UpdatedAt: now},
+		{ID: "a", Site: "a.com", Password: strong, UpdatedAt: now.AddDate(0, 0, -10)},
+		{ID: "b", Site: "b.com", Password: strong, UpdatedAt: now.AddDate(0, 0, -400)},
+		{ID: "ok", Site: "c.com", Password: "Correct-Horse-Battery-Staple-42"},
+		{ID: "note"},
+	}
+
+	report, err := health.Check(entries, health.Options{MinScore: 3, MaxAge: 365 * 24 * time.Hour, Now: now})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, map[string]int{"weak": 1, "reused": 2, "old": 1, "breached": 0}, report.Summary)
+
+	require.Len(t, report.Findings, 3)
+	assert.Equal(t, "weak", report.Findings[0].ID)
+	assert.Equal(t, []string{health.IssueWeak}, report.Findings[0].Issues)
+	assert.Less(t, report.Findings[0].Score, 3)
+
+	assert.Equal(t, []string{health.IssueReused}, report.Findings[1].Issues)
+	assert.Equal(t, []string{"b"}, report.Findings[1].ReusedWith)
+
+	assert.Equal(t, []string{health.IssueReused, health.IssueOld}, report.Findings[2].Issues)
+	assert.Equal(t, 400, report.Findings[2].AgeDays)
+}
+
+func TestBreachList(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("password1"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	data := "0000000000000000000000000000000000A:1\n" + hash[health.PrefixLen:] + ":2413945\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:health.PrefixLen]+".txt"), []byte(data), 0o600))
+
+	breaches, err := health.OpenBreachList(dir)
+	require.NoError(t, err)
+
+	count, err := breaches.Count("password1")
+	require.NoError(t, err)
+	assert.Equal(t, 2413945, count)
+
+	// Файла с префиксом хеша нет - пароль в утечках не встречался
+	count, err = breaches.Count("Correct-Horse-Battery-Staple-42")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	report, err := health.Check([]health.Entry{{ID: "1", Password: "password1"}}, health.Options{Breaches: breaches})
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, []string{health.IssueBreached}, report.Findings[0].Issues)
+	assert.Equal(t, 2413945, report.Findings[0].BreachCount)
+
+	_, err = health.OpenBreachList(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Типы элементов Bitwarden.
+const (
+	bitwardenLogin    = 1
+	bitwardenNote     = 2
+	bitwardenCard     = 3
+	bitwardenIdentity = 4
+)
+
+// Документ JSON-экспорта Bitwarden. Значения null читаются как пустые строки.
+type (
+	bitwardenExport struct {
+		Encrypted bool `json:"encrypted"`
+		Folders   []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"folders"`
+		Items []bitwardenItem `json:"items"`
+	}
+
+	bitwardenItem struct {
+		Type     int    `json:"type"`
+		Name     string `json:"name"`
+		Notes    string `json:"notes"`
+		Favorite bool   `json:"favorite"`
+		FolderID string `json:"folderId"`
+		Login    struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			TOTP     string `json:"totp"`
+			URIs     []struct {
+				URI string `json:"uri"`
+			} `json:"uris"`
+		} `json:"login"`
+		Card struct {
+			CardholderName string `json:"cardholderName"`
+			Brand          string `json:"brand"`
+			Number         string `json:"number"`
+			ExpMonth       string `json:"expMonth"`
+			ExpYear        string `json:"expYear"`
+			Code           string `json:"code"`
+		} `json:"card"`
+		Identity map[string]any `json:"identity"`
+		Fields   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	}
+)
+
+// parseBitwarden читает незашифрованный JSON-экспорт Bitwarden.
+func parseBitwarden(r io.Reader) ([]Record, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid Bitwarden JSON: %w", err)
+	}
+	if export.Encrypted {
+		return nil, errors.New("encrypted Bitwarden exports are not supported, export in unencrypted JSON format")
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	records := make([]Record, 0, len(export.Items))
+	for _, item := range export.Items {
+		rec := Record{
+			Title:    item.Name,
+			Notes:    item.Notes,
+			Favorite: item.Favorite,
+			Folder:   folders[item.FolderID],
+		}
+		switch item.Type {
+		case bitwardenLogin:
+			rec.Type = TypeLogin
+			rec.Username = item.Login.Username
+			rec.Password = item.Login.Password
+			rec.OTP = item.Login.TOTP
+			for i, u := range item.Login.URIs {
+				if i == 0 {
+					rec.URL = u.URI
+					continue
+				}
+				rec.Extra = append(rec.Extra, Field{Key: fmt.Sprintf("url%d", i+1), Value: u.URI})
+			}
+		case bitwardenCard:
+			rec.Type = TypeCard
+			expiry := item.Card.ExpMonth
+			if item.Card.ExpYear != "" {
+				expiry += "/" + item.Card.ExpYear
+			}
+			rec.Extra = append(rec.Extra,
+				Field{Key: "holder", Value: item.Card.CardholderName},
+				Field{Key: "brand", Value: item.Card.Brand},
+				Field{Key: "number", Value: item.Card.Number},
+				Field{Key: "expiry", Value: expiry},
+				Field{Key: "cvv", Value: item.Card.Code},
+			)
+		case bitwardenIdentity:
+			rec.Type = TypeText
+			for _, key := range []string{"title", "firstName", "middleName", "lastName", "company", "email", "phone",
+				"address1", "address2", "address3", "city", "state", "postalCode", "country",
+				"ssn", "passportNumber", "licenseNumber", "username"} {
+				if v, ok := item.Identity[key].(string); ok {
+					rec.Extra = append(rec.Extra, Field{Key: key, Value: v})
+				}
+			}
+		case bitwardenNote:
+			rec.Type = TypeText
+		}
+		for _, f := range item.Fields {
+			rec.Extra = append(rec.Extra, Field{Key: f.Name, Value: f.Value})
+		}
+		rec.finish()
+		records = append(records, rec)
+	}
+	return records, nil
+}
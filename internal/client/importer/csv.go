@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Поля записи, которым сопоставляются столбцы CSV.
+const (
+	FieldTitle    = "title"
+	FieldURL      = "url"
+	FieldUsername = "username"
+	FieldPassword = "password"
+	FieldOTP      = "otp"
+	FieldNotes    = "notes"
+	FieldTags     = "tags"
+	FieldFolder   = "folder"
+	FieldFavorite = "favorite"
+	FieldType     = "type"
+)
+
+// Fields - все поля записи, которым можно сопоставить столбцы.
+var Fields = []string{FieldTitle, FieldURL, FieldUsername, FieldPassword, FieldOTP, FieldNotes,
+	FieldTags, FieldFolder, FieldFavorite, FieldType}
+
+// Mapping сопоставляет полям записи названия столбцов CSV.
+type Mapping map[string]string
+
+// ParseMapping разбирает сопоставление вида "title=Name,password=Pass".
+func ParseMapping(s string) (Mapping, error) {
+	mapping := make(Mapping)
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(part, "=")
+		field, column = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, want field=column", part)
+		}
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q, want one of: %s", field, strings.Join(Fields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// columnNames - названия столбцов, которые распознаются для полей без явного
+// сопоставления. Сравнение выполняется без учета регистра.
+var columnNames = map[string][]string{
+	FieldTitle:    {"title", "name"},
+	FieldURL:      {"url", "website", "site", "uri", "login_uri"},
+	FieldUsername: {"username", "login", "user", "email", "login_username"},
+	FieldPassword: {"password", "pass", "login_password"},
+	FieldOTP:      {"otp", "otpauth", "totp", "login_totp"},
+	FieldNotes:    {"notes", "note", "comment", "extra"},
+	FieldTags:     {"tags", "tag", "labels"},
+	FieldFolder:   {"folder", "group", "grouping", "path"},
+	FieldFavorite: {"favorite", "fav"},
+	FieldType:     {"type"},
+}
+
+// presets - названия столбцов CSV-экспорта менеджеров паролей.
+var presets = map[string]map[string][]string{
+	Format1Password: {
+		FieldTitle: {"title"}, FieldURL: {"url", "website"}, FieldUsername: {"username"},
+		FieldPassword: {"password"}, FieldOTP: {"otpauth", "one-time password"}, FieldNotes: {"notes"},
+		FieldTags: {"tags"}, FieldFavorite: {"favorite"},
+	},
+	FormatChrome: {
+		FieldTitle: {"name"}, FieldURL: {"url"}, FieldUsername: {"username"},
+		FieldPassword: {"password"}, FieldNotes: {"note"},
+	},
+	FormatFirefox: {
+		FieldURL: {"url"}, FieldUsername: {"username"}, FieldPassword: {"password"},
+	},
+	FormatCSV: columnNames,
+}
+
+// parseCSV читает CSV-файл с заголовком. Столбцы полей определяются по названиям
+// из names, явное сопоставление mapping их переопределяет. С keepExtra значения
+// остальных столбцов сохраняются в данных записи под названиями столбцов.
+func parseCSV(r io.Reader, names map[string][]string, mapping Mapping, keepExtra bool) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty CSV file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	// Номера столбцов полей
+	columns := make(map[string]int)
+	for field, candidates := range names {
+		for i, name := range header {
+			if slices.Contains(candidates, strings.ToLower(strings.TrimSpace(name))) {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	for field, name := range mapping {
+		i := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+		if i < 0 {
+			return nil, fmt.Errorf("column %q for field %s not found", name, field)
+		}
+		columns[field] = i
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("no known columns in CSV header")
+	}
+	mapped := make(map[int]bool, len(columns))
+	for _, i := range columns {
+		mapped[i] = true
+	}
+
+	var records []Record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		rec := Record{
+			Title:    value(FieldTitle),
+			URL:      value(FieldURL),
+			Username: value(FieldUsername),
+			Password: value(FieldPassword),
+			OTP:      value(FieldOTP),
+			Notes:    value(FieldNotes),
+			Tags:     splitTags(value(FieldTags)),
+			Folder:   value(FieldFolder),
+		}
+		switch strings.ToLower(value(FieldFavorite)) {
+		case "1", "true", "yes", "y":
+			rec.Favorite = true
+		}
+		switch typ := strings.ToLower(value(FieldType)); typ {
+		case "", TypeLogin, TypeText, TypeCard:
+			rec.Type = typ
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %q", line, typ)
+		}
+		if keepExtra {
+			for i, v := range row {
+				if !mapped[i] && i < len(header) && strings.TrimSpace(v) != "" {
+					rec.Extra = append(rec.Extra, Field{Key: strings.TrimSpace(header[i]), Value: v})
+				}
+			}
+		}
+		if rec.Title == "" && rec.URL == "" && rec.Username == "" && rec.Password == "" && rec.Notes == "" && len(rec.Extra) == 0 {
+			continue
+		}
+		rec.finish()
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,161 @@
+// Package importer читает записи, экспортированные из других менеджеров паролей
+// (KeePass, Bitwarden, 1Password, Chrome, Firefox) и из произвольных CSV-файлов,
+// и преобразует их в формат записей goph-keeper: данные и метаданные вида
+// "key:value, key2:value2" с экранированными разделителями в полях.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+)
+
+// Поддерживаемые форматы.
+const (
+	FormatKeePass   = "keepass"   // XML-экспорт KeePass 2.x и KeePassXC
+	FormatBitwarden = "bitwarden" // незашифрованный JSON-экспорт Bitwarden
+	Format1Password = "1password" // CSV-экспорт 1Password
+	FormatChrome    = "chrome"    // CSV-экспорт паролей Chrome
+	FormatFirefox   = "firefox"   // CSV-экспорт паролей Firefox
+	FormatCSV       = "csv"       // CSV с сопоставлением столбцов
+)
+
+// Formats - все поддерживаемые форматы.
+var Formats = []string{FormatKeePass, FormatBitwarden, Format1Password, FormatChrome, FormatFirefox, FormatCSV}
+
+// Типы импортируемых записей; совпадают с типами записей сервера.
+const (
+	TypeLogin = "login"
+	TypeText  = "text"
+	TypeCard  = "card"
+)
+
+// Field - дополнительное поле записи.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Record - импортируемая запись.
+type Record struct {
+	Type     string
+	Title    string
+	URL      string
+	Username string
+	Password string
+	OTP      string // URI otpauth:// или секрет
+	Notes    string
+	Extra    []Field // прочие поля в порядке исходного файла
+	Tags     []string
+	Folder   string // путь папки, например "work/databases"
+	Favorite bool
+}
+
+// Data возвращает данные записи вида "login:admin, password:1234". Запятые
+// и двоеточия в полях экранируются (см. search.FormatPair).
+func (r Record) Data() string {
+	var pairs []string
+	add := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			pairs = append(pairs, search.FormatPair(key, value))
+		}
+	}
+	add("login", r.Username)
+	add("password", r.Password)
+	for _, f := range r.Extra {
+		add(f.Key, f.Value)
+	}
+	add("otp", r.OTP)
+	add("note", r.Notes)
+	return strings.Join(pairs, ", ")
+}
+
+// Meta возвращает метаданные записи вида "title:GitHub, website:https://github.com".
+func (r Record) Meta() string {
+	var pairs []string
+	if title := strings.TrimSpace(r.Title); title != "" {
+		pairs = append(pairs, search.FormatPair("title", title))
+	}
+	if url := strings.TrimSpace(r.URL); url != "" {
+		pairs = append(pairs, search.FormatPair("website", url))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// Key возвращает ключ записи для поиска дубликатов (см. Key).
+func (r Record) Key() string {
+	return Key(r.Type, r.Data(), r.Meta())
+}
+
+// Key возвращает ключ записи с типом typ, данными data и метаданными meta для поиска
+// дубликатов. Записи с сайтом или логином совпадают, если у них одинаковые тип, сайт
+// и логин без учета регистра; остальные - если совпадают тип, данные и метаданные.
+func Key(typ, data, meta string) string {
+	fields := search.ExtractFields(data, meta, nil)
+	if fields.Site != "" || fields.Username != "" {
+		return strings.Join([]string{typ, strings.ToLower(fields.Site), strings.ToLower(fields.Username)}, "\x00")
+	}
+	return strings.Join([]string{typ, data, meta}, "\x00")
+}
+
+// Dedupe разделяет записи на новые и дубликаты. Дубликатом считается запись, ключ
+// которой есть среди existing (ключи уже сохраненных записей) или у одной из
+// предыдущих записей records.
+func Dedupe(records []Record, existing []string) (fresh, duplicates []Record) {
+	seen := make(map[string]bool, len(existing)+len(records))
+	for _, key := range existing {
+		seen[key] = true
+	}
+	for _, r := range records {
+		key := r.Key()
+		if seen[key] {
+			duplicates = append(duplicates, r)
+			continue
+		}
+		seen[key] = true
+		fresh = append(fresh, r)
+	}
+	return fresh, duplicates
+}
+
+// Parse читает записи формата format из r. Сопоставление столбцов mapping
+// используется для CSV-форматов и может быть пустым.
+func Parse(format string, r io.Reader, mapping Mapping) ([]Record, error) {
+	switch format {
+	case FormatKeePass:
+		return parseKeePass(r)
+	case FormatBitwarden:
+		return parseBitwarden(r)
+	case Format1Password, FormatChrome, FormatFirefox, FormatCSV:
+		return parseCSV(r, presets[format], mapping, format == FormatCSV)
+	}
+	return nil, fmt.Errorf("unknown format %q, want one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// finish определяет тип записи по заполненным полям и нормализует теги и папку.
+func (r *Record) finish() {
+	if r.Type == "" {
+		switch {
+		case r.Username != "" || r.Password != "":
+			r.Type = TypeLogin
+		default:
+			r.Type = TypeText
+		}
+	}
+	r.Folder = strings.Trim(r.Folder, "/")
+	var tags []string
+	for _, tag := range r.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	r.Tags = tags
+}
+
+// splitTags разбирает список тегов, разделенных запятыми или точками с запятой.
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' })
+}
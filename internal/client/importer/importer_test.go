@@ -0,0 +1,203 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/importer"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keepassXML = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinEnabled>True</RecycleBinEnabled>
+		<RecycleBinUUID>YmluYmluYmluYmluYmluYg==</RecycleBinUUID>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>cm9vdHJvb3Ryb290cm9vdA==</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>GitHub</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value Protected="True">s3cret</Value></String>
+				<String><Key>URL</Key><Value>https://github.com</Value></String>
+				<String><Key>Notes</Key><Value></Value></String>
+				<String><Key>Recovery</Key><Value>code-1</Value></String>
+				<Tags>dev;work</Tags>
+				<History>
+					<Entry>
+						<String><Key>Password</Key><Value>old</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>d29ya3dvcmt3b3Jrd29yaw==</UUID>
+				<Name>Work</Name>
+				<Group>
+					<UUID>ZGJzZGJzZGJzZGJzZGJzZA==</UUID>
+					<Name>Databases</Name>
+					<Entry>
+						<String><Key>Title</Key><Value>Wiki notes</Value></String>
+						<String><Key>Notes</Key><Value>Wi-Fi code 1234</Value></String>
+					</Entry>
+				</Group>
+			</Group>
+			<Group>
+				<UUID>YmluYmluYmluYmluYmluYg==</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+func TestParseKeePass(t *testing.T) {
+	records, err := importer.Parse(importer.FormatKeePass, strings.NewReader(keepassXML), nil)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, importer.TypeLogin, records[0].Type)
+	assert.Equal(t, "login:alice, password:s3cret, Recovery:code-1", records[0].Data())
+	assert.Equal(t, "title:GitHub, website:https://github.com", records[0].Meta())
+	assert.Equal(t, []string{"dev", "work"}, records[0].Tags)
+	assert.Empty(t, records[0].Folder)
+
+	assert.Equal(t, importer.TypeText, records[1].Type)
+	assert.Equal(t, "Work/Databases", records[1].Folder)
+	assert.Equal(t, "note:Wi-Fi code 1234", records[1].Data())
+}
+
+const bitwardenJSON = `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Personal/Banking"}],
+  "items": [
+    {"type": 1, "name": "Example", "notes": null, "favorite": true, "folderId": null,
+     "login": {"username": "bob", "password": "pw", "totp": "otpauth://totp/Example:bob?secret=JBSWY3DPEHPK3PXP",
+               "uris": [{"uri": "https://example.com"}, {"uri": "https://m.example.com"}]},
+     "fields": [{"name": "PIN", "value": "0000", "type": 1}]},
+    {"type": 3, "name": "Visa", "folderId": "f1",
+     "card": {"cardholderName": "Bob", "brand": "Visa", "number": "4111111111111111", "expMonth": "7", "expYear": "2030", "code": "123"}},
+    {"type": 2, "name": "Door", "notes": "code 42", "secureNote": {"type": 0}}
+  ]
+}`
+
+func TestParseBitwarden(t *testing.T) {
+	records, err := importer.Parse(importer.FormatBitwarden, strings.NewReader(bitwardenJSON), nil)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	login := records[0]
+	assert.Equal(t, importer.TypeLogin, login.Type)
+	assert.True(t, login.Favorite)
+	assert.Equal(t, "login:bob, password:pw, url2:https://m.example.com, PIN:0000, otp:otpauth://totp/Example:bob?secret=JBSWY3DPEHPK3PXP", login.Data())
+	assert.Equal(t, "title:Example, website:https://example.com", login.Meta())
+
+	card := records[1]
+	assert.Equal(t, importer.TypeCard, card.Type)
+	assert.Equal(t, "Personal/Banking", card.Folder)
+	assert.Equal(t, "holder:Bob, brand:Visa, number:4111111111111111, expiry:7/2030, cvv:123", card.Data())
+
+	assert.Equal(t, importer.TypeText, records[2].Type)
+	assert.Equal(t, "note:code 42", records[2].Data())
+
+	_, err = importer.Parse(importer.FormatBitwarden, strings.NewReader(`{"encrypted": true, "items": []}`), nil)
+	assert.Error(t, err)
+}
+
+func TestParseCSVPresets(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+		want   importer.Record
+	}{
+		{
+			format: importer.FormatChrome,
+			input:  "name,url,username,password,note\nexample.com,https://example.com/login,alice,pw1,\n",
+			want: importer.Record{Type: importer.TypeLogin, Title: "example.com", URL: "https://example.com/login",
+				Username: "alice", Password: "pw1"},
+		},
+		{
+			format: importer.FormatFirefox,
+			input: `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"
+"https://example.com","alice","pw2",,"https://example.com","{0f0e}","1700000000000","1700000000000","1700000000000"
+`,
+			want: importer.Record{Type: importer.TypeLogin, URL: "https://example.com", Username: "alice", Password: "pw2"},
+		},
+		{
+			format: importer.Format1Password,
+			input: `"Title","Url","Username","Password","OTPAuth","Favorite","Archived","Tags","Notes"
+"Mail","https://mail.example.com","alice","pw3","","true","false","work;mail","imap"
+`,
+			want: importer.Record{Type: importer.TypeLogin, Title: "Mail", URL: "https://mail.example.com",
+				Username: "alice", Password: "pw3", Notes: "imap", Tags: []string{"work", "mail"}, Favorite: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			records, err := importer.Parse(tt.format, strings.NewReader(tt.input), nil)
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			assert.Equal(t, tt.want, records[0])
+		})
+	}
+}
+
+func TestParseCSVMapping(t *testing.T) {
+	input := "Site;Login;Secret;Group;Comment\n" +
+		"example.com;alice;pw;Work;vpn\n"
+
+	mapping, err := importer.ParseMapping("url=Site, username=Login, password=Secret, folder=Group")
+	require.NoError(t, err)
+
+	// Столбцы без сопоставления сохраняются в данных записи
+	records, err := importer.Parse(importer.FormatCSV, strings.NewReader(strings.ReplaceAll(input, ";", ",")), mapping)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "Work", records[0].Folder)
+	assert.Equal(t, "login:alice, password:pw, note:vpn", records[0].Data())
+	assert.Equal(t, "website:example.com", records[0].Meta())
+
+	_, err = importer.Parse(importer.FormatCSV, strings.NewReader("a,b\n1,2\n"), importer.Mapping{"password": "Secret"})
+	assert.Error(t, err)
+
+	_, err = importer.ParseMapping("colour=Site")
+	assert.Error(t, err)
+	_, err = importer.ParseMapping("url")
+	assert.Error(t, err)
+}
+
+func TestRecordEscapesSeparators(t *testing.T) {
+	r := importer.Record{Username: "alice", Password: "p,ss", Notes: "line 1, line 2", Title: "Work, main"}
+	assert.Equal(t, `login:alice, password:p\,ss, note:line 1\, line 2`, r.Data())
+	assert.Equal(t, `title:Work\, main`, r.Meta())
+
+	pairs := search.ParsePairs(r.Data())
+	assert.Equal(t, "p,ss", pairs["password"])
+	assert.Equal(t, "line 1, line 2", pairs["note"])
+}
+
+func TestDedupe(t *testing.T) {
+	records := []importer.Record{
+		{Type: importer.TypeLogin, URL: "example.com", Username: "alice", Password: "new"},
+		{Type: importer.TypeLogin, URL: "example.com", Username: "bob", Password: "pw"},
+		{Type: importer.TypeLogin, URL: "Example.com", Username: "Bob", Password: "other"},
+		{Type: importer.TypeText, Notes: "same"},
+		{Type: importer.TypeText, Notes: "same"},
+	}
+	existing := []string{importer.Key("login", "login:alice, password:old", "website:example.com")}
+
+	fresh, duplicates := importer.Dedupe(records, existing)
+	assert.Equal(t, []importer.Record{records[1], records[3]}, fresh)
+	assert.Equal(t, []importer.Record{records[0], records[2], records[4]}, duplicates)
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := importer.Parse("lastpass", strings.NewReader(""), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+)
+
+// Документ XML-экспорта KeePass. Записи истории (History/Entry) не импортируются.
+type (
+	keepassFile struct {
+		Meta struct {
+			RecycleBinEnabled string `xml:"RecycleBinEnabled"`
+			RecycleBinUUID    string `xml:"RecycleBinUUID"`
+		} `xml:"Meta"`
+		Root struct {
+			Groups []keepassGroup `xml:"Group"`
+		} `xml:"Root"`
+	}
+
+	keepassGroup struct {
+		UUID    string         `xml:"UUID"`
+		Name    string         `xml:"Name"`
+		Entries []keepassEntry `xml:"Entry"`
+		Groups  []keepassGroup `xml:"Group"`
+	}
+
+	keepassEntry struct {
+		Strings []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"String"`
+		Tags string `xml:"Tags"`
+	}
+)
+
+// parseKeePass читает XML-экспорт KeePass. Папки записей соответствуют группам,
+// корневая группа базы в путь не входит. Корзина пропускается.
+func parseKeePass(r io.Reader) ([]Record, error) {
+	var file keepassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid KeePass XML: %w", err)
+	}
+
+	recycleBin := ""
+	if file.Meta.RecycleBinEnabled != "False" {
+		recycleBin = file.Meta.RecycleBinUUID
+	}
+
+	var records []Record
+	var walk func(g keepassGroup, folder string)
+	walk = func(g keepassGroup, folder string) {
+		if recycleBin != "" && g.UUID == recycleBin {
+			return
+		}
+		for _, e := range g.Entries {
+			records = append(records, keepassRecord(e, folder))
+		}
+		for _, child := range g.Groups {
+			walk(child, path.Join(folder, child.Name))
+		}
+	}
+	for _, root := range file.Root.Groups {
+		walk(root, "")
+	}
+	return records, nil
+}
+
+// keepassRecord преобразует запись KeePass в Record.
+func keepassRecord(e keepassEntry, folder string) Record {
+	rec := Record{Folder: folder, Tags: splitTags(e.Tags)}
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title":
+			rec.Title = s.Value
+		case "UserName":
+			rec.Username = s.Value
+		case "Password":
+			rec.Password = s.Value
+		case "URL":
+			rec.URL = s.Value
+		case "Notes":
+			rec.Notes = s.Value
+		case "otp", "TOTP Seed":
+			rec.OTP = s.Value
+		default:
+			rec.Extra = append(rec.Extra, Field{Key: s.Key, Value: s.Value})
+		}
+	}
+	rec.finish()
+	return rec
+}
@@ -0,0 +1,140 @@
+// Package otp разбирает ключи одноразовых паролей в формате otpauth:// и вычисляет
+// коды TOTP (RFC 6238) и HOTP (RFC 4226).
+package otp
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+)
+
+// CredentialType - тип записи, данные которой содержат URI otpauth://.
+const CredentialType = "otp"
+
+// Типы одноразовых паролей.
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+)
+
+// DefaultPeriod - период TOTP по умолчанию.
+const DefaultPeriod = 30 * time.Second
+
+// ErrNotOTPAuth - строка не является URI otpauth://.
+var ErrNotOTPAuth = errors.New("not an otpauth:// URI")
+
+// Key - ключ одноразовых паролей.
+type Key struct {
+	Type      string
+	Issuer    string
+	Account   string
+	Secret    string        // секрет в base32
+	Algorithm otp.Algorithm // SHA1, SHA256 или SHA512
+	Digits    otp.Digits    // 6 или 8
+	Period    time.Duration // период TOTP
+	Counter   uint64        // начальное значение счетчика HOTP
+}
+
+// Parse разбирает и проверяет URI вида
+// otpauth://totp/Issuer:account?secret=...&algorithm=SHA256&digits=8&period=30.
+func Parse(uri string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != "otpauth" {
+		return nil, ErrNotOTPAuth
+	}
+	key, err := otp.NewKeyFromURL(u.String())
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+
+	k := &Key{
+		Type:    key.Type(),
+		Issuer:  key.Issuer(),
+		Account: key.AccountName(),
+		Secret:  strings.ToUpper(strings.TrimRight(key.Secret(), "=")),
+		Digits:  key.Digits(),
+	}
+	if k.Type != TypeTOTP && k.Type != TypeHOTP {
+		return nil, fmt.Errorf("unsupported OTP type %q, want totp or hotp", k.Type)
+	}
+
+	if k.Secret == "" {
+		return nil, errors.New("secret is required")
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(k.Secret); err != nil {
+		return nil, errors.New("secret is not valid base32")
+	}
+
+	switch strings.ToUpper(q.Get("algorithm")) {
+	case "", "SHA1":
+		k.Algorithm = otp.AlgorithmSHA1
+	case "SHA256":
+		k.Algorithm = otp.AlgorithmSHA256
+	case "SHA512":
+		k.Algorithm = otp.AlgorithmSHA512
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, want SHA1, SHA256 or SHA512", q.Get("algorithm"))
+	}
+
+	if k.Digits != otp.DigitsSix && k.Digits != otp.DigitsEight {
+		return nil, fmt.Errorf("unsupported number of digits %q, want 6 or 8", q.Get("digits"))
+	}
+
+	if k.Type == TypeTOTP {
+		k.Period = DefaultPeriod
+		if p := q.Get("period"); p != "" {
+			seconds, err := strconv.ParseUint(p, 10, 32)
+			if err != nil || seconds == 0 {
+				return nil, fmt.Errorf("invalid period %q", p)
+			}
+			k.Period = time.Duration(seconds) * time.Second
+		}
+		return k, nil
+	}
+
+	counter := q.Get("counter")
+	if counter == "" {
+		return nil, errors.New("counter is required for hotp")
+	}
+	if k.Counter, err = strconv.ParseUint(counter, 10, 63); err != nil {
+		return nil, fmt.Errorf("invalid counter %q", counter)
+	}
+	return k, nil
+}
+
+// TOTP возвращает код TOTP на момент t и время до его смены.
+func (k *Key) TOTP(t time.Time) (string, time.Duration, error) {
+	if k.Type != TypeTOTP {
+		return "", 0, fmt.Errorf("key type is %s, not totp", k.Type)
+	}
+	code, err := totp.GenerateCodeCustom(k.Secret, t, totp.ValidateOpts{
+		Period:    uint(k.Period / time.Second),
+		Digits:    k.Digits,
+		Algorithm: k.Algorithm,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	remaining := k.Period - time.Duration(t.UnixNano())%k.Period
+	return code, remaining, nil
+}
+
+// HOTP возвращает код HOTP для значения счетчика counter.
+func (k *Key) HOTP(counter uint64) (string, error) {
+	if k.Type != TypeHOTP {
+		return "", fmt.Errorf("key type is %s, not hotp", k.Type)
+	}
+	return hotp.GenerateCodeCustom(k.Secret, counter, hotp.ValidateOpts{
+		Digits:    k.Digits,
+		Algorithm: k.Algorithm,
+	})
+}
@@ -0,0 +1,84 @@
+package otp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/otp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Секреты из тестовых векторов RFC 6238 в base32
+const (
+	secretSHA1   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	secretSHA256 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA"
+	secretSHA512 = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA"
+)
+
+func TestTOTP(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		at   int64
+		want string
+	}{
+		{"sha1", "otpauth://totp/Example:alice?secret=" + secretSHA1 + "&digits=8", 59, "94287082"},
+		{"sha256", "otpauth://totp/Example:alice?secret=" + secretSHA256 + "&algorithm=SHA256&digits=8", 59, "46119246"},
+		{"sha512", "otpauth://totp/Example:alice?secret=" + secretSHA512 + "&algorithm=SHA512&digits=8", 59, "90693936"},
+		{"sha1 at 1111111109", "otpauth://totp/Example:alice?secret=" + secretSHA1 + "&digits=8", 1111111109, "07081804"},
+		{"six digits", "otpauth://totp/Example:alice?secret=" + secretSHA1, 59, "287082"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := otp.Parse(tt.uri)
+			require.NoError(t, err)
+			code, remaining, err := key.TOTP(time.Unix(tt.at, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+			assert.Equal(t, time.Second, remaining)
+		})
+	}
+}
+
+func TestHOTP(t *testing.T) {
+	key, err := otp.Parse("otpauth://hotp/Example:alice?secret=" + secretSHA1 + "&counter=3")
+	require.NoError(t, err)
+	assert.Equal(t, otp.TypeHOTP, key.Type)
+	assert.Equal(t, "Example", key.Issuer)
+	assert.Equal(t, "alice", key.Account)
+	assert.Equal(t, uint64(3), key.Counter)
+
+	// Тестовые векторы RFC 4226
+	for counter, want := range []string{"755224", "287082", "359152", "969429", "338314"} {
+		code, err := key.HOTP(uint64(counter))
+		require.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+
+	_, _, err = key.TOTP(time.Now())
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	key, err := otp.Parse("otpauth://totp/GitHub:bob?secret=jbswy3dpehpk3pxp&period=60&issuer=GitHub")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", key.Secret)
+	assert.Equal(t, time.Minute, key.Period)
+
+	invalid := map[string]string{
+		"not a uri":        "login:admin, password:1234",
+		"unknown type":     "otpauth://motp/x?secret=" + secretSHA1,
+		"no secret":        "otpauth://totp/x?digits=6",
+		"bad secret":       "otpauth://totp/x?secret=not-base32!",
+		"bad algorithm":    "otpauth://totp/x?secret=" + secretSHA1 + "&algorithm=MD5",
+		"bad digits":       "otpauth://totp/x?secret=" + secretSHA1 + "&digits=7",
+		"bad period":       "otpauth://totp/x?secret=" + secretSHA1 + "&period=0",
+		"no counter":       "otpauth://hotp/x?secret=" + secretSHA1,
+		"negative counter": "otpauth://hotp/x?secret=" + secretSHA1 + "&counter=-1",
+	}
+	for name, uri := range invalid {
+		_, err := otp.Parse(uri)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,117 @@
+package search
+
+import "strings"
+
+// Ключи, под которыми в данных и метаданных записи указываются сайт и логин,
+// например "website:example.com" и "login:admin, password:1234".
+var (
+	siteKeys     = []string{"website", "site", "url", "host"}
+	usernameKeys = []string{"login", "username", "user", "email"}
+)
+
+// escapeChar - символ экранирования в данных и метаданных записи: следующие за ним
+// ",", ":" и "\" считаются частью ключа или значения, а не разделителями.
+const escapeChar = '\\'
+
+// FormatPair возвращает поле "key:value" с экранированными разделителями,
+// которое ParsePairs и CutPair разбирают обратно в key и value.
+func FormatPair(key, value string) string {
+	return keyEscaper.Replace(key) + ":" + valueEscaper.Replace(value)
+}
+
+var (
+	keyEscaper   = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `:`, `\:`)
+	valueEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`)
+)
+
+// SplitPairs делит строку вида "key:value, key2:value2" на поля по неэкранированным
+// запятым. Экранирование в полях сохраняется.
+func SplitPairs(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case escapeChar:
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// CutPair делит поле на ключ и значение по первому неэкранированному двоеточию
+// и снимает экранирование. Если двоеточия нет, ok равно false.
+func CutPair(part string) (key, value string, ok bool) {
+	for i := 0; i < len(part); i++ {
+		switch part[i] {
+		case escapeChar:
+			i++
+		case ':':
+			return Unescape(part[:i]), Unescape(part[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// Unescape снимает экранирование разделителей. Символ "\" перед другими символами
+// сохраняется, чтобы значения вроде путей Windows не менялись.
+func Unescape(s string) string {
+	if !strings.ContainsRune(s, escapeChar) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == escapeChar && i+1 < len(s) && strings.IndexByte(`,:\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// ParsePairs разбирает строку вида "key:value, key2:value2" в словарь.
+// Ключи приводятся к нижнему регистру, части без двоеточия пропускаются.
+// Запятые и двоеточия внутри ключей и значений экранируются (см. FormatPair).
+func ParsePairs(s string) map[string]string {
+	pairs := make(map[string]string)
+	for _, part := range SplitPairs(s) {
+		key, value, ok := CutPair(part)
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+		if _, exists := pairs[key]; !exists {
+			pairs[key] = value
+		}
+	}
+	return pairs
+}
+
+// ExtractFields извлекает индексируемые поля из данных, метаданных и тегов записи.
+// Сайт ищется сначала в метаданных, затем в данных; логин — наоборот.
+func ExtractFields(data, meta string, tags []string) Fields {
+	dataPairs, metaPairs := ParsePairs(data), ParsePairs(meta)
+	return Fields{
+		Site:     firstValue(siteKeys, metaPairs, dataPairs),
+		Username: firstValue(usernameKeys, dataPairs, metaPairs),
+		Tags:     tags,
+	}
+}
+
+// firstValue возвращает первое найденное значение по списку ключей.
+func firstValue(keys []string, sources ...map[string]string) string {
+	for _, src := range sources {
+		for _, k := range keys {
+			if v, ok := src[k]; ok {
+				return v
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package search
+
+import "strings"
+
+// Веса совпадений при ранжировании результатов поиска.
+var fieldWeights = map[string]int{
+	FieldSite:     10,
+	FieldUsername: 6,
+	FieldTag:      4,
+}
+
+// Score вычисляет релевантность записи запросу по расшифрованным значениям полей.
+// Точное совпадение слова весит вдвое больше совпадения по префиксу.
+// Сервер возвращает все записи, совпавшие хотя бы по одному термину,
+// поэтому окончательный порядок определяется на клиенте.
+func Score(query string, f Fields) int {
+	values := map[string][]string{
+		FieldSite:     Tokenize(f.Site),
+		FieldUsername: Tokenize(f.Username),
+	}
+	for _, tag := range f.Tags {
+		values[FieldTag] = append(values[FieldTag], Tokenize(tag)...)
+	}
+
+	score := 0
+	for _, q := range Tokenize(query) {
+		for field, tokens := range values {
+			best := 0
+			for _, t := range tokens {
+				switch {
+				case t == q:
+					best = fieldWeights[field] * 2
+				case best == 0 && len([]rune(q)) >= MinPrefixLen && strings.HasPrefix(t, q):
+					best = fieldWeights[field]
+				}
+			}
+			score += best
+		}
+	}
+	return score
+}
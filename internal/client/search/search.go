@@ -0,0 +1,148 @@
+// Package search реализует клиентскую часть поиска по учетным данным с помощью
+// слепых индексов (blind indexes).
+//
+// Сервер не видит искомые значения: клиент вычисляет для каждого слова из
+// индексируемых полей ключевой HMAC и передает серверу только результат.
+// Для поиска по префиксу дополнительно индексируются HMAC всех префиксов слова,
+// начиная с MinPrefixLen символов. Тип термина (точное совпадение или префикс)
+// входит в подписываемые данные, поэтому сервер не может их различить.
+package search
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// MinPrefixLen - минимальная длина префикса, по которому возможен поиск.
+const MinPrefixLen = 3
+
+// MaxTermLen - максимальная длина индексируемого слова в символах.
+// Более длинные слова индексируются только точным совпадением.
+const MaxTermLen = 32
+
+// keyInfo - контекст для вывода ключа слепого индекса из ключа шифрования клиента.
+const keyInfo = "goph-keeper blind index v1"
+
+// Индексируемые поля записи.
+const (
+	FieldSite     = "site"
+	FieldUsername = "username"
+	FieldTag      = "tag"
+)
+
+// Fields - значения индексируемых полей записи.
+type Fields struct {
+	Site     string
+	Username string
+	Tags     []string
+}
+
+// Indexer вычисляет термины слепого индекса на ключе пользователя.
+type Indexer struct {
+	key []byte
+}
+
+// NewIndexer создает Indexer, выводя ключ индекса из секрета клиента через HKDF.
+// Ключ индекса не совпадает с ключом шифрования, поэтому утечка индекса
+// не раскрывает ключ шифрования.
+func NewIndexer(secret string) (*Indexer, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(keyInfo)), key); err != nil {
+		return nil, err
+	}
+	return &Indexer{key: key}, nil
+}
+
+// term вычисляет один термин индекса.
+func (ix *Indexer) term(kind, field, token string) string {
+	mac := hmac.New(sha256.New, ix.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IndexTerms возвращает отсортированный набор терминов для сохранения вместе с записью.
+func (ix *Indexer) IndexTerms(f Fields) []string {
+	set := make(map[string]struct{})
+	add := func(field, value string) {
+		for _, token := range Tokenize(value) {
+			set[ix.term("exact", field, token)] = struct{}{}
+			runes := []rune(token)
+			for n := MinPrefixLen; n < len(runes) && n <= MaxTermLen; n++ {
+				set[ix.term("prefix", field, string(runes[:n]))] = struct{}{}
+			}
+		}
+	}
+
+	add(FieldSite, f.Site)
+	add(FieldUsername, f.Username)
+	for _, tag := range f.Tags {
+		add(FieldTag, tag)
+	}
+
+	terms := make([]string, 0, len(set))
+	for t := range set {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// QueryTerms возвращает термины, по которым сервер ищет записи для запроса.
+// Каждое слово запроса ищется во всех полях как точное совпадение и как префикс.
+func (ix *Indexer) QueryTerms(query string) []string {
+	terms := make([]string, 0)
+	for _, token := range Tokenize(query) {
+		for _, field := range []string{FieldSite, FieldUsername, FieldTag} {
+			terms = append(terms, ix.term("exact", field, token))
+			if n := len([]rune(token)); n >= MinPrefixLen && n <= MaxTermLen {
+				terms = append(terms, ix.term("prefix", field, token))
+			}
+		}
+	}
+	return terms
+}
+
+// Tokenize приводит строку к нижнему регистру и разбивает её на слова.
+// Точки, дефисы и подчеркивания считаются частью слова, чтобы домены
+// и логины вида "john.doe" находились целиком; дополнительно индексируются
+// их составные части.
+func Tokenize(s string) []string {
+	seen := make(map[string]struct{})
+	tokens := make([]string, 0)
+	add := func(t string) {
+		t = strings.Trim(t, ".-_")
+		if t == "" {
+			return
+		}
+		if _, ok := seen[t]; ok {
+			return
+		}
+		seen[t] = struct{}{}
+		tokens = append(tokens, t)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_'
+	})
+	for _, w := range words {
+		add(w)
+		parts := strings.FieldsFunc(w, func(r rune) bool { return r == '.' || r == '-' || r == '_' })
+		if len(parts) > 1 {
+			for _, p := range parts {
+				add(p)
+			}
+		}
+	}
+	return tokens
+}
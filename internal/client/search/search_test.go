@@ -0,0 +1,71 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"mail.example.com", "mail", "example", "com"}, search.Tokenize("Mail.Example.com"))
+	assert.Equal(t, []string{"john_doe", "john", "doe", "work"}, search.Tokenize("john_doe, Work"))
+	assert.Empty(t, search.Tokenize(" ,:; "))
+}
+
+func TestExtractFields(t *testing.T) {
+	f := search.ExtractFields("login:admin, password:1234", "website:example.com", []string{"work"})
+	assert.Equal(t, search.Fields{Site: "example.com", Username: "admin", Tags: []string{"work"}}, f)
+}
+
+func TestParsePairsEscaped(t *testing.T) {
+	data := search.FormatPair("login", "admin") + ", " + search.FormatPair("password", `a,b\c:d`) +
+		", " + search.FormatPair("a:b", "x")
+	assert.Equal(t, `login:admin, password:a\,b\\c:d, a\:b:x`, data)
+	assert.Equal(t, map[string]string{"login": "admin", "password": `a,b\c:d`, "a:b": "x"}, search.ParsePairs(data))
+
+	// Обратная косая черта перед другими символами сохраняется
+	assert.Equal(t, map[string]string{"path": `C:\dir`}, search.ParsePairs(`path:C:\dir`))
+}
+
+func TestQueryMatchesIndex(t *testing.T) {
+	ix, err := search.NewIndexer("encryption-key")
+	assert.NoError(t, err)
+
+	index := ix.IndexTerms(search.Fields{Site: "github.com", Username: "octocat", Tags: []string{"dev"}})
+	contains := func(query string) bool {
+		set := make(map[string]struct{}, len(index))
+		for _, term := range index {
+			set[term] = struct{}{}
+		}
+		for _, term := range ix.QueryTerms(query) {
+			if _, ok := set[term]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.True(t, contains("github"), "exact token")
+	assert.True(t, contains("git"), "prefix")
+	assert.True(t, contains("OctoCat"), "case insensitive")
+	assert.True(t, contains("dev"), "tag")
+	assert.False(t, contains("gi"), "prefix shorter than MinPrefixLen")
+	assert.False(t, contains("gitlab"), "different token")
+
+	// Термины зависят от ключа: другой ключ не находит запись
+	other, err := search.NewIndexer("another-key")
+	assert.NoError(t, err)
+	assert.NotEqual(t, ix.QueryTerms("github"), other.QueryTerms("github"))
+}
+
+func TestScore(t *testing.T) {
+	site := search.Fields{Site: "github.com", Username: "alice"}
+	user := search.Fields{Site: "example.com", Username: "github-bot"}
+	prefix := search.Fields{Site: "gitea.io"}
+
+	assert.Greater(t, search.Score("github", site), search.Score("github", user))
+	assert.Greater(t, search.Score("github", user), search.Score("git", prefix))
+	assert.Greater(t, search.Score("git", prefix), 0)
+	assert.Equal(t, 0, search.Score("gitlab", site))
+}
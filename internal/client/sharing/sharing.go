@@ -0,0 +1,235 @@
+// Package sharing реализует шифрование данных общих записей и передачу их ключей
+// другим пользователям.
+//
+// Пара ключей X25519 пользователя выводится из секрета клиента
+// (security.encryption_key) через HKDF-SHA256, поэтому закрытый ключ не хранится
+// отдельно и одинаков на всех устройствах пользователя. Сервер получает только
+// открытый ключ.
+//
+// Ключ записи случаен (NewKey). Перед выдачей доступа данные записи шифруются на нем
+// функцией Seal (AES-256-GCM), поэтому сервер хранит их в виде шифртекста. Ключ
+// шифруется для каждого пользователя по схеме ECIES: одноразовый ключ X25519, общий
+// секрет которого с открытым ключом получателя через HKDF-SHA256 дает ключ
+// AES-256-GCM. Ключ, зашифрованный для владельца, хранится в заголовке данных
+// записи, ключи получателей - в выданных доступах. Сервер хранит их как есть
+// и не может расшифровать. При отзыве доступа владелец шифрует данные новым
+// ключом, поэтому прежний ключ больше ничего не открывает.
+package sharing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// KeySize - размер открытого ключа X25519 и ключа записи.
+const KeySize = 32
+
+// Контексты HKDF, разделяющие ключи разного назначения, выведенные из одного секрета.
+const (
+	identityInfo = "goph-keeper identity key"
+	wrapInfo     = "goph-keeper wrapped record key"
+)
+
+// SealedPrefix - префикс данных записи, зашифрованных функцией Seal.
+const SealedPrefix = "sealed:v1:"
+
+var (
+	// ErrInvalidKey - открытый ключ получателя имеет неверный формат.
+	ErrInvalidKey = errors.New("invalid public key")
+	// ErrUnwrap - ключ записи зашифрован не для этого пользователя или поврежден.
+	ErrUnwrap = errors.New("cannot decrypt record key")
+	// ErrOpen - данные записи зашифрованы другим ключом или повреждены.
+	ErrOpen = errors.New("cannot decrypt record data")
+)
+
+// derive возвращает KeySize байт, выведенных из секрета secret с солью salt и контекстом info.
+func derive(secret, salt, info string) []byte {
+	key := make([]byte, KeySize)
+	r := hkdf.New(sha256.New, []byte(secret), []byte(salt), []byte(info))
+	// HKDF-SHA256 выдает до 8160 байт, поэтому чтение 32 байт не завершается ошибкой
+	_, _ = io.ReadFull(r, key)
+	return key
+}
+
+// Identity возвращает пару ключей X25519 пользователя с секретом secret.
+func Identity(secret string) (*ecdh.PrivateKey, error) {
+	if secret == "" {
+		return nil, errors.New("empty secret")
+	}
+	return ecdh.X25519().NewPrivateKey(derive(secret, "", identityInfo))
+}
+
+// NewKey возвращает новый случайный ключ записи.
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Wrap шифрует ключ записи key на открытом ключе получателя recipient.
+// Результат: одноразовый открытый ключ, nonce и шифртекст AES-256-GCM.
+func Wrap(recipient, key []byte) ([]byte, error) {
+	pub, err := ecdh.X25519().NewPublicKey(recipient)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(pub)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	epk := ephemeral.PublicKey().Bytes()
+	aead, err := newAEAD(shared, epk, recipient)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append(epk, nonce...)
+	return aead.Seal(out, nonce, key, recipient), nil
+}
+
+// Unwrap расшифровывает ключ записи, зашифрованный функцией Wrap на открытом ключе priv.
+func Unwrap(priv *ecdh.PrivateKey, wrapped []byte) ([]byte, error) {
+	recipient := priv.PublicKey().Bytes()
+	if len(wrapped) < KeySize {
+		return nil, ErrUnwrap
+	}
+	epk, rest := wrapped[:KeySize], wrapped[KeySize:]
+
+	pub, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+	aead, err := newAEAD(shared, epk, recipient)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrUnwrap
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+
+	key, err := aead.Open(nil, nonce, ciphertext, recipient)
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+	return key, nil
+}
+
+// newAEAD создает шифр AES-256-GCM на ключе, выведенном из общего секрета shared
+// и открытых ключей обеих сторон.
+func newAEAD(shared, epk, recipient []byte) (cipher.AEAD, error) {
+	salt := string(epk) + string(recipient)
+	block, err := aes.NewCipher(derive(string(shared), salt, wrapInfo))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Sealed сообщает, зашифрованы ли данные записи data функцией Seal.
+func Sealed(data string) bool {
+	return strings.HasPrefix(data, SealedPrefix)
+}
+
+// Seal шифрует данные записи credentialID ключом записи key. owner - ключ записи,
+// зашифрованный функцией Wrap для владельца; он хранится в заголовке данных.
+// Результат: SealedPrefix, base64 от owner и через точку base64 от nonce
+// и шифртекста AES-256-GCM. Идентификатор записи и owner - связанные данные.
+func Seal(key, owner []byte, credentialID, data string) (string, error) {
+	aead, err := recordAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(data), associatedData(credentialID, owner))
+	return SealedPrefix + base64.StdEncoding.EncodeToString(owner) + "." +
+		base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OwnerKey возвращает ключ записи, зашифрованный для владельца, из заголовка
+// данных data, зашифрованных функцией Seal.
+func OwnerKey(data string) ([]byte, error) {
+	owner, _, err := parseSealed(data)
+	return owner, err
+}
+
+// Open расшифровывает данные записи credentialID, зашифрованные функцией Seal.
+func Open(key []byte, credentialID, data string) (string, error) {
+	owner, raw, err := parseSealed(data)
+	if err != nil {
+		return "", err
+	}
+	aead, err := recordAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < aead.NonceSize() {
+		return "", ErrOpen
+	}
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():],
+		associatedData(credentialID, owner))
+	if err != nil {
+		return "", ErrOpen
+	}
+	return string(plain), nil
+}
+
+// parseSealed разбирает данные, зашифрованные функцией Seal, на ключ владельца
+// и nonce с шифртекстом.
+func parseSealed(data string) (owner, raw []byte, err error) {
+	if !Sealed(data) {
+		return nil, nil, ErrOpen
+	}
+	header, body, ok := strings.Cut(strings.TrimPrefix(data, SealedPrefix), ".")
+	if !ok {
+		return nil, nil, ErrOpen
+	}
+	if owner, err = base64.StdEncoding.DecodeString(header); err != nil || len(owner) == 0 {
+		return nil, nil, ErrOpen
+	}
+	if raw, err = base64.StdEncoding.DecodeString(body); err != nil {
+		return nil, nil, ErrOpen
+	}
+	return owner, raw, nil
+}
+
+// associatedData связывает шифртекст с записью credentialID и ключом владельца owner.
+func associatedData(credentialID string, owner []byte) []byte {
+	return append([]byte(credentialID+"."), owner...)
+}
+
+// recordAEAD создает шифр AES-256-GCM на ключе записи key.
+func recordAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,149 @@
+package sharing_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/sol1corejz/goph-keeper/internal/client/sharing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityIsDeterministic(t *testing.T) {
+	a, err := sharing.Identity("secret")
+	require.NoError(t, err)
+	b, err := sharing.Identity("secret")
+	require.NoError(t, err)
+	c, err := sharing.Identity("other")
+	require.NoError(t, err)
+
+	assert.Equal(t, a.PublicKey().Bytes(), b.PublicKey().Bytes())
+	assert.NotEqual(t, a.PublicKey().Bytes(), c.PublicKey().Bytes())
+	assert.Len(t, a.PublicKey().Bytes(), sharing.KeySize)
+
+	_, err = sharing.Identity("")
+	assert.Error(t, err)
+}
+
+func TestNewKey(t *testing.T) {
+	key, err := sharing.NewKey()
+	require.NoError(t, err)
+	assert.Len(t, key, sharing.KeySize)
+
+	other, err := sharing.NewKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+// newKey возвращает новый ключ записи.
+func newKey(t *testing.T) []byte {
+	key, err := sharing.NewKey()
+	require.NoError(t, err)
+	return key
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	grantee, err := sharing.Identity("grantee secret")
+	require.NoError(t, err)
+	key := newKey(t)
+
+	wrapped, err := sharing.Wrap(grantee.PublicKey().Bytes(), key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(wrapped), string(key))
+
+	got, err := sharing.Unwrap(grantee, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	// Каждое шифрование использует новый одноразовый ключ
+	again, err := sharing.Wrap(grantee.PublicKey().Bytes(), key)
+	require.NoError(t, err)
+	assert.NotEqual(t, wrapped, again)
+}
+
+func TestUnwrapErrors(t *testing.T) {
+	grantee, err := sharing.Identity("grantee secret")
+	require.NoError(t, err)
+	other, err := sharing.Identity("other secret")
+	require.NoError(t, err)
+
+	wrapped, err := sharing.Wrap(grantee.PublicKey().Bytes(), newKey(t))
+	require.NoError(t, err)
+
+	// Ключ, зашифрованный для другого пользователя
+	_, err = sharing.Unwrap(other, wrapped)
+	assert.ErrorIs(t, err, sharing.ErrUnwrap)
+
+	// Поврежденный ключ
+	broken := append([]byte(nil), wrapped...)
+	broken[len(broken)-1] ^= 1
+	_, err = sharing.Unwrap(grantee, broken)
+	assert.ErrorIs(t, err, sharing.ErrUnwrap)
+
+	_, err = sharing.Unwrap(grantee, wrapped[:10])
+	assert.ErrorIs(t, err, sharing.ErrUnwrap)
+
+	_, err = sharing.Wrap([]byte("short"), []byte("key"))
+	assert.ErrorIs(t, err, sharing.ErrInvalidKey)
+}
+
+func TestSealOpen(t *testing.T) {
+	owner, err := sharing.Identity("owner secret")
+	require.NoError(t, err)
+	key := newKey(t)
+	wrapped, err := sharing.Wrap(owner.PublicKey().Bytes(), key)
+	require.NoError(t, err)
+
+	sealed, err := sharing.Seal(key, wrapped, "c1", "login: alice\npassword: secret")
+	require.NoError(t, err)
+	assert.True(t, sharing.Sealed(sealed))
+	assert.NotContains(t, sealed, "secret")
+	assert.False(t, sharing.Sealed("login: alice"))
+
+	// Владелец получает ключ записи из заголовка её данных
+	header, err := sharing.OwnerKey(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, wrapped, header)
+	got, err := sharing.Unwrap(owner, header)
+	require.NoError(t, err)
+
+	data, err := sharing.Open(got, "c1", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "login: alice\npassword: secret", data)
+
+	// Данные привязаны к ключу и идентификатору записи
+	_, err = sharing.Open(newKey(t), "c1", sealed)
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+	_, err = sharing.Open(key, "c2", sealed)
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+	_, err = sharing.Open(key, "c1", "login: alice")
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+	_, err = sharing.Open(key, "c1", sharing.SealedPrefix+"!!")
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+	_, err = sharing.OwnerKey("login: alice")
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+}
+
+func TestSealBindsOwnerKey(t *testing.T) {
+	owner, err := sharing.Identity("owner secret")
+	require.NoError(t, err)
+	key := newKey(t)
+	wrapped, err := sharing.Wrap(owner.PublicKey().Bytes(), key)
+	require.NoError(t, err)
+	sealed, err := sharing.Seal(key, wrapped, "c1", "data")
+	require.NoError(t, err)
+
+	// Заголовок с чужим ключом владельца не подходит к шифртексту
+	other, err := sharing.Wrap(owner.PublicKey().Bytes(), newKey(t))
+	require.NoError(t, err)
+	_, body, _ := strings.Cut(strings.TrimPrefix(sealed, sharing.SealedPrefix), ".")
+	forged := sharing.SealedPrefix + base64.StdEncoding.EncodeToString(other) + "." + body
+	_, err = sharing.Open(key, "c1", forged)
+	assert.ErrorIs(t, err, sharing.ErrOpen)
+
+	// Каждое шифрование использует новый nonce
+	again, err := sharing.Seal(key, wrapped, "c1", "data")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/otp"
+)
+
+// Types - типы записей, которые можно выбрать в форме.
+var Types = []string{"login", "text", "card", "otp", "binary"}
+
+// Поля формы.
+const (
+	fieldType = iota
+	fieldMeta
+	fieldData
+	fieldTags
+	fieldFolder
+	fieldCount
+)
+
+// fieldLabels - подписи полей формы.
+var fieldLabels = [fieldCount]string{"Тип", "Метаданные", "Данные", "Теги", "Папка"}
+
+// form - форма добавления или изменения записи.
+type form struct {
+	prev   backup.Entry // изменяемая запись; пустая для новой
+	isNew  bool
+	inputs [fieldCount]textinput.Model
+	focus  int
+}
+
+// newForm создает форму, заполненную полями записи e.
+func newForm(e backup.Entry, isNew bool) *form {
+	f := &form{prev: e, isNew: isNew}
+	values := [fieldCount]string{e.Type, e.Meta, e.Data, strings.Join(e.Tags, ", "), e.Folder}
+	for i := range f.inputs {
+		in := textinput.New()
+		in.Prompt = ""
+		in.CharLimit = 0
+		in.SetValue(values[i])
+		f.inputs[i] = in
+	}
+	f.inputs[fieldType].Placeholder = strings.Join(Types, ", ")
+	f.inputs[fieldMeta].Placeholder = "title:GitHub, website:https://github.com"
+	f.inputs[fieldData].Placeholder = "login:alice, password:..."
+	f.inputs[fieldTags].Placeholder = "work, dev"
+	f.inputs[fieldFolder].Placeholder = "work/dev"
+	// Данные содержат секреты и скрыты, пока их не показать
+	f.inputs[fieldData].EchoMode = textinput.EchoPassword
+
+	f.focus = fieldType
+	if !isNew {
+		// Тип существующей записи не изменяется
+		f.focus = fieldMeta
+	}
+	f.inputs[f.focus].Focus()
+	return f
+}
+
+// first возвращает первое доступное для ввода поле.
+func (f *form) first() int {
+	if f.isNew {
+		return fieldType
+	}
+	return fieldMeta
+}
+
+// last сообщает, что активно последнее поле формы.
+func (f *form) last() bool {
+	return f.focus == fieldCount-1
+}
+
+// update обрабатывает ввод в форме.
+func (f *form) update(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "tab", "down", "enter":
+		return f.setFocus(f.focus + 1)
+	case "shift+tab", "up":
+		return f.setFocus(f.focus - 1)
+	case "ctrl+r":
+		in := &f.inputs[fieldData]
+		if in.EchoMode == textinput.EchoPassword {
+			in.EchoMode = textinput.EchoNormal
+		} else {
+			in.EchoMode = textinput.EchoPassword
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return cmd
+}
+
+// setFocus делает активным поле i.
+func (f *form) setFocus(i int) tea.Cmd {
+	i = clamp(i, f.first(), fieldCount-1)
+	f.inputs[f.focus].Blur()
+	f.focus = i
+	return f.inputs[i].Focus()
+}
+
+// entry проверяет значения формы и возвращает запись для сохранения.
+func (f *form) entry() (backup.Entry, error) {
+	e := f.prev
+	e.Type = strings.TrimSpace(f.inputs[fieldType].Value())
+	e.Meta = strings.TrimSpace(f.inputs[fieldMeta].Value())
+	e.Data = strings.TrimSpace(f.inputs[fieldData].Value())
+	e.Tags = parseTags(f.inputs[fieldTags].Value())
+	e.Folder = strings.Trim(path.Clean("/"+strings.TrimSpace(f.inputs[fieldFolder].Value())), "/")
+
+	switch {
+	case !slices.Contains(Types, e.Type):
+		return e, fmt.Errorf("неизвестный тип %q, допустимы: %s", e.Type, strings.Join(Types, ", "))
+	case e.Meta == "":
+		return e, errors.New("не заполнены метаданные")
+	case e.Data == "":
+		return e, errors.New("не заполнены данные")
+	}
+	if e.Type == otp.CredentialType {
+		if _, err := otp.Parse(e.Data); err != nil {
+			return e, fmt.Errorf("некорректный ключ одноразовых паролей: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// parseTags разбирает список тегов через запятую без пустых и повторяющихся тегов.
+func parseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,534 @@
+// Package tui реализует полноэкранный интерфейс клиента для просмотра и изменения
+// записей хранилища: дерево папок, список записей с нечетким поиском, карточку
+// записи со скрытыми до запроса секретами, копирование в буфер обмена и формы
+// добавления и изменения записей.
+//
+// Записи загружаются и сохраняются через Backend, поэтому интерфейс одинаково
+// работает с сервером и с локальным архивом без подключения к сети.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/otp"
+)
+
+// ErrReadOnly - изменение записей без подключения к серверу.
+var ErrReadOnly = errors.New("vault is read-only offline")
+
+// Backend - источник записей хранилища.
+type Backend interface {
+	// Load возвращает все записи и папки пользователя.
+	Load(ctx context.Context) (backup.Vault, error)
+	// Save сохраняет запись e: добавляет новую, если ID пустой, иначе изменяет
+	// запись prev. Возвращает сохраненную запись.
+	Save(ctx context.Context, e, prev backup.Entry) (backup.Entry, error)
+	// Offline сообщает, что записи загружены из локального архива и не изменяются.
+	Offline() bool
+}
+
+// Clipboard - буфер обмена.
+type Clipboard interface {
+	ReadAll() (string, error)
+	WriteAll(text string) error
+}
+
+// Options - параметры интерфейса.
+type Options struct {
+	Clipboard        Clipboard        // буфер обмена; nil - копирование недоступно
+	ClipboardTimeout time.Duration    // время до очистки буфера после копирования; 0 - не очищать
+	Timeout          time.Duration    // таймаут обращения к Backend
+	Now              func() time.Time // текущее время для кодов TOTP; nil - time.Now
+}
+
+// pane - область экрана.
+type pane int
+
+const (
+	paneFolders pane = iota
+	paneList
+	paneDetail
+)
+
+// mode - режим ввода.
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeFilter
+	modeForm
+)
+
+// Сообщения фоновых операций.
+type (
+	loadedMsg struct {
+		vault backup.Vault
+		err   error
+	}
+	savedMsg struct {
+		entry backup.Entry
+		err   error
+	}
+	clearClipboardMsg struct{ text string }
+	tickMsg           time.Time
+)
+
+// Model - состояние интерфейса (модель Bubble Tea).
+type Model struct {
+	ctx     context.Context
+	backend Backend
+	opts    Options
+
+	vault    backup.Vault
+	folders  []string       // дерево папок без корня
+	visible  []backup.Entry // записи выбранной папки, подходящие под фильтр
+	loading  bool
+	offline  bool
+	status   string
+	width    int
+	height   int
+	focus    pane
+	mode     mode
+	folder   int // 0 - все записи, i - folders[i-1]
+	cursor   int
+	revealed bool
+	ticking  bool   // запланировано обновление кода TOTP
+	copied   string // скопированное значение, ожидающее очистки буфера
+
+	filter textinput.Model
+	form   *form
+}
+
+// New создает модель интерфейса для хранилища backend.
+func New(ctx context.Context, backend Backend, opts Options) *Model {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "поиск"
+	return &Model{
+		ctx:     ctx,
+		backend: backend,
+		opts:    opts,
+		loading: true,
+		focus:   paneList,
+		filter:  filter,
+	}
+}
+
+// Run запускает полноэкранный интерфейс и возвращает управление после выхода.
+// Скопированное значение, ожидающее очистки, удаляется из буфера обмена и при выходе,
+// так как запланированная очистка завершается вместе с программой.
+func Run(ctx context.Context, backend Backend, opts Options) error {
+	m := New(ctx, backend, opts)
+	_, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	// Программа могла завершиться отменой ctx, минуя обработку клавиш выхода
+	m.clearClipboard(m.copied)
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Init загружает записи хранилища.
+func (m *Model) Init() tea.Cmd {
+	return m.load()
+}
+
+// load загружает записи в фоне.
+func (m *Model) load() tea.Cmd {
+	m.loading = true
+	return func() tea.Msg {
+		ctx, cancel := m.withTimeout()
+		defer cancel()
+		v, err := m.backend.Load(ctx)
+		return loadedMsg{vault: v, err: err}
+	}
+}
+
+// save сохраняет запись в фоне.
+func (m *Model) save(e, prev backup.Entry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := m.withTimeout()
+		defer cancel()
+		saved, err := m.backend.Save(ctx, e, prev)
+		return savedMsg{entry: saved, err: err}
+	}
+}
+
+// withTimeout возвращает контекст обращения к Backend.
+func (m *Model) withTimeout() (context.Context, context.CancelFunc) {
+	if m.opts.Timeout > 0 {
+		return context.WithTimeout(m.ctx, m.opts.Timeout)
+	}
+	return context.WithCancel(m.ctx)
+}
+
+// Update обрабатывает события клавиатуры и результаты фоновых операций.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case loadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Ошибка загрузки: " + msg.err.Error()
+			return m, nil
+		}
+		m.vault = msg.vault
+		m.offline = m.backend.Offline()
+		m.refresh()
+		m.status = fmt.Sprintf("Записей: %d", len(m.vault.Entries))
+		if m.offline {
+			m.status += " (без подключения, только просмотр)"
+		}
+		return m, nil
+
+	case savedMsg:
+		if msg.err != nil {
+			m.status = "Ошибка сохранения: " + msg.err.Error()
+			return m, nil
+		}
+		m.form = nil
+		m.mode = modeBrowse
+		m.store(msg.entry)
+		m.status = "Запись сохранена"
+		return m, nil
+
+	case clearClipboardMsg:
+		if m.clearClipboard(msg.text) {
+			m.status = "Буфер обмена очищен"
+		}
+		if msg.text == m.copied {
+			m.copied = ""
+		}
+		return m, nil
+
+	case tickMsg:
+		// Код TOTP обновляется, пока он показан
+		if e, ok := m.selected(); ok && m.revealed && e.Type == otp.CredentialType {
+			return m, tick()
+		}
+		m.ticking = false
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, m.quit()
+		}
+		switch m.mode {
+		case modeFilter:
+			return m.updateFilter(msg)
+		case modeForm:
+			return m.updateForm(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+// updateBrowse обрабатывает клавиши в режиме просмотра.
+func (m *Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, m.quit()
+	case "tab":
+		m.focus = (m.focus + 1) % 3
+	case "shift+tab":
+		m.focus = (m.focus + 2) % 3
+	case "left", "h":
+		if m.focus > paneFolders {
+			m.focus--
+		}
+	case "right", "l", "enter":
+		if m.focus < paneDetail {
+			m.focus++
+		}
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "home", "g":
+		m.move(-len(m.vault.Entries) - len(m.folders) - 1)
+	case "end", "G":
+		m.move(len(m.vault.Entries) + len(m.folders) + 1)
+	case "/":
+		m.mode = modeFilter
+		m.focus = paneList
+		return m, m.filter.Focus()
+	case "esc":
+		if m.filter.Value() != "" {
+			m.filter.SetValue("")
+			m.refresh()
+		}
+	case "r":
+		if _, ok := m.selected(); ok {
+			m.revealed = !m.revealed
+			if m.revealed && !m.ticking {
+				m.ticking = true
+				return m, tick()
+			}
+		}
+	case "c":
+		return m, m.copySecret()
+	case "u":
+		if e, ok := m.selected(); ok {
+			return m, m.copy(Username(e), "логин")
+		}
+	case "ctrl+r":
+		return m, m.load()
+	case "n":
+		if m.checkWritable() {
+			folder := ""
+			if m.folder > 0 {
+				folder = m.folders[m.folder-1]
+			}
+			m.form = newForm(backup.Entry{Type: "login", Folder: folder}, true)
+			m.mode = modeForm
+		}
+	case "e":
+		if e, ok := m.selected(); ok && m.checkWritable() {
+			m.form = newForm(e, false)
+			m.mode = modeForm
+		}
+	case "f":
+		if e, ok := m.selected(); ok && m.checkWritable() {
+			updated := e
+			updated.Favorite = !e.Favorite
+			return m, m.save(updated, e)
+		}
+	}
+	return m, nil
+}
+
+// updateFilter обрабатывает ввод запроса поиска.
+func (m *Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.mode = modeBrowse
+		m.filter.Blur()
+		return m, nil
+	case "esc":
+		m.mode = modeBrowse
+		m.filter.Blur()
+		m.filter.SetValue("")
+		m.refresh()
+		return m, nil
+	case "up", "down":
+		m.move(map[string]int{"up": -1, "down": 1}[msg.String()])
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.refresh()
+	return m, cmd
+}
+
+// updateForm обрабатывает ввод в форме записи.
+func (m *Model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		m.mode = modeBrowse
+		m.status = "Изменения отменены"
+		return m, nil
+	case "ctrl+s":
+		return m, m.submit()
+	case "enter":
+		if m.form.last() {
+			return m, m.submit()
+		}
+	}
+	return m, m.form.update(msg)
+}
+
+// submit проверяет форму и сохраняет запись.
+func (m *Model) submit() tea.Cmd {
+	e, err := m.form.entry()
+	if err != nil {
+		m.status = err.Error()
+		return nil
+	}
+	m.status = "Сохранение..."
+	return m.save(e, m.form.prev)
+}
+
+// checkWritable сообщает, можно ли изменять записи, и объясняет, почему нельзя.
+func (m *Model) checkWritable() bool {
+	if m.offline {
+		m.status = "Без подключения к серверу записи доступны только для просмотра"
+		return false
+	}
+	if m.loading {
+		m.status = "Записи еще загружаются"
+		return false
+	}
+	return true
+}
+
+// move перемещает курсор в активной области на delta позиций.
+func (m *Model) move(delta int) {
+	switch m.focus {
+	case paneFolders:
+		m.folder = clamp(m.folder+delta, 0, len(m.folders))
+		m.cursor = 0
+		m.refresh()
+	case paneList, paneDetail:
+		m.cursor = clamp(m.cursor+delta, 0, len(m.visible)-1)
+	}
+	m.revealed = false
+}
+
+// refresh пересчитывает дерево папок и видимые записи, сохраняя выбранную запись.
+func (m *Model) refresh() {
+	selectedID := ""
+	if e, ok := m.selected(); ok {
+		selectedID = e.ID
+	}
+	current := ""
+	if m.folder > 0 && m.folder <= len(m.folders) {
+		current = m.folders[m.folder-1]
+	}
+
+	m.folders = FolderTree(m.vault)
+	m.folder = 0
+	if i := slices.Index(m.folders, current); i >= 0 {
+		m.folder = i + 1
+	}
+	m.visible = Filter(m.vault.Entries, current, m.filter.Value())
+
+	m.cursor = clamp(m.cursor, 0, len(m.visible)-1)
+	if i := slices.IndexFunc(m.visible, func(e backup.Entry) bool { return e.ID == selectedID }); i >= 0 && selectedID != "" {
+		m.cursor = i
+	}
+}
+
+// store добавляет сохраненную запись или заменяет прежнюю и выбирает ее.
+func (m *Model) store(e backup.Entry) {
+	i := slices.IndexFunc(m.vault.Entries, func(x backup.Entry) bool { return x.ID == e.ID })
+	if i >= 0 {
+		m.vault.Entries[i] = e
+	} else {
+		m.vault.Entries = append(m.vault.Entries, e)
+	}
+	m.refresh()
+	if i := slices.IndexFunc(m.visible, func(x backup.Entry) bool { return x.ID == e.ID }); i >= 0 {
+		m.cursor = i
+	}
+	m.revealed = false
+}
+
+// selected возвращает выбранную запись.
+func (m *Model) selected() (backup.Entry, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.visible) {
+		return backup.Entry{}, false
+	}
+	return m.visible[m.cursor], true
+}
+
+// copySecret копирует пароль выбранной записи, текущий код TOTP записи типа otp
+// или данные записи без пароля.
+func (m *Model) copySecret() tea.Cmd {
+	e, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if e.Type == otp.CredentialType {
+		code, err := m.totp(e)
+		if err != nil {
+			m.status = err.Error()
+			return nil
+		}
+		return m.copy(code, "код")
+	}
+	if password := Password(e.Data); password != "" {
+		return m.copy(password, "пароль")
+	}
+	return m.copy(e.Data, "данные")
+}
+
+// copy копирует text в буфер обмена и планирует очистку буфера.
+func (m *Model) copy(text, what string) tea.Cmd {
+	if m.opts.Clipboard == nil {
+		m.status = "Буфер обмена недоступен"
+		return nil
+	}
+	if text == "" {
+		m.status = "Нечего копировать: поле «" + what + "» пусто"
+		return nil
+	}
+	if err := m.opts.Clipboard.WriteAll(text); err != nil {
+		m.status = "Ошибка копирования: " + err.Error()
+		return nil
+	}
+	m.status = "Скопировано в буфер обмена: " + what
+	if m.opts.ClipboardTimeout <= 0 {
+		return nil
+	}
+	m.status += fmt.Sprintf(", очистка через %s", m.opts.ClipboardTimeout)
+	m.copied = text
+	return tea.Tick(m.opts.ClipboardTimeout, func(time.Time) tea.Msg {
+		return clearClipboardMsg{text: text}
+	})
+}
+
+// clearClipboard очищает буфер обмена, если в нем осталось значение text, и сообщает,
+// был ли буфер очищен. Значение, замененное пользователем, не трогается.
+func (m *Model) clearClipboard(text string) bool {
+	if m.opts.Clipboard == nil || text == "" {
+		return false
+	}
+	current, err := m.opts.Clipboard.ReadAll()
+	if err != nil || current != text {
+		return false
+	}
+	return m.opts.Clipboard.WriteAll("") == nil
+}
+
+// quit очищает буфер от скопированного значения, ожидающего очистки, и завершает
+// программу: запланированная очистка завершилась бы вместе с ней.
+func (m *Model) quit() tea.Cmd {
+	m.clearClipboard(m.copied)
+	m.copied = ""
+	return tea.Quit
+}
+
+// totp возвращает текущий код записи типа otp. Коды HOTP выдаются командой otp,
+// так как требуют увеличения счетчика на сервере.
+func (m *Model) totp(e backup.Entry) (string, error) {
+	key, err := otp.Parse(e.Data)
+	if err != nil {
+		return "", fmt.Errorf("некорректный ключ одноразовых паролей: %w", err)
+	}
+	if key.Type != otp.TypeTOTP {
+		return "", errors.New("коды HOTP выдает команда keepercli otp")
+	}
+	code, _, err := key.TOTP(m.opts.Now())
+	return code, err
+}
+
+// tick планирует обновление экрана через секунду.
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// clamp ограничивает v диапазоном [lo, hi]; при пустом диапазоне возвращает lo.
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,287 @@
+package tui_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend хранит записи в памяти и запоминает сохраненные.
+type fakeBackend struct {
+	vault   backup.Vault
+	offline bool
+	err     error
+	saved   []backup.Entry
+}
+
+func (b *fakeBackend) Load(context.Context) (backup.Vault, error) {
+	return b.vault, nil
+}
+
+func (b *fakeBackend) Save(_ context.Context, e, _ backup.Entry) (backup.Entry, error) {
+	if b.offline {
+		return e, tui.ErrReadOnly
+	}
+	if b.err != nil {
+		return e, b.err
+	}
+	if e.ID == "" {
+		e.ID = "new"
+	}
+	b.saved = append(b.saved, e)
+	return e, nil
+}
+
+func (b *fakeBackend) Offline() bool {
+	return b.offline
+}
+
+// fakeClipboard - буфер обмена в памяти.
+type fakeClipboard struct{ text string }
+
+func (c *fakeClipboard) ReadAll() (string, error) { return c.text, nil }
+
+func (c *fakeClipboard) WriteAll(text string) error {
+	c.text = text
+	return nil
+}
+
+func testVault() backup.Vault {
+	return backup.Vault{
+		Folders: []string{"work/dev", "personal"},
+		Entries: []backup.Entry{
+			{ID: "1", Type: "login", Data: "login:alice, password:hunter2", Meta: "title:GitHub, website:github.com", Folder: "work/dev", Tags: []string{"code"}},
+			{ID: "2", Type: "login", Data: "login:bob, password:qwerty", Meta: "title:Bank", Folder: "personal", Favorite: true},
+			{ID: "3", Type: "text", Data: "wifi code 12345", Meta: "title:Home Wi-Fi"},
+		},
+	}
+}
+
+// start создает модель и загружает в нее записи.
+func start(t *testing.T, backend tui.Backend, clip tui.Clipboard) *tui.Model {
+	m := tui.New(context.Background(), backend, tui.Options{Clipboard: clip})
+	m.Update(m.Init()())
+	m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	return m
+}
+
+// press отправляет модели клавиши и возвращает команду последней клавиши.
+func press(m *tui.Model, keys ...tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		_, cmd = m.Update(k)
+	}
+	return cmd
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPairs(t *testing.T) {
+	pairs, ok := tui.Pairs("login:alice, address:Main st, 5, website:https://example.com")
+	require.True(t, ok)
+	assert.Equal(t, []tui.Pair{
+		{Key: "login", Value: "alice"},
+		{Key: "address", Value: "Main st, 5"},
+		{Key: "website", Value: "https://example.com"},
+	}, pairs)
+
+	// Экранированная запятая не разделяет поля
+	pairs, ok = tui.Pairs(`password:p\,ss, note:a\, b`)
+	require.True(t, ok)
+	assert.Equal(t, []tui.Pair{{Key: "password", Value: "p,ss"}, {Key: "note", Value: "a, b"}}, pairs)
+
+	_, ok = tui.Pairs("just some text")
+	assert.False(t, ok)
+	assert.True(t, tui.IsSecret("Password"))
+	assert.False(t, tui.IsSecret("login"))
+}
+
+func TestFolderTree(t *testing.T) {
+	v := testVault()
+	v.Entries = append(v.Entries, backup.Entry{ID: "4", Folder: "work/ops/prod"})
+	assert.Equal(t, []string{"personal", "work", "work/dev", "work/ops", "work/ops/prod"}, tui.FolderTree(v))
+}
+
+func TestFilter(t *testing.T) {
+	entries := testVault().Entries
+
+	// Без запроса избранные записи первые, остальные по названию
+	all := tui.Filter(entries, "", "")
+	assert.Equal(t, []string{"Bank", "GitHub", "Home Wi-Fi"}, titles(all))
+
+	assert.Equal(t, []string{"GitHub"}, titles(tui.Filter(entries, "work", "")))
+	assert.Equal(t, []string{"GitHub"}, titles(tui.Filter(entries, "", "gthb")))
+	assert.Equal(t, []string{"Bank"}, titles(tui.Filter(entries, "", "bob")))
+
+	// Пароли не участвуют в поиске
+	assert.Empty(t, tui.Filter(entries, "", "hunter2"))
+}
+
+func titles(entries []backup.Entry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = tui.Title(e)
+	}
+	return result
+}
+
+func TestRevealOnDemand(t *testing.T) {
+	m := start(t, &fakeBackend{vault: testVault()}, nil)
+
+	// Первая запись списка - избранная Bank
+	view := m.View()
+	assert.Contains(t, view, "Bank")
+	assert.Contains(t, view, "bob")
+	assert.NotContains(t, view, "qwerty")
+	assert.Contains(t, view, tui.Mask)
+
+	press(m, runes("r"))
+	assert.Contains(t, m.View(), "qwerty")
+
+	// Выбор другой записи снова скрывает секреты
+	press(m, tea.KeyMsg{Type: tea.KeyDown})
+	view = m.View()
+	assert.NotContains(t, view, "hunter2")
+	assert.Contains(t, view, "alice")
+}
+
+func TestCopyAndClearClipboard(t *testing.T) {
+	clip := &fakeClipboard{}
+	backend := &fakeBackend{vault: testVault()}
+	m := tui.New(context.Background(), backend, tui.Options{Clipboard: clip, ClipboardTimeout: time.Millisecond})
+	m.Update(m.Init()())
+
+	cmd := press(m, runes("c"))
+	assert.Equal(t, "qwerty", clip.text)
+	require.NotNil(t, cmd)
+	clear := cmd()
+
+	press(m, runes("u"))
+	assert.Equal(t, "bob", clip.text)
+
+	// Буфер не очищается, если в нем уже другое значение
+	m.Update(clear)
+	assert.Equal(t, "bob", clip.text)
+
+	clip.text = "qwerty"
+	m.Update(clear)
+	assert.Empty(t, clip.text)
+}
+
+func TestQuitClearsClipboard(t *testing.T) {
+	for _, key := range []tea.KeyMsg{runes("q"), {Type: tea.KeyCtrlC}} {
+		clip := &fakeClipboard{}
+		backend := &fakeBackend{vault: testVault()}
+		m := tui.New(context.Background(), backend, tui.Options{Clipboard: clip, ClipboardTimeout: time.Minute})
+		m.Update(m.Init()())
+
+		press(m, runes("c"))
+		assert.Equal(t, "qwerty", clip.text)
+		cmd := press(m, key)
+		require.NotNil(t, cmd)
+		assert.Equal(t, tea.QuitMsg{}, cmd())
+		assert.Empty(t, clip.text, key.String())
+	}
+
+	// Значение, замененное пользователем, остается в буфере
+	clip := &fakeClipboard{}
+	m := tui.New(context.Background(), &fakeBackend{vault: testVault()}, tui.Options{Clipboard: clip, ClipboardTimeout: time.Minute})
+	m.Update(m.Init()())
+	press(m, runes("c"))
+	clip.text = "other"
+	press(m, runes("q"))
+	assert.Equal(t, "other", clip.text)
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	m := start(t, &fakeBackend{vault: testVault()}, nil)
+
+	press(m, runes("/"), runes("w"), runes("i"), runes("f"))
+	view := m.View()
+	assert.Contains(t, view, "Home Wi-Fi")
+	assert.NotContains(t, view, "Bank")
+
+	press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Contains(t, m.View(), "Bank")
+}
+
+func TestEditForm(t *testing.T) {
+	backend := &fakeBackend{vault: testVault()}
+	m := start(t, backend, nil)
+
+	// Изменение метаданных выбранной записи Bank
+	press(m, runes("e"))
+	press(m, tea.KeyMsg{Type: tea.KeyEnd}, runes(", note:main"))
+	cmd := press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+
+	require.Len(t, backend.saved, 1)
+	assert.Equal(t, "2", backend.saved[0].ID)
+	assert.Equal(t, "title:Bank, note:main", backend.saved[0].Meta)
+	assert.Equal(t, "login:bob, password:qwerty", backend.saved[0].Data)
+	assert.Contains(t, m.View(), "Запись сохранена")
+}
+
+func TestNewEntryValidation(t *testing.T) {
+	backend := &fakeBackend{vault: testVault()}
+	m := start(t, backend, nil)
+
+	press(m, runes("n"))
+	cmd := press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.View(), "не заполнены метаданные")
+
+	// Тип, метаданные, данные, теги и папка
+	press(m, tea.KeyMsg{Type: tea.KeyTab}, runes("title:Mail"),
+		tea.KeyMsg{Type: tea.KeyTab}, runes("login:carol, password:s3cret"),
+		tea.KeyMsg{Type: tea.KeyTab}, runes("mail, personal"))
+	// Пароль в форме скрыт
+	assert.NotContains(t, m.View(), "s3cret")
+
+	cmd = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	require.Len(t, backend.saved, 1)
+	assert.Equal(t, "login", backend.saved[0].Type)
+	assert.Equal(t, []string{"mail", "personal"}, backend.saved[0].Tags)
+}
+
+func TestOfflineIsReadOnly(t *testing.T) {
+	backend := &fakeBackend{vault: testVault(), offline: true}
+	m := start(t, backend, nil)
+
+	assert.Contains(t, m.View(), "БЕЗ ПОДКЛЮЧЕНИЯ")
+	press(m, runes("e"))
+	assert.Contains(t, m.View(), "только для просмотра")
+	assert.Nil(t, press(m, runes("f")))
+	assert.Empty(t, backend.saved)
+
+	// Просмотр работает без подключения
+	press(m, runes("r"))
+	assert.Contains(t, m.View(), "qwerty")
+}
+
+func TestSaveError(t *testing.T) {
+	backend := &fakeBackend{vault: testVault(), err: errors.New("connection refused")}
+	m := start(t, backend, nil)
+
+	press(m, runes("e"))
+	cmd := press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+
+	// Форма остается открытой, чтобы изменения не потерялись
+	view := m.View()
+	assert.Contains(t, view, "Ошибка сохранения: connection refused")
+	assert.Contains(t, view, "Изменение: Bank")
+}
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/search"
+)
+
+// Mask - строка, которой заменяются скрытые значения.
+const Mask = "••••••••"
+
+// secretKeys - ключи данных записи, значения которых скрываются до запроса.
+var secretKeys = []string{"password", "pass", "pwd", "secret", "pin", "cvv", "cvc", "number", "card", "otp", "token", "key"}
+
+// passwordKeys - ключи, под которыми в данных записи указывается пароль.
+var passwordKeys = []string{"password", "pass", "pwd"}
+
+// Pair - поле данных или метаданных записи вида "key:value".
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Pairs разбирает строку вида "key:value, key2:value2" с сохранением порядка полей
+// и снятием экранирования (см. search.FormatPair). Если строка не содержит полей,
+// ok равно false.
+func Pairs(s string) (pairs []Pair, ok bool) {
+	for _, part := range search.SplitPairs(s) {
+		key, value, found := search.CutPair(part)
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || key == "" || strings.ContainsAny(key, " /") {
+			// Часть без ключа продолжает значение предыдущего поля, например
+			// неэкранированный адрес с запятой из записей, сохраненных вручную
+			if len(pairs) == 0 {
+				return nil, false
+			}
+			pairs[len(pairs)-1].Value += "," + search.Unescape(part)
+			continue
+		}
+		pairs = append(pairs, Pair{Key: key, Value: value})
+	}
+	return pairs, len(pairs) > 0
+}
+
+// IsSecret сообщает, скрывается ли значение поля key до запроса.
+func IsSecret(key string) bool {
+	return slices.Contains(secretKeys, strings.ToLower(key))
+}
+
+// Password возвращает пароль из данных записи.
+func Password(data string) string {
+	pairs := search.ParsePairs(data)
+	for _, key := range passwordKeys {
+		if v, ok := pairs[key]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// Title возвращает заголовок записи: название из метаданных, сайт или начало метаданных.
+func Title(e backup.Entry) string {
+	meta := search.ParsePairs(e.Meta)
+	if title := meta["title"]; title != "" {
+		return title
+	}
+	if site := search.ExtractFields(e.Data, e.Meta, nil).Site; site != "" {
+		return site
+	}
+	if e.Meta != "" {
+		return e.Meta
+	}
+	return e.ID
+}
+
+// Username возвращает логин записи.
+func Username(e backup.Entry) string {
+	return search.ExtractFields(e.Data, e.Meta, nil).Username
+}
+
+// InFolder сообщает, находится ли запись в папке folder или во вложенной папке.
+// Пустая папка означает все записи.
+func InFolder(e backup.Entry, folder string) bool {
+	return folder == "" || e.Folder == folder || strings.HasPrefix(e.Folder, folder+"/")
+}
+
+// FolderTree возвращает пути всех папок хранилища, включая родительские папки
+// и папки записей, в порядке обхода дерева.
+func FolderTree(v backup.Vault) []string {
+	seen := make(map[string]bool)
+	var add func(p string)
+	add = func(p string) {
+		if p == "" || p == "." || p == "/" || seen[p] {
+			return
+		}
+		seen[p] = true
+		add(path.Dir(p))
+	}
+	for _, f := range v.Folders {
+		add(f)
+	}
+	for _, e := range v.Entries {
+		add(e.Folder)
+	}
+
+	tree := make([]string, 0, len(seen))
+	for p := range seen {
+		tree = append(tree, p)
+	}
+	// Сравнение по частям пути ставит вложенные папки сразу за родительской
+	slices.SortFunc(tree, func(a, b string) int {
+		return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+	})
+	return tree
+}
+
+// entrySource - записи для нечеткого поиска. Поиск идет только по открытым полям:
+// заголовку, логину, тегам и папке.
+type entrySource []backup.Entry
+
+func (s entrySource) String(i int) string {
+	e := s[i]
+	return strings.Join([]string{Title(e), Username(e), strings.Join(e.Tags, " "), e.Folder}, " ")
+}
+
+func (s entrySource) Len() int {
+	return len(s)
+}
+
+// Filter возвращает записи папки folder, подходящие под запрос query. Без запроса
+// записи упорядочены по заголовку, избранные первыми; с запросом - по релевантности.
+func Filter(entries []backup.Entry, folder, query string) []backup.Entry {
+	var inFolder []backup.Entry
+	for _, e := range entries {
+		if InFolder(e, folder) {
+			inFolder = append(inFolder, e)
+		}
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		slices.SortStableFunc(inFolder, func(a, b backup.Entry) int {
+			if a.Favorite != b.Favorite {
+				if a.Favorite {
+					return -1
+				}
+				return 1
+			}
+			return cmp.Compare(strings.ToLower(Title(a)), strings.ToLower(Title(b)))
+		})
+		return inFolder
+	}
+
+	matches := fuzzy.FindFrom(query, entrySource(inFolder))
+	result := make([]backup.Entry, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, inFolder[m.Index])
+	}
+	return result
+}
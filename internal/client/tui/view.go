@@ -0,0 +1,270 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sol1corejz/goph-keeper/internal/client/backup"
+	"github.com/sol1corejz/goph-keeper/internal/client/otp"
+)
+
+// Размеры экрана до получения размеров терминала и ширина колонок.
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+	foldersWidth  = 24
+	listWidth     = 36
+)
+
+// Стили интерфейса.
+var (
+	paneStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	focusStyle   = paneStyle.BorderForeground(lipgloss.Color("62"))
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	cursorStyle  = lipgloss.NewStyle().Reverse(true)
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	offlineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+)
+
+// Подсказки по клавишам в режимах ввода.
+var help = map[mode]string{
+	modeBrowse: "tab область  ↑↓ выбор  / поиск  r показать  c копировать секрет  u копировать логин  n новая  e изменить  f избранное  ctrl+r обновить  q выход",
+	modeFilter: "enter применить  esc сбросить  ↑↓ выбор",
+	modeForm:   "tab/↑↓ поле  ctrl+r показать данные  ctrl+s сохранить  esc отмена",
+}
+
+// View отрисовывает экран.
+func (m *Model) View() string {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	// Заголовок, строка состояния и подсказка занимают три строки, рамки - по две
+	inner := max(height-5, 1)
+	detailWidth := max(width-foldersWidth-listWidth-6, 20)
+
+	right := m.viewDetail(detailWidth, inner)
+	if m.mode == modeForm {
+		right = m.viewForm(detailWidth)
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		m.pane(paneFolders, foldersWidth, inner).Render(m.viewFolders(foldersWidth, inner)),
+		m.pane(paneList, listWidth, inner).Render(m.viewList(listWidth, inner)),
+		m.pane(paneDetail, detailWidth, inner).Render(clip(right, detailWidth, inner)),
+	)
+
+	header := titleStyle.Render("goph-keeper")
+	if m.offline {
+		header += "  " + offlineStyle.Render("БЕЗ ПОДКЛЮЧЕНИЯ · только просмотр")
+	}
+	if m.mode == modeFilter || m.filter.Value() != "" {
+		header += "  " + m.filter.View()
+	}
+	status := m.status
+	if m.loading {
+		status = "Загрузка..."
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		ansi.Truncate(header, width, "…"),
+		body,
+		ansi.Truncate(status, width, "…"),
+		dimStyle.Render(ansi.Truncate(help[m.mode], width, "…")),
+	)
+}
+
+// pane возвращает стиль области p; рамка активной области выделяется.
+func (m *Model) pane(p pane, width, height int) lipgloss.Style {
+	style := paneStyle
+	if m.focus == p {
+		style = focusStyle
+	}
+	return style.Width(width).Height(height)
+}
+
+// viewFolders отрисовывает дерево папок.
+func (m *Model) viewFolders(width, height int) string {
+	rows := make([]string, 0, len(m.folders)+1)
+	rows = append(rows, "Все записи")
+	for _, f := range m.folders {
+		depth := strings.Count(f, "/")
+		name := f[strings.LastIndex(f, "/")+1:]
+		rows = append(rows, strings.Repeat("  ", depth)+"▸ "+name)
+	}
+	return m.viewRows(rows, m.folder, m.focus == paneFolders, width, height)
+}
+
+// viewList отрисовывает список записей.
+func (m *Model) viewList(width, height int) string {
+	if len(m.visible) == 0 {
+		if m.loading {
+			return ""
+		}
+		return dimStyle.Render("Записей нет")
+	}
+	rows := make([]string, len(m.visible))
+	for i, e := range m.visible {
+		mark := "  "
+		if e.Favorite {
+			mark = "★ "
+		}
+		rows[i] = mark + Title(e)
+		if user := Username(e); user != "" {
+			rows[i] += dimStyle.Render(" · " + user)
+		}
+	}
+	return m.viewRows(rows, m.cursor, m.focus == paneList, width, height)
+}
+
+// viewRows отрисовывает строки с выделенной строкой cursor, прокручивая их так,
+// чтобы выделенная строка была видна.
+func (m *Model) viewRows(rows []string, cursor int, focused bool, width, height int) string {
+	start := max(cursor-height+1, 0)
+	end := min(start+height, len(rows))
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		line := ansi.Truncate(rows[i], width, "…")
+		if i == cursor {
+			if focused {
+				line = cursorStyle.Render(ansi.Strip(line))
+			} else {
+				line = titleStyle.Render(ansi.Strip(line))
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewDetail отрисовывает карточку выбранной записи. Секреты скрыты, пока их
+// не показать клавишей r.
+func (m *Model) viewDetail(width, height int) string {
+	e, ok := m.selected()
+	if !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(Title(e)) + "\n")
+	field := func(label, value string) {
+		if value != "" {
+			b.WriteString(dimStyle.Render(label+": ") + value + "\n")
+		}
+	}
+	field("Тип", e.Type)
+	field("Папка", e.Folder)
+	field("Теги", strings.Join(e.Tags, ", "))
+	if e.Favorite {
+		field("Избранное", "да")
+	}
+	if !e.UpdatedAt.IsZero() {
+		field("Изменена", e.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	b.WriteString("\n" + titleStyle.Render("Метаданные") + "\n")
+	if pairs, ok := Pairs(e.Meta); ok {
+		for _, p := range pairs {
+			field(p.Key, p.Value)
+		}
+	} else {
+		b.WriteString(e.Meta + "\n")
+	}
+
+	b.WriteString("\n" + titleStyle.Render("Данные") + "\n")
+	b.WriteString(m.viewData(e))
+
+	if !m.revealed {
+		b.WriteString("\n" + dimStyle.Render("r - показать скрытые значения"))
+	}
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
+
+// viewData отрисовывает данные записи со скрытыми секретами.
+func (m *Model) viewData(e backup.Entry) string {
+	secret := func(value string) string {
+		if m.revealed {
+			return value
+		}
+		return Mask
+	}
+
+	if e.Type == otp.CredentialType {
+		key, err := otp.Parse(e.Data)
+		if err != nil {
+			return "Некорректный ключ: " + err.Error() + "\n"
+		}
+		lines := []string{
+			dimStyle.Render("Издатель: ") + key.Issuer,
+			dimStyle.Render("Аккаунт: ") + key.Account,
+			dimStyle.Render("Ключ: ") + secret(e.Data),
+		}
+		if key.Type == otp.TypeTOTP {
+			code := Mask
+			if m.revealed {
+				c, remaining, err := key.TOTP(m.opts.Now())
+				if err != nil {
+					return err.Error() + "\n"
+				}
+				code = fmt.Sprintf("%s (осталось %d с)", c, int(remaining.Round(time.Second)/time.Second))
+			}
+			lines = append(lines, dimStyle.Render("Код: ")+code)
+		} else {
+			lines = append(lines, dimStyle.Render("Код: ")+"выдает команда keepercli otp "+e.ID)
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	pairs, ok := Pairs(e.Data)
+	if !ok {
+		return secret(e.Data) + "\n"
+	}
+	var b strings.Builder
+	for _, p := range pairs {
+		value := p.Value
+		if IsSecret(p.Key) {
+			value = secret(value)
+		}
+		b.WriteString(dimStyle.Render(p.Key+": ") + value + "\n")
+	}
+	return b.String()
+}
+
+// viewForm отрисовывает форму записи.
+func (m *Model) viewForm(width int) string {
+	f := m.form
+	var b strings.Builder
+	if f.isNew {
+		b.WriteString(titleStyle.Render("Новая запись") + "\n\n")
+	} else {
+		b.WriteString(titleStyle.Render("Изменение: "+Title(f.prev)) + "\n\n")
+	}
+	for i := range f.inputs {
+		label := fieldLabels[i]
+		if i == fieldType && !f.isNew {
+			b.WriteString(dimStyle.Render(label+": ") + f.prev.Type + "\n")
+			continue
+		}
+		if i == f.focus {
+			label = titleStyle.Render("> " + label)
+		} else {
+			label = dimStyle.Render("  " + label)
+		}
+		f.inputs[i].Width = max(width-4, 10)
+		b.WriteString(label + "\n  " + f.inputs[i].View() + "\n")
+	}
+	return b.String()
+}
+
+// clip обрезает текст до height строк шириной width.
+func clip(s string, width, height int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ключи полей запроса, которые middleware добавляют в контекст.
+const (
+	KeyRequestID = "request_id"
+	KeyUserID    = "user_id"
+	KeyMethod    = "method"
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+)
+
+// RequestIDHeader - заголовок HTTP и ключ метаданных gRPC с идентификатором запроса.
+const RequestIDHeader = "x-request-id"
+
+type ctxKey struct{}
+
+// WithContext возвращает контекст, записи логов из которого дополняются полями attrs.
+// Поля добавляются к уже сохраненным в ctx.
+func WithContext(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev := attrsFrom(ctx)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	merged = append(merged, prev...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// NewRequestID создает идентификатор запроса для случаев, когда клиент его не передал.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// attrsFrom возвращает поля логов, сохраненные в контексте.
+func attrsFrom(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler добавляет к каждой записи поля, сохраненные в контексте функцией WithContext,
+// и идентификаторы трассы и участка OpenTelemetry, если контекст их содержит.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle дополняет запись полями из контекста и передает ее вложенному обработчику.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := attrsFrom(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)],
+			slog.String(KeyTraceID, sc.TraceID().String()),
+			slog.String(KeySpanID, sc.SpanID().String()),
+		)
+	}
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs возвращает обработчик с дополнительными полями.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup возвращает обработчик, помещающий последующие поля в группу name.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
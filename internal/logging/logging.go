@@ -0,0 +1,117 @@
+// Package logging настраивает структурированное логирование на основе log/slog
+// для сервера и клиента.
+//
+// Записи выводятся в формате JSON в стандартный поток ошибок или в файл с ротацией
+// по размеру. Значения паролей, токенов и секретных данных всегда маскируются,
+// а поля запроса (идентификатор запроса, пользователь, метод) добавляются из контекста.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Значения ротации файла логов по умолчанию.
+const (
+	DefaultMaxSizeMB  = 100
+	DefaultMaxBackups = 5
+)
+
+// Options содержит параметры логирования из конфигурации.
+type Options struct {
+	// Level — уровень логирования: debug, info, warn или error.
+	Level string
+
+	// File — путь к файлу логов. Пустое значение означает вывод в стандартный поток ошибок.
+	File string
+
+	// MaxSizeMB — размер файла в мегабайтах, при достижении которого он ротируется.
+	MaxSizeMB int
+
+	// MaxBackups — количество хранимых ротированных файлов.
+	MaxBackups int
+}
+
+// ParseLevel преобразует название уровня логирования из конфигурации в slog.Level.
+// Пустое значение соответствует уровню info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// level - уровень логгеров, созданных New. Общий для всех логгеров, чтобы SetLevel
+// менял уровень без пересоздания логгера.
+var level = new(slog.LevelVar)
+
+// SetLevel меняет уровень логгеров, созданных New, без их пересоздания.
+func SetLevel(name string) error {
+	parsed, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// New создает логгер по параметрам opts. Возвращаемый io.Closer закрывает файл логов
+// и должен вызываться при завершении программы. Уровень логгера можно изменить
+// позднее функцией SetLevel.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	if err := SetLevel(opts.Level); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		out    io.Writer = os.Stderr
+		closer io.Closer = nopCloser{}
+	)
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0o750); err != nil {
+			return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file := &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+		}
+		if file.MaxSize <= 0 {
+			file.MaxSize = DefaultMaxSizeMB
+		}
+		if file.MaxBackups <= 0 {
+			file.MaxBackups = DefaultMaxBackups
+		}
+		out, closer = file, file
+	}
+
+	return slog.New(NewHandler(out, level)), closer, nil
+}
+
+// NewHandler создает JSON-обработчик с маскированием секретов и полями из контекста.
+func NewHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	return contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})}
+}
+
+// nopCloser - io.Closer для вывода в стандартный поток ошибок, который не нужно закрывать.
+type nopCloser struct{}
+
+// Close ничего не делает.
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestLogger(level slog.Level) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(NewHandler(&buf, level)), &buf
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	return out
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestLevelFiltering(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelWarn)
+
+	logger.Info("hidden")
+	assert.Empty(t, buf.String())
+
+	logger.Warn("shown")
+	assert.Equal(t, "shown", decode(t, buf)["msg"])
+}
+
+func TestRedactsSensitiveKeys(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	logger.Info("login",
+		slog.String("username", "alice"),
+		slog.String("password", "hunter2"),
+		slog.String("Token", "jwt"),
+		slog.String("jwt_secret", "s"),
+		slog.String("metadata", "visible"),
+	)
+
+	out := decode(t, buf)
+	assert.Equal(t, "alice", out["username"])
+	assert.Equal(t, Redacted, out["password"])
+	assert.Equal(t, Redacted, out["Token"])
+	assert.Equal(t, Redacted, out["jwt_secret"])
+	assert.Equal(t, "visible", out["metadata"])
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestRedactsNestedStructs(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	logger.Info("payload",
+		slog.Any("auth", models.AuthPayload{Username: "alice", Password: "hunter2"}),
+		slog.Any("creds", []models.Credential{{ID: "1", Data: "card 4111"}}),
+	)
+
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "4111")
+	out := decode(t, buf)
+	assert.Equal(t, "alice", out["auth"].(map[string]any)["username"])
+}
+
+func TestRedactsProtoMessages(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	logger.Info("rpc", slog.Any("request", &pb.AddCredentialsRequest{
+		Token:       "jwt",
+		Credentials: &pb.Credentials{Data: "secret payload", Meta: "bank", Tags: []string{"work"}},
+		SearchTerms: []string{"blind-index"},
+	}))
+
+	out := decode(t, buf)
+	req := out["request"].(map[string]any)
+	assert.Equal(t, Redacted, req["token"])
+	assert.Equal(t, Redacted, req["search_terms"])
+	creds := req["credentials"].(map[string]any)
+	assert.Equal(t, Redacted, creds["data"])
+	assert.Equal(t, "bank", creds["meta"])
+	assert.NotContains(t, buf.String(), "secret payload")
+	assert.NotContains(t, buf.String(), "blind-index")
+}
+
+func TestContextAttrs(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	ctx := WithContext(context.Background(), slog.String(KeyRequestID, "req-1"))
+	ctx = WithContext(ctx, slog.String(KeyUserID, "user-1"))
+	logger.InfoContext(ctx, "handled")
+
+	out := decode(t, buf)
+	assert.Equal(t, "req-1", out[KeyRequestID])
+	assert.Equal(t, "user-1", out[KeyUserID])
+}
+
+func TestTraceContextAttrs(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	logger.InfoContext(WithContext(ctx, slog.String(KeyRequestID, "req-1")), "handled")
+
+	out := decode(t, buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", out[KeyTraceID])
+	assert.Equal(t, "00f067aa0ba902b7", out[KeySpanID])
+	assert.Equal(t, "req-1", out[KeyRequestID])
+}
+
+func TestNewWritesToRotatedFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "server.log")
+
+	logger, closer, err := New(Options{Level: "debug", File: file, MaxSizeMB: 1})
+	require.NoError(t, err)
+	logger.Debug("started", slog.String("password", "hunter2"))
+	require.NoError(t, closer.Close())
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(content), `"msg":"started"`))
+	assert.NotContains(t, string(content), "hunter2")
+}
+
+func TestSetLevel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.log")
+
+	logger, closer, err := New(Options{Level: "warn", File: file})
+	require.NoError(t, err)
+	defer closer.Close()
+
+	logger.Info("before")
+	require.NoError(t, SetLevel("info"))
+	logger.Info("after")
+	assert.Error(t, SetLevel("verbose"))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), `"msg":"before"`)
+	assert.Contains(t, string(content), `"msg":"after"`)
+}
+
+func TestNewRejectsUnknownLevel(t *testing.T) {
+	_, _, err := New(Options{Level: "loud"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Redacted - значение, которым заменяются секретные поля.
+const Redacted = "[REDACTED]"
+
+// sensitiveParts - части имен полей, значения которых никогда не попадают в логи.
+// Имена сравниваются без учета регистра, символов "_" и "-".
+var sensitiveParts = []string{
+	"password",
+	"passwd",
+	"token",
+	"secret",
+	"authorization",
+	"cookie",
+	"jwt",
+	"encryptionkey",
+	"encryptedkey",
+	"privatekey",
+	"searchterms",
+}
+
+// sensitiveNames - имена полей, которые считаются секретными только при полном совпадении.
+var sensitiveNames = map[string]bool{
+	"data": true,
+}
+
+// IsSensitive сообщает, относится ли поле с именем key к секретным.
+func IsSensitive(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	if sensitiveNames[k] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr - функция ReplaceAttr обработчика. Маскирует секретные поля по имени,
+// а сообщения gRPC и структуры раскладывает на поля, чтобы вложенные секреты тоже
+// прошли проверку: обработчик вызывает redactAttr для каждого поля группы.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindAny {
+		a.Value = expand(a.Value.Any())
+	}
+	return a
+}
+
+// expand преобразует составное значение в группу полей. Значения, умеющие
+// представлять себя строкой или JSON, а также ошибки остаются без изменений.
+func expand(v any) slog.Value {
+	switch m := v.(type) {
+	case *timestamppb.Timestamp:
+		return slog.TimeValue(m.AsTime())
+	case proto.Message:
+		return protoValue(m.ProtoReflect())
+	}
+	switch v.(type) {
+	case nil, error, fmt.Stringer, json.Marshaler, encoding.TextMarshaler, []byte:
+		return slog.AnyValue(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return slog.AnyValue(v)
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		t := rv.Type()
+		attrs := make([]slog.Attr, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			attrs = append(attrs, slog.Any(fieldName(f), rv.Field(i).Interface()))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return slog.AnyValue(v)
+		}
+		attrs := make([]slog.Attr, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			attrs = append(attrs, slog.Any(iter.Key().String(), iter.Value().Interface()))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if k := rv.Type().Elem().Kind(); k <= reflect.Complex128 || k == reflect.String {
+			return slog.AnyValue(v)
+		}
+		attrs := make([]slog.Attr, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			attrs = append(attrs, slog.Any(strconv.Itoa(i), rv.Index(i).Interface()))
+		}
+		return slog.GroupValue(attrs...)
+	}
+	return slog.AnyValue(v)
+}
+
+// fieldName возвращает имя поля структуры из тега json, а при его отсутствии - имя поля Go.
+func fieldName(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// protoValue преобразует сообщение gRPC в группу полей с именами из .proto-файла.
+// Секретные поля маскируются сразу: обработчик не вызывает redactAttr для групп.
+func protoValue(m protoreflect.Message) slog.Value {
+	var attrs []slog.Attr
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if IsSensitive(string(fd.Name())) {
+			attrs = append(attrs, slog.String(string(fd.Name()), Redacted))
+			return true
+		}
+		attrs = append(attrs, slog.Attr{Key: string(fd.Name()), Value: protoFieldValue(fd, v)})
+		return true
+	})
+	return slog.GroupValue(attrs...)
+}
+
+// protoFieldValue преобразует значение поля сообщения gRPC.
+func protoFieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) slog.Value {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		attrs := make([]slog.Attr, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: protoScalar(fd, list.Get(i))})
+		}
+		return slog.GroupValue(attrs...)
+	case fd.IsMap():
+		var attrs []slog.Attr
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			attrs = append(attrs, slog.Attr{Key: k.String(), Value: protoScalar(fd.MapValue(), mv)})
+			return true
+		})
+		return slog.GroupValue(attrs...)
+	}
+	return protoScalar(fd, v)
+}
+
+// protoScalar преобразует одиночное значение поля сообщения gRPC. Вложенные
+// сообщения передаются как proto.Message, чтобы redactAttr проверил их поля.
+func protoScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) slog.Value {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return slog.AnyValue(v.Message().Interface())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return slog.StringValue(string(ev.Name()))
+		}
+		return slog.Int64Value(int64(v.Enum()))
+	case protoreflect.BytesKind:
+		return slog.IntValue(len(v.Bytes()))
+	}
+	return slog.AnyValue(v.Interface())
+}
@@ -0,0 +1,270 @@
+// Package passgen генерирует пароли и парольные фразы и оценивает их стойкость.
+//
+// Все случайные значения берутся из crypto/rand. Парольные фразы составляются
+// по методу diceware из большого словаря EFF (7776 слов).
+package passgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/ccojocar/zxcvbn-go"
+	"github.com/sethvargo/go-diceware/diceware"
+)
+
+// Наборы символов пароля. В Symbols нет запятой: она разделяет пары
+// "ключ:значение" в данных записи.
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!#$%&()*+-./:;<=>?@[]^_{|}~"
+)
+
+// Ambiguous - символы, которые легко перепутать при чтении или вводе вручную.
+const Ambiguous = "Il1|O0o"
+
+// Ограничения параметров генерации.
+const (
+	MinLength = 4
+	MaxLength = 1024
+	MinWords  = 3
+	MaxWords  = 64
+)
+
+// Оценки стойкости по энтропии в битах.
+const (
+	VeryWeak   = "очень слабый"
+	Weak       = "слабый"
+	Reasonable = "средний"
+	Strong     = "сильный"
+	VeryStrong = "очень сильный"
+)
+
+// ErrNoCharset - в параметрах генерации не выбран ни один набор символов.
+var ErrNoCharset = errors.New("at least one character class is required")
+
+// Options - параметры генерации пароля.
+type Options struct {
+	Length           int  // длина пароля в символах
+	Lower            bool // строчные латинские буквы
+	Upper            bool // прописные латинские буквы
+	Digits           bool // цифры
+	Symbols          bool // специальные символы
+	ExcludeAmbiguous bool // исключить похожие символы из Ambiguous
+}
+
+// DefaultOptions возвращает параметры по умолчанию: 20 символов из всех наборов.
+func DefaultOptions() Options {
+	return Options{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+}
+
+// classes возвращает выбранные наборы символов.
+func (o Options) classes() []string {
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{o.Lower, Lower},
+		{o.Upper, Upper},
+		{o.Digits, Digits},
+		{o.Symbols, Symbols},
+	} {
+		if !class.enabled {
+			continue
+		}
+		chars := class.chars
+		if o.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+	return classes
+}
+
+// Validate проверяет параметры генерации.
+func (o Options) Validate() error {
+	classes := o.classes()
+	if len(classes) == 0 {
+		return ErrNoCharset
+	}
+	if o.Length < MinLength || o.Length > MaxLength {
+		return fmt.Errorf("length must be between %d and %d, got %d", MinLength, MaxLength, o.Length)
+	}
+	if o.Length < len(classes) {
+		return fmt.Errorf("length %d is too short for %d character classes", o.Length, len(classes))
+	}
+	return nil
+}
+
+// Entropy возвращает энтропию пароля, сгенерированного с параметрами o, в битах.
+func (o Options) Entropy() float64 {
+	return float64(o.Length) * math.Log2(float64(len(strings.Join(o.classes(), ""))))
+}
+
+// Generate возвращает случайный пароль с параметрами o. Пароль содержит
+// хотя бы один символ каждого выбранного набора.
+func Generate(o Options) (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+
+	classes := o.classes()
+	alphabet := strings.Join(classes, "")
+	password := make([]byte, 0, o.Length)
+	for _, class := range classes {
+		c, err := pick(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < o.Length {
+		c, err := pick(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Обязательные символы наборов не должны стоять в начале пароля
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// Passphrase возвращает парольную фразу из words случайных слов словаря EFF,
+// разделенных separator.
+func Passphrase(words int, separator string) (string, error) {
+	if words < MinWords || words > MaxWords {
+		return "", fmt.Errorf("number of words must be between %d and %d, got %d", MinWords, MaxWords, words)
+	}
+	gen, err := diceware.NewGenerator(&diceware.GeneratorInput{WordList: wordList()})
+	if err != nil {
+		return "", err
+	}
+	list, err := gen.Generate(words)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(list, separator), nil
+}
+
+// PassphraseEntropy возвращает энтропию парольной фразы из words слов в битах.
+func PassphraseEntropy(words int) float64 {
+	return float64(words) * math.Log2(float64(wordList().(diceware.WordListNumWordser).NumWords()))
+}
+
+// wordList возвращает словарь парольных фраз.
+func wordList() diceware.WordList {
+	return diceware.WordListEffLarge()
+}
+
+// Entropy оценивает энтропию произвольного пароля в битах по его длине
+// и наборам символов, из которых он составлен. Повтор предыдущего символа
+// не добавляет энтропии. Оценка завышена для словарных паролей, но не занижает
+// стойкость случайных.
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	var length int
+	var prev rune = -1
+	for _, r := range password {
+		switch {
+		case r < unicode.MaxASCII && unicode.IsLower(r):
+			lower = true
+		case r < unicode.MaxASCII && unicode.IsUpper(r):
+			upper = true
+		case r < unicode.MaxASCII && unicode.IsDigit(r):
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+		if r != prev {
+			length++
+		}
+		prev = r
+	}
+
+	var pool int
+	for _, class := range []struct {
+		used bool
+		size int
+	}{
+		{lower, len(Lower)},
+		{upper, len(Upper)},
+		{digit, len(Digits)},
+		{symbol, 33}, // печатные символы ASCII, кроме букв и цифр, включая пробел
+		{other, 100},
+	} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(float64(pool))
+}
+
+// GuessEntropy оценивает энтропию пароля в битах алгоритмом zxcvbn: словарные
+// слова, клавиатурные последовательности, повторы, даты и userInputs (например,
+// имя пользователя) сильно снижают оценку. В отличие от Entropy оценка подходит
+// для проверки паролей, придуманных человеком.
+func GuessEntropy(password string, userInputs ...string) float64 {
+	if password == "" {
+		return 0
+	}
+	return zxcvbn.PasswordStrength(password, userInputs).Entropy
+}
+
+// Strength возвращает словесную оценку стойкости пароля с энтропией bits.
+func Strength(bits float64) string {
+	switch {
+	case bits < 28:
+		return VeryWeak
+	case bits < 36:
+		return Weak
+	case bits < 60:
+		return Reasonable
+	case bits < 128:
+		return Strong
+	default:
+		return VeryStrong
+	}
+}
+
+// pick возвращает случайный символ строки chars.
+func pick(chars string) (byte, error) {
+	i, err := randInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// randInt возвращает равномерно распределенное случайное число из [0, n).
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
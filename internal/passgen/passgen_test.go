@@ -0,0 +1,77 @@
+package passgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sol1corejz/goph-keeper/internal/passgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := passgen.DefaultOptions()
+	opts.Length = 32
+	for i := 0; i < 50; i++ {
+		password, err := passgen.Generate(opts)
+		require.NoError(t, err)
+		assert.Len(t, password, 32)
+		// Каждый выбранный набор представлен в пароле
+		for _, class := range []string{passgen.Lower, passgen.Upper, passgen.Digits, passgen.Symbols} {
+			assert.True(t, strings.ContainsAny(password, class), password)
+		}
+		assert.NotContains(t, password, ",")
+	}
+}
+
+func TestGenerateExcludeAmbiguous(t *testing.T) {
+	opts := passgen.Options{Length: 64, Upper: true, Digits: true, ExcludeAmbiguous: true}
+	for i := 0; i < 50; i++ {
+		password, err := passgen.Generate(opts)
+		require.NoError(t, err)
+		assert.False(t, strings.ContainsAny(password, passgen.Ambiguous), password)
+		assert.False(t, strings.ContainsAny(password, passgen.Lower+passgen.Symbols), password)
+	}
+}
+
+func TestGenerateRejectsBadOptions(t *testing.T) {
+	_, err := passgen.Generate(passgen.Options{Length: 20})
+	assert.ErrorIs(t, err, passgen.ErrNoCharset)
+
+	_, err = passgen.Generate(passgen.Options{Length: 2, Lower: true})
+	assert.ErrorContains(t, err, "length must be between")
+}
+
+func TestPassphrase(t *testing.T) {
+	phrase, err := passgen.Passphrase(6, "-")
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(phrase, "-"), 6)
+	assert.InDelta(t, 77.5, passgen.PassphraseEntropy(6), 0.1)
+
+	_, err = passgen.Passphrase(2, "-")
+	assert.Error(t, err)
+}
+
+func TestEntropy(t *testing.T) {
+	assert.Zero(t, passgen.Entropy(""))
+	assert.InDelta(t, 8*4.7, passgen.Entropy("abcdefgh"), 0.1)
+	// Повторы символа не увеличивают оценку
+	assert.Equal(t, passgen.Entropy("a"), passgen.Entropy("aaaaaaaa"))
+	assert.Greater(t, passgen.Entropy("Tr0ub4dor&3"), passgen.Entropy("troubadour"))
+
+	opts := passgen.DefaultOptions()
+	assert.InDelta(t, 129.5, opts.Entropy(), 0.1)
+	assert.Equal(t, passgen.VeryStrong, passgen.Strength(opts.Entropy()))
+	assert.Equal(t, passgen.VeryWeak, passgen.Strength(passgen.Entropy("qwert")))
+}
+
+func TestGuessEntropy(t *testing.T) {
+	assert.Zero(t, passgen.GuessEntropy(""))
+	// Словарный пароль с повтором получает высокую оценку по наборам символов,
+	// но не по zxcvbn
+	assert.Greater(t, passgen.Entropy("Password1!Password1!"), 100.0)
+	assert.Less(t, passgen.GuessEntropy("Password1!Password1!"), 28.0)
+	// Имя пользователя в пароле не добавляет стойкости
+	assert.Less(t, passgen.GuessEntropy("alice2024", "alice"), passgen.GuessEntropy("alice2024"))
+	assert.Greater(t, passgen.GuessEntropy("correct horse battery staple"), 60.0)
+}
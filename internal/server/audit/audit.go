@@ -0,0 +1,88 @@
+// Package audit содержит хэш-цепочку журнала аудита и её проверку.
+// Хэш записи вычисляется от хэша предыдущей записи и всех полей события,
+// поэтому удаление, вставка или изменение записи нарушает цепочку.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// ResultOK - результат успешно выполненного действия.
+const ResultOK = "ok"
+
+// Hash вычисляет хэш записи, следующей за записью с хэшем prevHash.
+// Номер и хэши самой записи в вычислении не участвуют.
+func Hash(prevHash string, e models.AuditEntry) string {
+	h := sha256.New()
+	fields := []string{
+		prevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.ActorID,
+		e.OrgID,
+		e.Action,
+		e.CredentialID,
+		e.ClientIP,
+		e.UserAgent,
+		e.Result,
+	}
+	// Длина перед каждым полем исключает неоднозначность при склейке
+	var size [8]byte
+	for _, field := range fields {
+		binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+		h.Write(size[:])
+		h.Write([]byte(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ChainError описывает первую запись, на которой нарушена цепочка.
+type ChainError struct {
+	ID     int64  // Номер записи
+	Reason string // Причина
+}
+
+// Error возвращает описание нарушения.
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at entry %d: %s", e.ID, e.Reason)
+}
+
+// Verifier последовательно проверяет записи журнала в порядке возрастания номеров.
+type Verifier struct {
+	prevHash string
+	lastID   int64
+	count    int64
+}
+
+// Check проверяет очередную запись журнала.
+func (v *Verifier) Check(e models.AuditEntry) error {
+	if e.ID <= v.lastID {
+		return &ChainError{ID: e.ID, Reason: "entries out of order"}
+	}
+	if e.PrevHash != v.prevHash {
+		return &ChainError{ID: e.ID, Reason: "previous hash mismatch, an entry was removed or inserted"}
+	}
+	if Hash(e.PrevHash, e) != e.Hash {
+		return &ChainError{ID: e.ID, Reason: "hash mismatch, the entry was modified"}
+	}
+	v.prevHash = e.Hash
+	v.lastID = e.ID
+	v.count++
+	return nil
+}
+
+// Count возвращает количество проверенных записей.
+func (v *Verifier) Count() int64 {
+	return v.count
+}
+
+// Head возвращает хэш последней проверенной записи. Сохраненный вне сервера,
+// он позволяет обнаружить удаление записей с конца журнала.
+func (v *Verifier) Head() string {
+	return v.prevHash
+}
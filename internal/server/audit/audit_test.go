@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// chain строит корректную цепочку из n записей.
+func chain(n int) []models.AuditEntry {
+	entries := make([]models.AuditEntry, 0, n)
+	prev := ""
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		e := models.AuditEntry{
+			ID:        int64(i + 1),
+			CreatedAt: start.Add(time.Duration(i) * time.Second),
+			ActorID:   "user",
+			Action:    "GetCredentials",
+			Result:    ResultOK,
+			PrevHash:  prev,
+		}
+		e.Hash = Hash(prev, e)
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// verify проверяет записи и возвращает первую ошибку.
+func verify(entries []models.AuditEntry) (*Verifier, error) {
+	v := &Verifier{}
+	for _, e := range entries {
+		if err := v.Check(e); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+func TestVerifyValidChain(t *testing.T) {
+	entries := chain(5)
+	v, err := verify(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), v.Count())
+	assert.Equal(t, entries[4].Hash, v.Head())
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func([]models.AuditEntry) []models.AuditEntry
+		id     int64
+	}{
+		{
+			name: "modified",
+			tamper: func(e []models.AuditEntry) []models.AuditEntry {
+				e[2].Result = "denied"
+				return e
+			},
+			id: 3,
+		},
+		{
+			name: "removed",
+			tamper: func(e []models.AuditEntry) []models.AuditEntry {
+				return append(e[:1], e[2:]...)
+			},
+			id: 3,
+		},
+		{
+			name: "reordered",
+			tamper: func(e []models.AuditEntry) []models.AuditEntry {
+				e[1], e[2] = e[2], e[1]
+				return e
+			},
+			id: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verify(tt.tamper(chain(4)))
+			var chainErr *ChainError
+			assert.ErrorAs(t, err, &chainErr)
+			assert.Equal(t, tt.id, chainErr.ID)
+		})
+	}
+}
+
+func TestHashIgnoresTimeZone(t *testing.T) {
+	e := chain(1)[0]
+	local := e
+	local.CreatedAt = e.CreatedAt.In(time.FixedZone("MSK", 3*60*60))
+	assert.Equal(t, Hash("", e), Hash("", local))
+}
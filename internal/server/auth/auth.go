@@ -6,14 +6,20 @@
 package auth
 
 import (
+	"context"
 	"errors"
-	"github.com/gofiber/fiber/v2/log"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/sol1corejz/goph-keeper/configs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"log/slog"
 	"time"
 )
 
+// tracerName - имя трассировщика участков проверки авторизации.
+const tracerName = "github.com/sol1corejz/goph-keeper/internal/server/auth"
+
 // Claims структура, содержащая информацию о пользователе,
 // которая будет закодирована в JWT токене.
 type Claims struct {
@@ -67,16 +73,14 @@ func ParseToken(config *configs.ServerConfig, tokenString string) (string, error
 
 	// Проверка валидности токена
 	if !token.Valid {
-		log.Info("Token is not valid")
+		slog.Debug("token is not valid")
 		return "", errors.New("token is not valid")
 	}
 
 	// Возврат UserID из claims
-	log.Info("Token is valid")
-
 	// Проверка, что userID является валидным UUID
 	if _, err = uuid.Parse(claims.UserID); err != nil {
-		log.Info("UserID is not a valid UUID")
+		slog.Debug("user id in token is not a valid UUID")
 		return "", errors.New("userID in token is not valid")
 	}
 
@@ -89,9 +93,24 @@ func CheckIsAuthorized(config *configs.ServerConfig, token string) (string, erro
 	// Извлекаем UserID из токена
 	userID, err := ParseToken(config, token)
 	if err != nil {
-		log.Info("Authorization failed:", err.Error())
+		slog.Debug("authorization failed", "error", err)
 		return "", errors.New("token is invalid")
 	}
 
 	return userID, nil
 }
+
+// Authorize проверяет токен так же, как CheckIsAuthorized, и отмечает проверку
+// отдельным участком трассировки запроса ctx.
+func Authorize(ctx context.Context, config *configs.ServerConfig, token string) (string, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "auth.Authorize")
+	defer span.End()
+
+	userID, err := CheckIsAuthorized(config, token)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+
+	return userID, nil
+}
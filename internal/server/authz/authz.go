@@ -0,0 +1,173 @@
+// Package authz содержит правила доступа к методам сервиса Keeper: личным записям,
+// общим записям и организациям. Хранилище только собирает сведения об отношении
+// пользователя к объекту вызова, решение о доступе принимается здесь.
+package authz
+
+import (
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+)
+
+// Action - действие, требующее проверки прав.
+type Action string
+
+// Действия в организации.
+const (
+	ViewOrganization  Action = "org.view"        // Просмотр участников, команд и коллекций
+	ReadSecrets       Action = "secrets.read"    // Чтение записей коллекций
+	WriteSecrets      Action = "secrets.write"   // Добавление записей в коллекции и их изменение
+	ManageMembers     Action = "org.members"     // Приглашение и удаление участников
+	ManageTeams       Action = "org.teams"       // Создание команд и изменение их состава
+	ManageCollections Action = "org.collections" // Создание коллекций и назначение их командам
+	SeeAllCollections Action = "collections.all" // Доступ ко всем коллекциям независимо от команд
+	ViewAudit         Action = "org.audit"       // Просмотр журнала аудита организации
+)
+
+// Действия с записью.
+const (
+	ReadCredential     Action = "credential.read"     // Чтение данных записи
+	WriteCredential    Action = "credential.write"    // Изменение данных записи
+	MoveCredential     Action = "credential.move"     // Помещение записи в коллекцию организации
+	OrganizeCredential Action = "credential.organize" // Теги
+	PlaceCredential    Action = "credential.place"    // Папка и избранное владельца
+	ManageCredential   Action = "credential.manage"   // Выдача доступа к записи
+)
+
+// permissions задает действия, разрешенные каждой роли.
+var permissions = map[models.OrgRole][]Action{
+	models.OrgRoleOwner:    {ViewOrganization, ReadSecrets, WriteSecrets, ManageMembers, ManageTeams, ManageCollections, SeeAllCollections, ViewAudit},
+	models.OrgRoleAdmin:    {ViewOrganization, ReadSecrets, WriteSecrets, ManageMembers, ManageTeams, ManageCollections, SeeAllCollections, ViewAudit},
+	models.OrgRoleMember:   {ViewOrganization, ReadSecrets, WriteSecrets},
+	models.OrgRoleReadOnly: {ViewOrganization, ReadSecrets},
+}
+
+// Resource - объект, к которому относится вызов метода.
+type Resource int
+
+// Объекты вызовов.
+const (
+	Public       Resource = iota // Вызов без аутентификации: регистрация и вход
+	Account                      // Данные самого пользователя
+	Credential                   // Запись из поля credential_id или id запроса
+	Organization                 // Организация из поля org_id запроса
+)
+
+// Rule - правило доступа к методу.
+type Rule struct {
+	Resource Resource // Объект вызова
+	Action   Action   // Действие с объектом
+	// Optional - организация в запросе необязательна: без неё вызов относится
+	// к данным самого пользователя.
+	Optional bool
+}
+
+// Rules - правила доступа к методам сервиса Keeper по полному имени метода.
+// Вызов метода без правила запрещен.
+var Rules = map[string]Rule{
+	pb.Keeper_Register_FullMethodName:            {Resource: Public},
+	pb.Keeper_Login_FullMethodName:               {Resource: Public},
+	pb.Keeper_AddCredentials_FullMethodName:      {Resource: Account},
+	pb.Keeper_BatchAddCredentials_FullMethodName: {Resource: Account},
+	pb.Keeper_EditCredentials_FullMethodName:     {Resource: Credential, Action: WriteCredential},
+	pb.Keeper_GetCredentials_FullMethodName:      {Resource: Account},
+	pb.Keeper_ListCredentials_FullMethodName:     {Resource: Account},
+	pb.Keeper_SearchCredentials_FullMethodName:   {Resource: Account},
+	pb.Keeper_UpdateTags_FullMethodName:          {Resource: Credential, Action: OrganizeCredential},
+	pb.Keeper_SetFavorite_FullMethodName:         {Resource: Credential, Action: PlaceCredential},
+	pb.Keeper_SetCredentialFolder_FullMethodName: {Resource: Credential, Action: PlaceCredential},
+	pb.Keeper_CreateFolder_FullMethodName:        {Resource: Account},
+	pb.Keeper_ListFolders_FullMethodName:         {Resource: Account},
+	pb.Keeper_RenameFolder_FullMethodName:        {Resource: Account},
+	pb.Keeper_MoveFolder_FullMethodName:          {Resource: Account},
+	pb.Keeper_DeleteFolder_FullMethodName:        {Resource: Account},
+	pb.Keeper_ShareCredential_FullMethodName:     {Resource: Credential, Action: ManageCredential},
+	pb.Keeper_RevokeShare_FullMethodName:         {Resource: Credential, Action: ManageCredential},
+	pb.Keeper_ListShares_FullMethodName:          {Resource: Credential, Action: ManageCredential},
+	pb.Keeper_ListSharedWithMe_FullMethodName:    {Resource: Account},
+	pb.Keeper_SetPublicKey_FullMethodName:        {Resource: Account},
+	pb.Keeper_GetPublicKey_FullMethodName:        {Resource: Account},
+	pb.Keeper_CreateOrganization_FullMethodName:  {Resource: Account},
+	pb.Keeper_ListOrganizations_FullMethodName:   {Resource: Account},
+	pb.Keeper_ListMembers_FullMethodName:         {Resource: Organization, Action: ViewOrganization},
+	pb.Keeper_InviteMember_FullMethodName:        {Resource: Organization, Action: ManageMembers},
+	pb.Keeper_ListInvites_FullMethodName:         {Resource: Account},
+	pb.Keeper_AcceptInvite_FullMethodName:        {Resource: Account},
+	pb.Keeper_RemoveMember_FullMethodName:        {Resource: Organization, Action: ManageMembers},
+	pb.Keeper_CreateTeam_FullMethodName:          {Resource: Organization, Action: ManageTeams},
+	pb.Keeper_AddTeamMember_FullMethodName:       {Resource: Organization, Action: ManageTeams},
+	pb.Keeper_CreateCollection_FullMethodName:    {Resource: Organization, Action: ManageCollections},
+	pb.Keeper_ListCollections_FullMethodName:     {Resource: Organization, Action: ViewOrganization},
+	pb.Keeper_AddToCollection_FullMethodName:     {Resource: Credential, Action: MoveCredential},
+	pb.Keeper_ListOrgCredentials_FullMethodName:  {Resource: Organization, Action: ReadSecrets},
+	pb.Keeper_GetAuditLog_FullMethodName:         {Resource: Organization, Action: ViewAudit, Optional: true},
+	pb.Keeper_NextOTPCounter_FullMethodName:      {Resource: Credential, Action: WriteCredential},
+}
+
+// Subject - пользователь, выполняющий вызов, и его отношение к объекту вызова.
+type Subject struct {
+	UserID     string                  // Идентификатор пользователя
+	Role       models.OrgRole          // Роль в организации из запроса
+	Credential models.CredentialAccess // Отношение к записи из запроса
+}
+
+// Check проверяет, разрешен ли пользователю sub вызов метода с правилом rule.
+func Check(rule Rule, sub Subject) bool {
+	switch rule.Resource {
+	case Public, Account:
+		return true
+	case Credential:
+		return CredentialAllowed(sub.Credential, rule.Action)
+	case Organization:
+		return Allowed(sub.Role, rule.Action)
+	}
+	return false
+}
+
+// Allowed проверяет, разрешено ли роли действие.
+func Allowed(role models.OrgRole, action Action) bool {
+	for _, a := range permissions[role] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionAllowed проверяет действие с записями коллекции. Владельцам
+// и администраторам доступны все коллекции, остальным участникам - коллекции,
+// доступные через команды (teamAccess).
+func CollectionAllowed(role models.OrgRole, teamAccess bool, action Action) bool {
+	return Allowed(role, action) && (teamAccess || Allowed(role, SeeAllCollections))
+}
+
+// CredentialAllowed проверяет действие с записью. Личной записью распоряжается
+// только владелец; получатели доступа читают запись, а с уровнем write и изменяют.
+// Записи организации читают, изменяют и отмечают тегами участники с доступом к их
+// коллекции; у таких записей нет владельца, поэтому выдать к ним личный доступ
+// нельзя, а папки и избранное - личные данные владельца - к ним не относятся.
+func CredentialAllowed(access models.CredentialAccess, action Action) bool {
+	inOrg := func(a Action) bool {
+		return access.OrgID != "" && CollectionAllowed(access.Role, access.TeamAccess, a)
+	}
+
+	switch action {
+	case ReadCredential:
+		return access.Owner || access.Share != "" || inOrg(ReadSecrets)
+	case WriteCredential:
+		return access.Owner || access.Share == models.SharePermissionWrite || inOrg(WriteSecrets)
+	case MoveCredential, OrganizeCredential:
+		return access.Owner || inOrg(WriteSecrets)
+	case PlaceCredential, ManageCredential:
+		return access.Owner
+	}
+	return false
+}
+
+// CanAssign проверяет, может ли участник с ролью actor назначить роль role
+// или удалить участника с этой ролью. Владельцами управляют только владельцы.
+func CanAssign(actor, role models.OrgRole) bool {
+	if !Allowed(actor, ManageMembers) || !role.Valid() {
+		return false
+	}
+	return role != models.OrgRoleOwner || actor == models.OrgRoleOwner
+}
@@ -0,0 +1,112 @@
+package authz
+
+import (
+	"testing"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		role   models.OrgRole
+		action Action
+		want   bool
+	}{
+		{models.OrgRoleOwner, ManageMembers, true},
+		{models.OrgRoleAdmin, ManageCollections, true},
+		{models.OrgRoleMember, WriteSecrets, true},
+		{models.OrgRoleMember, ManageMembers, false},
+		{models.OrgRoleReadOnly, ReadSecrets, true},
+		{models.OrgRoleReadOnly, WriteSecrets, false},
+		{models.OrgRole("guest"), ViewOrganization, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.action), func(t *testing.T) {
+			assert.Equal(t, tt.want, Allowed(tt.role, tt.action))
+		})
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	assert.True(t, CanAssign(models.OrgRoleOwner, models.OrgRoleOwner))
+	assert.True(t, CanAssign(models.OrgRoleAdmin, models.OrgRoleAdmin))
+	assert.True(t, CanAssign(models.OrgRoleAdmin, models.OrgRoleReadOnly))
+	assert.False(t, CanAssign(models.OrgRoleAdmin, models.OrgRoleOwner))
+	assert.False(t, CanAssign(models.OrgRoleMember, models.OrgRoleReadOnly))
+	assert.False(t, CanAssign(models.OrgRoleOwner, models.OrgRole("guest")))
+}
+
+func TestCredentialAllowed(t *testing.T) {
+	owner := models.CredentialAccess{Owner: true}
+	reader := models.CredentialAccess{Share: models.SharePermissionRead}
+	writer := models.CredentialAccess{Share: models.SharePermissionWrite}
+	orgMember := models.CredentialAccess{OrgID: "org", Role: models.OrgRoleMember, TeamAccess: true}
+	otherTeam := models.CredentialAccess{OrgID: "org", Role: models.OrgRoleMember}
+	orgAdmin := models.CredentialAccess{OrgID: "org", Role: models.OrgRoleAdmin}
+	orgReadOnly := models.CredentialAccess{OrgID: "org", Role: models.OrgRoleReadOnly, TeamAccess: true}
+
+	tests := []struct {
+		name   string
+		access models.CredentialAccess
+		action Action
+		want   bool
+	}{
+		{"owner manages", owner, ManageCredential, true},
+		{"owner organizes", owner, OrganizeCredential, true},
+		{"owner moves", owner, MoveCredential, true},
+		{"reader reads", reader, ReadCredential, true},
+		{"reader cannot write", reader, WriteCredential, false},
+		{"writer writes", writer, WriteCredential, true},
+		{"writer cannot move", writer, MoveCredential, false},
+		{"writer cannot manage", writer, ManageCredential, false},
+		{"writer cannot organize", writer, OrganizeCredential, false},
+		{"team member writes", orgMember, WriteCredential, true},
+		{"team member moves", orgMember, MoveCredential, true},
+		{"team member cannot manage", orgMember, ManageCredential, false},
+		{"team member organizes", orgMember, OrganizeCredential, true},
+		{"admin organizes any collection", orgAdmin, OrganizeCredential, true},
+		{"other team cannot organize", otherTeam, OrganizeCredential, false},
+		{"read-only cannot organize", orgReadOnly, OrganizeCredential, false},
+		{"owner places", owner, PlaceCredential, true},
+		{"writer cannot place", writer, PlaceCredential, false},
+		{"team member cannot place org credential", orgMember, PlaceCredential, false},
+		{"admin cannot place org credential", orgAdmin, PlaceCredential, false},
+		{"other team cannot read", otherTeam, ReadCredential, false},
+		{"admin sees all collections", orgAdmin, WriteCredential, true},
+		{"read-only reads", orgReadOnly, ReadCredential, true},
+		{"read-only cannot write", orgReadOnly, WriteCredential, false},
+		{"stranger cannot read", models.CredentialAccess{}, ReadCredential, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CredentialAllowed(tt.access, tt.action))
+		})
+	}
+}
+
+func TestRulesCoverService(t *testing.T) {
+	// Каждый метод сервиса проходит проверку прав, поэтому у каждого есть правило
+	for _, m := range pb.Keeper_ServiceDesc.Methods {
+		_, ok := Rules["/"+pb.Keeper_ServiceDesc.ServiceName+"/"+m.MethodName]
+		assert.True(t, ok, m.MethodName)
+	}
+	for _, s := range pb.Keeper_ServiceDesc.Streams {
+		_, ok := Rules["/"+pb.Keeper_ServiceDesc.ServiceName+"/"+s.StreamName]
+		assert.True(t, ok, s.StreamName)
+	}
+	assert.Len(t, Rules, len(pb.Keeper_ServiceDesc.Methods)+len(pb.Keeper_ServiceDesc.Streams))
+}
+
+func TestCheck(t *testing.T) {
+	assert.True(t, Check(Rules[pb.Keeper_Login_FullMethodName], Subject{}))
+	assert.True(t, Check(Rules[pb.Keeper_ListMembers_FullMethodName], Subject{Role: models.OrgRoleReadOnly}))
+	assert.False(t, Check(Rules[pb.Keeper_CreateTeam_FullMethodName], Subject{Role: models.OrgRoleMember}))
+	assert.False(t, Check(Rules[pb.Keeper_ShareCredential_FullMethodName], Subject{
+		Credential: models.CredentialAccess{Share: models.SharePermissionWrite},
+	}))
+	assert.False(t, Check(Rule{Resource: Resource(42)}, Subject{}))
+}
@@ -0,0 +1,337 @@
+// Package backup реализует резервное копирование хранилища сервера.
+//
+// Копия снимается через методы DumpTables и RestoreTables хранилища. Строки таблиц
+// хранятся в форме row_to_json PostgreSQL и описываются схемой storage.BackupSchema,
+// поэтому формат привязан к схеме базы данных; соответствие BackupSchema схеме
+// проверяется тестами хранилища. Файл копии состоит из строки
+// с открытым JSON-заголовком (формат, версия, параметры шифра, количество строк
+// и контрольные суммы таблиц) и зашифрованного содержимого: строки таблиц в JSON,
+// сжатые gzip и зашифрованные AES-256-GCM ключом, выведенным из ключа резервных
+// копий алгоритмом Argon2id. Заголовок передается шифру как связанные данные,
+// поэтому его изменение обнаруживается при расшифровке.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры формата.
+const (
+	FormatName      = "goph-keeper-server-backup"
+	Version         = 1
+	KDFArgon2id     = "argon2id"
+	CipherAES256GCM = "aes-256-gcm"
+	CompressionGzip = "gzip"
+
+	keyLen  = 32
+	saltLen = 16
+)
+
+// Ограничения параметров Argon2id при открытии копии.
+const (
+	maxKDFTime      = 64
+	maxKDFMemoryKiB = 4 * 1024 * 1024
+)
+
+// maxHeaderSize - наибольший размер строки заголовка.
+const maxHeaderSize = 1 << 20
+
+var (
+	// ErrNotBackup - файл не является резервной копией сервера.
+	ErrNotBackup = errors.New("not a goph-keeper server backup")
+	// ErrDecrypt - неверный ключ или копия повреждена.
+	ErrDecrypt = errors.New("wrong backup key or corrupted backup")
+	// ErrChecksum - содержимое копии не совпадает с заголовком.
+	ErrChecksum = errors.New("backup checksum mismatch")
+)
+
+// Snapshotter - хранилище, из которого снимается и в которое восстанавливается копия.
+// Реализуется хранилищем сервера и MemoryStore.
+type Snapshotter interface {
+	// DumpTables возвращает строки всех таблиц из согласованного снимка.
+	DumpTables(ctx context.Context) ([]models.BackupTable, error)
+	// RestoreTables загружает строки таблиц. Без replace хранилище должно быть пустым.
+	RestoreTables(ctx context.Context, tables []models.BackupTable, replace bool) error
+}
+
+// KDF - параметры вывода ключа шифрования.
+type KDF struct {
+	Name      string `json:"name"`
+	Salt      []byte `json:"salt"`
+	Time      uint32 `json:"time"`
+	MemoryKiB uint32 `json:"memory_kib"`
+	Threads   uint8  `json:"threads"`
+}
+
+// TableSummary - количество строк и контрольная сумма таблицы.
+type TableSummary struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+// Header - открытый заголовок копии.
+type Header struct {
+	Format      string         `json:"format"`
+	Version     int            `json:"version"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Compression string         `json:"compression"`
+	Cipher      string         `json:"cipher"`
+	KDF         KDF            `json:"kdf"`
+	Nonce       []byte         `json:"nonce"`
+	Tables      []TableSummary `json:"tables"`
+}
+
+// Rows возвращает общее количество строк в копии.
+func (h Header) Rows() int {
+	var n int
+	for _, t := range h.Tables {
+		n += t.Rows
+	}
+	return n
+}
+
+// Write снимает копию хранилища src и записывает ее в w, зашифровав ключом key.
+// Содержимое копии целиком держится в памяти.
+func Write(ctx context.Context, w io.Writer, src Snapshotter, key string) (Header, error) {
+	tables, err := src.DumpTables(ctx)
+	if err != nil {
+		return Header{}, err
+	}
+	// Контрольные суммы считаются по строкам в том виде, в котором их запишет
+	// кодировщик JSON: без пробелов между элементами. Строки сортируются, чтобы
+	// копии одинаковых данных совпадали
+	for _, t := range tables {
+		for i, row := range t.Rows {
+			var compact bytes.Buffer
+			if err = json.Compact(&compact, row); err != nil {
+				return Header{}, fmt.Errorf("table %s: %w", t.Name, err)
+			}
+			t.Rows[i] = compact.Bytes()
+		}
+		slices.SortFunc(t.Rows, func(a, b json.RawMessage) int { return bytes.Compare(a, b) })
+	}
+
+	h := Header{
+		Format:      FormatName,
+		Version:     Version,
+		CreatedAt:   time.Now().UTC(),
+		Compression: CompressionGzip,
+		Cipher:      CipherAES256GCM,
+		KDF:         KDF{Name: KDFArgon2id, Salt: make([]byte, saltLen), Time: 3, MemoryKiB: 64 * 1024, Threads: 4},
+		Tables:      Summarize(tables),
+	}
+	if _, err = rand.Read(h.KDF.Salt); err != nil {
+		return Header{}, err
+	}
+	aead, err := newAEAD(key, h.KDF)
+	if err != nil {
+		return Header{}, err
+	}
+	h.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(h.Nonce); err != nil {
+		return Header{}, err
+	}
+
+	var plaintext bytes.Buffer
+	zw := gzip.NewWriter(&plaintext)
+	enc := json.NewEncoder(zw)
+	// Экранирование &, < и > изменило бы строки и их контрольные суммы
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(tables); err != nil {
+		return Header{}, err
+	}
+	if err = zw.Close(); err != nil {
+		return Header{}, err
+	}
+
+	line, err := json.Marshal(h)
+	if err != nil {
+		return Header{}, err
+	}
+	if _, err = w.Write(append(line, '\n')); err != nil {
+		return Header{}, err
+	}
+	if _, err = w.Write(aead.Seal(nil, h.Nonce, plaintext.Bytes(), line)); err != nil {
+		return Header{}, err
+	}
+	return h, nil
+}
+
+// ReadHeader читает заголовок копии без расшифровки содержимого.
+func ReadHeader(r io.Reader) (Header, error) {
+	h, _, err := readHeader(bufio.NewReader(r))
+	return h, err
+}
+
+// Read читает копию из r, расшифровывает ее ключом key и проверяет количество
+// строк и контрольные суммы таблиц.
+func Read(r io.Reader, key string) (Header, []models.BackupTable, error) {
+	br := bufio.NewReader(r)
+	h, line, err := readHeader(br)
+	if err != nil {
+		return h, nil, err
+	}
+	aead, err := newAEAD(key, h.KDF)
+	if err != nil {
+		return h, nil, err
+	}
+	if len(h.Nonce) != aead.NonceSize() {
+		return h, nil, ErrDecrypt
+	}
+	ciphertext, err := io.ReadAll(br)
+	if err != nil {
+		return h, nil, err
+	}
+	plaintext, err := aead.Open(nil, h.Nonce, ciphertext, line)
+	if err != nil {
+		return h, nil, ErrDecrypt
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plaintext))
+	if err != nil {
+		return h, nil, fmt.Errorf("invalid backup contents: %w", err)
+	}
+	var tables []models.BackupTable
+	if err = json.NewDecoder(zr).Decode(&tables); err != nil {
+		return h, nil, fmt.Errorf("invalid backup contents: %w", err)
+	}
+	if err = Compare(h.Tables, Summarize(tables)); err != nil {
+		return h, nil, err
+	}
+	return h, tables, nil
+}
+
+// readHeader читает и проверяет строку заголовка. Возвращает также саму строку,
+// которая служит связанными данными шифра.
+func readHeader(br *bufio.Reader) (Header, []byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err != nil || len(line) > maxHeaderSize {
+		return Header{}, nil, ErrNotBackup
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+
+	var h Header
+	if err = json.Unmarshal(line, &h); err != nil || h.Format != FormatName {
+		return Header{}, nil, ErrNotBackup
+	}
+	switch {
+	case h.Version != Version:
+		return h, nil, fmt.Errorf("unsupported backup version %d", h.Version)
+	case h.Cipher != CipherAES256GCM:
+		return h, nil, fmt.Errorf("unsupported cipher %q", h.Cipher)
+	case h.Compression != CompressionGzip:
+		return h, nil, fmt.Errorf("unsupported compression %q", h.Compression)
+	}
+	if err = h.KDF.validate(); err != nil {
+		return h, nil, err
+	}
+	return h, slices.Clone(line), nil
+}
+
+// Restore читает копию из r и загружает ее в хранилище dst.
+func Restore(ctx context.Context, r io.Reader, dst Snapshotter, key string, replace bool) (Header, error) {
+	h, tables, err := Read(r, key)
+	if err != nil {
+		return h, err
+	}
+	return h, dst.RestoreTables(ctx, tables, replace)
+}
+
+// Verify проверяет копию из r: расшифровывает ее, восстанавливает во временное
+// хранилище в памяти, которое проверяет строки по storage.BackupSchema (столбцы
+// и их типы, ограничения CHECK, первичные, уникальные и внешние ключи), снимает
+// с него копию заново и сравнивает количество строк и контрольные суммы таблиц
+// с заголовком. Нарушение схемы возвращается как ErrSchema.
+func Verify(ctx context.Context, r io.Reader, key string) (Header, error) {
+	scratch := NewMemoryStore()
+	h, err := Restore(ctx, r, scratch, key, false)
+	if err != nil {
+		return h, err
+	}
+	tables, err := scratch.DumpTables(ctx)
+	if err != nil {
+		return h, err
+	}
+	return h, Compare(h.Tables, Summarize(tables))
+}
+
+// Summarize вычисляет количество строк и контрольные суммы таблиц. Контрольная
+// сумма - SHA-256 строк таблицы, каждая из которых завершается переводом строки.
+func Summarize(tables []models.BackupTable) []TableSummary {
+	result := make([]TableSummary, 0, len(tables))
+	for _, t := range tables {
+		sum := sha256.New()
+		for _, row := range t.Rows {
+			sum.Write(row)
+			sum.Write([]byte{'\n'})
+		}
+		result = append(result, TableSummary{Name: t.Name, Rows: len(t.Rows), SHA256: hex.EncodeToString(sum.Sum(nil))})
+	}
+	return result
+}
+
+// Compare сравнивает ожидаемые и фактические сводки таблиц.
+func Compare(want, got []TableSummary) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("%w: %d tables, want %d", ErrChecksum, len(got), len(want))
+	}
+	for i := range want {
+		switch {
+		case want[i].Name != got[i].Name:
+			return fmt.Errorf("%w: table %q, want %q", ErrChecksum, got[i].Name, want[i].Name)
+		case want[i].Rows != got[i].Rows:
+			return fmt.Errorf("%w: table %s has %d rows, want %d", ErrChecksum, want[i].Name, got[i].Rows, want[i].Rows)
+		case want[i].SHA256 != got[i].SHA256:
+			return fmt.Errorf("%w: table %s", ErrChecksum, want[i].Name)
+		}
+	}
+	return nil
+}
+
+// validate проверяет параметры вывода ключа из заголовка копии.
+func (k KDF) validate() error {
+	switch {
+	case k.Name != KDFArgon2id:
+		return fmt.Errorf("unsupported key derivation %q", k.Name)
+	case len(k.Salt) < saltLen:
+		return errors.New("key derivation salt is too short")
+	case k.Time == 0 || k.Time > maxKDFTime:
+		return fmt.Errorf("argon2id time must be between 1 and %d, got %d", maxKDFTime, k.Time)
+	case k.MemoryKiB < 8*uint32(k.Threads) || k.MemoryKiB > maxKDFMemoryKiB:
+		return fmt.Errorf("argon2id memory %d KiB is out of range", k.MemoryKiB)
+	case k.Threads == 0:
+		return errors.New("argon2id threads must be positive")
+	}
+	return nil
+}
+
+// newAEAD выводит ключ шифрования из ключа резервных копий и создает шифр AES-256-GCM.
+func newAEAD(key string, kdf KDF) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, errors.New("backup encryption key is required")
+	}
+	derived := argon2.IDKey([]byte(key), kdf.Salt, kdf.Time, kdf.MemoryKiB, kdf.Threads, keyLen)
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
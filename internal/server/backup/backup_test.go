@@ -0,0 +1,323 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/backup"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const key = "0123456789abcdef0123456789abcdef"
+
+// Идентификаторы строк тестовых копий.
+const (
+	aliceID = "11111111-1111-1111-1111-111111111111"
+	bobID   = "22222222-2222-2222-2222-222222222222"
+	credID  = "cccccccc-cccc-cccc-cccc-cccccccccccc"
+)
+
+// credential возвращает строку таблицы credentials с записью id пользователя userID.
+func credential(id, userID string) string {
+	return `{"uuid":"` + id + `","user_id":"` + userID + `","data":"secret","meta":null,"type":"text",` +
+		`"created_at":"2026-10-18T12:00:00+00:00","updated_at":"2026-10-18T12:00:00+00:00","org_id":null}`
+}
+
+// source возвращает хранилище в памяти с тестовыми данными.
+func source(t *testing.T) *backup.MemoryStore {
+	store := backup.NewMemoryStore()
+	err := store.RestoreTables(context.Background(), []models.BackupTable{
+		{Name: "users", Rows: []json.RawMessage{
+			json.RawMessage(`{"uuid":"22222222-2222-2222-2222-222222222222","username":"bob","password":"h2"}`),
+			json.RawMessage(`{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice","password":"h1"}`),
+		}},
+		{Name: "credentials", Rows: []json.RawMessage{json.RawMessage(credential(credID, aliceID))}},
+		{Name: "otp_counters", Rows: []json.RawMessage{}},
+	}, false)
+	require.NoError(t, err)
+	return store
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := backup.Write(context.Background(), &buf, source(t), key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, h.Rows())
+	assert.Equal(t, []string{"users", "credentials", "otp_counters"}, []string{h.Tables[0].Name, h.Tables[1].Name, h.Tables[2].Name})
+
+	// Содержимое зашифровано
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "alice")
+
+	header, err := backup.ReadHeader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, h.Tables, header.Tables)
+
+	got, tables, err := backup.Read(bytes.NewReader(buf.Bytes()), key)
+	require.NoError(t, err)
+	assert.Equal(t, h.Tables, got.Tables)
+	require.Len(t, tables, 3)
+	// Строки отсортированы
+	assert.JSONEq(t, `{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice","password":"h1"}`, string(tables[0].Rows[0]))
+}
+
+func TestWriteReadSpecialCharacters(t *testing.T) {
+	store := backup.NewMemoryStore()
+	row := strings.Replace(credential(credID, aliceID), `"data":"secret"`,
+		`"data":"https://example.com/?a=1&b=<2>"`, 1)
+	// Строка с пробелами, как у вложенных значений jsonb
+	row = strings.Replace(row, `"meta":null`, `"meta": "x & y"`, 1)
+	err := store.RestoreTables(context.Background(), []models.BackupTable{
+		{Name: "users", Rows: []json.RawMessage{
+			json.RawMessage(`{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice","password":"h1"}`),
+		}},
+		{Name: "credentials", Rows: []json.RawMessage{json.RawMessage(row)}},
+		{Name: "otp_counters", Rows: []json.RawMessage{}},
+	}, false)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = backup.Write(context.Background(), &buf, store, key)
+	require.NoError(t, err)
+
+	_, tables, err := backup.Read(bytes.NewReader(buf.Bytes()), key)
+	require.NoError(t, err)
+	assert.JSONEq(t, row, string(tables[1].Rows[0]))
+	assert.Contains(t, string(tables[1].Rows[0]), "?a=1&b=<2>")
+
+	restored := backup.NewMemoryStore()
+	_, err = backup.Restore(context.Background(), bytes.NewReader(buf.Bytes()), restored, key, false)
+	require.NoError(t, err)
+}
+
+func TestReadErrors(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := backup.Write(context.Background(), &buf, source(t), key)
+	require.NoError(t, err)
+	data := buf.Bytes()
+
+	_, _, err = backup.Read(bytes.NewReader(data), "another-key")
+	assert.ErrorIs(t, err, backup.ErrDecrypt)
+
+	// Изменение заголовка обнаруживается: он входит в связанные данные шифра
+	tampered := bytes.Replace(data, []byte(`"rows":2`), []byte(`"rows":3`), 1)
+	_, _, err = backup.Read(bytes.NewReader(tampered), key)
+	assert.ErrorIs(t, err, backup.ErrDecrypt)
+
+	tampered = bytes.Clone(data)
+	tampered[len(tampered)-1] ^= 1
+	_, _, err = backup.Read(bytes.NewReader(tampered), key)
+	assert.ErrorIs(t, err, backup.ErrDecrypt)
+
+	_, _, err = backup.Read(bytes.NewReader([]byte("{}\n")), key)
+	assert.ErrorIs(t, err, backup.ErrNotBackup)
+}
+
+func TestRestoreAndVerify(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	_, err := backup.Write(ctx, &buf, source(t), key)
+	require.NoError(t, err)
+
+	h, err := backup.Verify(ctx, bytes.NewReader(buf.Bytes()), key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, h.Rows())
+
+	// Восстановление в непустое хранилище требует замены данных
+	dst := source(t)
+	_, err = backup.Restore(ctx, bytes.NewReader(buf.Bytes()), dst, key, false)
+	assert.ErrorIs(t, err, storage.ErrNotEmpty)
+
+	_, err = backup.Restore(ctx, bytes.NewReader(buf.Bytes()), dst, key, true)
+	require.NoError(t, err)
+	tables, err := dst.DumpTables(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, h.Tables, backup.Summarize(tables))
+}
+
+func TestCompare(t *testing.T) {
+	tables := []models.BackupTable{{Name: "users", Rows: []json.RawMessage{json.RawMessage(`{"uuid":"11111111-1111-1111-1111-111111111111"}`)}}}
+	want := backup.Summarize(tables)
+
+	tables[0].Rows[0] = json.RawMessage(`{"uuid":"22222222-2222-2222-2222-222222222222"}`)
+	assert.ErrorIs(t, backup.Compare(want, backup.Summarize(tables)), backup.ErrChecksum)
+
+	tables[0].Rows = append(tables[0].Rows, json.RawMessage(`{"uuid":"u3"}`))
+	err := backup.Compare(want, backup.Summarize(tables))
+	assert.ErrorIs(t, err, backup.ErrChecksum)
+	assert.Contains(t, err.Error(), "table users has 2 rows, want 1")
+}
+
+func TestMemoryStoreRejectsInvalidRows(t *testing.T) {
+	err := backup.NewMemoryStore().RestoreTables(context.Background(), []models.BackupTable{
+		{Name: "users", Rows: []json.RawMessage{json.RawMessage(`[1, 2]`)}},
+	}, false)
+	assert.EqualError(t, err, "table users row 1 is not a JSON object")
+}
+
+// tablesSource - источник копии с произвольными строками, в том числе не прошедшими
+// бы проверку базы данных.
+type tablesSource []models.BackupTable
+
+func (s tablesSource) DumpTables(context.Context) ([]models.BackupTable, error) { return s, nil }
+func (s tablesSource) RestoreTables(context.Context, []models.BackupTable, bool) error {
+	return nil
+}
+
+func TestVerifyChecksSchema(t *testing.T) {
+	table := func(name string, rows ...string) models.BackupTable {
+		t := models.BackupTable{Name: name}
+		for _, r := range rows {
+			t.Rows = append(t.Rows, json.RawMessage(r))
+		}
+		return t
+	}
+	users := func(rows ...string) models.BackupTable {
+		return table("users", rows...)
+	}
+	alice := `{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice","password":"h1"}`
+
+	tests := []struct {
+		name   string
+		tables []models.BackupTable
+		err    string
+	}{
+		{
+			name:   "missing column",
+			tables: []models.BackupTable{users(`{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice"}`)},
+			err:    "table users row 1: missing column password",
+		},
+		{
+			name:   "unknown column",
+			tables: []models.BackupTable{users(`{"uuid":"11111111-1111-1111-1111-111111111111","username":"alice","password":"h1","login":"a"}`)},
+			err:    "table users row 1: unknown column login",
+		},
+		{
+			name:   "null in not null column",
+			tables: []models.BackupTable{users(`{"uuid":"11111111-1111-1111-1111-111111111111","username":null,"password":"h1"}`)},
+			err:    "table users row 1: null value in column username",
+		},
+		{
+			name:   "duplicate primary key",
+			tables: []models.BackupTable{users(alice, `{"uuid":"11111111-1111-1111-1111-111111111111","username":"bob","password":"h2"}`)},
+			err:    "table users row 2: duplicate key (uuid)",
+		},
+		{
+			name:   "duplicate unique key",
+			tables: []models.BackupTable{users(alice, `{"uuid":"22222222-2222-2222-2222-222222222222","username":"alice","password":"h2"}`)},
+			err:    "table users row 2: duplicate key (username)",
+		},
+		{
+			name: "missing referenced row",
+			tables: []models.BackupTable{users(alice),
+				{Name: "credentials", Rows: []json.RawMessage{json.RawMessage(credential(credID, bobID))}}},
+			err: "table credentials row 1: user_id references missing users.uuid",
+		},
+		{
+			name:   "invalid uuid",
+			tables: []models.BackupTable{users(`{"uuid":"u1","username":"alice","password":"h1"}`)},
+			err:    "table users row 1: column uuid: not a uuid",
+		},
+		{
+			name:   "duplicate uuid in other case",
+			tables: []models.BackupTable{users(alice, `{"uuid":"CCCCCCCC-CCCC-CCCC-CCCC-CCCCCCCCCCCC","username":"bob","password":"h2"}`, `{"uuid":"`+credID+`","username":"carol","password":"h3"}`)},
+			err:    "table users row 3: duplicate key (uuid)",
+		},
+		{
+			name: "credential without owner",
+			tables: []models.BackupTable{users(alice), table("credentials",
+				strings.Replace(credential(credID, aliceID), `"`+aliceID+`"`, "null", 1))},
+			err: "table credentials row 1: exactly one of user_id, org_id must be set",
+		},
+		{
+			name: "value outside check",
+			tables: []models.BackupTable{users(alice), table("organizations",
+				`{"uuid":"`+bobID+`","name":"acme","created_at":"2026-10-18T12:00:00+00:00"}`),
+				table("org_members", `{"org_id":"`+bobID+`","user_id":"`+aliceID+`","role":"root"}`)},
+			err: `table org_members row 1: column role: value "root" is not allowed`,
+		},
+		{
+			name: "negative counter",
+			tables: []models.BackupTable{users(alice), table("credentials", credential(credID, aliceID)),
+				table("otp_counters", `{"credential_id":"`+credID+`","counter":-1}`)},
+			err: "table otp_counters row 1: column counter: negative value",
+		},
+		{
+			name: "duplicate root folder",
+			tables: []models.BackupTable{users(alice), table("folders",
+				`{"uuid":"`+bobID+`","user_id":"`+aliceID+`","parent_id":null,"name":"work"}`,
+				`{"uuid":"`+credID+`","user_id":"`+aliceID+`","parent_id":null,"name":"work"}`)},
+			err: "table folders row 2: duplicate key (user_id, parent_id, name)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := backup.Write(context.Background(), &buf, tablesSource(tt.tables), key)
+			require.NoError(t, err)
+
+			_, err = backup.Verify(context.Background(), bytes.NewReader(buf.Bytes()), key)
+			assert.ErrorIs(t, err, backup.ErrSchema)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestFilesAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for _, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, 24 * time.Hour, 0} {
+		path := filepath.Join(dir, backup.FileName(now.Add(-age)))
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+	// Посторонние файлы не считаются копиями
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600))
+
+	f, err := backup.Latest(dir, now.Add(-30*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-48*time.Hour), f.CreatedAt)
+	_, err = backup.Latest(dir, now.Add(-100*time.Hour))
+	assert.ErrorIs(t, err, backup.ErrNoBackup)
+
+	// Остаются три последние копии, затем копии не старше 30 часов
+	removed, err := backup.Prune(dir, 3, 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, backup.FileName(now.Add(-72*time.Hour)))}, removed)
+
+	removed, err = backup.Prune(dir, 0, 30*time.Hour, now)
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+
+	files, err := backup.List(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, now, files[1].CreatedAt)
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+	path, h, err := backup.WriteFile(context.Background(), dir, source(t), key)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, backup.FileName(h.CreatedAt)), path)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = backup.Verify(context.Background(), f, key)
+	assert.NoError(t, err)
+
+	// Временный файл удален
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
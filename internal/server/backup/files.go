@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Имена файлов копий в каталоге: goph-keeper-20060102T150405Z.backup.
+const (
+	filePrefix = "goph-keeper-"
+	fileSuffix = ".backup"
+	fileTime   = "20060102T150405Z"
+)
+
+// ErrNoBackup - в каталоге нет подходящей копии.
+var ErrNoBackup = errors.New("no backup found")
+
+// File - файл копии в каталоге.
+type File struct {
+	Path      string
+	CreatedAt time.Time
+}
+
+// FileName возвращает имя файла копии, снятой в момент t.
+func FileName(t time.Time) string {
+	return filePrefix + t.UTC().Format(fileTime) + fileSuffix
+}
+
+// WriteFile снимает копию хранилища src в новый файл каталога dir и возвращает его путь.
+// Копия пишется во временный файл и переименовывается после записи, поэтому
+// в каталоге не остается недописанных копий.
+func WriteFile(ctx context.Context, dir string, src Snapshotter, key string) (string, Header, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", Header{}, err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*"+fileSuffix)
+	if err != nil {
+		return "", Header{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h, err := Write(ctx, tmp, src, key)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", h, err
+	}
+
+	path := filepath.Join(dir, FileName(h.CreatedAt))
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", h, err
+	}
+	return path, h, nil
+}
+
+// List возвращает копии каталога dir по возрастанию времени создания.
+// Время берется из имени файла.
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		t, err := time.Parse(fileTime, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Path: filepath.Join(dir, name), CreatedAt: t})
+	}
+	slices.SortFunc(files, func(a, b File) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return files, nil
+}
+
+// Latest возвращает последнюю копию каталога dir, снятую не позже at.
+// Нулевое at означает последнюю копию.
+func Latest(dir string, at time.Time) (File, error) {
+	files, err := List(dir)
+	if err != nil {
+		return File{}, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if at.IsZero() || !files[i].CreatedAt.After(at) {
+			return files[i], nil
+		}
+	}
+	return File{}, ErrNoBackup
+}
+
+// Prune удаляет из каталога dir копии сверх retention последних и копии старше maxAge.
+// Нулевые retention и maxAge не ограничивают количество и возраст копий.
+// Последняя копия не удаляется. Возвращает пути удаленных файлов.
+func Prune(dir string, retention int, maxAge time.Duration, now time.Time) ([]string, error) {
+	files, err := List(dir)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	var removed []string
+	for i, f := range files[:len(files)-1] {
+		extra := retention > 0 && len(files)-i > retention
+		expired := maxAge > 0 && now.Sub(f.CreatedAt) > maxAge
+		if !extra && !expired {
+			continue
+		}
+		if err := os.Remove(f.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, f.Path)
+	}
+	return removed, nil
+}
+
+// Schedule - параметры резервного копирования по расписанию.
+type Schedule struct {
+	Dir       string        // Каталог копий
+	Interval  time.Duration // Период копирования
+	Retention int           // Количество хранимых копий
+	MaxAge    time.Duration // Срок хранения копий
+	Key       string        // Ключ шифрования копий
+}
+
+// Run снимает копии хранилища src каждые Interval и удаляет устаревшие, пока
+// не отменен ctx. Ошибки записываются в лог и не останавливают расписание.
+func (s Schedule) Run(ctx context.Context, src Snapshotter) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, src)
+		}
+	}
+}
+
+// runOnce снимает одну копию и удаляет устаревшие.
+func (s Schedule) runOnce(ctx context.Context, src Snapshotter) {
+	start := time.Now()
+	path, h, err := WriteFile(ctx, s.Dir, src, s.Key)
+	if err != nil {
+		slog.Error("scheduled backup failed", "error", err)
+		return
+	}
+	slog.Info("backup created", "path", path, "rows", h.Rows(), "duration", time.Since(start))
+
+	removed, err := Prune(s.Dir, s.Retention, s.MaxAge, time.Now())
+	if err != nil {
+		slog.Error("failed to prune backups", "error", err)
+	}
+	for _, p := range removed {
+		slog.Info("old backup removed", "path", p)
+	}
+}
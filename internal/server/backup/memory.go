@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+)
+
+// MemoryStore - хранилище строк таблиц в памяти. Используется как временное
+// хранилище при проверке копий.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tables []models.BackupTable
+}
+
+// NewMemoryStore создает пустое хранилище в памяти.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// DumpTables возвращает копию строк всех таблиц в порядке их загрузки.
+func (m *MemoryStore) DumpTables(_ context.Context) ([]models.BackupTable, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]models.BackupTable, len(m.tables))
+	for i, t := range m.tables {
+		result[i] = models.BackupTable{Name: t.Name, Rows: cloneRows(t.Rows)}
+	}
+	return result, nil
+}
+
+// RestoreTables загружает строки таблиц, проверяя их по схеме storage.BackupSchema
+// так же, как их проверила бы база данных (см. checkTables). Без replace хранилище
+// должно быть пустым, иначе возвращается storage.ErrNotEmpty.
+func (m *MemoryStore) RestoreTables(_ context.Context, tables []models.BackupTable, replace bool) error {
+	if err := checkTables(tables); err != nil {
+		return err
+	}
+	loaded := make([]models.BackupTable, 0, len(tables))
+	for _, t := range tables {
+		loaded = append(loaded, models.BackupTable{Name: t.Name, Rows: cloneRows(t.Rows)})
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !replace && slices.ContainsFunc(m.tables, func(t models.BackupTable) bool { return len(t.Rows) > 0 }) {
+		return storage.ErrNotEmpty
+	}
+	m.tables = loaded
+	return nil
+}
+
+// cloneRows возвращает копию строк таблицы.
+func cloneRows(rows []json.RawMessage) []json.RawMessage {
+	result := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		result[i] = slices.Clone(row)
+	}
+	return result
+}
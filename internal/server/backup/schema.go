@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+)
+
+// ErrSchema - строки копии не соответствуют схеме базы данных.
+var ErrSchema = errors.New("backup does not match schema")
+
+// row - строка таблицы: значения столбцов в JSON.
+type row map[string]json.RawMessage
+
+// checkTables проверяет строки таблиц по схеме storage.BackupSchema: таблицы и столбцы
+// должны быть в схеме, у каждой строки должны быть все столбцы таблицы со значениями
+// их типов, NULL допускается только в столбцах, где его допускает схема, ограничения
+// CHECK выполняются, первичные и уникальные ключи не повторяются, а внешние ключи
+// ссылаются на строки копии. Отсутствующие таблицы считаются пустыми.
+func checkTables(tables []models.BackupTable) error {
+	parsed := make(map[string][]row, len(tables))
+	for _, t := range tables {
+		schema, ok := tableSchema(t.Name)
+		if !ok {
+			return fmt.Errorf("%w: %q", storage.ErrUnknownTable, t.Name)
+		}
+		if _, ok := parsed[t.Name]; ok {
+			return fmt.Errorf("duplicate table %q", t.Name)
+		}
+		rows := make([]row, 0, len(t.Rows))
+		for n, raw := range t.Rows {
+			var r row
+			if err := json.Unmarshal(raw, &r); err != nil || r == nil {
+				return fmt.Errorf("table %s row %d is not a JSON object", t.Name, n+1)
+			}
+			if err := checkColumns(schema, r); err != nil {
+				return fmt.Errorf("%w: table %s row %d: %w", ErrSchema, t.Name, n+1, err)
+			}
+			rows = append(rows, r)
+		}
+		parsed[t.Name] = rows
+	}
+
+	for _, schema := range storage.BackupSchema {
+		rows := parsed[schema.Name]
+		keys := append([][]string{schema.PrimaryKey}, schema.Unique...)
+		for k, key := range append(keys, schema.UniqueNull...) {
+			seen := make(map[string]bool, len(rows))
+			for n, r := range rows {
+				value, ok := r.key(schema, key, k >= len(keys))
+				if !ok {
+					// NULL не нарушает уникальность
+					continue
+				}
+				if seen[value] {
+					return fmt.Errorf("%w: table %s row %d: duplicate key (%s)", ErrSchema, schema.Name, n+1, strings.Join(key, ", "))
+				}
+				seen[value] = true
+			}
+		}
+
+		for _, ref := range schema.References {
+			target, _ := tableSchema(ref.Table)
+			targets := make(map[string]bool, len(parsed[ref.Table]))
+			for _, r := range parsed[ref.Table] {
+				if value, ok := r.key(target, []string{ref.RefColumn}, false); ok {
+					targets[value] = true
+				}
+			}
+			for n, r := range rows {
+				if value, ok := r.key(schema, []string{ref.Column}, false); ok && !targets[value] {
+					return fmt.Errorf("%w: table %s row %d: %s references missing %s.%s",
+						ErrSchema, schema.Name, n+1, ref.Column, ref.Table, ref.RefColumn)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkColumns проверяет, что у строки r есть все столбцы таблицы schema, нет лишних,
+// значения соответствуют типам и ограничениям столбцов, а NULL стоит только в столбцах,
+// которые его допускают.
+func checkColumns(schema models.BackupTableSchema, r row) error {
+	for _, c := range schema.Columns {
+		value, ok := r[c.Name]
+		if !ok {
+			return fmt.Errorf("missing column %s", c.Name)
+		}
+		if isNull(value) {
+			if !c.Nullable {
+				return fmt.Errorf("null value in column %s", c.Name)
+			}
+			continue
+		}
+		if err := checkValue(c, value); err != nil {
+			return fmt.Errorf("column %s: %w", c.Name, err)
+		}
+	}
+	if len(r) != len(schema.Columns) {
+		for name := range r {
+			if !slices.ContainsFunc(schema.Columns, func(c models.BackupColumn) bool { return c.Name == name }) {
+				return fmt.Errorf("unknown column %s", name)
+			}
+		}
+	}
+	if len(schema.ExactlyOne) > 0 {
+		set := 0
+		for _, name := range schema.ExactlyOne {
+			if !isNull(r[name]) {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("exactly one of %s must be set", strings.Join(schema.ExactlyOne, ", "))
+		}
+	}
+	return nil
+}
+
+// checkValue проверяет, что значение value, отличное от NULL, имеет тип столбца c
+// в представлении row_to_json и удовлетворяет его ограничениям.
+func checkValue(c models.BackupColumn, value json.RawMessage) error {
+	if c.Type == models.BackupBigint {
+		n, err := strconv.ParseInt(string(bytes.TrimSpace(value)), 10, 64)
+		if err != nil {
+			return errors.New("not a bigint")
+		}
+		if c.NonNegative && n < 0 {
+			return errors.New("negative value")
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return fmt.Errorf("not a %s string", c.Type)
+	}
+	// PostgreSQL не хранит нулевой символ в текстовых значениях
+	if strings.ContainsRune(s, 0) {
+		return errors.New("contains NUL character")
+	}
+	switch c.Type {
+	case models.BackupUUID:
+		if _, err := parseUUID(s); err != nil {
+			return errors.New("not a uuid")
+		}
+	case models.BackupTimestamp:
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			return errors.New("not a timestamp")
+		}
+	case models.BackupBytea:
+		data, ok := strings.CutPrefix(s, `\x`)
+		if _, err := hex.DecodeString(data); !ok || err != nil {
+			return errors.New("not a bytea")
+		}
+	}
+	if len(c.Values) > 0 && !slices.Contains(c.Values, s) {
+		return fmt.Errorf("value %q is not allowed", s)
+	}
+	return nil
+}
+
+// parseUUID разбирает UUID в формах, которые принимает PostgreSQL.
+func parseUUID(s string) (uuid.UUID, error) {
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		return uuid.UUID{}, errors.New("invalid uuid")
+	}
+	return uuid.Parse(s)
+}
+
+// key возвращает значение ключа из столбцов columns таблицы schema. Строки сравниваются
+// по значению, а UUID - в каноническом виде, как их сравнивает PostgreSQL. Если один
+// из столбцов NULL и nullEqual равно false, ok равно false; с nullEqual NULL входит
+// в ключ как отдельное значение.
+func (r row) key(schema models.BackupTableSchema, columns []string, nullEqual bool) (value string, ok bool) {
+	parts := make([]string, len(columns))
+	for i, name := range columns {
+		if isNull(r[name]) {
+			if !nullEqual {
+				return "", false
+			}
+			parts[i] = "null"
+			continue
+		}
+		parts[i] = string(bytes.TrimSpace(r[name]))
+		var s string
+		if err := json.Unmarshal(r[name], &s); err != nil {
+			continue
+		}
+		c := schema.Columns[slices.IndexFunc(schema.Columns, func(c models.BackupColumn) bool { return c.Name == name })]
+		if c.Type == models.BackupUUID {
+			if id, err := parseUUID(s); err == nil {
+				s = id.String()
+			}
+		}
+		parts[i] = strconv.Quote(s)
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// tableSchema возвращает описание таблицы name из storage.BackupSchema.
+func tableSchema(name string) (models.BackupTableSchema, bool) {
+	i := slices.IndexFunc(storage.BackupSchema, func(s models.BackupTableSchema) bool { return s.Name == name })
+	if i < 0 {
+		return models.BackupTableSchema{}, false
+	}
+	return storage.BackupSchema[i], true
+}
+
+// isNull сообщает, является ли значение столбца JSON-значением null.
+func isNull(value json.RawMessage) bool {
+	value = bytes.TrimSpace(value)
+	return len(value) == 0 || string(value) == "null"
+}
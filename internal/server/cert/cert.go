@@ -6,13 +6,17 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
-	"github.com/gofiber/fiber/v2/log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math/big"
 	"net"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,13 +49,13 @@ func GenerateCert() ([]byte, []byte) {
 	// Генерируем новый RSA-приватный ключ длиной 4096 бит.
 	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
 	if err != nil {
-		log.Error("Ошибка генерации приватного ключа:", err)
+		slog.Error("Ошибка генерации приватного ключа", "error", err)
 	}
 
 	// Создаём сертификат на основе шаблона.
 	certBytes, err := x509.CreateCertificate(rand.Reader, cert, cert, &privateKey.PublicKey, privateKey)
 	if err != nil {
-		log.Error("Ошибка создания сертификата:", err)
+		slog.Error("Ошибка создания сертификата", "error", err)
 	}
 
 	// Кодируем сертификат в формате PEM.
@@ -90,3 +94,63 @@ func SaveCert(certPEM, keyPEM []byte) error {
 	}
 	return nil
 }
+
+// CheckCert проверяет, что файл path содержит сертификат в формате PEM,
+// срок действия которого включает момент now.
+func CheckCert(path string, now time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errors.New("no PEM certificate found")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	if now.Before(certificate.NotBefore) {
+		return fmt.Errorf("certificate is not valid before %s", certificate.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", certificate.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// KeyPair хранит загруженные сертификат и ключ и позволяет заменить их без
+// перезапуска сервера: новые TLS-соединения получают сертификат, загруженный
+// последним вызовом Reload.
+type KeyPair struct {
+	certFile string
+	keyFile  string
+	current  atomic.Pointer[tls.Certificate]
+}
+
+// LoadKeyPair загружает сертификат и ключ из файлов certFile и keyFile.
+func LoadKeyPair(certFile, keyFile string) (*KeyPair, error) {
+	kp := &KeyPair{certFile: certFile, keyFile: keyFile}
+	if err := kp.Reload(); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// Reload перечитывает сертификат и ключ из файлов. При ошибке продолжает
+// использоваться ранее загруженный сертификат.
+func (kp *KeyPair) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		return err
+	}
+	kp.current.Store(&certificate)
+	return nil
+}
+
+// GetCertificate возвращает текущий сертификат. Используется в tls.Config.
+func (kp *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.current.Load(), nil
+}
@@ -0,0 +1,47 @@
+package cert
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyPair(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	certPEM, keyPEM := GenerateCert()
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+}
+
+func TestKeyPairReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeKeyPair(t, certFile, keyFile)
+
+	kp, err := LoadKeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	first, err := kp.GetCertificate(nil)
+	require.NoError(t, err)
+
+	writeKeyPair(t, certFile, keyFile)
+	require.NoError(t, kp.Reload())
+	second, err := kp.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Certificate[0], second.Certificate[0])
+
+	// Поврежденный файл не заменяет загруженный сертификат
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	assert.Error(t, kp.Reload())
+	current, err := kp.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second, current)
+
+	parsed, err := x509.ParseCertificate(current.Certificate[0])
+	require.NoError(t, err)
+	assert.True(t, parsed.NotAfter.After(time.Now()))
+}
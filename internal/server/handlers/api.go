@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// APIPrefix - префикс маршрутов версионированного REST API.
+const APIPrefix = "/api/v1"
+
+// Route - маршрут REST API, вызывающий метод сервиса Keeper.
+//
+// Сообщение запроса заполняется из JSON-тела (для методов с телом), параметров
+// строки запроса и параметров пути. Имена параметров совпадают с именами полей
+// сообщения в keeper.proto, поля вложенных сообщений задаются через точку,
+// например filter.tag.
+type Route struct {
+	Method  string // HTTP-метод
+	Path    string // путь относительно APIPrefix в формате Fiber
+	RPC     string // метод сервиса Keeper
+	Status  int    // статус успешного ответа
+	Summary string // описание для документации OpenAPI
+	Public  bool   // маршрут доступен без токена аутентификации
+}
+
+// APIRoutes - маршруты REST API версии 1.
+var APIRoutes = []Route{
+	{fiber.MethodPost, "/users", "Register", fiber.StatusCreated, "Регистрация пользователя", true},
+	{fiber.MethodPost, "/sessions", "Login", fiber.StatusCreated, "Вход пользователя", true},
+
+	{fiber.MethodGet, "/credentials", "GetCredentials", fiber.StatusOK, "Страница записей пользователя", false},
+	{fiber.MethodPost, "/credentials", "AddCredentials", fiber.StatusCreated, "Добавление записи", false},
+	{fiber.MethodPost, "/credentials/batch", "BatchAddCredentials", fiber.StatusCreated, "Добавление нескольких записей одной транзакцией", false},
+	{fiber.MethodPost, "/credentials/search", "SearchCredentials", fiber.StatusOK, "Поиск записей по слепому индексу", false},
+	{fiber.MethodGet, "/credentials/:id", "GetCredentials", fiber.StatusOK, "Получение записи", false},
+	{fiber.MethodPut, "/credentials/:id", "EditCredentials", fiber.StatusOK, "Изменение записи", false},
+	{fiber.MethodPatch, "/credentials/:credential_id/tags", "UpdateTags", fiber.StatusOK, "Добавление и удаление тегов записи", false},
+	{fiber.MethodPut, "/credentials/:credential_id/favorite", "SetFavorite", fiber.StatusOK, "Отметка записи как избранной", false},
+	{fiber.MethodPut, "/credentials/:credential_id/folder", "SetCredentialFolder", fiber.StatusOK, "Перемещение записи в папку", false},
+	{fiber.MethodGet, "/credentials/:credential_id/shares", "ListShares", fiber.StatusOK, "Пользователи, которым открыта запись", false},
+	{fiber.MethodPost, "/credentials/:credential_id/shares", "ShareCredential", fiber.StatusCreated, "Открытие доступа к записи", false},
+	{fiber.MethodDelete, "/credentials/:credential_id/shares/:username", "RevokeShare", fiber.StatusNoContent, "Отзыв доступа к записи", false},
+	{fiber.MethodGet, "/shared-with-me", "ListSharedWithMe", fiber.StatusOK, "Записи, открытые пользователю", false},
+	{fiber.MethodPut, "/keys", "SetPublicKey", fiber.StatusOK, "Публикация открытого ключа пользователя", false},
+	{fiber.MethodGet, "/users/:username/key", "GetPublicKey", fiber.StatusOK, "Открытый ключ пользователя", false},
+
+	{fiber.MethodGet, "/folders", "ListFolders", fiber.StatusOK, "Папки пользователя", false},
+	{fiber.MethodPost, "/folders", "CreateFolder", fiber.StatusCreated, "Создание папки", false},
+	{fiber.MethodPut, "/folders/:folder_id/name", "RenameFolder", fiber.StatusOK, "Переименование папки", false},
+	{fiber.MethodPut, "/folders/:folder_id/parent", "MoveFolder", fiber.StatusOK, "Перемещение папки", false},
+	{fiber.MethodDelete, "/folders/:folder_id", "DeleteFolder", fiber.StatusNoContent, "Удаление папки с вложенными папками, записи переходят в корень", false},
+
+	{fiber.MethodGet, "/orgs", "ListOrganizations", fiber.StatusOK, "Организации пользователя", false},
+	{fiber.MethodPost, "/orgs", "CreateOrganization", fiber.StatusCreated, "Создание организации", false},
+	{fiber.MethodGet, "/orgs/:org_id/members", "ListMembers", fiber.StatusOK, "Участники организации", false},
+	{fiber.MethodDelete, "/orgs/:org_id/members/:username", "RemoveMember", fiber.StatusNoContent, "Исключение участника", false},
+	{fiber.MethodPost, "/orgs/:org_id/invites", "InviteMember", fiber.StatusCreated, "Приглашение в организацию", false},
+	{fiber.MethodGet, "/invites", "ListInvites", fiber.StatusOK, "Приглашения пользователя", false},
+	{fiber.MethodPost, "/invites/:org_id/accept", "AcceptInvite", fiber.StatusOK, "Принятие приглашения", false},
+	{fiber.MethodPost, "/orgs/:org_id/teams", "CreateTeam", fiber.StatusCreated, "Создание команды", false},
+	{fiber.MethodPost, "/orgs/:org_id/teams/:team/members", "AddTeamMember", fiber.StatusCreated, "Добавление участника в команду", false},
+	{fiber.MethodGet, "/orgs/:org_id/collections", "ListCollections", fiber.StatusOK, "Коллекции организации", false},
+	{fiber.MethodPost, "/orgs/:org_id/collections", "CreateCollection", fiber.StatusCreated, "Создание коллекции", false},
+	{fiber.MethodPost, "/orgs/:org_id/collections/:collection/credentials", "AddToCollection", fiber.StatusCreated, "Добавление записи в коллекцию", false},
+	{fiber.MethodGet, "/orgs/:org_id/credentials", "ListOrgCredentials", fiber.StatusOK, "Записи организации", false},
+
+	{fiber.MethodPost, "/credentials/:credential_id/otp-counter", "NextOTPCounter", fiber.StatusOK, "Следующее значение счетчика HOTP записи", false},
+
+	{fiber.MethodGet, "/audit", "GetAuditLog", fiber.StatusOK, "Журнал аудита пользователя или организации", false},
+}
+
+// RegisterAPI регистрирует маршруты REST API и документ OpenAPI в router.
+func RegisterAPI(router fiber.Router) {
+	api := router.Group(APIPrefix)
+	api.Get("/openapi.json", OpenAPIHandler)
+	for _, route := range APIRoutes {
+		api.Add(route.Method, route.Path, apiHandler(route))
+	}
+
+	// Ошибка для неизвестных маршрутов возвращается в формате API
+	api.All("/*", func(c *fiber.Ctx) error {
+		return writeError(c, status.Errorf(codes.NotFound, "no route %s %s", c.Method(), c.Path()))
+	})
+}
+
+// apiHandler возвращает обработчик маршрута REST API route.
+func apiHandler(route Route) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := requestToken(c)
+		decode := func(v any) error {
+			msg, ok := v.(proto.Message)
+			if !ok {
+				return status.Errorf(codes.Internal, "unexpected request type %T", v)
+			}
+			if err := bindRequest(c, msg); err != nil {
+				return err
+			}
+			setToken(msg, token)
+			return nil
+		}
+
+		resp, err := callUnary(c, route.RPC, decode)
+		if err != nil {
+			return writeError(c, err)
+		}
+		setSessionCookies(c, resp)
+
+		if route.Status == fiber.StatusNoContent {
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+		return writeProto(c, route.Status, resp)
+	}
+}
+
+// hasBody сообщает, передается ли сообщение запроса для HTTP-метода в теле.
+func hasBody(method string) bool {
+	return method != fiber.MethodGet && method != fiber.MethodDelete
+}
+
+// bindRequest заполняет сообщение запроса msg из тела, строки запроса и параметров пути.
+// Параметры пути имеют приоритет над телом запроса.
+func bindRequest(c *fiber.Ctx, msg proto.Message) error {
+	if hasBody(c.Method()) && len(bytes.TrimSpace(c.Body())) > 0 {
+		if err := protojson.Unmarshal(c.Body(), msg); err != nil {
+			return invalidArgument("failed to parse payload data: " + err.Error())
+		}
+	}
+
+	var err error
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if err == nil {
+			err = setField(msg.ProtoReflect(), string(key), string(value))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.Route().Params {
+		if err := setField(msg.ProtoReflect(), name, c.Params(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField присваивает полю path сообщения msg значение value, преобразованное
+// к типу поля. Поля вложенных сообщений задаются через точку.
+func setField(msg protoreflect.Message, path, value string) error {
+	name, rest, nested := strings.Cut(path, ".")
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if field == nil || field.Name() == "token" {
+		return invalidArgument(fmt.Sprintf("unknown parameter %q", path))
+	}
+
+	if nested {
+		if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() {
+			return invalidArgument(fmt.Sprintf("unknown parameter %q", path))
+		}
+		return setField(msg.Mutable(field).Message(), rest, value)
+	}
+
+	v, err := scalarValue(field, value)
+	if err != nil {
+		return invalidArgument(fmt.Sprintf("invalid value of parameter %q: %v", path, err))
+	}
+	if field.IsList() {
+		msg.Mutable(field).List().Append(v)
+		return nil
+	}
+	msg.Set(field, v)
+	return nil
+}
+
+// scalarValue преобразует строку value в значение скалярного поля field.
+func scalarValue(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field type %s", field.Kind())
+	}
+}
+
+// legacyDeprecation - дата, с которой маршруты без версии считаются устаревшими,
+// в формате заголовка Deprecation (RFC 9745): 18.10.2026.
+const legacyDeprecation = "@1792281600"
+
+// Deprecated возвращает middleware устаревшего маршрута: ответ получает заголовок
+// Deprecation и ссылку Link на заменяющий маршрут successor REST API.
+func Deprecated(successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", legacyDeprecation)
+		c.Set(fiber.HeaderLink, "<"+APIPrefix+successor+`>; rel="successor-version"`)
+		return c.Next()
+	}
+}
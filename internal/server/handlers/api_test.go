@@ -0,0 +1,287 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiApp возвращает приложение с маршрутами REST API и конфигурацией cfg.
+func apiApp(cfg *configs.ServerConfig) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	handlers.RegisterAPI(app)
+	return app
+}
+
+// expectCredentialAccess ожидает запрос отношения пользователя userID к записи credentialID
+// и возвращает владение записью owner.
+func expectCredentialAccess(mock sqlmock.Sqlmock, credentialID, userID string, owner bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.uuid = $1")).
+		WithArgs(credentialID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner", "org_id", "share", "role", "team_access"}).
+			AddRow(owner, "", "", "", false))
+}
+
+func TestAPIBindsPathAndBody(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	credentialID := uuid.New().String()
+	expectCredentialAccess(mock, credentialID, userID, true)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_favorites")).
+		WithArgs(credentialID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	req := httptest.NewRequest(fiber.MethodPut, "/api/v1/credentials/"+credentialID+"/favorite",
+		strings.NewReader(`{"favorite":true,"credential_id":"ignored"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := apiApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIErrors(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	app := apiApp(cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		want   string
+	}{
+		{"missing token", fiber.MethodPut, "/api/v1/credentials/42", `{}`, fiber.StatusUnauthorized, "unauthorized"},
+		{"unknown query parameter", fiber.MethodGet, "/api/v1/credentials?colour=red", "", fiber.StatusBadRequest, `unknown parameter "colour"`},
+		{"token in query", fiber.MethodGet, "/api/v1/folders?token=abc", "", fiber.StatusBadRequest, `unknown parameter "token"`},
+		{"bad number", fiber.MethodGet, "/api/v1/credentials?page_size=many", "", fiber.StatusBadRequest, `invalid value of parameter "page_size"`},
+		{"unknown route", fiber.MethodGet, "/api/v1/secrets", "", fiber.StatusNotFound, "no route GET /api/v1/secrets"},
+		{"register without password", fiber.MethodPost, "/api/v1/users", `{"userData":{"username":"alice"}}`, fiber.StatusBadRequest, "username and password are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, resp.StatusCode)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Contains(t, body["error"], tt.want)
+		})
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	resp, err := apiApp(&configs.ServerConfig{}).Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/openapi.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	// Каждый маршрут описан в документе с уникальным идентификатором операции
+	ids := map[string]bool{}
+	for _, route := range handlers.APIRoutes {
+		path := regexp.MustCompile(`:(\w+)`).ReplaceAllString(handlers.APIPrefix+route.Path, "{$1}")
+		operation, ok := doc.Paths[path][strings.ToLower(route.Method)]
+		require.True(t, ok, "%s %s", route.Method, path)
+
+		id := operation["operationId"].(string)
+		assert.False(t, ids[id], "duplicate operationId %s", id)
+		ids[id] = true
+	}
+
+	edit := doc.Paths["/api/v1/credentials/{id}"]["put"]
+	assert.Equal(t, "EditCredentials", edit["operationId"])
+	assert.Contains(t, edit, "requestBody")
+	assert.Equal(t, []any{}, doc.Paths["/api/v1/users"]["post"]["security"])
+}
+
+func TestDeprecated(t *testing.T) {
+	app := fiber.New()
+	app.Post("/edit-credentials", handlers.Deprecated("/credentials"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/edit-credentials", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Regexp(t, `^@\d+$`, resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</api/v1/credentials>; rel="successor-version"`, resp.Header.Get("Link"))
+}
+
+func TestAPINextOTPCounter(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	credentialID := uuid.New().String()
+	expectCredentialAccess(mock, credentialID, userID, true)
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO otp_counters")).
+		WithArgs(credentialID, int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"counter"}).AddRow(int64(4)))
+
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/credentials/"+credentialID+"/otp-counter",
+		strings.NewReader(`{"initial_counter":3}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := apiApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "4", body["counter"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Отрицательный счетчик отклоняется без изменения данных
+	expectCredentialAccess(mock, credentialID, userID, true)
+	req = httptest.NewRequest(fiber.MethodPost, "/api/v1/credentials/"+credentialID+"/otp-counter",
+		strings.NewReader(`{"initial_counter":-1}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = apiApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestAPIBatchAddCredentials(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(sqlmock.AnyArg(), userID, "login", "login:admin", "website:example.com").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(sqlmock.AnyArg(), userID, "text", "note", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body := `{"items":[
+		{"credentials":{"type":"login","data":"login:admin","meta":"website:example.com"}},
+		{"credentials":{"data":"note"}}
+	]}`
+	req := httptest.NewRequest(fiber.MethodPost, "/api/v1/credentials/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := apiApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var result struct {
+		IDs []string `json:"ids"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Len(t, result.IDs, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Запись с неизвестным типом отклоняет весь пакет до открытия транзакции
+	body = `{"items":[{"credentials":{"data":"a"}},{"credentials":{"type":"unknown","data":"b"}}]}`
+	req = httptest.NewRequest(fiber.MethodPost, "/api/v1/credentials/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = apiApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPISharingKeys(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	app := apiApp(cfg)
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// Открытый ключ X25519 - ровно 32 байта
+	key := strings.Repeat("A", 43) + "="
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_keys")).
+		WithArgs(userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.Equal(t, fiber.StatusOK, send(fiber.MethodPut, "/api/v1/keys", `{"public_key":"`+key+`"}`))
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPut, "/api/v1/keys", `{"public_key":"AAAA"}`))
+
+	// Ключ получателя, который его не опубликовал
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT k.public_key FROM users u")).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"public_key"}).AddRow(nil))
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodGet, "/api/v1/users/bob/key", ""))
+
+	// Доступ без зашифрованного ключа записи отклоняется без изменения данных
+	credentialID := uuid.New().String()
+	expectCredentialAccess(mock, credentialID, userID, true)
+	assert.Equal(t, fiber.StatusBadRequest, send(fiber.MethodPost, "/api/v1/credentials/"+credentialID+"/shares",
+		`{"username":"bob","permission":"read"}`))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/audit"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/valyala/fasthttp"
+)
+
+// AuditMiddleware записывает в журнал аудита каждый HTTP-запрос.
+// Пользователь определяется так же, как при вызове сервиса: по полю token тела
+// запроса, заголовку Authorization или cookie token, а при входе и регистрации -
+// по выданной в ответе cookie. Организация события задается проверкой прав вызова
+// сервиса Keeper (setAuditOrg), а не полями запроса. Ошибка записи в журнал
+// не прерывает обработку запроса.
+func AuditMiddleware(c *fiber.Ctx) error {
+	ctx, scope := withAuditScope(c.UserContext())
+	c.SetUserContext(ctx)
+	err := c.Next()
+
+	entry := models.AuditEntry{
+		Action:    c.Method() + " " + c.Path(),
+		ClientIP:  c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Result:    audit.ResultOK,
+	}
+
+	if status := responseStatus(c, err); status >= fiber.StatusBadRequest {
+		entry.Result = "status " + strconv.Itoa(status)
+	}
+
+	var body auditBody
+	if len(c.Body()) > 0 {
+		// Тело может не быть JSON, тогда данные берутся только из пути и строки запроса
+		_ = json.Unmarshal(c.Body(), &body)
+	}
+	entry.ActorID = requestActor(c)
+
+	// Идентификатор записи передается в пути, в строке запроса или в теле запроса
+	entry.CredentialID = firstNonEmpty(c.Params("id"), c.Params("credential_id"), c.Query("id"),
+		body.ID, body.CredentialID, body.CredentialIDCamel)
+	entry.OrgID = scope.org()
+
+	if auditErr := storage.DBStorage.AppendAudit(c.UserContext(), entry); auditErr != nil {
+		slog.ErrorContext(c.UserContext(), "failed to write audit entry", "error", auditErr)
+	}
+
+	return err
+}
+
+// auditBody - поля тела запроса, которые попадают в журнал аудита. Шлюз принимает
+// имена полей как в keeper.proto и в lowerCamelCase, поэтому читаются оба варианта.
+type auditBody struct {
+	ID                string `json:"id"`
+	CredentialID      string `json:"credential_id"`
+	CredentialIDCamel string `json:"credentialId"`
+}
+
+// firstNonEmpty возвращает первое непустое значение из values.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// responseStatus возвращает код ответа на запрос с учетом ошибки, возвращенной обработчиком.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// requestActor возвращает идентификатор пользователя по токену запроса так же,
+// как его определяет сервис: по полю token тела запроса, а если его нет - по заголовку
+// Authorization или cookie token. При входе и регистрации пользователь определяется
+// по выданной в ответе cookie. Для анонимных запросов возвращается пустая строка.
+func requestActor(c *fiber.Ctx) string {
+	cfg, ok := c.Locals("config").(*configs.ServerConfig)
+	if !ok {
+		return ""
+	}
+	token := bodyToken(c)
+	if token == "" {
+		token = requestToken(c)
+	}
+	if token == "" {
+		token = responseCookie(c, TokenCookie)
+	}
+	if token == "" {
+		return ""
+	}
+	userID, err := auth.CheckIsAuthorized(cfg, token)
+	if err != nil {
+		return ""
+	}
+	return userID
+}
+
+// bodyToken возвращает поле token JSON-тела запроса. Токен в теле передают
+// клиенты шлюза POST /rpc/{method}.
+func bodyToken(c *fiber.Ctx) string {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if len(c.Body()) > 0 && json.Unmarshal(c.Body(), &body) != nil {
+		return ""
+	}
+	return body.Token
+}
+
+// responseCookie возвращает значение cookie, установленной обработчиком в ответе.
+func responseCookie(c *fiber.Ctx, name string) string {
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+
+	cookie.SetKey(name)
+	if !c.Response().Header.Cookie(cookie) {
+		return ""
+	}
+	return string(cookie.Value())
+}
@@ -0,0 +1,81 @@
+package internal_test
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	orgID := uuid.New().String()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	app.Use(handlers.AuditMiddleware)
+	app.Post("/rpc/:method", handlers.RPCHandler)
+
+	expectEntry := func(action, org, result string) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_log")).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_log")).
+			WithArgs(sqlmock.AnyArg(), userID, org, action, "", sqlmock.AnyArg(), "",
+				result, "", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+	call := func(body string) {
+		req := httptest.NewRequest(fiber.MethodPost, "/rpc/ListMembers", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		_, err := app.Test(req)
+		require.NoError(t, err)
+	}
+
+	// Организация, права в которой проверены, попадает в событие
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM org_members")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT m.user_id, u.username, m.role")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "role"}))
+	expectEntry("POST /rpc/ListMembers", orgID, "ok")
+	call(`{"orgId":"` + orgID + `"}`)
+
+	// Чужую организацию из запроса нельзя подставить в её журнал
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM org_members")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	expectEntry("POST /rpc/ListMembers", "", "status 404")
+	call(`{"orgId":"` + orgID + `"}`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
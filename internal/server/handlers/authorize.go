@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	"github.com/sol1corejz/goph-keeper/internal/server/authz"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+)
+
+// authorize проверяет токен запроса in и права пользователя на вызов метода method
+// по правилам authz.Rules. Через неё проходят все методы сервиса Keeper, в том числе
+// вызовы через HTTP-шлюз, которые не проходят через перехватчики gRPC.
+// Возвращает пользователя и его отношение к объекту вызова, а при ошибке - также
+// сообщение для поля error ответа.
+func (s *KeeperServer) authorize(ctx context.Context, method string, in any) (authz.Subject, string, error) {
+	var sub authz.Subject
+
+	rule, ok := authz.Rules[method]
+	if !ok {
+		return sub, "Недостаточно прав", errForbidden
+	}
+	if rule.Resource == authz.Public {
+		return sub, "", nil
+	}
+
+	var token string
+	if m, ok := in.(tokenMessage); ok {
+		token = m.GetToken()
+	}
+	if token == "" {
+		return sub, "Неавторизован", errUnauthenticated
+	}
+	userID, err := auth.Authorize(ctx, s.config(), token)
+	if err != nil {
+		return sub, "Не валидный токен аутентификации", errInvalidToken
+	}
+	sub.UserID = userID
+
+	var orgID string
+	if m, ok := in.(orgIDMessage); ok {
+		orgID = m.GetOrgId()
+	}
+	// Без организации вызов относится к данным самого пользователя
+	if rule.Optional && orgID == "" {
+		return sub, "", nil
+	}
+
+	// Роль нужна и при вызове с записью, если в запросе есть организация:
+	// запись помещается в её коллекцию. Организация в вызове с данными самого
+	// пользователя (принятие приглашения) проверяется обработчиком
+	if rule.Resource == authz.Organization || (rule.Resource == authz.Credential && orgID != "") {
+		sub.Role, err = storage.DBStorage.GetOrgRole(ctx, orgID, sub.UserID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return sub, "Организация не найдена", err
+		}
+		if err != nil {
+			return sub, "Ошибка получения данных", err
+		}
+	}
+
+	if rule.Resource == authz.Credential {
+		sub.Credential, err = storage.DBStorage.CredentialAccess(ctx, sub.UserID, requestCredentialID(in))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return sub, "Ошибка получения данных", err
+		}
+		// Запись, которую пользователь не может прочитать, для него не существует
+		if err != nil || !authz.CredentialAllowed(sub.Credential, authz.ReadCredential) {
+			return sub, "Запись не найдена", storage.ErrNotFound
+		}
+	}
+
+	if !authz.Check(rule, sub) {
+		return sub, "Недостаточно прав", errForbidden
+	}
+
+	// Событие аудита относится к организации, права в которой проверены: из запроса
+	// или той, которой принадлежит запись
+	switch {
+	case rule.Resource == authz.Organization || (rule.Resource == authz.Credential && orgID != ""):
+		setAuditOrg(ctx, orgID)
+	case rule.Resource == authz.Credential:
+		setAuditOrg(ctx, sub.Credential.OrgID)
+	}
+	return sub, "", nil
+}
+
+// requestCredentialID возвращает идентификатор записи из поля credential_id или id запроса.
+func requestCredentialID(in any) string {
+	switch m := in.(type) {
+	case credentialIDMessage:
+		return m.GetCredentialId()
+	case idMessage:
+		return m.GetId()
+	}
+	return ""
+}
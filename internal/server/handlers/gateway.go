@@ -0,0 +1,224 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Методы gRPC-сервиса Keeper, доступные через HTTP-шлюз, по имени.
+var (
+	gatewayMethods = map[string]grpc.MethodDesc{}
+	gatewayStreams = map[string]grpc.StreamDesc{}
+)
+
+func init() {
+	for _, m := range pb.Keeper_ServiceDesc.Methods {
+		gatewayMethods[m.MethodName] = m
+	}
+	for _, s := range pb.Keeper_ServiceDesc.Streams {
+		// Через HTTP доступны вызовы, в которых поток передает только одна сторона:
+		// поток клиента состоит из единственного сообщения из тела запроса
+		if s.ServerStreams != s.ClientStreams {
+			gatewayStreams[s.StreamName] = s
+		}
+	}
+}
+
+// jsonMarshal - параметры кодирования ответов шлюза: имена полей как в keeper.proto
+// и все поля сообщения, включая пустые.
+var jsonMarshal = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// RPCHandler вызывает метод gRPC-сервиса Keeper, имя которого указано в пути запроса
+// POST /rpc/{method}. Тело запроса - JSON-представление сообщения запроса из keeper.proto,
+// ответ - JSON-представление сообщения ответа. Для методов с потоком сервера
+// возвращается JSON-массив всех сообщений потока, для методов с потоком клиента
+// тело запроса - единственное сообщение потока.
+//
+// Токен аутентификации берется из поля token тела запроса, заголовка
+// Authorization: Bearer или cookie token. Ошибки возвращаются в виде
+// {"error": "...", "code": "..."} с HTTP-статусом, соответствующим коду gRPC.
+func RPCHandler(c *fiber.Ctx) error {
+	name := c.Params("method")
+	token := requestToken(c)
+	decode := func(v any) error {
+		return decodeRequest(c.Body(), v, token)
+	}
+
+	if desc, ok := gatewayStreams[name]; ok && desc.ServerStreams {
+		stream := &gatewayStream{ctx: httpContext(c), decode: decode}
+		if err := desc.Handler(keeperServer(c), stream); err != nil {
+			return writeError(c, err)
+		}
+		return writeProtoList(c, stream.sent)
+	}
+
+	resp, err := callUnary(c, name, decode)
+	if err != nil {
+		return writeError(c, err)
+	}
+	setSessionCookies(c, resp)
+	return writeProto(c, fiber.StatusOK, resp)
+}
+
+// callUnary вызывает метод name сервиса Keeper с одним сообщением запроса и одним
+// сообщением ответа: унарный или с потоком клиента из одного сообщения.
+// decode заполняет сообщение запроса.
+func callUnary(c *fiber.Ctx, name string, decode func(any) error) (proto.Message, error) {
+	if desc, ok := gatewayStreams[name]; ok && desc.ClientStreams {
+		stream := &gatewayStream{ctx: httpContext(c), decode: decode}
+		if err := desc.Handler(keeperServer(c), stream); err != nil {
+			return nil, err
+		}
+		if len(stream.sent) != 1 {
+			return nil, status.Errorf(codes.Internal, "method %q returned %d messages", name, len(stream.sent))
+		}
+		return stream.sent[0], nil
+	}
+
+	method, ok := gatewayMethods[name]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %q", name)
+	}
+	resp, err := method.Handler(keeperServer(c), httpContext(c), decode, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(proto.Message), nil
+}
+
+// keeperServer возвращает сервис Keeper с текущей конфигурацией запроса.
+func keeperServer(c *fiber.Ctx) *KeeperServer {
+	return &KeeperServer{Config: c.Locals("config").(*configs.ServerConfig)}
+}
+
+// httpContext возвращает контекст вызова сервиса Keeper из HTTP-запроса.
+func httpContext(c *fiber.Ctx) context.Context {
+	return withTransport(c.UserContext(), metrics.TransportHTTP)
+}
+
+// decodeRequest разбирает JSON-тело запроса в сообщение v и подставляет токен
+// аутентификации, если он не передан в теле. Пустое тело соответствует пустому сообщению.
+func decodeRequest(body []byte, v any, token string) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected request type %T", v)
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := protojson.Unmarshal(body, msg); err != nil {
+			return invalidArgument("failed to parse payload data: " + err.Error())
+		}
+	}
+	setToken(msg, token)
+	return nil
+}
+
+// writeError отправляет ошибку вызова в едином для HTTP API формате.
+func writeError(c *fiber.Ctx, err error) error {
+	st := status.Convert(toStatus(err))
+	return c.Status(HTTPStatus(st.Code())).JSON(fiber.Map{
+		"error": st.Message(),
+		"code":  st.Code().String(),
+	})
+}
+
+// writeProto отправляет сообщение msg в JSON-представлении.
+func writeProto(c *fiber.Ctx, code int, msg proto.Message) error {
+	data, err := jsonMarshal.Marshal(msg)
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(code).Send(data)
+}
+
+// writeProtoList отправляет сообщения msgs JSON-массивом.
+func writeProtoList(c *fiber.Ctx, msgs []proto.Message) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, msg := range msgs {
+		data, err := jsonMarshal.Marshal(msg)
+		if err != nil {
+			return writeError(c, err)
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// setSessionCookies сохраняет в cookie токен, выданный при регистрации или входе,
+// чтобы браузерные клиенты могли не передавать его в каждом запросе, и CSRF-токен
+// этой сессии для изменяющих запросов.
+func setSessionCookies(c *fiber.Ctx, msg proto.Message) {
+	var token string
+	switch m := msg.(type) {
+	case *pb.RegisterResponse:
+		token = m.GetToken()
+	case *pb.LoginResponse:
+		token = m.GetToken()
+	}
+	if token == "" {
+		return
+	}
+
+	c.Cookie(sessionCookie(TokenCookie, token, true))
+	c.Cookie(sessionCookie(CSRFCookie, csrfToken(c.Locals("config").(*configs.ServerConfig), token), false))
+}
+
+// gatewayStream - серверная сторона потокового вызова через HTTP-шлюз.
+// Единственное сообщение клиента берется из тела запроса, а сообщения
+// сервера накапливаются для ответа.
+type gatewayStream struct {
+	ctx      context.Context
+	decode   func(any) error
+	received bool
+	sent     []proto.Message
+}
+
+// Context возвращает контекст HTTP-запроса.
+func (s *gatewayStream) Context() context.Context { return s.ctx }
+
+// SetHeader ничего не делает: заголовки gRPC в HTTP-ответ не передаются.
+func (s *gatewayStream) SetHeader(metadata.MD) error { return nil }
+
+// SendHeader ничего не делает: заголовки gRPC в HTTP-ответ не передаются.
+func (s *gatewayStream) SendHeader(metadata.MD) error { return nil }
+
+// SetTrailer ничего не делает: трейлеры gRPC в HTTP-ответ не передаются.
+func (s *gatewayStream) SetTrailer(metadata.MD) {}
+
+// SendMsg добавляет сообщение сервера в ответ.
+func (s *gatewayStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected response type %T", m)
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// RecvMsg разбирает тело запроса при первом вызове и возвращает io.EOF при последующих.
+func (s *gatewayStream) RecvMsg(m any) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	return s.decode(m)
+}
@@ -0,0 +1,363 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gatewayApp возвращает приложение с маршрутами HTTP API и конфигурацией cfg.
+func gatewayApp(cfg *configs.ServerConfig) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	app.Post("/rpc/:method", handlers.RPCHandler)
+	app.Get("/credentials", handlers.GetCredentials)
+	return app
+}
+
+func TestRPCHandlerErrors(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	cfg.Security.MinPasswordEntropy = 40
+	app := gatewayApp(cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header map[string]string
+		code   int
+		want   map[string]string
+	}{
+		{
+			name:   "unknown method",
+			method: fiber.MethodPost,
+			path:   "/rpc/DropDatabase",
+			code:   fiber.StatusNotImplemented,
+			want:   map[string]string{"code": "Unimplemented", "error": `unknown method "DropDatabase"`},
+		},
+		{
+			name:   "missing token",
+			method: fiber.MethodPost,
+			path:   "/rpc/ListFolders",
+			code:   fiber.StatusUnauthorized,
+			want:   map[string]string{"code": "Unauthenticated", "error": "unauthorized"},
+		},
+		{
+			name:   "invalid bearer token",
+			method: fiber.MethodPost,
+			path:   "/rpc/ListFolders",
+			header: map[string]string{"Authorization": "Bearer broken"},
+			code:   fiber.StatusUnauthorized,
+			want:   map[string]string{"code": "Unauthenticated", "error": "invalid token"},
+		},
+		{
+			name:   "invalid token on legacy route",
+			method: fiber.MethodGet,
+			path:   "/credentials",
+			header: map[string]string{"Cookie": "token=broken"},
+			code:   fiber.StatusUnauthorized,
+			want:   map[string]string{"code": "Unauthenticated", "error": "invalid token"},
+		},
+		{
+			name:   "malformed body",
+			method: fiber.MethodPost,
+			path:   "/rpc/Register",
+			body:   `{"userData":`,
+			code:   fiber.StatusBadRequest,
+		},
+		{
+			name:   "missing password",
+			method: fiber.MethodPost,
+			path:   "/rpc/Register",
+			body:   `{"userData":{"username":"alice"}}`,
+			code:   fiber.StatusBadRequest,
+			want:   map[string]string{"code": "InvalidArgument", "error": "username and password are required"},
+		},
+		{
+			name:   "malformed public key",
+			method: fiber.MethodPost,
+			path:   "/rpc/Register",
+			body:   `{"userData":{"username":"alice","password":"qwerty"},"publicKey":"AAAA"}`,
+			code:   fiber.StatusBadRequest,
+			want:   map[string]string{"code": "InvalidArgument", "error": "public key must be 32 bytes"},
+		},
+		{
+			name:   "weak password",
+			method: fiber.MethodPost,
+			path:   "/rpc/Register",
+			body:   `{"userData":{"username":"alice","password":"qwerty"}}`,
+			code:   fiber.StatusBadRequest,
+			want:   map[string]string{"code": "InvalidArgument", "error": "password is too weak: 2 bits of entropy, at least 40 required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, resp.StatusCode)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			if tt.want != nil {
+				assert.Equal(t, tt.want, body)
+			}
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestRPCHandlerBearerToken(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	folderID := uuid.New().String()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM folders WHERE user_id = $1")).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "user_id", "parent_id", "name"}).
+			AddRow(folderID, userID, "", "work"))
+
+	req := httptest.NewRequest(fiber.MethodPost, "/rpc/ListFolders", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := gatewayApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"path":"work"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRPCHandlerRequiresToken(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	app := gatewayApp(cfg)
+
+	// Проверка прав не зависит от перехватчиков gRPC, поэтому действует и в шлюзе
+	for _, method := range pb.Keeper_ServiceDesc.Methods {
+		if method.MethodName == "Register" || method.MethodName == "Login" {
+			continue
+		}
+		req := httptest.NewRequest(fiber.MethodPost, "/rpc/"+method.MethodName, nil)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, method.MethodName)
+	}
+}
+
+func TestRPCHandlerCredentialAccess(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	app := gatewayApp(cfg)
+	credentialID := uuid.New().String()
+
+	call := func(method, body string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/rpc/"+method, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	expectAccess := func(share string) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.uuid = $1")).
+			WithArgs(credentialID, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"owner", "org_id", "share", "role", "team_access"}).
+				AddRow(false, "", share, "", false))
+	}
+
+	// Чужая запись без выданного доступа для пользователя не существует
+	expectAccess("")
+	assert.Equal(t, fiber.StatusNotFound, call("EditCredentials", `{"id":"`+credentialID+`"}`))
+
+	// Доступ на чтение не разрешает изменять запись и выдавать доступ к ней
+	expectAccess("read")
+	assert.Equal(t, fiber.StatusForbidden, call("EditCredentials", `{"id":"`+credentialID+`"}`))
+	expectAccess("write")
+	assert.Equal(t, fiber.StatusForbidden, call("ListShares", `{"credentialId":"`+credentialID+`"}`))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRPCHandlerAcceptInvite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	orgID := uuid.New().String()
+
+	// Приглашенный еще не участник, поэтому его роль не проверяется
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM org_invites")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO org_members")).
+		WithArgs(orgID, userID, "member").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/rpc/AcceptInvite", strings.NewReader(`{"orgId":"`+orgID+`"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := gatewayApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRPCHandlerListErrors(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	app := gatewayApp(cfg)
+
+	call := func(body string) (int, map[string]string) {
+		req := httptest.NewRequest(fiber.MethodPost, "/rpc/GetCredentials", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var out map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return resp.StatusCode, out
+	}
+
+	// Каждая ошибка параметров выборки сообщается отдельно, а не как неизвестная папка
+	code, body := call(`{"pageToken":"broken"}`)
+	assert.Equal(t, fiber.StatusBadRequest, code)
+	assert.Equal(t, "invalid page token", body["error"])
+
+	code, body = call(`{"sortBy":"name"}`)
+	assert.Equal(t, fiber.StatusBadRequest, code)
+	assert.Equal(t, "invalid sort field", body["error"])
+
+	code, body = call(`{"filter":{"type":"bogus"}}`)
+	assert.Equal(t, fiber.StatusBadRequest, code)
+	assert.Equal(t, "invalid credential type", body["error"])
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM folders WHERE user_id = $1")).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "user_id", "parent_id", "name"}))
+	code, body = call(`{"filter":{"folder":"missing"}}`)
+	assert.Equal(t, fiber.StatusNotFound, code)
+	assert.Equal(t, "not found", body["error"])
+
+	// Внутренняя ошибка хранилища не выдается за отсутствие папки
+	mock.ExpectQuery(regexp.QuoteMeta("FROM folders WHERE user_id = $1")).
+		WithArgs(userID).
+		WillReturnError(io.ErrUnexpectedEOF)
+	code, body = call(`{"filter":{"folder":"work"}}`)
+	assert.Equal(t, fiber.StatusInternalServerError, code)
+	assert.Equal(t, "failed to retrieve credentials", body["error"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenUnaryInterceptor(t *testing.T) {
+	server := &handlers.KeeperServer{Config: &configs.ServerConfig{}}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer from-metadata"))
+
+	var got string
+	handler := func(ctx context.Context, req any) (any, error) {
+		got = req.(*pb.ListFoldersRequest).Token
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Keeper/ListFolders"}
+
+	_, err := server.TokenUnaryInterceptor(ctx, &pb.ListFoldersRequest{}, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "from-metadata", got)
+
+	// Токен из тела запроса имеет приоритет
+	_, err = server.TokenUnaryInterceptor(ctx, &pb.ListFoldersRequest{Token: "from-body"}, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "from-body", got)
+}
+
+func TestStatusUnaryInterceptor(t *testing.T) {
+	server := &handlers.KeeperServer{Config: &configs.ServerConfig{}}
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Keeper/RenameFolder"}
+
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{storage.ErrNotFound, codes.NotFound},
+		{storage.ErrAlreadyExists, codes.AlreadyExists},
+		{storage.ErrFolderCycle, codes.FailedPrecondition},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{io.ErrUnexpectedEOF, codes.Internal},
+		{status.Error(codes.PermissionDenied, "forbidden"), codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		handler := func(ctx context.Context, req any) (any, error) { return nil, tt.err }
+		_, err := server.StatusUnaryInterceptor(context.Background(), nil, info, handler)
+		assert.Equal(t, tt.code, status.Code(err), tt.err.Error())
+	}
+}
@@ -2,49 +2,37 @@ package internal
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
-	"github.com/sol1corejz/goph-keeper/configs"
-	"github.com/sol1corejz/goph-keeper/internal/server/auth"
-	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
-	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
 )
 
 // GetCredentials обрабатывает запросы на получение учетных данных пользователя.
-// Она извлекает токен из cookies, проверяет его валидность и авторизует пользователя.
-// После этого она извлекает учетные данные из базы данных и возвращает их в ответе.
+// Она получает страницу учетных данных через сервис Keeper, как gRPC-метод GetCredentials,
+// и возвращает её в ответе. Токен берется из заголовка Authorization: Bearer или cookie token.
+// Параметры page_size, page_token, id, type, tag, folder, favorites, sort_by и descending
+// передаются в строке запроса.
 func GetCredentials(c *fiber.Ctx) error {
-	// Получение конфига из контекста
-	cfg := c.Locals("config").(*configs.ServerConfig)
-
-	// Получение токена из cookies
-	token := c.Cookies("token")
-	if token == "" {
-		log.Info("No token cookie provided")
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "unauthorized",
-		})
-	}
-
-	// Проверка авторизации
-	userID, err := auth.CheckIsAuthorized(cfg, token)
-	if err != nil {
-		log.Info("token is invalid")
-		return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{
-			"error": "token is invalid",
-		})
+	// Параметры постраничной выборки из строки запроса
+	req := &pb.GetCredentialsRequest{
+		Token:      requestToken(c),
+		Id:         c.Query("id"),
+		PageSize:   int32(c.QueryInt("page_size")),
+		PageToken:  c.Query("page_token"),
+		SortBy:     c.Query("sort_by"),
+		Descending: c.QueryBool("descending"),
+		Filter: &pb.CredentialsFilter{
+			Type:          c.Query("type"),
+			Tag:           c.Query("tag"),
+			Folder:        c.Query("folder"),
+			FavoritesOnly: c.QueryBool("favorites"),
+		},
 	}
 
-	// Получение учетных данных пользователя из базы данных
-	var credentialsData []internal.Credential
-	credentialsData, err = storage.DBStorage.GetCredentials(userID)
+	// Получение страницы учетных данных пользователя
+	resp, err := keeperServer(c).GetCredentials(httpContext(c), req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to retrieve credentials",
-		})
+		return writeError(c, err)
 	}
 
 	// Отправка учетных данных в ответе
-	return c.JSON(fiber.Map{
-		"credentials": credentialsData,
-	})
+	return writeProto(c, fiber.StatusOK, resp)
 }
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/audit"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Интерфейсы сообщений gRPC, из которых журнал аудита извлекает участников события.
+type (
+	tokenMessage        interface{ GetToken() string }
+	credentialIDMessage interface{ GetCredentialId() string }
+	idMessage           interface{ GetId() string }
+	orgIDMessage        interface{ GetOrgId() string }
+)
+
+// auditScope - данные события аудита, которые становятся известны только
+// при обработке вызова.
+type auditScope struct {
+	mu    sync.Mutex
+	orgID string
+}
+
+// auditScopeKey - ключ контекста, в котором хранится auditScope вызова.
+type auditScopeKey struct{}
+
+// withAuditScope возвращает контекст вызова с пустыми данными события аудита.
+func withAuditScope(ctx context.Context) (context.Context, *auditScope) {
+	scope := &auditScope{}
+	return context.WithValue(ctx, auditScopeKey{}, scope), scope
+}
+
+// setAuditOrg относит событие аудита вызова к организации orgID. Вызывается только
+// после проверки прав, поэтому организацию из запроса нельзя подставить в чужой журнал.
+func setAuditOrg(ctx context.Context, orgID string) {
+	if scope, ok := ctx.Value(auditScopeKey{}).(*auditScope); ok {
+		scope.mu.Lock()
+		scope.orgID = orgID
+		scope.mu.Unlock()
+	}
+}
+
+// org возвращает организацию события.
+func (a *auditScope) org() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.orgID
+}
+
+// AuditUnaryInterceptor записывает в журнал аудита каждый вызов gRPC-метода, кроме служебных.
+func (s *KeeperServer) AuditUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isSystemMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ctx, scope := withAuditScope(ctx)
+	resp, err := handler(ctx, req)
+	s.recordRPC(ctx, info.FullMethod, req, resp, scope, err)
+	return resp, err
+}
+
+// AuditStreamInterceptor записывает в журнал аудита каждый потоковый вызов.
+// Участники события извлекаются из первого сообщения клиента.
+func (s *KeeperServer) AuditStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isSystemMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	ctx, scope := withAuditScope(ss.Context())
+	stream := &recordingStream{ServerStream: ss, ctx: ctx}
+	err := handler(srv, stream)
+	s.recordRPC(ctx, info.FullMethod, stream.first, nil, scope, err)
+	return err
+}
+
+// isSystemMethod сообщает, относится ли метод к служебным сервисам gRPC
+// (grpc.health.v1, grpc.reflection). Их вызовы в журнал аудита не записываются.
+func isSystemMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.")
+}
+
+// recordingStream запоминает первое полученное от клиента сообщение.
+type recordingStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	first any
+}
+
+// Context возвращает контекст вызова с данными события аудита.
+func (s *recordingStream) Context() context.Context { return s.ctx }
+
+// RecvMsg получает сообщение клиента и запоминает первое из них.
+func (s *recordingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+	return err
+}
+
+// recordRPC формирует событие аудита по вызову gRPC-метода и сохраняет его.
+// Организация события берется из scope: её задает проверка прав, а не запрос.
+// Ошибка записи в журнал не прерывает обработку запроса, но попадает в лог.
+func (s *KeeperServer) recordRPC(ctx context.Context, fullMethod string, req, resp any, scope *auditScope, callErr error) {
+	entry := models.AuditEntry{
+		Action: path.Base(fullMethod),
+		Result: audit.ResultOK,
+	}
+	if callErr != nil {
+		entry.Result = status.Convert(callErr).Message()
+	}
+
+	// Пользователь определяется по токену запроса, а при входе и регистрации - по выданному токену
+	entry.ActorID = s.actorID(req, resp)
+
+	entry.CredentialID = requestCredentialID(req)
+	entry.OrgID = scope.org()
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.ClientIP); err == nil {
+			entry.ClientIP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			entry.UserAgent = ua[0]
+		}
+	}
+
+	if err := storage.DBStorage.AppendAudit(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit entry", "error", err)
+	}
+}
+
+// actorID возвращает идентификатор пользователя по первому действительному токену
+// из сообщений msgs или пустую строку, если токена нет.
+func (s *KeeperServer) actorID(msgs ...any) string {
+	for _, msg := range msgs {
+		if m, ok := msg.(tokenMessage); ok && m.GetToken() != "" {
+			if userID, err := auth.CheckIsAuthorized(s.config(), m.GetToken()); err == nil {
+				return userID
+			}
+		}
+	}
+	return ""
+}
+
+// GetAuditLog — gRPC-обработчик для получения журнала аудита пользователя или организации.
+func (s *KeeperServer) GetAuditLog(ctx context.Context, in *pb.GetAuditLogRequest) (*pb.GetAuditLogResponse, error) {
+	resp := &pb.GetAuditLogResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_GetAuditLog_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	filter := models.AuditFilter{
+		ActorID:  sub.UserID,
+		OrgID:    in.OrgId,
+		BeforeID: in.BeforeId,
+		Limit:    int(in.PageSize),
+	}
+	if filter.Limit <= 0 || filter.Limit > storage.MaxPageSize {
+		filter.Limit = storage.DefaultPageSize
+	}
+
+	entries, err := storage.DBStorage.ListAudit(ctx, filter)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, &pb.AuditEntry{
+			Id:           e.ID,
+			CreatedAt:    timestamppb.New(e.CreatedAt),
+			ActorId:      e.ActorID,
+			OrgId:        e.OrgID,
+			Action:       e.Action,
+			CredentialId: e.CredentialID,
+			ClientIp:     e.ClientIP,
+			UserAgent:    e.UserAgent,
+			Result:       e.Result,
+			Hash:         e.Hash,
+		})
+	}
+	return resp, nil
+}
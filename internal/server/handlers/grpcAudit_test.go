@@ -0,0 +1,146 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuditUnaryInterceptor(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	server := &handlers.KeeperServer{Config: cfg}
+
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	assert.NoError(t, err)
+
+	credentialID := uuid.New().String()
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "keepercli/1.0"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_log")).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_log")).
+		WithArgs(sqlmock.AnyArg(), userID, "", "SetFavorite", credentialID, "10.0.0.7", "keepercli/1.0",
+			"failed", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &pb.SetFavoriteResponse{}, errors.New("failed")
+	}
+	req := &pb.SetFavoriteRequest{Token: token, CredentialId: credentialID}
+
+	_, err = server.AuditUnaryInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/Keeper/SetFavorite"}, handler)
+	assert.EqualError(t, err, "failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditInterceptorOrgFromAuthorization(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	server := &handlers.KeeperServer{Config: cfg}
+
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	assert.NoError(t, err)
+	credentialID := uuid.New().String()
+	orgID := uuid.New().String()
+
+	expectEntry := func(action, credential, org string) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_log")).
+			WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_log")).
+			WithArgs(sqlmock.AnyArg(), userID, org, action, credential, "", "",
+				sqlmock.AnyArg(), "", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	// Изменение записи организации попадает в её журнал, хотя в запросе организации нет
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.uuid = $1")).
+		WithArgs(credentialID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"owner", "org_id", "share", "role", "team_access"}).
+			AddRow(false, orgID, "", "member", true))
+	expectEntry("EditCredentials", credentialID, orgID)
+
+	edit := &pb.EditCredentialsRequest{Token: token, Id: credentialID, SearchTerms: []string{"invalid"}}
+	_, err = server.AuditUnaryInterceptor(context.Background(), edit,
+		&grpc.UnaryServerInfo{FullMethod: pb.Keeper_EditCredentials_FullMethodName},
+		func(ctx context.Context, req any) (any, error) {
+			return server.EditCredentials(ctx, req.(*pb.EditCredentialsRequest))
+		})
+	assert.Error(t, err)
+
+	// Организация из запроса, в которой у пользователя нет прав, в событие не попадает
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM org_members")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	expectEntry("ListMembers", "", "")
+
+	list := &pb.ListMembersRequest{Token: token, OrgId: orgID}
+	_, err = server.AuditUnaryInterceptor(context.Background(), list,
+		&grpc.UnaryServerInfo{FullMethod: pb.Keeper_ListMembers_FullMethodName},
+		func(ctx context.Context, req any) (any, error) {
+			return server.ListMembers(ctx, req.(*pb.ListMembersRequest))
+		})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditInterceptorSkipsSystemServices(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	server := &handlers.KeeperServer{Config: &configs.ServerConfig{}}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+
+	_, err = server.AuditUnaryInterceptor(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// BatchAddCredentials — потоковый gRPC-обработчик для добавления записей одной транзакцией.
+// Клиент передает записи частями в нескольких сообщениях, токен проверяется по первому.
+// Все сообщения принимаются и проверяются до открытия транзакции, поэтому медленный
+// клиент не удерживает соединение с базой данных; затем записи сохраняются одной
+// короткой транзакцией - все или ни одна. Некорректная запись отклоняет весь вызов,
+// в ошибке указывается её номер среди всех записей вызова.
+func (s *KeeperServer) BatchAddCredentials(stream pb.Keeper_BatchAddCredentialsServer) error {
+	ctx := stream.Context()
+
+	// Проверка авторизации по первому сообщению потока
+	in, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return invalidArgument("no credentials to add")
+	}
+	if err != nil {
+		return err
+	}
+	sub, _, err := s.authorize(ctx, pb.Keeper_BatchAddCredentials_FullMethodName, in)
+	if err != nil {
+		return err
+	}
+
+	var (
+		creds []models.Credential
+		size  int
+	)
+	for ; err == nil; in, err = stream.Recv() {
+		if len(in.Items) > storage.MaxBatchSize {
+			return invalidArgument(fmt.Sprintf("batch is too large: %d credentials, at most %d allowed", len(in.Items), storage.MaxBatchSize))
+		}
+		if len(creds)+len(in.Items) > storage.MaxImportSize {
+			return invalidArgument(fmt.Sprintf("too many credentials, at most %d allowed per call", storage.MaxImportSize))
+		}
+		if size += proto.Size(in); size > storage.MaxImportBytes {
+			return invalidArgument(fmt.Sprintf("credentials are too large, at most %d bytes allowed per call", storage.MaxImportBytes))
+		}
+
+		for _, item := range in.Items {
+			cred, err := batchCredential(sub.UserID, item, len(creds)+1)
+			if err != nil {
+				return err
+			}
+			creds = append(creds, cred)
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		return err
+	}
+	if len(creds) == 0 {
+		return invalidArgument("no credentials to add")
+	}
+
+	if err = storage.DBStorage.SaveCredentials(ctx, creds); err != nil {
+		// Ошибки входных данных передаются клиенту как есть
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidSearchTerm) {
+			return err
+		}
+		return errors.New("failed to save credential data")
+	}
+
+	ids := make([]string, len(creds))
+	for i, cred := range creds {
+		ids[i] = cred.ID
+	}
+	return stream.SendAndClose(&pb.BatchAddCredentialsResponse{Ids: ids})
+}
+
+// batchCredential проверяет запись item с номером n из вызова BatchAddCredentials
+// и возвращает её для сохранения.
+func batchCredential(userID string, item *pb.NewCredentials, n int) (models.Credential, error) {
+	credentialType := models.CredentialType(item.GetCredentials().GetType())
+	if credentialType == "" {
+		credentialType = models.DefaultCredentialType
+	}
+	if !credentialType.Valid() {
+		return models.Credential{}, invalidArgument(fmt.Sprintf("item %d: invalid credential type", n))
+	}
+
+	for _, name := range models.SplitFolderPath(item.FolderPath) {
+		if !models.ValidFolderName(name) {
+			return models.Credential{}, invalidArgument(fmt.Sprintf("item %d: invalid folder name", n))
+		}
+	}
+
+	if err := storage.ValidateSearchTerms(item.SearchTerms, storage.MaxIndexTerms); err != nil {
+		return models.Credential{}, fmt.Errorf("item %d: %w", n, err)
+	}
+
+	return models.Credential{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Type:   credentialType,
+		Data:   item.GetCredentials().GetData(),
+		Meta:   item.GetCredentials().GetMeta(),
+		Tags:   models.NormalizeTags(item.GetCredentials().GetTags()),
+
+		FolderID:    item.GetCredentials().GetFolderId(),
+		FolderPath:  item.FolderPath,
+		Favorite:    item.GetCredentials().GetFavorite(),
+		SearchTerms: item.SearchTerms,
+	}, nil
+}
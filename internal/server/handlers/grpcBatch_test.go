@@ -0,0 +1,119 @@
+package internal_test
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchStream - поток клиента BatchAddCredentials с заранее заданными сообщениями.
+type batchStream struct {
+	grpc.ServerStream
+	requests []*pb.BatchAddCredentialsRequest
+	resp     *pb.BatchAddCredentialsResponse
+}
+
+func (s *batchStream) Context() context.Context { return context.Background() }
+
+func (s *batchStream) Recv() (*pb.BatchAddCredentialsRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *batchStream) SendAndClose(resp *pb.BatchAddCredentialsResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestBatchAddCredentialsStream(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	server := &handlers.KeeperServer{Config: cfg}
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	folderID := uuid.New().String()
+
+	// Записи всех сообщений потока и папка из пути сохраняются одной транзакцией
+	// после получения последнего сообщения
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(sqlmock.AnyArg(), userID, "text", "first", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO folders")).
+		WithArgs(sqlmock.AnyArg(), userID, nil, "work").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT uuid FROM folders WHERE user_id = $1")).
+		WithArgs(userID, nil, "work").
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow(folderID))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(sqlmock.AnyArg(), userID, "text", "second", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM folders")).
+		WithArgs(folderID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_folders")).
+		WithArgs(sqlmock.AnyArg(), folderID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	stream := &batchStream{requests: []*pb.BatchAddCredentialsRequest{
+		{Token: token, Items: []*pb.NewCredentials{{Credentials: &pb.Credentials{Data: "first"}}}},
+		{Items: []*pb.NewCredentials{{Credentials: &pb.Credentials{Data: "second"}, FolderPath: "work"}}},
+	}}
+	require.NoError(t, server.BatchAddCredentials(stream))
+	assert.Len(t, stream.resp.GetIds(), 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Ошибка в последнем сообщении отклоняет вызов до открытия транзакции
+	stream = &batchStream{requests: []*pb.BatchAddCredentialsRequest{
+		{Token: token, Items: []*pb.NewCredentials{{Credentials: &pb.Credentials{Data: "first"}}}},
+		{Items: []*pb.NewCredentials{{Credentials: &pb.Credentials{Type: "unknown", Data: "second"}}}},
+	}}
+	err = server.BatchAddCredentials(stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "item 2")
+	assert.Nil(t, stream.resp)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchAddCredentialsRequiresToken(t *testing.T) {
+	server := &handlers.KeeperServer{Config: &configs.ServerConfig{}}
+
+	stream := &batchStream{requests: []*pb.BatchAddCredentialsRequest{
+		{Items: []*pb.NewCredentials{{Credentials: &pb.Credentials{Data: "first"}}}},
+	}}
+	err := server.BatchAddCredentials(stream)
+	assert.Error(t, err)
+	assert.Nil(t, stream.resp)
+
+	// Пустой поток не открывает транзакцию
+	err = server.BatchAddCredentials(&batchStream{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"strings"
+)
+
+// organizeErrorMessage возвращает сообщение для ошибок работы с папками, тегами и избранным.
+func organizeErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Запись или папка не найдена"
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return "Папка с таким именем уже существует"
+	case errors.Is(err, storage.ErrFolderCycle):
+		return "Нельзя переместить папку в саму себя"
+	case errors.Is(err, storage.ErrInvalidSearchTerm):
+		return "Некорректный поисковый индекс"
+	default:
+		return "Ошибка сохранения данных"
+	}
+}
+
+// UpdateTags — gRPC-обработчик для добавления и удаления тегов записи.
+func (s *KeeperServer) UpdateTags(ctx context.Context, in *pb.UpdateTagsRequest) (*pb.UpdateTagsResponse, error) {
+	resp := &pb.UpdateTagsResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_UpdateTags_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.ValidateSearchTerms(in.SearchTerms, storage.MaxIndexTerms); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+
+	err = storage.DBStorage.UpdateCredentialTags(ctx, in.CredentialId,
+		models.NormalizeTags(in.Add), models.NormalizeTags(in.Remove), in.SearchTerms)
+	if err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// SetFavorite — gRPC-обработчик для установки и снятия отметки «избранное».
+func (s *KeeperServer) SetFavorite(ctx context.Context, in *pb.SetFavoriteRequest) (*pb.SetFavoriteResponse, error) {
+	resp := &pb.SetFavoriteResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_SetFavorite_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.SetFavorite(ctx, in.CredentialId, in.Favorite); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// SetCredentialFolder — gRPC-обработчик для перемещения записи в папку.
+func (s *KeeperServer) SetCredentialFolder(ctx context.Context, in *pb.SetCredentialFolderRequest) (*pb.SetCredentialFolderResponse, error) {
+	resp := &pb.SetCredentialFolderResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_SetCredentialFolder_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.SetCredentialFolder(ctx, sub.UserID, in.CredentialId, in.FolderId); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// CreateFolder — gRPC-обработчик для создания папки по пути.
+// С флагом parents недостающие родительские папки создаются автоматически.
+func (s *KeeperServer) CreateFolder(ctx context.Context, in *pb.CreateFolderRequest) (*pb.CreateFolderResponse, error) {
+	resp := &pb.CreateFolderResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_CreateFolder_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	names := models.SplitFolderPath(in.Path)
+	if len(names) == 0 {
+		resp.Error = "Не указан путь к папке"
+		return resp, invalidArgument("empty folder path")
+	}
+	for _, name := range names {
+		if !models.ValidFolderName(name) {
+			resp.Error = "Некорректное имя папки"
+			return resp, invalidArgument("invalid folder name")
+		}
+	}
+
+	folders, err := storage.DBStorage.ListFolders(ctx, sub.UserID)
+	if err != nil {
+		resp.Error = "Ошибка получения папок"
+		return resp, errors.New("failed to retrieve folders")
+	}
+	byPath := make(map[string]models.Folder, len(folders))
+	for _, f := range folders {
+		byPath[f.Path] = f
+	}
+
+	// Проходим путь от корня, создавая недостающие папки
+	var folder models.Folder
+	for i, name := range names {
+		path := strings.Join(names[:i+1], models.FolderPathSeparator)
+		if existing, ok := byPath[path]; ok {
+			if i == len(names)-1 {
+				resp.Error = organizeErrorMessage(storage.ErrAlreadyExists)
+				return resp, storage.ErrAlreadyExists
+			}
+			folder = existing
+			continue
+		}
+		if i < len(names)-1 && !in.Parents {
+			resp.Error = "Родительская папка не найдена"
+			return resp, storage.ErrNotFound
+		}
+
+		folder = models.Folder{
+			ID:       uuid.New().String(),
+			UserID:   sub.UserID,
+			ParentID: folder.ID,
+			Name:     name,
+			Path:     path,
+		}
+		if err = storage.DBStorage.CreateFolder(ctx, folder); err != nil {
+			resp.Error = organizeErrorMessage(err)
+			return resp, err
+		}
+	}
+
+	resp.Folder = toProtoFolder(folder)
+	return resp, nil
+}
+
+// ListFolders — gRPC-обработчик для получения дерева папок пользователя.
+func (s *KeeperServer) ListFolders(ctx context.Context, in *pb.ListFoldersRequest) (*pb.ListFoldersResponse, error) {
+	resp := &pb.ListFoldersResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListFolders_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	folders, err := storage.DBStorage.ListFolders(ctx, sub.UserID)
+	if err != nil {
+		resp.Error = "Ошибка получения папок"
+		return resp, errors.New("failed to retrieve folders")
+	}
+
+	resp.Folders = make([]*pb.Folder, 0, len(folders))
+	for _, f := range folders {
+		resp.Folders = append(resp.Folders, toProtoFolder(f))
+	}
+	return resp, nil
+}
+
+// RenameFolder — gRPC-обработчик для переименования папки.
+func (s *KeeperServer) RenameFolder(ctx context.Context, in *pb.RenameFolderRequest) (*pb.RenameFolderResponse, error) {
+	resp := &pb.RenameFolderResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_RenameFolder_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if !models.ValidFolderName(in.Name) {
+		resp.Error = "Некорректное имя папки"
+		return resp, invalidArgument("invalid folder name")
+	}
+
+	if err = storage.DBStorage.RenameFolder(ctx, sub.UserID, in.FolderId, in.Name); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+	return resp, nil
+}
+
+// MoveFolder — gRPC-обработчик для перемещения папки вместе с содержимым.
+func (s *KeeperServer) MoveFolder(ctx context.Context, in *pb.MoveFolderRequest) (*pb.MoveFolderResponse, error) {
+	resp := &pb.MoveFolderResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_MoveFolder_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.MoveFolder(ctx, sub.UserID, in.FolderId, in.ParentId); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+	return resp, nil
+}
+
+// DeleteFolder — gRPC-обработчик для удаления папки и всех вложенных папок.
+func (s *KeeperServer) DeleteFolder(ctx context.Context, in *pb.DeleteFolderRequest) (*pb.DeleteFolderResponse, error) {
+	resp := &pb.DeleteFolderResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_DeleteFolder_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.DeleteFolder(ctx, sub.UserID, in.FolderId); err != nil {
+		resp.Error = organizeErrorMessage(err)
+		return resp, err
+	}
+	return resp, nil
+}
+
+// toProtoFolder преобразует папку в gRPC-сообщение.
+func toProtoFolder(f models.Folder) *pb.Folder {
+	return &pb.Folder{
+		Id:       f.ID,
+		ParentId: f.ParentID,
+		Name:     f.Name,
+		Path:     f.Path,
+	}
+}
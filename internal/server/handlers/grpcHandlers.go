@@ -3,19 +3,34 @@ package internal
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/passgen"
 	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
 	models "github.com/sol1corejz/goph-keeper/internal/server/models"
 	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
 	pb "github.com/sol1corejz/goph-keeper/proto"
 	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // KeeperServer реализует gRPC Keeper.
 type KeeperServer struct {
 	pb.UnimplementedKeeperServer
 	Config *configs.ServerConfig
+
+	// Store - текущая конфигурация с учетом перезагрузок. Если задано, используется вместо Config.
+	Store *configs.Store
+}
+
+// config возвращает текущую конфигурацию сервера.
+func (s *KeeperServer) config() *configs.ServerConfig {
+	if s.Store != nil {
+		return s.Store.Load()
+	}
+	return s.Config
 }
 
 // Register — gRPC-обработчик регистрации пользователя.
@@ -26,6 +41,32 @@ func (s *KeeperServer) Register(ctx context.Context, in *pb.RegisterRequest) (*p
 	// Создание ответа с пустым значением, чтобы он всегда был возвращен
 	resp := &pb.RegisterResponse{}
 
+	// Проверка доступа: метод открыт для вызова без токена
+	if _, msg, err := s.authorize(ctx, pb.Keeper_Register_FullMethodName, in); err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Проверка входных данных
+	if in.GetUserData().GetUsername() == "" || in.GetUserData().GetPassword() == "" {
+		resp.Error = "Не указан логин или пароль"
+		return resp, invalidArgument("username and password are required")
+	}
+
+	// Открытый ключ необязателен, но если передан, должен иметь верный размер
+	if in.PublicKey != nil && len(in.PublicKey) != models.PublicKeySize {
+		resp.Error = "Некорректный открытый ключ"
+		return resp, invalidArgument("public key must be 32 bytes")
+	}
+
+	// Проверка стойкости пароля
+	if minimum := s.config().Security.MinPasswordEntropy; minimum > 0 {
+		if bits := passgen.GuessEntropy(in.UserData.Password, in.UserData.Username); bits < minimum {
+			resp.Error = fmt.Sprintf("Слишком простой пароль: %.0f бит энтропии, требуется не менее %.0f", bits, minimum)
+			return resp, invalidArgument(fmt.Sprintf("password is too weak: %.0f bits of entropy, at least %.0f required", bits, minimum))
+		}
+	}
+
 	// Хеширование пароля
 	hashedPassword, err := HashPassword(in.UserData.Password)
 	if err != nil {
@@ -40,8 +81,9 @@ func (s *KeeperServer) Register(ctx context.Context, in *pb.RegisterRequest) (*p
 		Password: hashedPassword,
 	}
 
-	// Сохранение в БД
-	err = storage.DBStorage.CreateUser(userData)
+	// Сохранение пользователя и его открытого ключа в БД одной транзакцией.
+	// Токен не хранится на сервере, поэтому выдается после фиксации транзакции
+	err = storage.DBStorage.CreateUser(ctx, userData, in.PublicKey)
 	if err != nil {
 		if errors.Is(err, storage.ErrAlreadyExists) {
 			resp.Error = "Пользователь уже зарегистрирован"
@@ -52,7 +94,7 @@ func (s *KeeperServer) Register(ctx context.Context, in *pb.RegisterRequest) (*p
 	}
 
 	// Генерация токена
-	token, err := auth.GenerateToken(s.Config, userUuid)
+	token, err := auth.GenerateToken(s.config(), userUuid)
 	if err != nil {
 		resp.Error = "Ошибка генерации токена"
 		return resp, err
@@ -68,18 +110,25 @@ func (s *KeeperServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.Logi
 	// Создание ответа с пустым значением, чтобы он всегда был возвращен
 	resp := &pb.LoginResponse{}
 
+	// Проверка доступа: метод открыт для вызова без токена
+	if _, msg, err := s.authorize(ctx, pb.Keeper_Login_FullMethodName, in); err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
 	// Входные данные для авторизации
 	loginData := models.AuthPayload{
-		Username: in.UserData.Username,
-		Password: in.UserData.Password,
+		Username: in.GetUserData().GetUsername(),
+		Password: in.GetUserData().GetPassword(),
 	}
 
 	// Получение пользователя из базы данных
-	userData, err := storage.DBStorage.GetUser(loginData.Username)
+	userData, err := storage.DBStorage.GetUser(ctx, loginData.Username)
 	if err != nil {
 		if errors.Is(storage.ErrNotFound, err) {
+			metrics.LoginFailed(transportFrom(ctx), metrics.ReasonUnknownUser)
 			resp.Error = "Неправильный логин или пароль"
-			return resp, err
+			return resp, errWrongCredentials
 		}
 		resp.Error = err.Error()
 		return resp, err
@@ -88,12 +137,17 @@ func (s *KeeperServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.Logi
 	// Сравнение пароля из входных данных с паролем из базы данных
 	err = bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(loginData.Password))
 	if err != nil {
+		metrics.LoginFailed(transportFrom(ctx), metrics.ReasonWrongPassword)
 		resp.Error = "Неправильный логин или пароль"
-		return resp, err
+		return resp, errWrongCredentials
 	}
 
 	// Генерация токена аутентификации
-	token, err := auth.GenerateToken(s.Config, userData.ID)
+	token, err := auth.GenerateToken(s.config(), userData.ID)
+	if err != nil {
+		resp.Error = "Ошибка генерации токена"
+		return resp, err
+	}
 
 	// Отправка успешного ответа
 	resp.Token = token
@@ -105,37 +159,58 @@ func (s *KeeperServer) AddCredentials(ctx context.Context, in *pb.AddCredentials
 	// Создание ответа с пустым значением, чтобы он всегда был возвращен
 	resp := &pb.AddCredentialsResponse{}
 
-	// Получение токена
-	token := in.Token
-	if token == "" {
-		resp.Error = "Неавторизован"
-		return resp, errors.New("unauthorized")
-	}
-
 	// Парсинг входных данных
 	credentialsPayload := models.CredentialPayload{
-		Data: in.Credentials.Data,
-		Meta: in.Credentials.Meta,
+		Type: in.GetCredentials().GetType(),
+		Data: in.GetCredentials().GetData(),
+		Meta: in.GetCredentials().GetMeta(),
+		Tags: in.GetCredentials().GetTags(),
 	}
 
 	// Проверка авторизации
-	userID, err := auth.CheckIsAuthorized(s.Config, token)
+	sub, msg, err := s.authorize(ctx, pb.Keeper_AddCredentials_FullMethodName, in)
 	if err != nil {
-		resp.Error = "Не валидный токен аутентификации"
-		return resp, errors.New("invalid token")
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Проверка типа данных
+	credentialType := models.CredentialType(credentialsPayload.Type)
+	if credentialType == "" {
+		credentialType = models.DefaultCredentialType
+	}
+	if !credentialType.Valid() {
+		resp.Error = "Неизвестный тип данных"
+		return resp, invalidArgument("invalid credential type")
+	}
+
+	// Проверка терминов слепого индекса
+	if err = storage.ValidateSearchTerms(in.SearchTerms, storage.MaxIndexTerms); err != nil {
+		resp.Error = "Некорректный поисковый индекс"
+		return resp, err
 	}
 
 	// Подготовка данных для сохранения в базе данных
 	credentialsData := models.Credential{
 		ID:     uuid.New().String(),
-		UserID: userID,
+		UserID: sub.UserID,
+		Type:   credentialType,
 		Data:   credentialsPayload.Data,
 		Meta:   credentialsPayload.Meta,
+		Tags:   models.NormalizeTags(credentialsPayload.Tags),
+
+		FolderID:    in.GetCredentials().GetFolderId(),
+		Favorite:    in.GetCredentials().GetFavorite(),
+		SearchTerms: in.SearchTerms,
 	}
 
 	// Сохранение учетных данных в базе данных
-	err = storage.DBStorage.SaveCredential(credentialsData)
+	err = storage.DBStorage.SaveCredential(ctx, credentialsData)
 	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Папка не найдена"
+			return resp, err
+		}
 		resp.Error = "Ошибка добавления данных"
 		return resp, errors.New("failed to save credential data")
 	}
@@ -149,37 +224,42 @@ func (s *KeeperServer) EditCredentials(ctx context.Context, in *pb.EditCredentia
 	// Создание ответа с пустым значением, чтобы он всегда был возвращен
 	resp := &pb.EditCredentialsResponse{}
 
-	// Получение токена
-	token := in.Token
-	if token == "" {
-		resp.Error = "Неавторизован"
-		return resp, errors.New("unauthorized")
-	}
-
 	// Парсинг входных данных
 	credentialsPayload := models.CredentialPayload{
-		Data: in.Credentials.Data,
-		Meta: in.Credentials.Meta,
+		Data: in.GetCredentials().GetData(),
+		Meta: in.GetCredentials().GetMeta(),
 	}
 
 	// Проверка авторизации
-	userID, err := auth.CheckIsAuthorized(s.Config, token)
+	sub, msg, err := s.authorize(ctx, pb.Keeper_EditCredentials_FullMethodName, in)
 	if err != nil {
-		resp.Error = "Не валидный токен аутентификации"
-		return resp, errors.New("invalid token")
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Проверка терминов слепого индекса
+	if err = storage.ValidateSearchTerms(in.SearchTerms, storage.MaxIndexTerms); err != nil {
+		resp.Error = "Некорректный поисковый индекс"
+		return resp, err
 	}
 
 	// Подготовка данных для сохранения в базе данных
 	credentialsData := models.Credential{
 		ID:     in.Id,
-		UserID: userID,
+		UserID: sub.UserID,
 		Data:   credentialsPayload.Data,
 		Meta:   credentialsPayload.Meta,
+
+		SearchTerms: in.SearchTerms,
 	}
 
 	// Сохранение учетных данных в базе данных
-	err = storage.DBStorage.EditCredential(credentialsData)
+	err = storage.DBStorage.EditCredential(ctx, credentialsData)
 	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Запись не найдена"
+			return resp, err
+		}
 		resp.Error = "Ошибка обновления данных"
 		return resp, errors.New("failed to edit credential data")
 	}
@@ -189,43 +269,190 @@ func (s *KeeperServer) EditCredentials(ctx context.Context, in *pb.EditCredentia
 
 }
 
-// GetCredentials — gRPC-обработчик для получения данных польхователя.
+// GetCredentials — gRPC-обработчик для получения страницы данных пользователя.
 func (s *KeeperServer) GetCredentials(ctx context.Context, in *pb.GetCredentialsRequest) (*pb.GetCredentialsResponse, error) {
 	// Создание ответа с пустым значением, чтобы он всегда был возвращен
 	resp := &pb.GetCredentialsResponse{}
 
-	// Получение токена
-	token := in.Token
-	if token == "" {
-		resp.Error = "Неавторизован"
-		return resp, errors.New("unauthorized")
+	// Проверка авторизации
+	sub, msg, err := s.authorize(ctx, pb.Keeper_GetCredentials_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Параметры выборки
+	opts, err := listOptionsFromRequest(ctx, sub.UserID, in)
+	if err != nil {
+		resp.Error = listErrorMessage(err)
+		return resp, listError(err)
+	}
+
+	// Получение страницы учетных данных пользователя из базы данных
+	page, err := storage.DBStorage.ListCredentials(ctx, sub.UserID, opts)
+	if err != nil {
+		resp.Error = listErrorMessage(err)
+		return resp, listError(err)
+	}
+
+	// Преобразование данных для отправки ответа
+	credentials := make([]*pb.Credentials, 0, len(page.Credentials))
+	for _, credential := range page.Credentials {
+		credentials = append(credentials, toProtoCredentials(credential))
+	}
+
+	// Отправка учетных данных в ответе
+	resp.Credentials = credentials
+	resp.NextPageToken = page.NextPageToken
+	return resp, nil
+}
+
+// ListCredentials — потоковый gRPC-обработчик, передающий все данные пользователя,
+// подходящие под фильтр. Данные читаются из хранилища постранично, поэтому
+// в памяти одновременно находится не больше одной страницы.
+func (s *KeeperServer) ListCredentials(in *pb.GetCredentialsRequest, stream pb.Keeper_ListCredentialsServer) error {
+	ctx := stream.Context()
+
+	// Проверка авторизации
+	sub, _, err := s.authorize(ctx, pb.Keeper_ListCredentials_FullMethodName, in)
+	if err != nil {
+		return err
+	}
+
+	opts, err := listOptionsFromRequest(ctx, sub.UserID, in)
+	if err != nil {
+		return listError(err)
+	}
+
+	for {
+		page, err := storage.DBStorage.ListCredentials(ctx, sub.UserID, opts)
+		if err != nil {
+			return listError(err)
+		}
+
+		for _, credential := range page.Credentials {
+			if err = stream.Send(toProtoCredentials(credential)); err != nil {
+				return err
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return nil
+		}
+		opts.PageToken = page.NextPageToken
 	}
+}
+
+// SearchCredentials — gRPC-обработчик поиска данных пользователя по слепому индексу.
+// Сервер сравнивает только переданные клиентом HMAC терминов и не видит сам запрос.
+func (s *KeeperServer) SearchCredentials(ctx context.Context, in *pb.SearchCredentialsRequest) (*pb.SearchCredentialsResponse, error) {
+	// Создание ответа с пустым значением, чтобы он всегда был возвращен
+	resp := &pb.SearchCredentialsResponse{}
 
 	// Проверка авторизации
-	userID, err := auth.CheckIsAuthorized(s.Config, token)
+	sub, msg, err := s.authorize(ctx, pb.Keeper_SearchCredentials_FullMethodName, in)
 	if err != nil {
-		resp.Error = "Не валидный токен аутентификации"
-		return resp, errors.New("invalid token")
+		resp.Error = msg
+		return resp, err
 	}
 
-	// Получение учетных данных пользователя из базы данных
-	var credentialsData []models.Credential
-	credentialsData, err = storage.DBStorage.GetCredentials(userID)
+	// Проверка терминов запроса
+	if err = storage.ValidateSearchTerms(in.Terms, storage.MaxQueryTerms); err != nil {
+		resp.Error = "Некорректный поисковый запрос"
+		return resp, err
+	}
+
+	// Поиск учетных данных в базе данных
+	credentialsData, err := storage.DBStorage.SearchCredentials(ctx, sub.UserID, in.Terms, int(in.Limit))
 	if err != nil {
-		resp.Error = "Ошибка получения данных"
-		return resp, errors.New("failed to retrieve credentials")
+		resp.Error = "Ошибка поиска данных"
+		return resp, errors.New("failed to search credentials")
 	}
 
 	// Преобразование данных для отправки ответа
-	credentials := make([]*pb.Credentials, 0)
+	credentials := make([]*pb.Credentials, 0, len(credentialsData))
 	for _, credential := range credentialsData {
-		credentials = append(credentials, &pb.Credentials{
-			Data: credential.Data,
-			Meta: credential.Meta,
-		})
+		credentials = append(credentials, toProtoCredentials(credential))
 	}
 
-	// Отправка учетных данных в ответе
 	resp.Credentials = credentials
 	return resp, nil
 }
+
+// listOptionsFromRequest формирует параметры выборки из gRPC-запроса.
+// Путь к папке в фильтре преобразуется в её идентификатор.
+func listOptionsFromRequest(ctx context.Context, userID string, in *pb.GetCredentialsRequest) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		PageSize:  int(in.PageSize),
+		PageToken: in.PageToken,
+		ID:        in.Id,
+		SortBy:    in.SortBy,
+		Desc:      in.Descending,
+	}
+	if in.Filter != nil {
+		opts.Type = models.CredentialType(in.Filter.Type)
+		if opts.Type != "" && !opts.Type.Valid() {
+			return opts, errInvalidType
+		}
+		opts.Tag = in.Filter.Tag
+		opts.Favorites = in.Filter.FavoritesOnly
+		if in.Filter.Folder != "" {
+			folder, err := storage.DBStorage.FindFolderByPath(ctx, userID, in.Filter.Folder)
+			if err != nil {
+				return opts, err
+			}
+			opts.FolderID = folder.ID
+		}
+	}
+	return opts, nil
+}
+
+// errInvalidType - ошибка, возвращаемая при фильтре по неизвестному типу данных.
+var errInvalidType = errors.New("invalid credential type")
+
+// listErrorMessage возвращает сообщение для ошибок разбора параметров выборки
+// и чтения страницы учетных данных.
+func listErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Папка не найдена"
+	case errors.Is(err, storage.ErrInvalidPageToken):
+		return "Некорректный токен страницы"
+	case errors.Is(err, storage.ErrInvalidSort):
+		return "Некорректное поле сортировки"
+	case errors.Is(err, errInvalidType):
+		return "Неизвестный тип данных"
+	default:
+		return "Ошибка получения данных"
+	}
+}
+
+// listError возвращает ошибку выборки учетных данных: ошибки входных данных
+// передаются как есть, а внутренние ошибки хранилища скрываются от клиента.
+func listError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound),
+		errors.Is(err, storage.ErrInvalidPageToken),
+		errors.Is(err, storage.ErrInvalidSort):
+		return err
+	case errors.Is(err, errInvalidType):
+		return invalidArgument(err.Error())
+	default:
+		return errors.New("failed to retrieve credentials")
+	}
+}
+
+// toProtoCredentials преобразует учетные данные в gRPC-сообщение.
+func toProtoCredentials(credential models.Credential) *pb.Credentials {
+	return &pb.Credentials{
+		Id:        credential.ID,
+		Type:      string(credential.Type),
+		Data:      credential.Data,
+		Meta:      credential.Meta,
+		Tags:      credential.Tags,
+		FolderId:  credential.FolderID,
+		Favorite:  credential.Favorite,
+		CreatedAt: timestamppb.New(credential.CreatedAt),
+		UpdatedAt: timestamppb.New(credential.UpdatedAt),
+	}
+}
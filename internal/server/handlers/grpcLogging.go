@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingUnaryInterceptor добавляет в контекст вызова идентификатор запроса, метод
+// и пользователя и по завершении вызова записывает в лог его итог.
+func (s *KeeperServer) LoggingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	ctx = requestContext(ctx, info.FullMethod)
+	userID := s.actorID(req)
+	if userID != "" {
+		ctx = logging.WithContext(ctx, slog.String(logging.KeyUserID, userID))
+	}
+
+	resp, err := handler(ctx, req)
+
+	// При входе и регистрации пользователь известен только после выдачи токена
+	issuedTo := ""
+	if userID == "" {
+		issuedTo = s.actorID(resp)
+	}
+	logRPC(ctx, start, issuedTo, err)
+	return resp, err
+}
+
+// LoggingStreamInterceptor добавляет в контекст потокового вызова идентификатор
+// запроса и метод и по завершении вызова записывает в лог его итог.
+// Пользователь определяется по первому сообщению клиента.
+func (s *KeeperServer) LoggingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	ctx := requestContext(ss.Context(), info.FullMethod)
+	stream := &recordingStream{ServerStream: &contextStream{ServerStream: ss, ctx: ctx}}
+	err := handler(srv, stream)
+
+	logRPC(ctx, start, s.actorID(stream.first), err)
+	return err
+}
+
+// contextStream подменяет контекст потокового вызова.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст вызова с полями логов.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestContext дополняет контекст вызова идентификатором запроса и методом.
+// Идентификатор берется из метаданных x-request-id или создается заново
+// и возвращается клиенту в заголовке ответа.
+func requestContext(ctx context.Context, fullMethod string) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(logging.RequestIDHeader); len(ids) > 0 {
+			requestID = ids[0]
+		}
+	}
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(logging.RequestIDHeader, requestID))
+
+	return logging.WithContext(ctx,
+		slog.String(logging.KeyRequestID, requestID),
+		slog.String(logging.KeyMethod, fullMethod),
+	)
+}
+
+// logRPC записывает в лог итог вызова gRPC-метода. userID передается, если пользователь
+// не был известен до вызова и поэтому отсутствует в контексте.
+func logRPC(ctx context.Context, start time.Time, userID string, err error) {
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if userID != "" {
+		attrs = append(attrs, slog.String(logging.KeyUserID, userID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, codeLevel(code), "rpc finished", attrs...)
+}
+
+// codeLevel возвращает уровень записи лога для кода завершения gRPC-вызова.
+func codeLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
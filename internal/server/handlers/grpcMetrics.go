@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsUnaryInterceptor учитывает gRPC-вызов в метриках и отмечает активность пользователя.
+func (s *KeeperServer) MetricsUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+	metrics.Sessions.Touch(s.actorID(req, resp))
+	return resp, err
+}
+
+// MetricsStreamInterceptor учитывает потоковый gRPC-вызов в метриках и отмечает
+// активность пользователя по первому сообщению клиента.
+func (s *KeeperServer) MetricsStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	stream := &recordingStream{ServerStream: ss}
+	err := handler(srv, stream)
+
+	metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+	metrics.Sessions.Touch(s.actorID(stream.first))
+	return err
+}
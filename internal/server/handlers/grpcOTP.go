@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+)
+
+// NextOTPCounter — gRPC-обработчик для получения следующего значения счетчика HOTP записи.
+func (s *KeeperServer) NextOTPCounter(ctx context.Context, in *pb.NextOTPCounterRequest) (*pb.NextOTPCounterResponse, error) {
+	resp := &pb.NextOTPCounterResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_NextOTPCounter_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if in.InitialCounter < 0 {
+		resp.Error = "Счетчик не может быть отрицательным"
+		return resp, invalidArgument("initial counter must not be negative")
+	}
+
+	counter, err := storage.DBStorage.NextOTPCounter(ctx, in.CredentialId, in.InitialCounter)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Запись не найдена"
+			return resp, err
+		}
+		resp.Error = "Ошибка сохранения данных"
+		return resp, err
+	}
+
+	resp.Counter = counter
+	return resp, nil
+}
@@ -0,0 +1,404 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/internal/server/authz"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// orgErrorMessage возвращает сообщение для ошибок работы с организациями.
+func orgErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Организация, команда или запись не найдена"
+	case errors.Is(err, storage.ErrUserNotFound):
+		return "Пользователь не найден"
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return "Уже существует"
+	case errors.Is(err, storage.ErrLastOwner):
+		return "Нельзя удалить последнего владельца организации"
+	case errors.Is(err, storage.ErrSealedCredential):
+		return "Данные записи зашифрованы ключом записи: передайте их расшифрованными"
+	default:
+		return "Ошибка сохранения данных"
+	}
+}
+
+// validOrgName проверяет имя организации, команды или коллекции.
+func validOrgName(name string) bool {
+	return name != "" && name == strings.TrimSpace(name) && len(name) <= models.MaxFolderNameLen
+}
+
+// CreateOrganization — gRPC-обработчик для создания организации.
+func (s *KeeperServer) CreateOrganization(ctx context.Context, in *pb.CreateOrganizationRequest) (*pb.CreateOrganizationResponse, error) {
+	resp := &pb.CreateOrganizationResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_CreateOrganization_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if !validOrgName(in.Name) {
+		resp.Error = "Некорректное имя организации"
+		return resp, invalidArgument("invalid organization name")
+	}
+
+	org := models.Organization{ID: uuid.New().String(), Name: in.Name, Role: models.OrgRoleOwner}
+	if err = storage.DBStorage.CreateOrganization(ctx, org, sub.UserID); err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	setAuditOrg(ctx, org.ID)
+	resp.Organization = toProtoOrganization(org)
+	return resp, nil
+}
+
+// ListOrganizations — gRPC-обработчик для получения организаций пользователя.
+func (s *KeeperServer) ListOrganizations(ctx context.Context, in *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	resp := &pb.ListOrganizationsResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListOrganizations_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	orgs, err := storage.DBStorage.ListOrganizations(ctx, sub.UserID)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, org := range orgs {
+		resp.Organizations = append(resp.Organizations, toProtoOrganization(org))
+	}
+	return resp, nil
+}
+
+// ListMembers — gRPC-обработчик для получения участников организации.
+func (s *KeeperServer) ListMembers(ctx context.Context, in *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	resp := &pb.ListMembersResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_ListMembers_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	members, err := storage.DBStorage.ListMembers(ctx, in.OrgId)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, member := range members {
+		resp.Members = append(resp.Members, &pb.OrgMember{Username: member.Username, Role: string(member.Role)})
+	}
+	return resp, nil
+}
+
+// InviteMember — gRPC-обработчик для приглашения пользователя в организацию.
+func (s *KeeperServer) InviteMember(ctx context.Context, in *pb.InviteMemberRequest) (*pb.InviteMemberResponse, error) {
+	resp := &pb.InviteMemberResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_InviteMember_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Роль по умолчанию — участник
+	role := models.OrgRole(in.Role)
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+	if !role.Valid() {
+		resp.Error = "Неизвестная роль"
+		return resp, invalidArgument("invalid role")
+	}
+	if !authz.CanAssign(sub.Role, role) {
+		resp.Error = "Недостаточно прав"
+		return resp, errForbidden
+	}
+
+	if err = storage.DBStorage.InviteMember(ctx, in.OrgId, in.Username, role); err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			resp.Error = "Пользователь уже состоит в организации"
+			return resp, err
+		}
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// ListInvites — gRPC-обработчик для получения приглашений пользователя.
+func (s *KeeperServer) ListInvites(ctx context.Context, in *pb.ListInvitesRequest) (*pb.ListInvitesResponse, error) {
+	resp := &pb.ListInvitesResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListInvites_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	invites, err := storage.DBStorage.ListInvites(ctx, sub.UserID)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, invite := range invites {
+		resp.Invites = append(resp.Invites, &pb.OrgInvite{
+			OrgId:     invite.OrgID,
+			OrgName:   invite.OrgName,
+			Role:      string(invite.Role),
+			CreatedAt: timestamppb.New(invite.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// AcceptInvite — gRPC-обработчик для принятия приглашения в организацию.
+func (s *KeeperServer) AcceptInvite(ctx context.Context, in *pb.AcceptInviteRequest) (*pb.AcceptInviteResponse, error) {
+	resp := &pb.AcceptInviteResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_AcceptInvite_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.AcceptInvite(ctx, sub.UserID, in.OrgId); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Приглашение не найдено"
+			return resp, err
+		}
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	// Приглашение проверено при принятии, поэтому событие относится к организации
+	setAuditOrg(ctx, in.OrgId)
+	return resp, nil
+}
+
+// RemoveMember — gRPC-обработчик для удаления участника из организации.
+// Участник сразу теряет доступ к коллекциям, а доступные ему записи отмечаются для смены.
+func (s *KeeperServer) RemoveMember(ctx context.Context, in *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	resp := &pb.RemoveMemberResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_RemoveMember_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	member, err := storage.DBStorage.GetOrgMember(ctx, in.OrgId, in.Username)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Участник не найден"
+			return resp, err
+		}
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+	if !authz.CanAssign(sub.Role, member.Role) {
+		resp.Error = "Недостаточно прав"
+		return resp, errForbidden
+	}
+
+	// Участник, видевший все коллекции, мог прочитать любую запись организации
+	allCollections := authz.Allowed(member.Role, authz.SeeAllCollections)
+	flagged, err := storage.DBStorage.RemoveMember(ctx, in.OrgId, member.UserID, allCollections)
+	if err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	resp.FlaggedForRotation = flagged
+	return resp, nil
+}
+
+// CreateTeam — gRPC-обработчик для создания команды организации.
+func (s *KeeperServer) CreateTeam(ctx context.Context, in *pb.CreateTeamRequest) (*pb.CreateTeamResponse, error) {
+	resp := &pb.CreateTeamResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_CreateTeam_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if !validOrgName(in.Name) {
+		resp.Error = "Некорректное имя команды"
+		return resp, invalidArgument("invalid team name")
+	}
+
+	err = storage.DBStorage.CreateTeam(ctx, models.Team{ID: uuid.New().String(), OrgID: in.OrgId, Name: in.Name})
+	if err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// AddTeamMember — gRPC-обработчик для добавления участника организации в команду.
+func (s *KeeperServer) AddTeamMember(ctx context.Context, in *pb.AddTeamMemberRequest) (*pb.AddTeamMemberResponse, error) {
+	resp := &pb.AddTeamMemberResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_AddTeamMember_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if err = storage.DBStorage.AddTeamMember(ctx, in.OrgId, in.Team, in.Username); err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// CreateCollection — gRPC-обработчик для создания коллекции организации.
+func (s *KeeperServer) CreateCollection(ctx context.Context, in *pb.CreateCollectionRequest) (*pb.CreateCollectionResponse, error) {
+	resp := &pb.CreateCollectionResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_CreateCollection_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if !validOrgName(in.Name) {
+		resp.Error = "Некорректное имя коллекции"
+		return resp, invalidArgument("invalid collection name")
+	}
+
+	collection := models.Collection{
+		ID:    uuid.New().String(),
+		OrgID: in.OrgId,
+		Name:  in.Name,
+		Teams: models.NormalizeTags(in.Teams),
+	}
+	if err = storage.DBStorage.CreateCollection(ctx, collection); err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	resp.Collection = &pb.Collection{Id: collection.ID, Name: collection.Name, Teams: collection.Teams}
+	return resp, nil
+}
+
+// ListCollections — gRPC-обработчик для получения доступных пользователю коллекций организации.
+func (s *KeeperServer) ListCollections(ctx context.Context, in *pb.ListCollectionsRequest) (*pb.ListCollectionsResponse, error) {
+	resp := &pb.ListCollectionsResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListCollections_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	collections, err := storage.DBStorage.ListCollections(ctx, sub.UserID, in.OrgId)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, c := range collections {
+		if !authz.CollectionAllowed(sub.Role, c.TeamAccess, authz.ReadSecrets) {
+			continue
+		}
+		resp.Collections = append(resp.Collections, &pb.Collection{Id: c.ID, Name: c.Name, Teams: c.Teams})
+	}
+	return resp, nil
+}
+
+// AddToCollection — gRPC-обработчик для помещения записи в коллекцию организации.
+// Собственная запись пользователя переходит во владение организации.
+func (s *KeeperServer) AddToCollection(ctx context.Context, in *pb.AddToCollectionRequest) (*pb.AddToCollectionResponse, error) {
+	resp := &pb.AddToCollectionResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_AddToCollection_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Запись организации перемещается только между коллекциями этой организации
+	if sub.Credential.OrgID != "" && sub.Credential.OrgID != in.OrgId {
+		resp.Error = "Запись не найдена"
+		return resp, storage.ErrNotFound
+	}
+
+	collection, err := storage.DBStorage.GetCollection(ctx, sub.UserID, in.OrgId, in.Collection)
+	if err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+	// Коллекция, недоступная пользователю, для него не существует
+	if !authz.CollectionAllowed(sub.Role, collection.TeamAccess, authz.WriteSecrets) {
+		resp.Error = orgErrorMessage(storage.ErrNotFound)
+		return resp, storage.ErrNotFound
+	}
+
+	// Взамен зашифрованных данных личной записи передаются расшифрованные
+	if strings.HasPrefix(in.Data, models.SealedDataPrefix) {
+		resp.Error = "Данные записи должны быть расшифрованы"
+		return resp, invalidArgument("transferred data must not be sealed")
+	}
+
+	if err = storage.DBStorage.AddToCollection(ctx, in.CredentialId, in.OrgId, collection.ID, in.Data); err != nil {
+		resp.Error = orgErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// ListOrgCredentials — gRPC-обработчик для получения доступных пользователю записей организации.
+func (s *KeeperServer) ListOrgCredentials(ctx context.Context, in *pb.ListOrgCredentialsRequest) (*pb.ListOrgCredentialsResponse, error) {
+	resp := &pb.ListOrgCredentialsResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListOrgCredentials_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	creds, err := storage.DBStorage.ListOrgCredentials(ctx, sub.UserID, in.OrgId)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, cred := range creds {
+		if !authz.CollectionAllowed(sub.Role, cred.TeamAccess, authz.ReadSecrets) {
+			continue
+		}
+		resp.Credentials = append(resp.Credentials, &pb.OrgCredentials{
+			Credentials:      toProtoCredentials(cred.Credential),
+			CollectionId:     cred.CollectionID,
+			RotationRequired: cred.RotationRequired,
+		})
+	}
+	return resp, nil
+}
+
+// toProtoOrganization преобразует организацию в сообщение gRPC.
+func toProtoOrganization(org models.Organization) *pb.Organization {
+	return &pb.Organization{Id: org.ID, Name: org.Name, Role: string(org.Role)}
+}
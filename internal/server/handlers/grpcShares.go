@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+)
+
+// shareErrorMessage возвращает сообщение для ошибок выдачи и отзыва доступа.
+func shareErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Запись не найдена"
+	case errors.Is(err, storage.ErrUserNotFound):
+		return "Пользователь не найден"
+	case errors.Is(err, storage.ErrShareToSelf):
+		return "Нельзя выдать доступ владельцу записи"
+	case errors.Is(err, storage.ErrSharesChanged):
+		return "Список получателей доступа изменился, повторите отзыв"
+	default:
+		return "Ошибка сохранения данных"
+	}
+}
+
+// ShareCredential — gRPC-обработчик для выдачи доступа к записи другому пользователю.
+func (s *KeeperServer) ShareCredential(ctx context.Context, in *pb.ShareCredentialRequest) (*pb.ShareCredentialResponse, error) {
+	resp := &pb.ShareCredentialResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ShareCredential_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Уровень доступа по умолчанию — только чтение
+	permission := models.SharePermission(in.Permission)
+	if permission == "" {
+		permission = models.SharePermissionRead
+	}
+	if !permission.Valid() {
+		resp.Error = "Неизвестный уровень доступа"
+		return resp, invalidArgument("invalid share permission")
+	}
+
+	// Ключ записи шифрует клиент владельца на открытом ключе получателя,
+	// поэтому доступ без него получатель использовать не сможет
+	if len(in.EncryptedKey) == 0 {
+		resp.Error = "Не передан ключ записи, зашифрованный для получателя"
+		return resp, invalidArgument("encrypted key is required")
+	}
+	if len(in.EncryptedKey) > models.MaxEncryptedKeyLen {
+		resp.Error = "Слишком длинный ключ записи"
+		return resp, invalidArgument("encrypted key is too long")
+	}
+
+	err = storage.DBStorage.ShareCredential(ctx, sub.UserID, models.CredentialShare{
+		CredentialID: in.CredentialId,
+		GranteeName:  in.Username,
+		Permission:   permission,
+		EncryptedKey: in.EncryptedKey,
+	})
+	if err != nil {
+		resp.Error = shareErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// RevokeShare — gRPC-обработчик для отзыва доступа к записи.
+func (s *KeeperServer) RevokeShare(ctx context.Context, in *pb.RevokeShareRequest) (*pb.RevokeShareResponse, error) {
+	resp := &pb.RevokeShareResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_RevokeShare_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	// Клиент владельца передает данные зашифрованной записи, зашифрованные новым
+	// ключом, и этот ключ для оставшихся получателей
+	var rekey *models.CredentialRekey
+	if in.Data != "" {
+		if !strings.HasPrefix(in.Data, models.SealedDataPrefix) {
+			resp.Error = "Данные записи должны быть зашифрованы новым ключом записи"
+			return resp, invalidArgument("rekeyed data must be sealed")
+		}
+		for _, key := range in.EncryptedKeys {
+			if len(key) == 0 || len(key) > models.MaxEncryptedKeyLen {
+				resp.Error = "Некорректный ключ записи"
+				return resp, invalidArgument("invalid encrypted key")
+			}
+		}
+		rekey = &models.CredentialRekey{Data: in.Data, EncryptedKeys: in.EncryptedKeys}
+	} else if len(in.EncryptedKeys) > 0 {
+		resp.Error = "Не переданы данные записи, зашифрованные новым ключом"
+		return resp, invalidArgument("encrypted keys without data")
+	}
+
+	if err = storage.DBStorage.RevokeShare(ctx, in.CredentialId, in.Username, rekey); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			resp.Error = "Доступ не найден"
+			return resp, err
+		}
+		resp.Error = shareErrorMessage(err)
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// ListShares — gRPC-обработчик для получения списка пользователей с доступом к записи.
+func (s *KeeperServer) ListShares(ctx context.Context, in *pb.ListSharesRequest) (*pb.ListSharesResponse, error) {
+	resp := &pb.ListSharesResponse{}
+
+	_, msg, err := s.authorize(ctx, pb.Keeper_ListShares_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	shares, err := storage.DBStorage.ListShares(ctx, in.CredentialId)
+	if err != nil {
+		resp.Error = shareErrorMessage(err)
+		return resp, err
+	}
+
+	for _, share := range shares {
+		resp.Shares = append(resp.Shares, &pb.Share{
+			Username:   share.GranteeName,
+			Permission: string(share.Permission),
+			CreatedAt:  timestamppb.New(share.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// ListSharedWithMe — gRPC-обработчик для получения записей, к которым пользователю выдан доступ.
+func (s *KeeperServer) ListSharedWithMe(ctx context.Context, in *pb.ListSharedWithMeRequest) (*pb.ListSharedWithMeResponse, error) {
+	resp := &pb.ListSharedWithMeResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_ListSharedWithMe_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	shared, err := storage.DBStorage.ListSharedWithMe(ctx, sub.UserID)
+	if err != nil {
+		resp.Error = "Ошибка получения данных"
+		return resp, err
+	}
+
+	for _, cred := range shared {
+		resp.Credentials = append(resp.Credentials, &pb.SharedCredentials{
+			Credentials:  toProtoCredentials(cred.Credential),
+			Owner:        cred.OwnerName,
+			Permission:   string(cred.Permission),
+			EncryptedKey: cred.EncryptedKey,
+		})
+	}
+	return resp, nil
+}
+
+// SetPublicKey — gRPC-обработчик для публикации открытого ключа пользователя.
+func (s *KeeperServer) SetPublicKey(ctx context.Context, in *pb.SetPublicKeyRequest) (*pb.SetPublicKeyResponse, error) {
+	resp := &pb.SetPublicKeyResponse{}
+
+	sub, msg, err := s.authorize(ctx, pb.Keeper_SetPublicKey_FullMethodName, in)
+	if err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	if len(in.PublicKey) != models.PublicKeySize {
+		resp.Error = "Некорректный открытый ключ"
+		return resp, invalidArgument("public key must be 32 bytes")
+	}
+
+	if err = storage.DBStorage.SetPublicKey(ctx, sub.UserID, in.PublicKey); err != nil {
+		resp.Error = "Ошибка сохранения данных"
+		return resp, err
+	}
+	return resp, nil
+}
+
+// GetPublicKey — gRPC-обработчик для получения открытого ключа пользователя,
+// на котором шифруется ключ записи при выдаче ему доступа.
+func (s *KeeperServer) GetPublicKey(ctx context.Context, in *pb.GetPublicKeyRequest) (*pb.GetPublicKeyResponse, error) {
+	resp := &pb.GetPublicKeyResponse{}
+
+	if _, msg, err := s.authorize(ctx, pb.Keeper_GetPublicKey_FullMethodName, in); err != nil {
+		resp.Error = msg
+		return resp, err
+	}
+
+	key, err := storage.DBStorage.GetPublicKey(ctx, in.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			resp.Error = "Пользователь не найден"
+		case errors.Is(err, storage.ErrNoPublicKey):
+			resp.Error = "Пользователь не опубликовал открытый ключ"
+		default:
+			resp.Error = "Ошибка получения данных"
+		}
+		return resp, err
+	}
+
+	resp.PublicKey = key
+	return resp, nil
+}
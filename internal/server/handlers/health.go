@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/internal/server/health"
+)
+
+// LivenessHandler отвечает на проверку живости /healthz, пока процесс обслуживает запросы.
+// Зависимости здесь не проверяются: перезапуск сервера не восстановит базу данных.
+func LivenessHandler(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": health.StatusOK})
+}
+
+// NewReadinessHandler возвращает обработчик проверки готовности /readyz.
+// Обработчик выполняет проверки checker и отвечает 503, если хотя бы одна не пройдена.
+func NewReadinessHandler(checker *health.Checker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := checker.Check(c.UserContext())
+		if !report.Ready() {
+			c.Status(fiber.StatusServiceUnavailable)
+		}
+		return c.JSON(report)
+	}
+}
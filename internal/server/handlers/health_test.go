@@ -0,0 +1,45 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	"github.com/sol1corejz/goph-keeper/internal/server/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	app := fiber.New()
+	app.Get("/healthz", handlers.LivenessHandler)
+	app.Get("/readyz", handlers.NewReadinessHandler(health.NewChecker(db, "")))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/healthz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	mock.ExpectPing()
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusFail, report.Checks[health.CheckDatabase])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
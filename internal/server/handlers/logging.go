@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+)
+
+// RequestLogger добавляет в контекст запроса идентификатор запроса, метод и пользователя,
+// чтобы записи логов обработчиков содержали эти поля, и по завершении запроса
+// записывает в лог его итог. Идентификатор запроса берется из заголовка X-Request-ID
+// или создается заново и возвращается клиенту в том же заголовке.
+func RequestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+
+	requestID := c.Get(logging.RequestIDHeader)
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	c.Set(logging.RequestIDHeader, requestID)
+
+	ctx := logging.WithContext(c.UserContext(),
+		slog.String(logging.KeyRequestID, requestID),
+		slog.String(logging.KeyMethod, c.Method()+" "+c.Path()),
+	)
+	userID := requestActor(c)
+	if userID != "" {
+		ctx = logging.WithContext(ctx, slog.String(logging.KeyUserID, userID))
+	}
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	status := responseStatus(c, err)
+	attrs := []slog.Attr{
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("client_ip", c.IP()),
+	}
+	// При входе и регистрации пользователь известен только после выдачи токена
+	if userID == "" {
+		if userID = requestActor(c); userID != "" {
+			attrs = append(attrs, slog.String(logging.KeyUserID, userID))
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, statusLevel(status), "http request", attrs...)
+
+	return err
+}
+
+// statusLevel возвращает уровень записи лога для кода ответа HTTP.
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		return slog.LevelError
+	case status >= fiber.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,117 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// captureLogs подменяет логгер по умолчанию на время теста и возвращает
+// записанные в него JSON-записи.
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(&buf, slog.LevelDebug)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var rec map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &rec))
+			records = append(records, rec)
+		}
+		return records
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	records := captureLogs(t)
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	app.Use(handlers.RequestLogger)
+	app.Get("/credentials", func(c *fiber.Ctx) error {
+		slog.InfoContext(c.UserContext(), "inside handler")
+		return c.SendStatus(fiber.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/credentials", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("Cookie", "token="+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", resp.Header.Get("X-Request-ID"))
+
+	logs := records()
+	require.Len(t, logs, 2)
+	for _, rec := range logs {
+		assert.Equal(t, "req-42", rec[logging.KeyRequestID])
+		assert.Equal(t, userID, rec[logging.KeyUserID])
+		assert.Equal(t, "GET /credentials", rec[logging.KeyMethod])
+	}
+	assert.Equal(t, "http request", logs[1]["msg"])
+	assert.Equal(t, "WARN", logs[1]["level"])
+	assert.EqualValues(t, fiber.StatusNotFound, logs[1]["status"])
+}
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	records := captureLogs(t)
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	server := &handlers.KeeperServer{Config: cfg}
+
+	userID := uuid.New().String()
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-7"))
+	handler := func(ctx context.Context, req any) (any, error) {
+		slog.InfoContext(ctx, "inside handler")
+		return &pb.LoginResponse{Token: token}, nil
+	}
+
+	_, err = server.LoggingUnaryInterceptor(ctx, &pb.LoginRequest{UserData: &pb.User{Username: "alice", Password: "hunter2"}},
+		&grpc.UnaryServerInfo{FullMethod: "/Keeper/Login"}, handler)
+	require.NoError(t, err)
+
+	logs := records()
+	require.Len(t, logs, 2)
+	assert.Equal(t, "req-7", logs[0][logging.KeyRequestID])
+	assert.Equal(t, "/Keeper/Login", logs[0][logging.KeyMethod])
+	assert.Nil(t, logs[0][logging.KeyUserID])
+
+	assert.Equal(t, "rpc finished", logs[1]["msg"])
+	assert.Equal(t, "OK", logs[1]["code"])
+	assert.Equal(t, userID, logs[1][logging.KeyUserID])
+}
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+)
+
+// unmatchedRoute - значение метки route для запросов, не совпавших ни с одним маршрутом.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware учитывает HTTP-запрос в метриках и отмечает активность пользователя.
+// В метку route попадает шаблон маршрута, а не путь запроса.
+func MetricsMiddleware(c *fiber.Ctx) error {
+	start := time.Now()
+	self := c.Route()
+
+	err := c.Next()
+
+	// Если обработчик не найден, текущим маршрутом остается сам middleware
+	route := c.Route().Path
+	if c.Route() == self {
+		route = unmatchedRoute
+	}
+	status := strconv.Itoa(responseStatus(c, err))
+	metrics.ObserveHTTP(c.Method(), route, status, time.Since(start))
+	metrics.Sessions.Touch(requestActor(c))
+
+	return err
+}
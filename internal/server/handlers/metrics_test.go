@@ -0,0 +1,35 @@
+package internal_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddlewareLabelsRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(handlers.MetricsMiddleware)
+	app.Get("/folders/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	for _, path := range []string{"/folders/1", "/folders/2", "/no-such-route"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `gophkeeper_http_requests_total{method="GET",route="/folders/:id",status="204"} 2`)
+	assert.Contains(t, string(body), `gophkeeper_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, string(body), `route="/folders/1"`)
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	pb "github.com/sol1corejz/goph-keeper/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// APIVersion - версия REST API в документе OpenAPI.
+const APIVersion = "1.0.0"
+
+// errorSchema - имя схемы ответа с ошибкой в документе OpenAPI.
+const errorSchema = "Error"
+
+// OpenAPI возвращает документ OpenAPI 3 для маршрутов APIRoutes. Параметры и схемы
+// сообщений строятся по описанию сервиса Keeper из keeper.proto, поэтому документ
+// не расходится с обработчиками.
+func OpenAPI() map[string]any {
+	service := pb.File_keeper_proto.Services().ByName("Keeper")
+	schemas := map[string]any{
+		errorSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"error": map[string]any{"type": "string", "description": "Описание ошибки"},
+				"code":  map[string]any{"type": "string", "description": "Код gRPC, например NotFound"},
+			},
+			"required": []string{"error", "code"},
+		},
+	}
+
+	paths := map[string]any{}
+	operationIDs := map[string]bool{}
+	for _, route := range APIRoutes {
+		method := service.Methods().ByName(protoreflect.Name(route.RPC))
+		in, out := method.Input(), method.Output()
+		if hasBody(route.Method) {
+			addSchema(schemas, in)
+		}
+		addSchema(schemas, out)
+
+		pathParams := routeParams(route.Path)
+		var params []any
+		for _, name := range pathParams {
+			params = append(params, map[string]any{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   fieldSchema(in.Fields().ByName(protoreflect.Name(name))),
+			})
+		}
+		if !hasBody(route.Method) {
+			params = append(params, queryParams(in, "", pathParams)...)
+		}
+
+		operation := map[string]any{
+			"operationId": operationID(route, pathParams, operationIDs),
+			"summary":     route.Summary,
+			"tags":        []string{strings.Split(strings.TrimPrefix(route.Path, "/"), "/")[0]},
+			"responses":   responses(route, out),
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if hasBody(route.Method) {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					fiber.MIMEApplicationJSON: map[string]any{"schema": schemaRef(string(in.Name()))},
+				},
+			}
+		}
+		if route.Public {
+			operation["security"] = []any{}
+		}
+
+		path := APIPrefix + openAPIPath(route.Path)
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "GophKeeper API",
+			"version": APIVersion,
+			"description": "REST API сервиса GophKeeper. Каждый маршрут вызывает метод gRPC-сервиса Keeper " +
+				"из keeper.proto. Токен аутентификации передается в заголовке Authorization: Bearer или в cookie token. " +
+				"Изменяющие запросы с cookie token передают в заголовке X-CSRF-Token значение cookie csrf_token.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"cookieAuth": map[string]any{"type": "apiKey", "in": "cookie", "name": TokenCookie},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []string{}},
+			map[string]any{"cookieAuth": []string{}},
+		},
+	}
+}
+
+// openAPIDocument - закодированный документ OpenAPI. Документ не меняется
+// во время работы сервера, поэтому строится один раз.
+var openAPIDocument = sync.OnceValues(func() ([]byte, error) {
+	return json.MarshalIndent(OpenAPI(), "", "  ")
+})
+
+// OpenAPIHandler отдает документ OpenAPI REST API.
+func OpenAPIHandler(c *fiber.Ctx) error {
+	data, err := openAPIDocument()
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(data)
+}
+
+// responses возвращает описание ответов маршрута route с сообщением ответа out.
+func responses(route Route, out protoreflect.MessageDescriptor) map[string]any {
+	success := map[string]any{"description": route.Summary}
+	if route.Status != fiber.StatusNoContent {
+		success["content"] = map[string]any{
+			fiber.MIMEApplicationJSON: map[string]any{"schema": schemaRef(string(out.Name()))},
+		}
+	}
+	return map[string]any{
+		strconv.Itoa(route.Status): success,
+		"default": map[string]any{
+			"description": "Ошибка",
+			"content": map[string]any{
+				fiber.MIMEApplicationJSON: map[string]any{"schema": schemaRef(errorSchema)},
+			},
+		},
+	}
+}
+
+// operationID возвращает уникальный идентификатор операции маршрута route.
+// Маршруты одного метода различаются параметрами пути.
+func operationID(route Route, pathParams []string, used map[string]bool) string {
+	id := route.RPC
+	if used[id] && len(pathParams) > 0 {
+		id += "By" + pascalCase(pathParams[len(pathParams)-1])
+	}
+	used[id] = true
+	return id
+}
+
+// pascalCase преобразует имя в snake_case в PascalCase: credential_id - CredentialId.
+func pascalCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// routeParams возвращает имена параметров пути маршрута в формате Fiber.
+func routeParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// openAPIPath преобразует путь в формате Fiber (/credentials/:id) в формат OpenAPI (/credentials/{id}).
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// queryParams возвращает параметры строки запроса для скалярных полей сообщения msg.
+// Поля вложенных сообщений получают имена через точку, поле token и параметры пути пропускаются.
+func queryParams(msg protoreflect.MessageDescriptor, prefix string, skip []string) []any {
+	var params []any
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := prefix + string(field.Name())
+		if name == "token" || slices.Contains(skip, name) {
+			continue
+		}
+		switch {
+		case field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap():
+			params = append(params, queryParams(field.Message(), name+".", skip)...)
+		case field.IsList() || field.IsMap():
+			continue
+		default:
+			params = append(params, map[string]any{
+				"name":   name,
+				"in":     "query",
+				"schema": fieldSchema(field),
+			})
+		}
+	}
+	return params
+}
+
+// schemaRef возвращает ссылку на схему name из раздела components.
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// addSchema добавляет в schemas схему сообщения msg и всех вложенных сообщений.
+func addSchema(schemas map[string]any, msg protoreflect.MessageDescriptor) {
+	name := string(msg.Name())
+	if _, ok := schemas[name]; ok || msg.FullName() == "google.protobuf.Timestamp" {
+		return
+	}
+
+	properties := map[string]any{}
+	schema := map[string]any{"type": "object", "properties": properties}
+	schemas[name] = schema
+
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		properties[string(field.Name())] = fieldSchema(field)
+		if field.Kind() == protoreflect.MessageKind {
+			addSchema(schemas, field.Message())
+		}
+	}
+}
+
+// fieldSchema возвращает схему значения поля field в JSON-представлении protobuf.
+func fieldSchema(field protoreflect.FieldDescriptor) map[string]any {
+	var schema map[string]any
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		schema = map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		schema = map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// 64-битные числа кодируются в JSON строкой
+		schema = map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		schema = map[string]any{"type": "number"}
+	case protoreflect.BytesKind:
+		schema = map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		schema = map[string]any{"type": "string"}
+	case protoreflect.MessageKind:
+		if field.Message().FullName() == "google.protobuf.Timestamp" {
+			schema = map[string]any{"type": "string", "format": "date-time"}
+		} else {
+			schema = schemaRef(string(field.Message().Name()))
+		}
+	default:
+		schema = map[string]any{"type": "string"}
+	}
+
+	if field.IsList() {
+		return map[string]any{"type": "array", "items": schema}
+	}
+	return schema
+}
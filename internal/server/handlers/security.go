@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/logging"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultContentSecurityPolicy - политика Content-Security-Policy по умолчанию.
+// API отдает только JSON, поэтому браузеру запрещены любые ресурсы и встраивание во фреймы.
+const DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// Параметры защиты от CSRF по схеме double-submit cookie.
+const (
+	// CSRFCookie - cookie с CSRF-токеном. Доступна скриптам веб-клиента.
+	CSRFCookie = "csrf_token"
+
+	// CSRFHeader - заголовок, в котором веб-клиент повторяет значение CSRFCookie.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// errInvalidCSRFToken - CSRF-токен изменяющего запроса отсутствует или неверен.
+var errInvalidCSRFToken = status.Error(codes.PermissionDenied, "invalid CSRF token")
+
+// SecurityHeaders добавляет к ответам заголовки, ограничивающие браузер:
+// Content-Security-Policy, X-Content-Type-Options, X-Frame-Options, Referrer-Policy,
+// а для HTTPS-запросов - Strict-Transport-Security.
+func SecurityHeaders(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*configs.ServerConfig)
+
+	csp := cfg.Server.ContentSecurityPolicy
+	if csp == "" {
+		csp = DefaultContentSecurityPolicy
+	}
+	c.Set(fiber.HeaderContentSecurityPolicy, csp)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderXFrameOptions, "DENY")
+	c.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+
+	if maxAge := cfg.Server.HSTSMaxAge; maxAge > 0 && c.Protocol() == "https" {
+		c.Set(fiber.HeaderStrictTransportSecurity,
+			"max-age="+strconv.FormatInt(int64(maxAge.Seconds()), 10)+"; includeSubDomains")
+	}
+	return c.Next()
+}
+
+// CORSMiddleware разрешает запросы браузерных клиентов с источников из
+// server.cors.allowed_origins и отвечает на предварительные запросы. Настройки
+// читаются при каждом запросе, поэтому применяются при перезагрузке конфигурации.
+func CORSMiddleware(c *fiber.Ctx) error {
+	cors := c.Locals("config").(*configs.ServerConfig).Server.CORS
+	origin := c.Get(fiber.HeaderOrigin)
+	if origin == "" || len(cors.AllowedOrigins) == 0 {
+		return c.Next()
+	}
+	c.Vary(fiber.HeaderOrigin)
+
+	preflight := c.Method() == fiber.MethodOptions && c.Get(fiber.HeaderAccessControlRequestMethod) != ""
+	wildcard := slices.Contains(cors.AllowedOrigins, "*")
+	if !wildcard && !slices.Contains(cors.AllowedOrigins, origin) {
+		// Без заголовков CORS браузер не передаст ответ скрипту чужого источника
+		if preflight {
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+		return c.Next()
+	}
+
+	if wildcard && !cors.AllowCredentials {
+		c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+	}
+	if cors.AllowCredentials {
+		c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if !preflight {
+		c.Set(fiber.HeaderAccessControlExposeHeaders, "Deprecation, Link, "+logging.RequestIDHeader)
+		return c.Next()
+	}
+
+	c.Vary(fiber.HeaderAccessControlRequestMethod, fiber.HeaderAccessControlRequestHeaders)
+	c.Set(fiber.HeaderAccessControlAllowMethods, strings.Join(cors.AllowedMethods, ", "))
+	c.Set(fiber.HeaderAccessControlAllowHeaders, strings.Join(cors.AllowedHeaders, ", "))
+	if cors.MaxAge > 0 {
+		c.Set(fiber.HeaderAccessControlMaxAge, strconv.FormatInt(int64(cors.MaxAge.Seconds()), 10))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CSRFMiddleware защищает от CSRF запросы, аутентифицированные cookie token.
+// Изменяющий запрос должен передать в заголовке X-CSRF-Token значение cookie
+// csrf_token, выданной вместе с cookie token. CSRF-токен вычисляется из токена
+// сессии и секрета сервера, поэтому его нельзя подобрать и не нужно хранить.
+//
+// Запросы с заголовком Authorization и запросы без действительной cookie token
+// не проверяются: браузер не подставляет их автоматически.
+func CSRFMiddleware(c *fiber.Ctx) error {
+	cfg := c.Locals("config").(*configs.ServerConfig)
+
+	session := c.Cookies(TokenCookie)
+	if session == "" || bearerToken(c.Get(fiber.HeaderAuthorization)) != "" {
+		return c.Next()
+	}
+	if _, err := auth.CheckIsAuthorized(cfg, session); err != nil {
+		return c.Next()
+	}
+
+	expected := csrfToken(cfg, session)
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		// Клиент, получивший сессию до появления CSRF-токена, получает его при чтении
+		if c.Cookies(CSRFCookie) != expected {
+			c.Cookie(sessionCookie(CSRFCookie, expected, false))
+		}
+		return c.Next()
+	}
+
+	header := c.Get(CSRFHeader)
+	if header == "" || header != c.Cookies(CSRFCookie) || !hmac.Equal([]byte(header), []byte(expected)) {
+		return writeError(c, errInvalidCSRFToken)
+	}
+	return c.Next()
+}
+
+// csrfToken возвращает CSRF-токен сессии session: HMAC-SHA256 токена сессии
+// на секрете JWT.
+func csrfToken(cfg *configs.ServerConfig, session string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.Security.JWTSecret))
+	mac.Write([]byte("csrf:" + session))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// sessionCookie возвращает cookie сессии name со значением value. Cookie передается
+// только по HTTPS и только в запросах с того же сайта; httpOnly скрывает ее от скриптов.
+func sessionCookie(name, value string, httpOnly bool) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  time.Now().Add(auth.TokenExp),
+		HTTPOnly: httpOnly,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	}
+}
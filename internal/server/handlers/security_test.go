@@ -0,0 +1,176 @@
+package internal_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sol1corejz/goph-keeper/configs"
+	"github.com/sol1corejz/goph-keeper/internal/server/auth"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// securityApp возвращает приложение с middleware безопасности и REST API.
+func securityApp(cfg *configs.ServerConfig) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("config", cfg)
+		return c.Next()
+	})
+	app.Use(handlers.SecurityHeaders)
+	app.Use(handlers.CORSMiddleware)
+	app.Use(handlers.CSRFMiddleware)
+	handlers.RegisterAPI(app)
+	return app
+}
+
+// responseCookie возвращает cookie name из ответа resp.
+func responseCookie(resp *http.Response, name string) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestSessionCookiesAndCSRF(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	previous := storage.DBStorage
+	storage.DBStorage = storage.StorageImpl{DB: mockDB}
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	app := securityApp(cfg)
+
+	// Вход выдает cookie сессии и CSRF-токен
+	userID := uuid.New().String()
+	hash, err := handlers.HashPassword("password")
+	require.NoError(t, err)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM users WHERE username=$1")).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "username", "password_hash"}).AddRow(userID, "alice", hash))
+
+	req := httptest.NewRequest(fiber.MethodPost, handlers.APIPrefix+"/sessions",
+		strings.NewReader(`{"userData":{"username":"alice","password":"password"}}`))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	session := responseCookie(resp, handlers.TokenCookie)
+	require.NotNil(t, session)
+	assert.True(t, session.HttpOnly)
+	assert.True(t, session.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, session.SameSite)
+
+	csrf := responseCookie(resp, handlers.CSRFCookie)
+	require.NotNil(t, csrf)
+	assert.False(t, csrf.HttpOnly)
+	assert.True(t, csrf.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, csrf.SameSite)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	cookies := handlers.TokenCookie + "=" + session.Value + "; " + handlers.CSRFCookie + "=" + csrf.Value
+	createFolder := func(header map[string]string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodPost, handlers.APIPrefix+"/folders", strings.NewReader(`{"path":""}`))
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Изменяющий запрос с cookie без CSRF-токена отклоняется
+	resp = createFolder(map[string]string{"Cookie": cookies})
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp = createFolder(map[string]string{"Cookie": cookies, handlers.CSRFHeader: "forged"})
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	// С заголовком X-CSRF-Token запрос доходит до обработчика и отклоняется им из-за пустого пути
+	resp = createFolder(map[string]string{"Cookie": cookies, handlers.CSRFHeader: csrf.Value})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	// Запросы с заголовком Authorization не проверяются: браузер не подставляет его сам
+	token, err := auth.GenerateToken(cfg, userID)
+	require.NoError(t, err)
+	resp = createFolder(map[string]string{"Authorization": "Bearer " + token, "Cookie": cookies})
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCSRFCookieReissuedOnRead(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Security.JWTSecret = "test-secret"
+	token, err := auth.GenerateToken(cfg, uuid.New().String())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(fiber.MethodGet, handlers.APIPrefix+"/openapi.json", nil)
+	req.Header.Set("Cookie", handlers.TokenCookie+"="+token)
+	resp, err := securityApp(cfg).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NotNil(t, responseCookie(resp, handlers.CSRFCookie))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Server.HSTSMaxAge = 24 * time.Hour
+
+	resp, err := securityApp(cfg).Test(httptest.NewRequest(fiber.MethodGet, handlers.APIPrefix+"/openapi.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", resp.Header.Get(fiber.HeaderXFrameOptions))
+	assert.Equal(t, handlers.DefaultContentSecurityPolicy, resp.Header.Get(fiber.HeaderContentSecurityPolicy))
+	// HSTS отправляется только по HTTPS
+	assert.Empty(t, resp.Header.Get(fiber.HeaderStrictTransportSecurity))
+
+	cfg.Server.ContentSecurityPolicy = "default-src 'self'"
+	resp, err = securityApp(cfg).Test(httptest.NewRequest(fiber.MethodGet, handlers.APIPrefix+"/openapi.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "default-src 'self'", resp.Header.Get(fiber.HeaderContentSecurityPolicy))
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := &configs.ServerConfig{}
+	cfg.Server.CORS.AllowedOrigins = []string{"https://vault.example.com"}
+	cfg.Server.CORS.AllowedMethods = []string{fiber.MethodGet, fiber.MethodPost}
+	cfg.Server.CORS.AllowedHeaders = []string{"Content-Type", handlers.CSRFHeader}
+	cfg.Server.CORS.AllowCredentials = true
+	cfg.Server.CORS.MaxAge = 10 * time.Minute
+	app := securityApp(cfg)
+
+	preflight := func(origin string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodOptions, handlers.APIPrefix+"/folders", nil)
+		req.Header.Set(fiber.HeaderOrigin, origin)
+		req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodPost)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := preflight("https://vault.example.com")
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://vault.example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "GET, POST", resp.Header.Get(fiber.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "Content-Type, X-CSRF-Token", resp.Header.Get(fiber.HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "600", resp.Header.Get(fiber.HeaderAccessControlMaxAge))
+
+	resp = preflight("https://evil.example.com")
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+}
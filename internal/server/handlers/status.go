@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/metrics"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ошибки авторизации, общие для gRPC и HTTP.
+var (
+	// errUnauthenticated - токен аутентификации не передан.
+	errUnauthenticated = status.Error(codes.Unauthenticated, "unauthorized")
+
+	// errInvalidToken - токен аутентификации недействителен или истек.
+	errInvalidToken = status.Error(codes.Unauthenticated, "invalid token")
+
+	// errWrongCredentials - неверный логин или пароль. Не сообщает,
+	// существует ли пользователь.
+	errWrongCredentials = status.Error(codes.Unauthenticated, "wrong login or password")
+
+	// errForbidden - роли пользователя недостаточно для действия.
+	errForbidden = status.Error(codes.PermissionDenied, "forbidden")
+)
+
+// invalidArgument возвращает ошибку некорректных входных данных.
+func invalidArgument(msg string) error {
+	return status.Error(codes.InvalidArgument, msg)
+}
+
+// toStatus преобразует ошибку обработчика в ошибку gRPC с кодом, соответствующим
+// ее причине. Ошибки, уже содержащие код, возвращаются без изменений, а ошибки
+// неизвестного происхождения получают код Internal.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrUserNotFound):
+		code = codes.NotFound
+	case errors.Is(err, storage.ErrAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, storage.ErrInvalidPageToken),
+		errors.Is(err, storage.ErrInvalidSort),
+		errors.Is(err, storage.ErrInvalidSearchTerm):
+		code = codes.InvalidArgument
+	case errors.Is(err, storage.ErrFolderCycle),
+		errors.Is(err, storage.ErrShareToSelf),
+		errors.Is(err, storage.ErrLastOwner),
+		errors.Is(err, storage.ErrNoPublicKey):
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, err.Error())
+}
+
+// HTTPStatus возвращает HTTP-статус, соответствующий коду gRPC.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// StatusUnaryInterceptor преобразует ошибки обработчиков в ошибки gRPC с кодами,
+// по которым клиенты и HTTP-шлюз различают причины отказа.
+func (s *KeeperServer) StatusUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, toStatus(err)
+}
+
+// StatusStreamInterceptor преобразует ошибки потоковых обработчиков в ошибки gRPC с кодами.
+func (s *KeeperServer) StatusStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return toStatus(handler(srv, ss))
+}
+
+// transportKey - ключ контекста, в котором хранится транспорт вызова.
+type transportKey struct{}
+
+// withTransport сохраняет в контексте транспорт, по которому пришел вызов.
+func withTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey{}, transport)
+}
+
+// transportFrom возвращает транспорт вызова. По умолчанию - gRPC.
+func transportFrom(ctx context.Context) string {
+	if transport, ok := ctx.Value(transportKey{}).(string); ok {
+		return transport
+	}
+	return metrics.TransportGRPC
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TokenCookie - имя cookie, в которой HTTP-клиенты передают токен аутентификации.
+const TokenCookie = "token"
+
+// bearerScheme - схема авторизации в заголовке Authorization.
+const bearerScheme = "bearer"
+
+// bearerToken извлекает токен из значения заголовка Authorization вида "Bearer <токен>".
+// Для других схем возвращается пустая строка.
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, bearerScheme) {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// requestToken возвращает токен аутентификации HTTP-запроса из заголовка
+// Authorization, а если его нет - из cookie token.
+func requestToken(c *fiber.Ctx) string {
+	if token := bearerToken(c.Get(fiber.HeaderAuthorization)); token != "" {
+		return token
+	}
+	return c.Cookies(TokenCookie)
+}
+
+// metadataToken возвращает токен из метаданных authorization gRPC-вызова.
+func metadataToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token := bearerToken(value); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// setToken записывает token в поле token сообщения, если такое поле есть и не заполнено.
+// Токен, переданный в теле запроса, имеет приоритет.
+func setToken(msg any, token string) {
+	m, ok := msg.(proto.Message)
+	if !ok || token == "" {
+		return
+	}
+	r := m.ProtoReflect()
+	field := r.Descriptor().Fields().ByName("token")
+	if field == nil || field.Kind() != protoreflect.StringKind || r.Get(field).String() != "" {
+		return
+	}
+	r.Set(field, protoreflect.ValueOfString(token))
+}
+
+// TokenUnaryInterceptor подставляет в запрос токен из метаданных authorization,
+// если клиент не передал его в теле запроса.
+func (s *KeeperServer) TokenUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	setToken(req, metadataToken(ctx))
+	return handler(ctx, req)
+}
+
+// TokenStreamInterceptor подставляет токен из метаданных authorization
+// в сообщения потокового вызова.
+func (s *KeeperServer) TokenStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &tokenStream{ServerStream: ss, token: metadataToken(ss.Context())})
+}
+
+// tokenStream подставляет токен в каждое полученное от клиента сообщение.
+type tokenStream struct {
+	grpc.ServerStream
+	token string
+}
+
+// RecvMsg получает сообщение клиента и подставляет в него токен.
+func (s *tokenStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	setToken(m, s.token)
+	return nil
+}
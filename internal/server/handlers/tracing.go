@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName - имя трассировщика участков HTTP-запросов.
+const tracerName = "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+
+// TracingMiddleware начинает участок трассировки для HTTP-запроса и передает его
+// обработчикам через c.UserContext(). Родительский контекст берется из заголовка
+// traceparent, поэтому запрос клиента и его обработка на сервере попадают в одну трассу.
+func TracingMiddleware(c *fiber.Ctx) error {
+	self := c.Route()
+	parent := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+
+	ctx, span := otel.Tracer(tracerName).Start(parent, c.Method(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+		),
+	)
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	// Имя участка содержит шаблон маршрута, а не путь, как и метка route в метриках
+	route := c.Route().Path
+	if c.Route() == self {
+		route = unmatchedRoute
+	}
+	status := responseStatus(c, err)
+	span.SetName(c.Method() + " " + route)
+	span.SetAttributes(
+		semconv.HTTPRoute(route),
+		semconv.HTTPResponseStatusCode(status),
+	)
+	if err != nil {
+		span.RecordError(err)
+	}
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+	}
+
+	return err
+}
+
+// headerCarrier позволяет распространителю OpenTelemetry читать и записывать
+// заголовки запроса fasthttp.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+// Get возвращает значение заголовка key.
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+// Set устанавливает значение заголовка key.
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+// Keys возвращает имена всех заголовков запроса.
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, h.header.Len())
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
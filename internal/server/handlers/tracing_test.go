@@ -0,0 +1,89 @@
+package internal_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	handlers "github.com/sol1corejz/goph-keeper/internal/server/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans устанавливает глобальный провайдер трассировки, записывающий участки
+// в память, и останавливает его по завершении теста.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(prevPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+func TestTracingMiddlewareContinuesRemoteTrace(t *testing.T) {
+	exporter := recordSpans(t)
+
+	var handlerTraceID trace.TraceID
+	app := fiber.New()
+	app.Use(handlers.TracingMiddleware)
+	app.Get("/folders/:id", func(c *fiber.Ctx) error {
+		handlerTraceID = trace.SpanContextFromContext(c.UserContext()).TraceID()
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/folders/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /folders/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, span.SpanContext.TraceID(), handlerTraceID)
+	assert.Contains(t, span.Attributes, semconv.HTTPRoute("/folders/:id"))
+	assert.Contains(t, span.Attributes, semconv.HTTPResponseStatusCode(fiber.StatusNoContent))
+	assert.Equal(t, codes.Unset, span.Status.Code)
+}
+
+func TestTracingMiddlewareMarksServerErrors(t *testing.T) {
+	exporter := recordSpans(t)
+
+	app := fiber.New()
+	app.Use(handlers.TracingMiddleware)
+	app.Get("/credentials", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"/credentials", "/no-such-route"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GET /credentials", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "GET unmatched", spans[1].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	assert.False(t, spans[1].Parent.IsValid())
+}
@@ -0,0 +1,124 @@
+// Package health проверяет готовность сервера goph-keeper обслуживать запросы:
+// доступность базы данных и срок действия TLS-сертификата.
+//
+// Результат проверки отдается HTTP-обработчиком /readyz и сервисом grpc.health.v1,
+// статус которого Watch периодически обновляет.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/cert"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Названия проверок в отчете.
+const (
+	CheckDatabase    = "database"
+	CheckCertificate = "certificate"
+)
+
+// Состояния сервера и отдельных проверок в отчете.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+const (
+	// DefaultInterval - период обновления статуса сервиса grpc.health.v1.
+	DefaultInterval = 10 * time.Second
+
+	// DefaultTimeout - время, за которое должна завершиться каждая проверка.
+	DefaultTimeout = 2 * time.Second
+)
+
+// Pinger проверяет соединение с базой данных. Ему соответствует *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Report - результат проверки готовности сервера.
+type Report struct {
+	// Status - ok, если все проверки пройдены, иначе fail.
+	Status string `json:"status"`
+
+	// Checks - состояние каждой проверки по ее названию.
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready сообщает, пройдены ли все проверки.
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Checker выполняет проверки готовности сервера.
+type Checker struct {
+	db       Pinger
+	certFile string
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+// NewChecker создает проверку готовности для базы данных db и сертификата в файле certFile.
+// Пустой certFile отключает проверку сертификата.
+func NewChecker(db Pinger, certFile string) *Checker {
+	return &Checker{
+		db:       db,
+		certFile: certFile,
+		timeout:  DefaultTimeout,
+		now:      time.Now,
+	}
+}
+
+// Check выполняет все проверки. Причины неудачных проверок записываются в лог,
+// но не попадают в отчет, который отдается без авторизации.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]string)}
+
+	record := func(name string, err error) {
+		if err != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+			report.Checks[name] = StatusFail
+			report.Status = StatusFail
+			return
+		}
+		report.Checks[name] = StatusOK
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	record(CheckDatabase, c.db.PingContext(pingCtx))
+
+	if c.certFile != "" {
+		record(CheckCertificate, cert.CheckCert(c.certFile, c.now()))
+	}
+
+	return report
+}
+
+// Watch выполняет проверки каждые interval и устанавливает по их результату статус
+// сервисов services в srv. Пустое имя сервиса обозначает сервер в целом.
+// Watch завершается при отмене ctx.
+func (c *Checker) Watch(ctx context.Context, srv *health.Server, interval time.Duration, services ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if c.Check(ctx).Ready() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		for _, service := range services {
+			srv.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
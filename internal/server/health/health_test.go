@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sol1corejz/goph-keeper/internal/server/cert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newPingMock(t *testing.T) (Pinger, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func writeCert(t *testing.T) string {
+	t.Helper()
+	certPEM, _ := cert.GenerateCert()
+	path := filepath.Join(t.TempDir(), "server.crt")
+	require.NoError(t, os.WriteFile(path, certPEM, 0600))
+	return path
+}
+
+func TestCheckReady(t *testing.T) {
+	db, mock := newPingMock(t)
+	mock.ExpectPing()
+
+	report := NewChecker(db, writeCert(t)).Check(context.Background())
+
+	assert.True(t, report.Ready())
+	assert.Equal(t, map[string]string{CheckDatabase: StatusOK, CheckCertificate: StatusOK}, report.Checks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckDatabaseUnavailable(t *testing.T) {
+	db, mock := newPingMock(t)
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	report := NewChecker(db, "").Check(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, map[string]string{CheckDatabase: StatusFail}, report.Checks)
+}
+
+func TestCheckCertificate(t *testing.T) {
+	db, mock := newPingMock(t)
+	path := writeCert(t)
+
+	checker := NewChecker(db, path)
+	checker.now = func() time.Time { return time.Now().AddDate(11, 0, 0) }
+	mock.ExpectPing()
+	report := checker.Check(context.Background())
+	assert.False(t, report.Ready())
+	assert.Equal(t, StatusFail, report.Checks[CheckCertificate])
+
+	checker = NewChecker(db, filepath.Join(t.TempDir(), "missing.crt"))
+	mock.ExpectPing()
+	report = checker.Check(context.Background())
+	assert.Equal(t, StatusFail, report.Checks[CheckCertificate])
+	assert.Equal(t, StatusOK, report.Checks[CheckDatabase])
+}
+
+func TestWatchUpdatesServingStatus(t *testing.T) {
+	db, mock := newPingMock(t)
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	srv := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	NewChecker(db, "").Watch(ctx, srv, time.Hour, "", "Keeper")
+
+	for _, service := range []string{"", "Keeper"} {
+		resp, err := srv.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+	}
+}
@@ -0,0 +1,123 @@
+// Package metrics содержит метрики Prometheus сервера goph-keeper: количество и
+// длительность gRPC-вызовов и HTTP-запросов, состояние пула соединений с базой данных,
+// неудачные попытки входа и количество активных сессий.
+//
+// Метрики регистрируются в собственном реестре Registry и отдаются обработчиком Handler,
+// который сервер запускает на отдельном адресе, недоступном вместе с публичным API.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - префикс имен всех метрик сервера.
+const namespace = "gophkeeper"
+
+// Registry - реестр метрик сервера.
+var Registry = prometheus.NewRegistry()
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Total number of gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of gRPC calls by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Total number of failed login attempts by transport and reason.",
+	}, []string{"transport", "reason"})
+)
+
+// Причины неудачного входа для LoginFailed.
+const (
+	ReasonUnknownUser   = "unknown_user"
+	ReasonWrongPassword = "wrong_password"
+)
+
+// Транспорты, по которым принимаются запросы.
+const (
+	TransportGRPC = "grpc"
+	TransportHTTP = "http"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests, rpcDuration,
+		httpRequests, httpDuration,
+		loginFailures,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "active_sessions",
+			Help:      "Number of users with an authenticated request within the session window.",
+		}, func() float64 { return float64(Sessions.Active()) }),
+	)
+}
+
+// ObserveRPC учитывает завершенный gRPC-вызов.
+func ObserveRPC(method, code string, duration time.Duration) {
+	rpcRequests.WithLabelValues(method, code).Inc()
+	rpcDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// ObserveHTTP учитывает завершенный HTTP-запрос. route - шаблон маршрута,
+// а не путь запроса, чтобы количество рядов метрики не зависело от запросов.
+func ObserveHTTP(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// LoginFailed учитывает неудачную попытку входа.
+func LoginFailed(transport, reason string) {
+	loginFailures.WithLabelValues(transport, reason).Inc()
+}
+
+// RegisterDB добавляет в реестр статистику пула соединений db.
+// Повторная регистрация того же пула игнорируется.
+func RegisterDB(db *sql.DB) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return nil
+	}
+	return err
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
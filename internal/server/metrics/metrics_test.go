@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTrackerExpiresIdleUsers(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewSessionTracker(15 * time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Touch("alice")
+	tracker.Touch("bob")
+	tracker.Touch("")
+	assert.Equal(t, 2, tracker.Active())
+
+	now = now.Add(10 * time.Minute)
+	tracker.Touch("alice")
+	now = now.Add(10 * time.Minute)
+	assert.Equal(t, 1, tracker.Active())
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, 0, tracker.Active())
+}
+
+func TestObserveRPC(t *testing.T) {
+	before := testutil.ToFloat64(rpcRequests.WithLabelValues("/Keeper/Login", "OK"))
+
+	ObserveRPC("/Keeper/Login", "OK", 20*time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(rpcRequests.WithLabelValues("/Keeper/Login", "OK")))
+}
+
+func TestLoginFailed(t *testing.T) {
+	before := testutil.ToFloat64(loginFailures.WithLabelValues(TransportHTTP, ReasonWrongPassword))
+
+	LoginFailed(TransportHTTP, ReasonWrongPassword)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(loginFailures.WithLabelValues(TransportHTTP, ReasonWrongPassword)))
+}
+
+func TestRegisterDBIsIdempotent(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.NoError(t, RegisterDB(db))
+	require.NoError(t, RegisterDB(db))
+}
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	ObserveHTTP("GET", "/credentials", "200", time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `gophkeeper_http_requests_total{method="GET",route="/credentials",status="200"}`)
+	assert.Contains(t, string(body), "gophkeeper_active_sessions")
+	assert.Contains(t, string(body), "go_goroutines")
+}
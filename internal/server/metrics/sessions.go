@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionWindow - время после последнего запроса пользователя, в течение которого
+// его сессия считается активной. Токены не хранятся на сервере, поэтому сессия
+// определяется по активности, а не по сроку действия токена.
+const SessionWindow = 15 * time.Minute
+
+// Sessions - учет активных сессий сервера.
+var Sessions = NewSessionTracker(SessionWindow)
+
+// SessionTracker учитывает пользователей, выполнявших запросы в пределах окна активности.
+type SessionTracker struct {
+	mu       sync.Mutex
+	window   time.Duration
+	lastSeen map[string]time.Time
+	now      func() time.Time
+}
+
+// NewSessionTracker создает учет сессий с окном активности window.
+func NewSessionTracker(window time.Duration) *SessionTracker {
+	return &SessionTracker{
+		window:   window,
+		lastSeen: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Touch отмечает запрос пользователя userID.
+func (t *SessionTracker) Touch(userID string) {
+	if userID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSeen[userID] = t.now()
+}
+
+// Active возвращает количество активных сессий и забывает пользователей,
+// не выполнявших запросов дольше окна активности.
+func (t *SessionTracker) Active() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	threshold := t.now().Add(-t.window)
+	for userID, seen := range t.lastSeen {
+		if seen.Before(threshold) {
+			delete(t.lastSeen, userID)
+		}
+	}
+	return len(t.lastSeen)
+}
@@ -4,18 +4,29 @@
 // отвечающая за взаимодействие с базой данных и обработку аутентификации пользователей.
 package internal
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
 // AuthPayload представляет данные для аутентификации пользователя.
 // Используется при отправке запроса на вход в систему.
 type AuthPayload struct {
-	Username string `json:"username"` // Имя пользователя
-	Password string `json:"password"` // Пароль
+	Username  string `json:"username"`             // Имя пользователя
+	Password  string `json:"password"`             // Пароль
+	PublicKey []byte `json:"public_key,omitempty"` // Открытый ключ X25519, необязателен и учитывается только при регистрации
 }
 
 // CredentialPayload содержит учетные данные пользователя.
 // Используется для передачи логина и пароля с дополнительными метаданными.
 type CredentialPayload struct {
-	Data string `json:"data"` // Основная информация (например, логин и пароль)
-	Meta string `json:"meta"` // Дополнительные метаданные (например, описание, время создания)
+	Type     string   `json:"type"`      // Тип данных (см. CredentialType)
+	Data     string   `json:"data"`      // Основная информация (например, логин и пароль)
+	Meta     string   `json:"meta"`      // Дополнительные метаданные (например, описание, время создания)
+	Tags     []string `json:"tags"`      // Теги для фильтрации записей
+	FolderID string   `json:"folder_id"` // Папка, в которую помещается запись
+	Favorite bool     `json:"favorite"`  // Отметить запись как избранную
 }
 
 // EditCredentialPayload содержит учетные данные пользователя.
@@ -26,12 +37,75 @@ type EditCredentialPayload struct {
 	Meta string `json:"meta"` // Дополнительные метаданные (например, описание, время создания)
 }
 
+// CredentialType - тип хранимых данных.
+type CredentialType string
+
+// Поддерживаемые типы хранимых данных.
+const (
+	CredentialTypeLogin  CredentialType = "login"  // Пара логин/пароль
+	CredentialTypeText   CredentialType = "text"   // Произвольные текстовые данные
+	CredentialTypeBinary CredentialType = "binary" // Произвольные бинарные данные
+	CredentialTypeCard   CredentialType = "card"   // Данные банковской карты
+	CredentialTypeOTP    CredentialType = "otp"    // Ключ одноразовых паролей (URI otpauth://)
+)
+
+// DefaultCredentialType - тип, назначаемый записи, если он не указан явно.
+const DefaultCredentialType = CredentialTypeText
+
+// Valid проверяет, что тип входит в список поддерживаемых.
+func (t CredentialType) Valid() bool {
+	switch t {
+	case CredentialTypeLogin, CredentialTypeText, CredentialTypeBinary, CredentialTypeCard, CredentialTypeOTP:
+		return true
+	}
+	return false
+}
+
 // Credential представляет учетную запись пользователя, сохраненную в системе.
 type Credential struct {
-	ID     string `json:"id"`      // Уникальный идентификатор учетной записи
-	UserID string `json:"user_id"` // Идентификатор владельца учетной записи
-	Data   string `json:"data"`    // Основная информация (логин/пароль)
-	Meta   string `json:"meta"`    // Дополнительные метаданные
+	ID        string         `json:"id"`         // Уникальный идентификатор учетной записи
+	UserID    string         `json:"user_id"`    // Идентификатор владельца учетной записи
+	Type      CredentialType `json:"type"`       // Тип данных
+	Data      string         `json:"data"`       // Основная информация (логин/пароль)
+	Meta      string         `json:"meta"`       // Дополнительные метаданные
+	Tags      []string       `json:"tags"`       // Теги записи
+	FolderID  string         `json:"folder_id"`  // Папка записи, пустая строка — корень
+	Favorite  bool           `json:"favorite"`   // Запись отмечена как избранная
+	CreatedAt time.Time      `json:"created_at"` // Время создания записи
+	UpdatedAt time.Time      `json:"updated_at"` // Время последнего изменения записи
+
+	// SearchTerms - термины слепого индекса, вычисленные клиентом. Не возвращаются клиенту.
+	// nil при редактировании означает, что индекс записи не меняется.
+	SearchTerms []string `json:"-"`
+
+	// FolderPath - путь папки, в которую помещается новая запись, если FolderID пуст.
+	// Недостающие папки пути создаются при сохранении записи.
+	FolderPath string `json:"-"`
+}
+
+// Поля, по которым можно сортировать список учетных данных.
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// ListOptions задает параметры постраничного получения учетных данных.
+type ListOptions struct {
+	PageSize  int            // Максимальное количество записей на странице
+	PageToken string         // Курсор, полученный вместе с предыдущей страницей
+	ID        string         // Фильтр по идентификатору записи
+	Type      CredentialType // Фильтр по типу записи
+	Tag       string         // Фильтр по тегу
+	FolderID  string         // Фильтр по папке (только записи непосредственно в ней)
+	Favorites bool           // Только избранные записи
+	SortBy    string         // Поле сортировки (SortByCreatedAt или SortByUpdatedAt)
+	Desc      bool           // Сортировка по убыванию
+}
+
+// CredentialsPage - страница учетных данных и курсор для получения следующей.
+type CredentialsPage struct {
+	Credentials   []Credential `json:"credentials"`
+	NextPageToken string       `json:"next_page_token"` // Пустая строка, если страница последняя
 }
 
 // User представляет зарегистрированного пользователя в системе.
@@ -40,3 +114,267 @@ type User struct {
 	Username string `json:"username"` // Имя пользователя
 	Password string `json:"password"` // Хешированный пароль пользователя
 }
+
+// NormalizeTags убирает пробелы по краям тегов, пустые значения и дубликаты,
+// сохраняя исходный порядок.
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// FolderPathSeparator - разделитель имен папок в пути.
+const FolderPathSeparator = "/"
+
+// MaxFolderNameLen - максимальная длина имени папки.
+const MaxFolderNameLen = 255
+
+// Folder представляет папку для группировки учетных данных.
+// Папки образуют дерево: у корневых папок ParentID пустой.
+type Folder struct {
+	ID       string `json:"id"`        // Уникальный идентификатор папки
+	UserID   string `json:"user_id"`   // Идентификатор владельца папки
+	ParentID string `json:"parent_id"` // Родительская папка, пустая строка — корень
+	Name     string `json:"name"`      // Имя папки
+	Path     string `json:"path"`      // Полный путь от корня, например "work/databases"
+}
+
+// ValidFolderName проверяет, что имя папки непустое, не содержит разделителя пути
+// и не превышает MaxFolderNameLen.
+func ValidFolderName(name string) bool {
+	return name != "" &&
+		name == strings.TrimSpace(name) &&
+		!strings.Contains(name, FolderPathSeparator) &&
+		len(name) <= MaxFolderNameLen
+}
+
+// SplitFolderPath разбивает путь к папке на имена, пропуская пустые части.
+func SplitFolderPath(path string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(path, FolderPathSeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// SharePermission - уровень доступа получателя к чужой записи.
+type SharePermission string
+
+// Уровни доступа к общей записи.
+const (
+	SharePermissionRead  SharePermission = "read"  // Только чтение
+	SharePermissionWrite SharePermission = "write" // Чтение и изменение данных
+)
+
+// Valid проверяет, что уровень доступа входит в список поддерживаемых.
+func (p SharePermission) Valid() bool {
+	switch p {
+	case SharePermissionRead, SharePermissionWrite:
+		return true
+	}
+	return false
+}
+
+// CredentialShare описывает выданный другому пользователю доступ к записи.
+type CredentialShare struct {
+	CredentialID string          `json:"credential_id"` // Идентификатор записи
+	GranteeID    string          `json:"grantee_id"`    // Идентификатор получателя
+	GranteeName  string          `json:"grantee"`       // Имя пользователя получателя
+	Permission   SharePermission `json:"permission"`    // Уровень доступа
+	// Ключ записи, зашифрованный клиентом на открытом ключе получателя.
+	// Сервер хранит его как есть и не может расшифровать.
+	EncryptedKey []byte    `json:"encrypted_key,omitempty"`
+	CreatedAt    time.Time `json:"created_at"` // Время выдачи доступа
+}
+
+// PublicKeySize - размер открытого ключа X25519, на котором клиенты шифруют
+// ключи записей для получателя доступа.
+const PublicKeySize = 32
+
+// MaxEncryptedKeyLen - максимальный размер зашифрованного ключа записи.
+const MaxEncryptedKeyLen = 1024
+
+// SealedDataPrefix - префикс данных записи, зашифрованных клиентом ключом записи
+// перед выдачей доступа. Сервер не может их расшифровать.
+const SealedDataPrefix = "sealed:"
+
+// CredentialRekey - данные записи, зашифрованные клиентом владельца новым ключом
+// записи при отзыве доступа, и новый ключ, зашифрованный для оставшихся получателей.
+type CredentialRekey struct {
+	Data          string            // Данные записи
+	EncryptedKeys map[string][]byte // Ключи записи по имени пользователя получателя
+}
+
+// SharedCredential - запись другого пользователя, к которой выдан доступ.
+type SharedCredential struct {
+	Credential
+	OwnerName    string          `json:"owner"`                   // Имя пользователя владельца
+	Permission   SharePermission `json:"permission"`              // Уровень доступа
+	EncryptedKey []byte          `json:"encrypted_key,omitempty"` // Ключ записи для получателя
+}
+
+// OrgRole - роль участника организации.
+type OrgRole string
+
+// Роли участников организации в порядке убывания прав.
+const (
+	OrgRoleOwner    OrgRole = "owner"     // Владелец: полный доступ, управление владельцами
+	OrgRoleAdmin    OrgRole = "admin"     // Администратор: управление участниками, командами и коллекциями
+	OrgRoleMember   OrgRole = "member"    // Участник: чтение и изменение записей доступных коллекций
+	OrgRoleReadOnly OrgRole = "read-only" // Только чтение записей доступных коллекций
+)
+
+// Valid проверяет, что роль входит в список поддерживаемых.
+func (r OrgRole) Valid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember, OrgRoleReadOnly:
+		return true
+	}
+	return false
+}
+
+// Organization - организация, которой принадлежат коллекции записей.
+type Organization struct {
+	ID        string    `json:"id"`         // Уникальный идентификатор организации
+	Name      string    `json:"name"`       // Уникальное имя организации
+	Role      OrgRole   `json:"role"`       // Роль текущего пользователя в организации
+	CreatedAt time.Time `json:"created_at"` // Время создания
+}
+
+// OrgMember - участник организации.
+type OrgMember struct {
+	UserID   string  `json:"user_id"`  // Идентификатор пользователя
+	Username string  `json:"username"` // Имя пользователя
+	Role     OrgRole `json:"role"`     // Роль в организации
+}
+
+// OrgInvite - приглашение пользователя в организацию.
+type OrgInvite struct {
+	OrgID     string    `json:"org_id"`     // Идентификатор организации
+	OrgName   string    `json:"org_name"`   // Имя организации
+	Role      OrgRole   `json:"role"`       // Роль, назначаемая после принятия приглашения
+	CreatedAt time.Time `json:"created_at"` // Время приглашения
+}
+
+// Team - команда участников организации.
+type Team struct {
+	ID    string `json:"id"`     // Уникальный идентификатор команды
+	OrgID string `json:"org_id"` // Организация команды
+	Name  string `json:"name"`   // Имя команды, уникальное в организации
+}
+
+// Collection - коллекция записей организации. Если коллекция назначена командам,
+// её записи видят только участники этих команд, владельцы и администраторы.
+type Collection struct {
+	ID    string   `json:"id"`     // Уникальный идентификатор коллекции
+	OrgID string   `json:"org_id"` // Организация коллекции
+	Name  string   `json:"name"`   // Имя коллекции, уникальное в организации
+	Teams []string `json:"teams"`  // Имена команд с доступом; пустой список — все участники
+
+	// TeamAccess - коллекция доступна пользователю через команды: у неё нет команд
+	// или пользователь состоит в одной из них. Роль учитывает пакет authz.
+	TeamAccess bool `json:"-"`
+}
+
+// OrgCredential - запись из коллекции организации.
+type OrgCredential struct {
+	Credential
+	CollectionID     string `json:"collection_id"`     // Коллекция записи
+	RotationRequired bool   `json:"rotation_required"` // Запись нужно сменить: доступ к ней имел удаленный участник
+	TeamAccess       bool   `json:"-"`                 // Коллекция записи доступна пользователю через команды
+}
+
+// CredentialAccess - отношение пользователя к записи. Хранилище только собирает
+// эти сведения, решение о доступе принимает пакет authz.
+type CredentialAccess struct {
+	Owner      bool            // Пользователь - владелец личной записи
+	OrgID      string          // Организация - владелец записи, пустая строка для личных записей
+	Share      SharePermission // Выданный пользователю доступ к записи, пустой, если его нет
+	Role       OrgRole         // Роль пользователя в организации записи, пустая, если он в ней не состоит
+	TeamAccess bool            // Коллекция записи доступна пользователю через команды
+}
+
+// AuditEntry - запись журнала аудита. Каждая запись содержит хэш предыдущей,
+// поэтому удаление или изменение записей обнаруживается проверкой цепочки.
+type AuditEntry struct {
+	ID           int64     `json:"id"`            // Порядковый номер записи
+	CreatedAt    time.Time `json:"created_at"`    // Время события
+	ActorID      string    `json:"actor_id"`      // Пользователь, выполнивший действие
+	OrgID        string    `json:"org_id"`        // Организация, в которой выполнено действие
+	Action       string    `json:"action"`        // Действие, например "EditCredentials" или "POST /credentials"
+	CredentialID string    `json:"credential_id"` // Запись, к которой относится действие
+	ClientIP     string    `json:"client_ip"`     // IP-адрес клиента
+	UserAgent    string    `json:"user_agent"`    // Клиентское приложение
+	Result       string    `json:"result"`        // "ok" или текст ошибки
+	PrevHash     string    `json:"prev_hash"`     // Хэш предыдущей записи
+	Hash         string    `json:"hash"`          // Хэш этой записи
+}
+
+// AuditFilter задает выборку из журнала аудита. Непустой OrgID выбирает события
+// организации, иначе - события пользователя ActorID.
+type AuditFilter struct {
+	ActorID  string // События пользователя
+	OrgID    string // События организации
+	BeforeID int64  // Только записи с меньшим номером; 0 - с последней записи
+	Limit    int    // Максимальное количество записей
+}
+
+// BackupTable - строки таблицы хранилища в резервной копии. Каждая строка - JSON-объект
+// со всеми столбцами таблицы в том виде, в котором их возвращает row_to_json PostgreSQL:
+// UUID и метки времени - строки, BYTEA - строка вида "\\x<hex>". Формат привязан
+// к схеме базы данных сервера; таблицы и их ключи описывает BackupTableSchema.
+type BackupTable struct {
+	Name string            `json:"name"` // Имя таблицы
+	Rows []json.RawMessage `json:"rows"` // Строки таблицы
+}
+
+// BackupTableSchema - описание таблицы резервной копии, по которому копия проверяется
+// без базы данных: столбцы и их типы, ключи, внешние ключи и ограничения CHECK.
+type BackupTableSchema struct {
+	Name       string            // Имя таблицы
+	Columns    []BackupColumn    // Все столбцы таблицы
+	PrimaryKey []string          // Столбцы первичного ключа
+	Unique     [][]string        // Наборы столбцов с уникальными значениями; строки с NULL не сравниваются
+	UniqueNull [][]string        // Уникальные индексы, в которых NULL равны между собой
+	References []BackupReference // Внешние ключи
+	ExactlyOne []string          // Столбцы, из которых ровно один не NULL
+}
+
+// Типы столбцов резервной копии и их представление в row_to_json.
+const (
+	BackupUUID      = "uuid"        // Строка с UUID
+	BackupText      = "text"        // Строка
+	BackupBigint    = "bigint"      // Целое число
+	BackupTimestamp = "timestamptz" // Строка с меткой времени RFC 3339
+	BackupBytea     = "bytea"       // Строка вида "\\x<hex>"
+)
+
+// BackupColumn - столбец таблицы резервной копии.
+type BackupColumn struct {
+	Name        string   // Имя столбца
+	Type        string   // Тип столбца, одна из констант Backup*
+	Nullable    bool     // Допускается NULL
+	Values      []string // Допустимые значения; пусто - любые
+	NonNegative bool     // Число не меньше нуля
+}
+
+// BackupReference - внешний ключ: значение столбца Column, если оно не NULL,
+// должно встречаться в столбце RefColumn таблицы Table.
+type BackupReference struct {
+	Column    string // Столбец таблицы
+	Table     string // Таблица, на которую ссылается столбец
+	RefColumn string // Столбец таблицы Table
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// teamAccess возвращает SQL-условие доступа пользователя с плейсхолдером userArg
+// к коллекции с псевдонимом col через команды: у коллекции нет команд или пользователь
+// состоит в одной из них. Роль участника здесь не учитывается - её проверяет пакет authz.
+func teamAccess(userArg string) string {
+	return `(NOT EXISTS (SELECT 1 FROM collection_teams ct WHERE ct.collection_id = col.uuid)
+		OR EXISTS (
+			SELECT 1 FROM collection_teams ct
+			JOIN team_members tm ON tm.team_id = ct.team_id
+			WHERE ct.collection_id = col.uuid AND tm.user_id = ` + userArg + `
+		))`
+}
+
+// CredentialAccess возвращает отношение пользователя к записи: владение, выданный
+// доступ, роль в организации записи и доступ к её коллекции через команды.
+// Возвращает ErrNotFound, если записи нет.
+func (s *StorageImpl) CredentialAccess(ctx context.Context, userID, credentialID string) (internal.CredentialAccess, error) {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return internal.CredentialAccess{}, ErrNotFound
+	}
+
+	var access internal.CredentialAccess
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(c.user_id = $2, false), COALESCE(c.org_id::text, ''),
+			COALESCE((SELECT s.permission FROM credential_shares s
+				WHERE s.credential_id = c.uuid AND s.grantee_id = $2), ''),
+			COALESCE((SELECT m.role FROM org_members m
+				WHERE m.org_id = c.org_id AND m.user_id = $2), ''),
+			EXISTS(SELECT 1 FROM collection_credentials cc
+				JOIN collections col ON col.uuid = cc.collection_id
+				WHERE cc.credential_id = c.uuid AND `+teamAccess("$2")+`)
+		FROM credentials c WHERE c.uuid = $1
+	`, credentialID, userID).Scan(&access.Owner, &access.OrgID, &access.Share, &access.Role, &access.TeamAccess)
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.CredentialAccess{}, ErrNotFound
+	}
+	if err != nil {
+		slog.Error("failed to get credential access", "error", err)
+		return internal.CredentialAccess{}, err
+	}
+	return access, nil
+}
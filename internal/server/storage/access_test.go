@@ -0,0 +1,42 @@
+package internal_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialAccess(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, credentialID, orgID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	columns := []string{"owner", "org_id", "share", "role", "team_access"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.uuid = $1")).
+		WithArgs(credentialID, userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(false, orgID, "", "member", true))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.uuid = $1")).
+		WithArgs(credentialID, userID).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	access, err := store.CredentialAccess(context.Background(), userID, credentialID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CredentialAccess{OrgID: orgID, Role: models.OrgRoleMember, TeamAccess: true}, access)
+
+	_, err = store.CredentialAccess(context.Background(), userID, credentialID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Некорректный идентификатор не доходит до базы данных
+	_, err = store.CredentialAccess(context.Background(), userID, "not-a-uuid")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
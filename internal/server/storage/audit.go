@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sol1corejz/goph-keeper/internal/server/audit"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// auditLockKey - ключ advisory-блокировки, упорядочивающей добавление записей в журнал.
+const auditLockKey = 0x676b61756469 // "gkaudi"
+
+// auditColumns - список столбцов, выбираемых при чтении журнала аудита.
+const auditColumns = `id, created_at, actor_id, org_id, action, credential_id, client_ip, user_agent, result, prev_hash, hash`
+
+// AppendAudit добавляет событие в конец журнала аудита, связывая его с последней записью.
+// Время события округляется до микросекунд - точности хранения в PostgreSQL,
+// чтобы хэш совпадал при проверке.
+func (s *StorageImpl) AppendAudit(ctx context.Context, entry internal.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditLockKey); err != nil {
+			slog.Error("failed to lock audit log", "error", err)
+			return err
+		}
+
+		var prevHash string
+		err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("failed to read audit log head", "error", err)
+			return err
+		}
+
+		entry.PrevHash = prevHash
+		entry.Hash = audit.Hash(prevHash, entry)
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO audit_log (created_at, actor_id, org_id, action, credential_id, client_ip, user_agent, result, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, entry.CreatedAt, entry.ActorID, entry.OrgID, entry.Action, entry.CredentialID,
+			entry.ClientIP, entry.UserAgent, entry.Result, entry.PrevHash, entry.Hash)
+		if err != nil {
+			slog.Error("failed to append audit entry", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ListAudit возвращает записи журнала пользователя или организации от новых к старым.
+func (s *StorageImpl) ListAudit(ctx context.Context, filter internal.AuditFilter) ([]internal.AuditEntry, error) {
+	var (
+		query strings.Builder
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	query.WriteString(`SELECT ` + auditColumns + ` FROM audit_log WHERE `)
+	if filter.OrgID != "" {
+		query.WriteString(`org_id = ` + arg(filter.OrgID))
+	} else {
+		query.WriteString(`actor_id = ` + arg(filter.ActorID))
+	}
+	if filter.BeforeID > 0 {
+		query.WriteString(` AND id < ` + arg(filter.BeforeID))
+	}
+	query.WriteString(` ORDER BY id DESC LIMIT ` + arg(filter.Limit))
+
+	return s.queryAudit(ctx, query.String(), args...)
+}
+
+// ScanAudit возвращает до limit записей журнала с номером больше afterID
+// в порядке возрастания номеров. Используется для проверки цепочки.
+func (s *StorageImpl) ScanAudit(ctx context.Context, afterID int64, limit int) ([]internal.AuditEntry, error) {
+	return s.queryAudit(ctx, `SELECT `+auditColumns+` FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+}
+
+// queryAudit выполняет запрос к журналу аудита и считывает записи.
+func (s *StorageImpl) queryAudit(ctx context.Context, query string, args ...any) ([]internal.AuditEntry, error) {
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error("failed to read audit log", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]internal.AuditEntry, 0)
+	for rows.Next() {
+		var e internal.AuditEntry
+		err = rows.Scan(&e.ID, &e.CreatedAt, &e.ActorID, &e.OrgID, &e.Action, &e.CredentialID,
+			&e.ClientIP, &e.UserAgent, &e.Result, &e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// VerifyAudit проверяет целостность всей цепочки журнала аудита.
+// Возвращает проверитель с количеством записей и хэшем последней записи
+// или *audit.ChainError для первой нарушенной записи.
+func (s *StorageImpl) VerifyAudit(ctx context.Context, batchSize int) (*audit.Verifier, error) {
+	v := &audit.Verifier{}
+	var lastID int64
+	for {
+		entries, err := s.ScanAudit(ctx, lastID, batchSize)
+		if err != nil {
+			return v, err
+		}
+		for _, e := range entries {
+			if err = v.Check(e); err != nil {
+				return v, err
+			}
+			lastID = e.ID
+		}
+		if len(entries) < batchSize {
+			return v, nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package internal_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sol1corejz/goph-keeper/internal/server/audit"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// auditRows возвращает набор строк журнала аудита.
+func auditRows(entries ...models.AuditEntry) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "created_at", "actor_id", "org_id", "action", "credential_id",
+		"client_ip", "user_agent", "result", "prev_hash", "hash"})
+	for _, e := range entries {
+		rows.AddRow(e.ID, e.CreatedAt, e.ActorID, e.OrgID, e.Action, e.CredentialID,
+			e.ClientIP, e.UserAgent, e.Result, e.PrevHash, e.Hash)
+	}
+	return rows
+}
+
+func TestAppendAuditLinksToHead(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	entry := models.AuditEntry{
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 123456789, time.UTC),
+		ActorID:   "user",
+		Action:    "EditCredentials",
+		Result:    audit.ResultOK,
+	}
+	head := "previous"
+
+	// Время округляется до микросекунд, как хранит PostgreSQL
+	stored := entry
+	stored.CreatedAt = entry.CreatedAt.Truncate(time.Microsecond)
+	hash := audit.Hash(head, stored)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(head))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_log")).
+		WithArgs(stored.CreatedAt, "user", "", "EditCredentials", "", "", "", audit.ResultOK, head, hash).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AppendAudit(context.Background(), entry))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppendAuditFirstEntry(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT hash FROM audit_log")).
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_log")).
+		WithArgs(sqlmock.AnyArg(), "", "", "Login", "", "", "", "invalid password", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AppendAudit(context.Background(), models.AuditEntry{Action: "Login", Result: "invalid password"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyAudit(t *testing.T) {
+	first := models.AuditEntry{ID: 1, CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Action: "Register", Result: audit.ResultOK}
+	first.Hash = audit.Hash("", first)
+	second := models.AuditEntry{ID: 2, CreatedAt: first.CreatedAt.Add(time.Minute), Action: "Login", Result: audit.ResultOK, PrevHash: first.Hash}
+	second.Hash = audit.Hash(first.Hash, second)
+
+	t.Run("valid", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer mockDB.Close()
+
+		store := &storage.StorageImpl{DB: mockDB}
+
+		mock.ExpectQuery(regexp.QuoteMeta("FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2")).
+			WithArgs(0, 2).
+			WillReturnRows(auditRows(first, second))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2")).
+			WithArgs(2, 2).
+			WillReturnRows(auditRows())
+
+		v, err := store.VerifyAudit(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), v.Count())
+		assert.Equal(t, second.Hash, v.Head())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer mockDB.Close()
+
+		store := &storage.StorageImpl{DB: mockDB}
+		tampered := second
+		tampered.Result = "denied"
+
+		mock.ExpectQuery(regexp.QuoteMeta("FROM audit_log WHERE id > $1")).
+			WithArgs(0, 10).
+			WillReturnRows(auditRows(first, tampered))
+
+		_, err = store.VerifyAudit(context.Background(), 10)
+		var chainErr *audit.ChainError
+		assert.ErrorAs(t, err, &chainErr)
+		assert.Equal(t, int64(2), chainErr.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListAuditByOrganization(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM audit_log WHERE org_id = $1 AND id < $2 ORDER BY id DESC LIMIT $3")).
+		WithArgs("org", int64(100), 20).
+		WillReturnRows(auditRows())
+
+	entries, err := store.ListAudit(context.Background(), models.AuditFilter{ActorID: "user", OrgID: "org", BeforeID: 100, Limit: 20})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
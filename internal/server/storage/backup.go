@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// ErrNotEmpty - восстановление без замены данных в непустое хранилище.
+var ErrNotEmpty = errors.New("storage is not empty")
+
+// ErrUnknownTable - резервная копия содержит таблицу, которой нет в схеме.
+var ErrUnknownTable = errors.New("unknown table")
+
+// col описывает столбец BackupSchema без NULL.
+func col(name, typ string) internal.BackupColumn {
+	return internal.BackupColumn{Name: name, Type: typ}
+}
+
+// nullCol описывает столбец BackupSchema, допускающий NULL.
+func nullCol(name, typ string) internal.BackupColumn {
+	return internal.BackupColumn{Name: name, Type: typ, Nullable: true}
+}
+
+// ref описывает внешний ключ BackupSchema.
+func ref(column, table, refColumn string) internal.BackupReference {
+	return internal.BackupReference{Column: column, Table: table, RefColumn: refColumn}
+}
+
+// roleColumn - столбец роли участника организации.
+var roleColumn = internal.BackupColumn{Name: "role", Type: internal.BackupText,
+	Values: []string{"owner", "admin", "member", "read-only"}}
+
+// Короткие имена типов столбцов для BackupSchema.
+const (
+	uuidType      = internal.BackupUUID
+	textType      = internal.BackupText
+	timestampType = internal.BackupTimestamp
+	byteaType     = internal.BackupBytea
+)
+
+// BackupSchema - таблицы, входящие в резервную копию, в порядке внешних ключей:
+// каждая таблица следует за таблицами, на которые ссылается. Описание повторяет
+// schema, по нему копия проверяется без базы данных. TestBackupSchemaMatchesSchema
+// сверяет его с выражениями schema, поэтому миграция без изменения описания не проходит тесты.
+var BackupSchema = []internal.BackupTableSchema{
+	{
+		Name:       "users",
+		Columns:    []internal.BackupColumn{col("uuid", uuidType), col("username", textType), col("password", textType)},
+		PrimaryKey: []string{"uuid"},
+		Unique:     [][]string{{"username"}},
+	},
+	{
+		Name:       "organizations",
+		Columns:    []internal.BackupColumn{col("uuid", uuidType), col("name", textType), col("created_at", timestampType)},
+		PrimaryKey: []string{"uuid"},
+		Unique:     [][]string{{"name"}},
+	},
+	{
+		Name: "credentials",
+		Columns: []internal.BackupColumn{col("uuid", uuidType), nullCol("user_id", uuidType), col("data", textType),
+			nullCol("meta", textType), col("type", textType), col("created_at", timestampType),
+			col("updated_at", timestampType), nullCol("org_id", uuidType)},
+		PrimaryKey: []string{"uuid"},
+		References: []internal.BackupReference{ref("user_id", "users", "uuid"), ref("org_id", "organizations", "uuid")},
+		ExactlyOne: []string{"user_id", "org_id"},
+	},
+	{
+		Name:       "credential_tags",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType), col("tag", textType)},
+		PrimaryKey: []string{"credential_id", "tag"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid")},
+	},
+	{
+		Name:       "credential_search_index",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType), col("term", textType)},
+		PrimaryKey: []string{"credential_id", "term"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid")},
+	},
+	{
+		Name:       "folders",
+		Columns:    []internal.BackupColumn{col("uuid", uuidType), col("user_id", uuidType), nullCol("parent_id", uuidType), col("name", textType)},
+		PrimaryKey: []string{"uuid"},
+		UniqueNull: [][]string{{"user_id", "parent_id", "name"}},
+		References: []internal.BackupReference{ref("user_id", "users", "uuid"), ref("parent_id", "folders", "uuid")},
+	},
+	{
+		Name:       "credential_folders",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType), col("folder_id", uuidType)},
+		PrimaryKey: []string{"credential_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid"), ref("folder_id", "folders", "uuid")},
+	},
+	{
+		Name:       "credential_favorites",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType)},
+		PrimaryKey: []string{"credential_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid")},
+	},
+	{
+		Name: "credential_shares",
+		Columns: []internal.BackupColumn{col("credential_id", uuidType), col("grantee_id", uuidType),
+			{Name: "permission", Type: textType, Values: []string{"read", "write"}},
+			nullCol("encrypted_key", byteaType), col("created_at", timestampType)},
+		PrimaryKey: []string{"credential_id", "grantee_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid"), ref("grantee_id", "users", "uuid")},
+	},
+	{
+		Name:       "org_members",
+		Columns:    []internal.BackupColumn{col("org_id", uuidType), col("user_id", uuidType), roleColumn},
+		PrimaryKey: []string{"org_id", "user_id"},
+		References: []internal.BackupReference{ref("org_id", "organizations", "uuid"), ref("user_id", "users", "uuid")},
+	},
+	{
+		Name:       "org_invites",
+		Columns:    []internal.BackupColumn{col("org_id", uuidType), col("user_id", uuidType), roleColumn, col("created_at", timestampType)},
+		PrimaryKey: []string{"org_id", "user_id"},
+		References: []internal.BackupReference{ref("org_id", "organizations", "uuid"), ref("user_id", "users", "uuid")},
+	},
+	{
+		Name:       "teams",
+		Columns:    []internal.BackupColumn{col("uuid", uuidType), col("org_id", uuidType), col("name", textType)},
+		PrimaryKey: []string{"uuid"},
+		Unique:     [][]string{{"org_id", "name"}},
+		References: []internal.BackupReference{ref("org_id", "organizations", "uuid")},
+	},
+	{
+		Name:       "team_members",
+		Columns:    []internal.BackupColumn{col("team_id", uuidType), col("user_id", uuidType)},
+		PrimaryKey: []string{"team_id", "user_id"},
+		References: []internal.BackupReference{ref("team_id", "teams", "uuid"), ref("user_id", "users", "uuid")},
+	},
+	{
+		Name:       "collections",
+		Columns:    []internal.BackupColumn{col("uuid", uuidType), col("org_id", uuidType), col("name", textType)},
+		PrimaryKey: []string{"uuid"},
+		Unique:     [][]string{{"org_id", "name"}},
+		References: []internal.BackupReference{ref("org_id", "organizations", "uuid")},
+	},
+	{
+		Name:       "collection_teams",
+		Columns:    []internal.BackupColumn{col("collection_id", uuidType), col("team_id", uuidType)},
+		PrimaryKey: []string{"collection_id", "team_id"},
+		References: []internal.BackupReference{ref("collection_id", "collections", "uuid"), ref("team_id", "teams", "uuid")},
+	},
+	{
+		Name:       "collection_credentials",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType), col("collection_id", uuidType)},
+		PrimaryKey: []string{"credential_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid"), ref("collection_id", "collections", "uuid")},
+	},
+	{
+		Name:       "credential_rotations",
+		Columns:    []internal.BackupColumn{col("credential_id", uuidType), col("flagged_at", timestampType)},
+		PrimaryKey: []string{"credential_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid")},
+	},
+	{
+		// Журнал аудита не ссылается на другие таблицы, чтобы переживать удаление данных
+		Name: "audit_log",
+		Columns: []internal.BackupColumn{col("id", internal.BackupBigint), col("created_at", timestampType),
+			col("actor_id", textType), col("org_id", textType), col("action", textType), col("credential_id", textType),
+			col("client_ip", textType), col("user_agent", textType), col("result", textType),
+			col("prev_hash", textType), col("hash", textType)},
+		PrimaryKey: []string{"id"},
+		Unique:     [][]string{{"hash"}},
+	},
+	{
+		Name: "otp_counters",
+		Columns: []internal.BackupColumn{col("credential_id", uuidType),
+			{Name: "counter", Type: internal.BackupBigint, NonNegative: true}},
+		PrimaryKey: []string{"credential_id"},
+		References: []internal.BackupReference{ref("credential_id", "credentials", "uuid")},
+	},
+	{
+		Name:       "user_keys",
+		Columns:    []internal.BackupColumn{col("user_id", uuidType), col("public_key", byteaType), col("updated_at", timestampType)},
+		PrimaryKey: []string{"user_id"},
+		References: []internal.BackupReference{ref("user_id", "users", "uuid")},
+	},
+}
+
+// backupTables - имена таблиц BackupSchema в порядке загрузки.
+var backupTables = func() []string {
+	names := make([]string, len(BackupSchema))
+	for i, t := range BackupSchema {
+		names[i] = t.Name
+	}
+	return names
+}()
+
+// DumpTables возвращает строки всех таблиц схемы. Чтение выполняется в одной транзакции
+// REPEATABLE READ, поэтому снимок согласован при работающем сервере.
+func (s *StorageImpl) DumpTables(ctx context.Context) ([]internal.BackupTable, error) {
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		slog.Error("failed to begin snapshot transaction", "error", err)
+		return nil, err
+	}
+	// Транзакция только читает данные, откат ничего не отменяет
+	defer tx.Rollback()
+
+	tables := make([]internal.BackupTable, 0, len(backupTables))
+	for _, name := range backupTables {
+		rows, err := dumpTable(ctx, tx, name)
+		if err != nil {
+			slog.Error("failed to dump table", "table", name, "error", err)
+			return nil, fmt.Errorf("dump %s: %w", name, err)
+		}
+		tables = append(tables, internal.BackupTable{Name: name, Rows: rows})
+	}
+	return tables, nil
+}
+
+// dumpTable возвращает строки таблицы в виде JSON-объектов.
+func dumpTable(ctx context.Context, tx *sql.Tx, name string) ([]json.RawMessage, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT row_to_json(t)::text FROM `+name+` t`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []json.RawMessage{}
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return nil, err
+		}
+		result = append(result, json.RawMessage(row))
+	}
+	return result, rows.Err()
+}
+
+// RestoreTables загружает строки таблиц одной транзакцией. Без replace хранилище
+// должно быть пустым, иначе возвращается ErrNotEmpty; с replace существующие данные
+// удаляются. Таблицы загружаются в порядке внешних ключей независимо от порядка в tables.
+func (s *StorageImpl) RestoreTables(ctx context.Context, tables []internal.BackupTable, replace bool) error {
+	byName := make(map[string][]json.RawMessage, len(tables))
+	for _, t := range tables {
+		if !slices.Contains(backupTables, t.Name) {
+			return fmt.Errorf("%w: %q", ErrUnknownTable, t.Name)
+		}
+		byName[t.Name] = append(byName[t.Name], t.Rows...)
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if replace {
+			// TRUNCATE не вызывает построчный триггер, запрещающий удаление из audit_log
+			if _, err := tx.ExecContext(ctx, `TRUNCATE `+strings.Join(backupTables, ", ")+` RESTART IDENTITY CASCADE`); err != nil {
+				slog.Error("failed to truncate tables", "error", err)
+				return err
+			}
+		} else {
+			checks := make([]string, len(backupTables))
+			for i, name := range backupTables {
+				checks[i] = `EXISTS (SELECT 1 FROM ` + name + `)`
+			}
+			var notEmpty bool
+			if err := tx.QueryRowContext(ctx, `SELECT `+strings.Join(checks, " OR ")).Scan(&notEmpty); err != nil {
+				slog.Error("failed to check tables", "error", err)
+				return err
+			}
+			if notEmpty {
+				return ErrNotEmpty
+			}
+		}
+
+		for _, name := range backupTables {
+			rows := byName[name]
+			if len(rows) == 0 {
+				continue
+			}
+			data, err := json.Marshal(rows)
+			if err != nil {
+				return err
+			}
+			// Внешние ключи проверяются в конце выражения, поэтому порядок строк
+			// таблиц со ссылкой на себя (folders) не важен
+			if _, err := tx.ExecContext(ctx, `INSERT INTO `+name+` SELECT * FROM json_populate_recordset(NULL::`+name+`, $1::json)`, string(data)); err != nil {
+				slog.Error("failed to restore table", "table", name, "error", err)
+				return fmt.Errorf("restore %s: %w", name, err)
+			}
+		}
+
+		// Номера новых записей журнала аудита продолжают восстановленные
+		if _, err := tx.ExecContext(ctx, `SELECT setval(pg_get_serial_sequence('audit_log', 'id'), COALESCE((SELECT MAX(id) FROM audit_log), 0) + 1, false)`); err != nil {
+			slog.Error("failed to reset audit sequence", "error", err)
+			return err
+		}
+		return nil
+	})
+}
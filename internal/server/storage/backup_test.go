@@ -0,0 +1,119 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpTables(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT row_to_json(t)::text FROM users t")).
+		WillReturnRows(sqlmock.NewRows([]string{"row"}).AddRow(`{"uuid":"u1","login":"alice"}`))
+	// Остальные таблицы пусты
+	for i := 0; i < 19; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT row_to_json(t)::text FROM")).
+			WillReturnRows(sqlmock.NewRows([]string{"row"}))
+	}
+	mock.ExpectRollback()
+
+	tables, err := store.DumpTables(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tables, 20)
+	assert.Equal(t, "users", tables[0].Name)
+	assert.JSONEq(t, `{"uuid":"u1","login":"alice"}`, string(tables[0].Rows[0]))
+	assert.Equal(t, "user_keys", tables[19].Name)
+	assert.Empty(t, tables[19].Rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTables(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	tables := []models.BackupTable{
+		// Порядок таблиц в копии не важен: credentials загружается после users
+		{Name: "credentials", Rows: []json.RawMessage{json.RawMessage(`{"uuid":"c1","user_id":"u1"}`)}},
+		{Name: "users", Rows: []json.RawMessage{json.RawMessage(`{"uuid":"u1"}`)}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM users) OR")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users SELECT * FROM json_populate_recordset(NULL::users, $1::json)")).
+		WithArgs(`[{"uuid":"u1"}]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials SELECT * FROM json_populate_recordset(NULL::credentials, $1::json)")).
+		WithArgs(`[{"uuid":"c1","user_id":"u1"}]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT setval(pg_get_serial_sequence('audit_log', 'id')")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = store.RestoreTables(context.Background(), tables, false)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTablesNotEmpty(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = store.RestoreTables(context.Background(), nil, false)
+	assert.ErrorIs(t, err, storage.ErrNotEmpty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTablesReplace(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("TRUNCATE users, organizations, credentials,")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT setval(")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = store.RestoreTables(context.Background(), []models.BackupTable{{Name: "users"}}, true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreTablesUnknownTable(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+
+	// Имя таблицы подставляется в запрос, поэтому неизвестные имена отклоняются до обращения к базе
+	err = store.RestoreTables(context.Background(), []models.BackupTable{{Name: "users; DROP TABLE users"}}, true)
+	assert.ErrorIs(t, err, storage.ErrUnknownTable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// ErrFolderCycle - ошибка, возвращаемая при попытке переместить папку внутрь самой себя.
+var ErrFolderCycle = errors.New("folder cannot be moved into itself")
+
+// nullableUUID преобразует пустую строку в NULL.
+func nullableUUID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+// checkFolderOwner проверяет, что папка существует и принадлежит пользователю.
+func checkFolderOwner(ctx context.Context, tx *sql.Tx, userID, folderID string) error {
+	if _, err := uuid.Parse(folderID); err != nil {
+		return ErrNotFound
+	}
+	var exists bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM folders WHERE uuid = $1 AND user_id = $2)
+	`, folderID, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateFolder создает папку пользователя. Пустой parentID создает корневую папку.
+// Возвращает ErrAlreadyExists, если у родителя уже есть папка с таким именем.
+func (s *StorageImpl) CreateFolder(ctx context.Context, folder internal.Folder) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if folder.ParentID != "" {
+			if err := checkFolderOwner(ctx, tx, folder.UserID, folder.ParentID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO folders (uuid, user_id, parent_id, name) VALUES ($1, $2, $3, $4)
+		`, folder.ID, folder.UserID, nullableUUID(folder.ParentID), folder.Name)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to create folder", "error", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ensureFolderPath возвращает идентификатор папки пользователя с путем path,
+// создавая недостающие папки пути в транзакции tx. known - пути и идентификаторы
+// папок, уже найденных в этой транзакции; пополняется найденными папками.
+func ensureFolderPath(ctx context.Context, tx *sql.Tx, userID, path string, known map[string]string) (string, error) {
+	names := internal.SplitFolderPath(path)
+	var parentID string
+	for i, name := range names {
+		p := strings.Join(names[:i+1], internal.FolderPathSeparator)
+		if id, ok := known[p]; ok {
+			parentID = id
+			continue
+		}
+
+		// Папку могли создать параллельно, поэтому конфликт имени не считается ошибкой
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO folders (uuid, user_id, parent_id, name) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), name) DO NOTHING
+		`, uuid.New().String(), userID, nullableUUID(parentID), name)
+		if err != nil {
+			slog.Error("failed to create folder", "error", err)
+			return "", err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			SELECT uuid FROM folders WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM $2::uuid AND name = $3
+		`, userID, nullableUUID(parentID), name).Scan(&parentID)
+		if err != nil {
+			slog.Error("failed to find folder", "error", err)
+			return "", err
+		}
+		known[p] = parentID
+	}
+	return parentID, nil
+}
+
+// ListFolders возвращает все папки пользователя с вычисленными путями,
+// упорядоченные по пути.
+func (s *StorageImpl) ListFolders(ctx context.Context, userID string) ([]internal.Folder, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT uuid, user_id, COALESCE(parent_id::text, ''), name FROM folders WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		slog.Error("failed to retrieve folders", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := make([]internal.Folder, 0)
+	for rows.Next() {
+		var f internal.Folder
+		if err = rows.Scan(&f.ID, &f.UserID, &f.ParentID, &f.Name); err != nil {
+			slog.Error("failed to retrieve folders", "error", err)
+			return nil, err
+		}
+		folders = append(folders, f)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error("failed to retrieve folders", "error", err)
+		return nil, err
+	}
+
+	return buildFolderPaths(folders), nil
+}
+
+// buildFolderPaths заполняет пути папок и сортирует их по пути.
+func buildFolderPaths(folders []internal.Folder) []internal.Folder {
+	byID := make(map[string]*internal.Folder, len(folders))
+	for i := range folders {
+		byID[folders[i].ID] = &folders[i]
+	}
+
+	for i := range folders {
+		names := []string{folders[i].Name}
+		seen := map[string]bool{folders[i].ID: true}
+		for parent := byID[folders[i].ParentID]; parent != nil && !seen[parent.ID]; parent = byID[parent.ParentID] {
+			seen[parent.ID] = true
+			names = append(names, parent.Name)
+		}
+		for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+			names[l], names[r] = names[r], names[l]
+		}
+		folders[i].Path = strings.Join(names, internal.FolderPathSeparator)
+	}
+
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].Path < folders[j].Path
+	})
+	return folders
+}
+
+// FindFolderByPath возвращает папку пользователя по пути вида "work/databases".
+func (s *StorageImpl) FindFolderByPath(ctx context.Context, userID, path string) (internal.Folder, error) {
+	wanted := strings.Join(internal.SplitFolderPath(path), internal.FolderPathSeparator)
+	if wanted == "" {
+		return internal.Folder{}, ErrNotFound
+	}
+
+	folders, err := s.ListFolders(ctx, userID)
+	if err != nil {
+		return internal.Folder{}, err
+	}
+	for _, f := range folders {
+		if f.Path == wanted {
+			return f, nil
+		}
+	}
+	return internal.Folder{}, ErrNotFound
+}
+
+// RenameFolder переименовывает папку пользователя.
+func (s *StorageImpl) RenameFolder(ctx context.Context, userID, folderID, name string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE folders SET name = $1 WHERE uuid = $2`, name, folderID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to rename folder", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// MoveFolder перемещает папку вместе с содержимым в другую папку. Пустой parentID
+// перемещает папку в корень. Перемещение папки в саму себя или в свою вложенную
+// папку возвращает ErrFolderCycle.
+func (s *StorageImpl) MoveFolder(ctx context.Context, userID, folderID, parentID string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+			return err
+		}
+
+		if parentID != "" {
+			if err := checkFolderOwner(ctx, tx, userID, parentID); err != nil {
+				return err
+			}
+
+			var cycle bool
+			err := tx.QueryRowContext(ctx, `
+				WITH RECURSIVE subtree AS (
+					SELECT uuid FROM folders WHERE uuid = $1
+					UNION ALL
+					SELECT f.uuid FROM folders f JOIN subtree st ON f.parent_id = st.uuid
+				)
+				SELECT EXISTS(SELECT 1 FROM subtree WHERE uuid = $2)
+			`, folderID, parentID).Scan(&cycle)
+			if err != nil {
+				slog.Error("failed to check folder tree", "error", err)
+				return err
+			}
+			if cycle {
+				return ErrFolderCycle
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE folders SET parent_id = $1 WHERE uuid = $2`, nullableUUID(parentID), folderID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to move folder", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteFolder удаляет папку и все вложенные папки. Учетные данные из удаленных
+// папок не удаляются, а переходят в корень.
+func (s *StorageImpl) DeleteFolder(ctx context.Context, userID, folderID string) error {
+	if _, err := uuid.Parse(folderID); err != nil {
+		return ErrNotFound
+	}
+
+	result, err := s.DB.ExecContext(ctx, `DELETE FROM folders WHERE uuid = $1 AND user_id = $2`, folderID, userID)
+	if err != nil {
+		slog.Error("failed to delete folder", "error", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetCredentialFolder помещает запись в папку пользователя userID. Пустой folderID
+// перемещает запись в корень. Право распоряжаться записью проверяет пакет authz.
+func (s *StorageImpl) SetCredentialFolder(ctx context.Context, userID, credentialID, folderID string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return setCredentialFolder(ctx, tx, userID, credentialID, folderID)
+	})
+}
+
+// setCredentialFolder помещает запись в папку внутри транзакции.
+func setCredentialFolder(ctx context.Context, tx *sql.Tx, userID, credentialID, folderID string) error {
+	if folderID == "" {
+		_, err := tx.ExecContext(ctx, `DELETE FROM credential_folders WHERE credential_id = $1`, credentialID)
+		return err
+	}
+
+	if err := checkFolderOwner(ctx, tx, userID, folderID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO credential_folders (credential_id, folder_id) VALUES ($1, $2)
+		ON CONFLICT (credential_id) DO UPDATE SET folder_id = EXCLUDED.folder_id
+	`, credentialID, folderID)
+	if err != nil {
+		slog.Error("failed to set credential folder", "error", err)
+	}
+	return err
+}
+
+// SetFavorite устанавливает или снимает отметку «избранное» у записи.
+func (s *StorageImpl) SetFavorite(ctx context.Context, credentialID string, favorite bool) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return setFavorite(ctx, tx, credentialID, favorite)
+	})
+}
+
+// setFavorite устанавливает отметку «избранное» внутри транзакции.
+func setFavorite(ctx context.Context, tx *sql.Tx, credentialID string, favorite bool) error {
+	var err error
+	if favorite {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO credential_favorites (credential_id) VALUES ($1) ON CONFLICT DO NOTHING
+		`, credentialID)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM credential_favorites WHERE credential_id = $1`, credentialID)
+	}
+	if err != nil {
+		slog.Error("failed to update favorite", "error", err)
+	}
+	return err
+}
+
+// UpdateCredentialTags добавляет и удаляет теги записи. Если переданы термины
+// слепого индекса, индекс записи заменяется целиком, так как теги индексируются.
+func (s *StorageImpl) UpdateCredentialTags(ctx context.Context, credentialID string, add, remove, searchTerms []string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, tag := range remove {
+			_, err := tx.ExecContext(ctx, `DELETE FROM credential_tags WHERE credential_id = $1 AND tag = $2`, credentialID, tag)
+			if err != nil {
+				slog.Error("failed to remove credential tag", "error", err)
+				return err
+			}
+		}
+
+		for _, tag := range add {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO credential_tags (credential_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING
+			`, credentialID, tag)
+			if err != nil {
+				slog.Error("failed to save credential tag", "error", err)
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE credentials SET updated_at = now() WHERE uuid = $1`, credentialID); err != nil {
+			slog.Error("failed to update credential", "error", err)
+			return err
+		}
+
+		if searchTerms == nil {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM credential_search_index WHERE credential_id = $1`, credentialID); err != nil {
+			slog.Error("failed to clear search index", "error", err)
+			return err
+		}
+		return saveSearchTerms(ctx, tx, credentialID, searchTerms)
+	})
+}
@@ -0,0 +1,211 @@
+package internal_test
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestCreateFolder(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	folder := models.Folder{
+		ID:       uuid.New().String(),
+		UserID:   uuid.New().String(),
+		ParentID: uuid.New().String(),
+		Name:     "databases",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM folders WHERE uuid = $1 AND user_id = $2)")).
+		WithArgs(folder.ParentID, folder.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO folders (uuid, user_id, parent_id, name) VALUES ($1, $2, $3, $4)")).
+		WithArgs(folder.ID, folder.UserID, folder.ParentID, folder.Name).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.CreateFolder(context.Background(), folder))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFolderAlreadyExists(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	folder := models.Folder{ID: uuid.New().String(), UserID: uuid.New().String(), Name: "work"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO folders")).
+		WithArgs(folder.ID, folder.UserID, nil, folder.Name).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.CreateFolder(context.Background(), folder), storage.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListFoldersBuildsPaths(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	work, db, personal := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM folders WHERE user_id = $1")).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "user_id", "parent_id", "name"}).
+			AddRow(db, userID, work, "databases").
+			AddRow(personal, userID, "", "personal").
+			AddRow(work, userID, "", "work"))
+
+	folders, err := store.ListFolders(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Folder{
+		{ID: personal, UserID: userID, Name: "personal", Path: "personal"},
+		{ID: work, UserID: userID, Name: "work", Path: "work"},
+		{ID: db, UserID: userID, ParentID: work, Name: "databases", Path: "work/databases"},
+	}, folders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveFolderIntoDescendant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, folderID, childID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	for _, id := range []string{folderID, childID} {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM folders WHERE uuid = $1 AND user_id = $2)")).
+			WithArgs(id, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE subtree")).
+		WithArgs(folderID, childID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.MoveFolder(context.Background(), userID, folderID, childID), storage.ErrFolderCycle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveFolderToRoot(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, folderID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM folders")).
+		WithArgs(folderID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE folders SET parent_id = $1 WHERE uuid = $2")).
+		WithArgs(nil, folderID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.MoveFolder(context.Background(), userID, folderID, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFolderNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, folderID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM folders WHERE uuid = $1 AND user_id = $2")).
+		WithArgs(folderID, userID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, store.DeleteFolder(context.Background(), userID, folderID), storage.ErrNotFound)
+	assert.ErrorIs(t, store.DeleteFolder(context.Background(), userID, "not-a-uuid"), storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetFavorite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credID := uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_favorites (credential_id) VALUES ($1)")).
+		WithArgs(credID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_favorites WHERE credential_id = $1")).
+		WithArgs(credID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.SetFavorite(context.Background(), credID, true))
+	assert.NoError(t, store.SetFavorite(context.Background(), credID, false))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCredentialTags(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credID := uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_tags WHERE credential_id = $1 AND tag = $2")).
+		WithArgs(credID, "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_tags")).
+		WithArgs(credID, "new").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET updated_at = now() WHERE uuid = $1")).
+		WithArgs(credID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.UpdateCredentialTags(context.Background(), credID, []string{"new"}, []string{"old"}, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListCredentialsByFolderAndFavorites(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, folderID := uuid.New().String(), uuid.New().String()
+	cred := models.Credential{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeText, Tags: []string{}, FolderID: folderID, Favorite: true}
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND EXISTS (SELECT 1 FROM credential_folders cf WHERE cf.credential_id = c.uuid AND cf.folder_id = $2) AND EXISTS (SELECT 1 FROM credential_favorites fv WHERE fv.credential_id = c.uuid) ORDER BY")).
+		WithArgs(userID, folderID, storage.DefaultPageSize+1).
+		WillReturnRows(credentialRows(cred))
+
+	page, err := store.ListCredentials(context.Background(), userID, models.ListOptions{FolderID: folderID, Favorites: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credential{cred}, page.Credentials)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
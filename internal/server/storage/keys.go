@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+)
+
+// ErrNoPublicKey - ошибка, возвращаемая, если пользователь не опубликовал открытый ключ.
+var ErrNoPublicKey = errors.New("public key not published")
+
+// SetPublicKey сохраняет открытый ключ пользователя, заменяя ранее опубликованный.
+func (s *StorageImpl) SetPublicKey(ctx context.Context, userID string, key []byte) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET public_key = EXCLUDED.public_key, updated_at = now()
+	`, userID, key)
+	if err != nil {
+		slog.Error("failed to save public key", "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetPublicKey возвращает открытый ключ пользователя username. Возвращает
+// ErrUserNotFound, если пользователя нет, и ErrNoPublicKey, если он не опубликовал ключ.
+func (s *StorageImpl) GetPublicKey(ctx context.Context, username string) ([]byte, error) {
+	var key []byte
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT k.public_key FROM users u
+		LEFT JOIN user_keys k ON k.user_id = u.uuid
+		WHERE u.username = $1
+	`, username).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		slog.Error("failed to get public key", "error", err)
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrNoPublicKey
+	}
+	return key, nil
+}
@@ -0,0 +1,57 @@
+package internal_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPublicKey(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, key := uuid.New().String(), make([]byte, 32)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2)")).
+		WithArgs(userID, key).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, store.SetPublicKey(context.Background(), userID, key))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPublicKey(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	query := regexp.QuoteMeta("SELECT k.public_key FROM users u")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	mock.ExpectQuery(query).WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"public_key"}).AddRow(key))
+	got, err := store.GetPublicKey(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	// Пользователь есть, но ключ не опубликован
+	mock.ExpectQuery(query).WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"public_key"}).AddRow(nil))
+	_, err = store.GetPublicKey(context.Background(), "bob")
+	assert.ErrorIs(t, err, storage.ErrNoPublicKey)
+
+	mock.ExpectQuery(query).WithArgs("nobody").
+		WillReturnRows(sqlmock.NewRows([]string{"public_key"}))
+	_, err = store.GetPublicKey(context.Background(), "nobody")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
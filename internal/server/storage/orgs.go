@@ -0,0 +1,580 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// ErrLastOwner - ошибка, возвращаемая при попытке удалить последнего владельца организации.
+var ErrLastOwner = errors.New("organization must have at least one owner")
+
+// ErrSealedCredential - ошибка, возвращаемая при попытке передать организации
+// запись, зашифрованную ключом записи, без её расшифрованных данных.
+var ErrSealedCredential = errors.New("credential is sealed with a record key")
+
+// orgCredentialIDs - подзапрос идентификаторов записей из коллекций организации $1.
+const orgCredentialIDs = `SELECT cc.credential_id FROM collection_credentials cc
+	JOIN collections col ON col.uuid = cc.collection_id WHERE col.org_id = $1`
+
+// findUserID возвращает идентификатор пользователя по имени или ErrUserNotFound.
+func findUserID(ctx context.Context, tx *sql.Tx, username string) (string, error) {
+	var userID string
+	err := tx.QueryRowContext(ctx, `SELECT uuid FROM users WHERE username = $1`, username).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	return userID, err
+}
+
+// CreateOrganization создает организацию и делает ownerID её владельцем.
+// Возвращает ErrAlreadyExists, если имя организации занято.
+func (s *StorageImpl) CreateOrganization(ctx context.Context, org internal.Organization, ownerID string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO organizations (uuid, name) VALUES ($1, $2)`, org.ID, org.Name)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to create organization", "error", err)
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)
+		`, org.ID, ownerID, internal.OrgRoleOwner)
+		if err != nil {
+			slog.Error("failed to add organization owner", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ListOrganizations возвращает организации, в которых состоит пользователь, с его ролью.
+func (s *StorageImpl) ListOrganizations(ctx context.Context, userID string) ([]internal.Organization, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT o.uuid, o.name, m.role, o.created_at
+		FROM organizations o
+		JOIN org_members m ON m.org_id = o.uuid
+		WHERE m.user_id = $1
+		ORDER BY o.name
+	`, userID)
+	if err != nil {
+		slog.Error("failed to list organizations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := make([]internal.Organization, 0)
+	for rows.Next() {
+		var org internal.Organization
+		if err = rows.Scan(&org.ID, &org.Name, &org.Role, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// GetOrgRole возвращает роль пользователя в организации или ErrNotFound,
+// если пользователь в ней не состоит.
+func (s *StorageImpl) GetOrgRole(ctx context.Context, orgID, userID string) (internal.OrgRole, error) {
+	if _, err := uuid.Parse(orgID); err != nil {
+		return "", ErrNotFound
+	}
+
+	var role internal.OrgRole
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2
+	`, orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		slog.Error("failed to get organization role", "error", err)
+		return "", err
+	}
+	return role, nil
+}
+
+// GetOrgMember возвращает участника организации по имени пользователя.
+func (s *StorageImpl) GetOrgMember(ctx context.Context, orgID, username string) (internal.OrgMember, error) {
+	member := internal.OrgMember{Username: username}
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT m.user_id, m.role FROM org_members m
+		JOIN users u ON u.uuid = m.user_id
+		WHERE m.org_id = $1 AND u.username = $2
+	`, orgID, username).Scan(&member.UserID, &member.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.OrgMember{}, ErrNotFound
+	}
+	if err != nil {
+		slog.Error("failed to get organization member", "error", err)
+		return internal.OrgMember{}, err
+	}
+	return member, nil
+}
+
+// ListMembers возвращает участников организации.
+func (s *StorageImpl) ListMembers(ctx context.Context, orgID string) ([]internal.OrgMember, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT m.user_id, u.username, m.role
+		FROM org_members m
+		JOIN users u ON u.uuid = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY u.username
+	`, orgID)
+	if err != nil {
+		slog.Error("failed to list organization members", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]internal.OrgMember, 0)
+	for rows.Next() {
+		var member internal.OrgMember
+		if err = rows.Scan(&member.UserID, &member.Username, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// InviteMember приглашает пользователя в организацию с указанной ролью.
+// Повторное приглашение заменяет роль. Возвращает ErrAlreadyExists,
+// если пользователь уже состоит в организации.
+func (s *StorageImpl) InviteMember(ctx context.Context, orgID, username string, role internal.OrgRole) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		userID, err := findUserID(ctx, tx, username)
+		if err != nil {
+			return err
+		}
+
+		var member bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM org_members WHERE org_id = $1 AND user_id = $2)
+		`, orgID, userID).Scan(&member)
+		if err != nil {
+			return err
+		}
+		if member {
+			return ErrAlreadyExists
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO org_invites (org_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role, created_at = now()
+		`, orgID, userID, role)
+		if err != nil {
+			slog.Error("failed to invite member", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ListInvites возвращает приглашения пользователя.
+func (s *StorageImpl) ListInvites(ctx context.Context, userID string) ([]internal.OrgInvite, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT i.org_id, o.name, i.role, i.created_at
+		FROM org_invites i
+		JOIN organizations o ON o.uuid = i.org_id
+		WHERE i.user_id = $1
+		ORDER BY i.created_at
+	`, userID)
+	if err != nil {
+		slog.Error("failed to list invites", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := make([]internal.OrgInvite, 0)
+	for rows.Next() {
+		var invite internal.OrgInvite
+		if err = rows.Scan(&invite.OrgID, &invite.OrgName, &invite.Role, &invite.CreatedAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// AcceptInvite принимает приглашение пользователя в организацию.
+func (s *StorageImpl) AcceptInvite(ctx context.Context, userID, orgID string) error {
+	if _, err := uuid.Parse(orgID); err != nil {
+		return ErrNotFound
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var role internal.OrgRole
+		err := tx.QueryRowContext(ctx, `
+			DELETE FROM org_invites WHERE org_id = $1 AND user_id = $2 RETURNING role
+		`, orgID, userID).Scan(&role)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			slog.Error("failed to accept invite", "error", err)
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)
+		`, orgID, userID, role)
+		if err != nil {
+			slog.Error("failed to add organization member", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// RemoveMember удаляет участника memberID из организации и возвращает число записей,
+// отмеченных для смены: записей коллекций, доступных участнику через команды, или всех
+// записей организации, если allCollections. Доступ к записям коллекций пропадает сразу,
+// так как определяется членством. Записи коллекций принадлежат организации, поэтому
+// остаются в ней; доступы к ним, выданные участнику, отзываются.
+func (s *StorageImpl) RemoveMember(ctx context.Context, orgID, memberID string, allCollections bool) (int64, error) {
+	var flagged int64
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		var role internal.OrgRole
+		err := tx.QueryRowContext(ctx, `
+			SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2 FOR UPDATE
+		`, orgID, memberID).Scan(&role)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if role == internal.OrgRoleOwner {
+			var owners int
+			err = tx.QueryRowContext(ctx, `
+				SELECT count(*) FROM org_members WHERE org_id = $1 AND role = 'owner'
+			`, orgID).Scan(&owners)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return ErrLastOwner
+			}
+		}
+
+		// Отмечаем записи, которые участник мог прочитать, пока состоит в организации
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO credential_rotations (credential_id)
+			SELECT cc.credential_id FROM collection_credentials cc
+			JOIN collections col ON col.uuid = cc.collection_id
+			WHERE col.org_id = $1 AND ($3 OR `+teamAccess("$2")+`)
+			ON CONFLICT (credential_id) DO NOTHING
+		`, orgID, memberID, allCollections)
+		if err != nil {
+			slog.Error("failed to flag credentials for rotation", "error", err)
+			return err
+		}
+		if flagged, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		statements := []struct {
+			query string
+			args  []any
+		}{
+			{`DELETE FROM credential_shares WHERE grantee_id = $2 AND credential_id IN (` + orgCredentialIDs + `)`,
+				[]any{orgID, memberID}},
+			{`DELETE FROM team_members WHERE user_id = $2 AND team_id IN (SELECT uuid FROM teams WHERE org_id = $1)`,
+				[]any{orgID, memberID}},
+			{`DELETE FROM org_members WHERE org_id = $1 AND user_id = $2`,
+				[]any{orgID, memberID}},
+		}
+		for _, stmt := range statements {
+			if _, err = tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+				slog.Error("failed to remove organization member", "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return flagged, nil
+}
+
+// CreateTeam создает команду организации.
+// Возвращает ErrAlreadyExists, если команда с таким именем уже есть.
+func (s *StorageImpl) CreateTeam(ctx context.Context, team internal.Team) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO teams (uuid, org_id, name) VALUES ($1, $2, $3)
+	`, team.ID, team.OrgID, team.Name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		slog.Error("failed to create team", "error", err)
+		return err
+	}
+	return nil
+}
+
+// AddTeamMember добавляет участника организации в команду.
+// Возвращает ErrNotFound, если команды нет, и ErrUserNotFound,
+// если пользователь не состоит в организации.
+func (s *StorageImpl) AddTeamMember(ctx context.Context, orgID, teamName, username string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var teamID string
+		err := tx.QueryRowContext(ctx, `SELECT uuid FROM teams WHERE org_id = $1 AND name = $2`, orgID, teamName).Scan(&teamID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var userID string
+		err = tx.QueryRowContext(ctx, `
+			SELECT m.user_id FROM org_members m
+			JOIN users u ON u.uuid = m.user_id
+			WHERE m.org_id = $1 AND u.username = $2
+		`, orgID, username).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO team_members (team_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+		`, teamID, userID)
+		if err != nil {
+			slog.Error("failed to add team member", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// CreateCollection создает коллекцию организации и назначает её командам collection.Teams.
+// Возвращает ErrAlreadyExists, если имя занято, и ErrNotFound, если команды нет.
+func (s *StorageImpl) CreateCollection(ctx context.Context, collection internal.Collection) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO collections (uuid, org_id, name) VALUES ($1, $2, $3)
+		`, collection.ID, collection.OrgID, collection.Name)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to create collection", "error", err)
+			return err
+		}
+
+		for _, team := range collection.Teams {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO collection_teams (collection_id, team_id)
+				SELECT $1, uuid FROM teams WHERE org_id = $2 AND name = $3
+				ON CONFLICT DO NOTHING
+			`, collection.ID, collection.OrgID, team)
+			if err != nil {
+				slog.Error("failed to assign collection to team", "error", err)
+				return err
+			}
+			if affected, err := result.RowsAffected(); err != nil {
+				return err
+			} else if affected == 0 {
+				return ErrNotFound
+			}
+		}
+		return nil
+	})
+}
+
+// collectionColumns возвращает список столбцов, выбираемых при чтении коллекций
+// пользователем с плейсхолдером userArg. Команды собираются в одну строку,
+// разделенную символом tagSeparator.
+func collectionColumns(userArg string) string {
+	return `col.uuid, col.org_id, col.name,
+		COALESCE((SELECT string_agg(t.name, E'\x1f' ORDER BY t.name)
+			FROM collection_teams ct JOIN teams t ON t.uuid = ct.team_id
+			WHERE ct.collection_id = col.uuid), ''),
+		` + teamAccess(userArg)
+}
+
+// scanCollection считывает коллекцию, выбранную со столбцами collectionColumns.
+func scanCollection(row rowScanner) (internal.Collection, error) {
+	var (
+		collection internal.Collection
+		teams      string
+	)
+	err := row.Scan(&collection.ID, &collection.OrgID, &collection.Name, &teams, &collection.TeamAccess)
+	if err != nil {
+		return internal.Collection{}, err
+	}
+	collection.Teams = make([]string, 0)
+	if teams != "" {
+		collection.Teams = strings.Split(teams, tagSeparator)
+	}
+	return collection, nil
+}
+
+// ListCollections возвращает все коллекции организации с отметкой доступа пользователя
+// через команды. Какие из них пользователь видит, решает пакет authz.
+func (s *StorageImpl) ListCollections(ctx context.Context, userID, orgID string) ([]internal.Collection, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+collectionColumns("$2")+`
+		FROM collections col
+		WHERE col.org_id = $1
+		ORDER BY col.name
+	`, orgID, userID)
+	if err != nil {
+		slog.Error("failed to list collections", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]internal.Collection, 0)
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// GetCollection возвращает коллекцию организации по имени с отметкой доступа
+// пользователя через команды или ErrNotFound.
+func (s *StorageImpl) GetCollection(ctx context.Context, userID, orgID, name string) (internal.Collection, error) {
+	collection, err := scanCollection(s.DB.QueryRowContext(ctx, `
+		SELECT `+collectionColumns("$2")+`
+		FROM collections col
+		WHERE col.org_id = $1 AND col.name = $3
+	`, orgID, userID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return internal.Collection{}, ErrNotFound
+	}
+	if err != nil {
+		slog.Error("failed to get collection", "error", err)
+		return internal.Collection{}, err
+	}
+	return collection, nil
+}
+
+// AddToCollection помещает запись в коллекцию collectionID организации orgID.
+// Личная запись переходит во владение организации: папка, отметка «избранное»,
+// слепой индекс и выданные доступы относятся к прежнему владельцу и удаляются.
+// Данные личной записи, зашифрованные ключом записи, организация прочитать не может,
+// поэтому они заменяются расшифрованными данными data; без них возвращается
+// ErrSealedCredential. Право перемещать запись и изменять коллекцию проверяет пакет authz.
+func (s *StorageImpl) AddToCollection(ctx context.Context, credentialID, orgID, collectionID, data string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var sealed bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT org_id IS NULL AND data LIKE $2 FROM credentials WHERE uuid = $1 FOR UPDATE
+		`, credentialID, internal.SealedDataPrefix+"%").Scan(&sealed)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			slog.Error("failed to get credential", "error", err)
+			return err
+		}
+		if sealed && data == "" {
+			return ErrSealedCredential
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO collection_credentials (credential_id, collection_id) VALUES ($1, $2)
+			ON CONFLICT (credential_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+		`, credentialID, collectionID)
+		if err != nil {
+			slog.Error("failed to add credential to collection", "error", err)
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE credentials SET user_id = NULL, org_id = $2 WHERE uuid = $1 AND org_id IS NULL
+		`, credentialID, orgID)
+		if err != nil {
+			slog.Error("failed to transfer credential to organization", "error", err)
+			return err
+		}
+		transferred, err := result.RowsAffected()
+		if err != nil || transferred == 0 {
+			return err
+		}
+
+		if sealed {
+			_, err = tx.ExecContext(ctx, `UPDATE credentials SET data = $2 WHERE uuid = $1`, credentialID, data)
+			if err != nil {
+				slog.Error("failed to transfer credential to organization", "error", err)
+				return err
+			}
+		}
+
+		for _, query := range []string{
+			`DELETE FROM credential_folders WHERE credential_id = $1`,
+			`DELETE FROM credential_favorites WHERE credential_id = $1`,
+			`DELETE FROM credential_search_index WHERE credential_id = $1`,
+			`DELETE FROM credential_shares WHERE credential_id = $1`,
+		} {
+			if _, err = tx.ExecContext(ctx, query, credentialID); err != nil {
+				slog.Error("failed to transfer credential to organization", "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListOrgCredentials возвращает все записи коллекций организации с отметкой доступа
+// пользователя к их коллекциям через команды. Какие из них пользователь видит, решает
+// пакет authz. Папка и отметка «избранное» относятся только к личным записям и не возвращаются.
+func (s *StorageImpl) ListOrgCredentials(ctx context.Context, userID, orgID string) ([]internal.OrgCredential, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+credentialColumns+`, cc.collection_id,
+			EXISTS(SELECT 1 FROM credential_rotations r WHERE r.credential_id = c.uuid),
+			`+teamAccess("$2")+`
+		FROM collection_credentials cc
+		JOIN collections col ON col.uuid = cc.collection_id
+		JOIN credentials c ON c.uuid = cc.credential_id
+		WHERE col.org_id = $1
+		ORDER BY col.name, c.created_at, c.uuid
+	`, orgID, userID)
+	if err != nil {
+		slog.Error("failed to list organization credentials", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := make([]internal.OrgCredential, 0)
+	for rows.Next() {
+		var cred internal.OrgCredential
+		cred.Credential, err = scanCredential(tailScanner{
+			row:   rows,
+			extra: []any{&cred.CollectionID, &cred.RotationRequired, &cred.TeamAccess},
+		})
+		if err != nil {
+			return nil, err
+		}
+		cred.FolderID = ""
+		cred.Favorite = false
+		creds = append(creds, cred)
+	}
+
+	return creds, rows.Err()
+}
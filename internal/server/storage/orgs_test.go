@@ -0,0 +1,327 @@
+package internal_test
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	ownerID := uuid.New().String()
+	org := models.Organization{ID: uuid.New().String(), Name: "acme"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO organizations (uuid, name) VALUES ($1, $2)")).
+		WithArgs(org.ID, org.Name).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)")).
+		WithArgs(org.ID, ownerID, models.OrgRoleOwner).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.CreateOrganization(context.Background(), org, ownerID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrganizationAlreadyExists(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	org := models.Organization{ID: uuid.New().String(), Name: "acme"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO organizations")).
+		WithArgs(org.ID, org.Name).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.CreateOrganization(context.Background(), org, uuid.New().String()), storage.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptInvite(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, orgID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM org_invites WHERE org_id = $1 AND user_id = $2 RETURNING role")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("read-only"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)")).
+		WithArgs(orgID, userID, models.OrgRoleReadOnly).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AcceptInvite(context.Background(), userID, orgID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptInviteNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, orgID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM org_invites")).
+		WithArgs(orgID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.AcceptInvite(context.Background(), userID, orgID), storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveMemberLastOwner(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	orgID, ownerID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM org_members WHERE org_id = $1 AND user_id = $2 FOR UPDATE")).
+		WithArgs(orgID, ownerID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("owner"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM org_members WHERE org_id = $1 AND role = 'owner'")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err = store.RemoveMember(context.Background(), orgID, ownerID, true)
+	assert.ErrorIs(t, err, storage.ErrLastOwner)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveMemberFlagsCredentials(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	orgID, memberID := uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT role FROM org_members")).
+		WithArgs(orgID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_rotations (credential_id)")).
+		WithArgs(orgID, memberID, false).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_shares WHERE grantee_id = $2")).
+		WithArgs(orgID, memberID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM team_members WHERE user_id = $2")).
+		WithArgs(orgID, memberID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM org_members WHERE org_id = $1 AND user_id = $2")).
+		WithArgs(orgID, memberID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	flagged, err := store.RemoveMember(context.Background(), orgID, memberID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), flagged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCollectionUnknownTeam(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	collection := models.Collection{ID: uuid.New().String(), OrgID: uuid.New().String(), Name: "prod", Teams: []string{"ops"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO collections (uuid, org_id, name) VALUES ($1, $2, $3)")).
+		WithArgs(collection.ID, collection.OrgID, collection.Name).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO collection_teams (collection_id, team_id)")).
+		WithArgs(collection.ID, collection.OrgID, "ops").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.CreateCollection(context.Background(), collection), storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToCollectionTransfersOwnership(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT org_id IS NULL AND data LIKE $2 FROM credentials WHERE uuid = $1 FOR UPDATE")).
+		WithArgs(credentialID, "sealed:%").
+		WillReturnRows(sqlmock.NewRows([]string{"sealed"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO collection_credentials (credential_id, collection_id)")).
+		WithArgs(credentialID, collectionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET user_id = NULL, org_id = $2 WHERE uuid = $1 AND org_id IS NULL")).
+		WithArgs(credentialID, orgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"credential_folders", "credential_favorites", "credential_search_index", "credential_shares"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE credential_id = $1")).
+			WithArgs(credentialID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AddToCollection(context.Background(), credentialID, orgID, collectionID, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToCollectionMovesOrgCredential(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	// Запись уже принадлежит организации: владелец не меняется
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT org_id IS NULL AND data LIKE $2")).
+		WithArgs(credentialID, "sealed:%").
+		WillReturnRows(sqlmock.NewRows([]string{"sealed"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO collection_credentials (credential_id, collection_id)")).
+		WithArgs(credentialID, collectionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET user_id = NULL")).
+		WithArgs(credentialID, orgID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AddToCollection(context.Background(), credentialID, orgID, collectionID, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToCollectionSealedCredential(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	// Организация не может прочитать данные, зашифрованные ключом записи
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT org_id IS NULL AND data LIKE $2")).
+		WithArgs(credentialID, "sealed:%").
+		WillReturnRows(sqlmock.NewRows([]string{"sealed"}).AddRow(true))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.AddToCollection(context.Background(), credentialID, orgID, collectionID, ""), storage.ErrSealedCredential)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToCollectionUnsealsCredential(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	// Расшифрованные данные заменяют зашифрованные в той же транзакции, что и передача
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT org_id IS NULL AND data LIKE $2")).
+		WithArgs(credentialID, "sealed:%").
+		WillReturnRows(sqlmock.NewRows([]string{"sealed"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO collection_credentials (credential_id, collection_id)")).
+		WithArgs(credentialID, collectionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET user_id = NULL")).
+		WithArgs(credentialID, orgID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET data = $2 WHERE uuid = $1")).
+		WithArgs(credentialID, "login: alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"credential_folders", "credential_favorites", "credential_search_index", "credential_shares"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE credential_id = $1")).
+			WithArgs(credentialID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.AddToCollection(context.Background(), credentialID, orgID, collectionID, "login: alice"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCollection(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM collections col")).
+		WithArgs(orgID, userID, "prod").
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "org_id", "name", "teams", "team_access"}).
+			AddRow(collectionID, orgID, "prod", "dev\x1fops", false))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM collections col")).
+		WithArgs(orgID, userID, "missing").
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "org_id", "name", "teams", "team_access"}))
+
+	collection, err := store.GetCollection(context.Background(), userID, orgID, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Collection{ID: collectionID, OrgID: orgID, Name: "prod", Teams: []string{"dev", "ops"}}, collection)
+
+	_, err = store.GetCollection(context.Background(), userID, orgID, "missing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListOrgCredentials(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, orgID, collectionID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	cred := models.Credential{ID: uuid.New().String(), UserID: uuid.New().String(), Type: models.CredentialTypeLogin, Tags: []string{}}
+
+	rows := sqlmock.NewRows([]string{"uuid", "user_id", "type", "data", "meta", "created_at", "updated_at", "tags",
+		"folder_id", "favorite", "collection_id", "rotation_required", "team_access"}).
+		AddRow(cred.ID, cred.UserID, cred.Type, cred.Data, cred.Meta, cred.CreatedAt, cred.UpdatedAt, "",
+			uuid.New().String(), true, collectionID, true, true)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM collection_credentials cc")).
+		WithArgs(orgID, userID).
+		WillReturnRows(rows)
+
+	creds, err := store.ListOrgCredentials(context.Background(), userID, orgID)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OrgCredential{{
+		Credential:       cred,
+		CollectionID:     collectionID,
+		RotationRequired: true,
+		TeamAccess:       true,
+	}}, creds)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// NextOTPCounter возвращает следующее значение счетчика HOTP записи credentialID.
+// Первый вызов сохраняет и возвращает initial - счетчик из ключа записи, каждый
+// следующий увеличивает сохраненное значение на единицу. Счетчик общий для всех
+// пользователей с правом изменять запись, поэтому коды не повторяются между их
+// устройствами. Возвращает ErrNotFound, если записи нет.
+func (s *StorageImpl) NextOTPCounter(ctx context.Context, credentialID string, initial int64) (int64, error) {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return 0, ErrNotFound
+	}
+
+	var counter int64
+	err := s.DB.QueryRowContext(ctx, `
+		INSERT INTO otp_counters (credential_id, counter)
+		SELECT uuid, $2 FROM credentials WHERE uuid = $1
+		ON CONFLICT (credential_id) DO UPDATE SET counter = otp_counters.counter + 1
+		RETURNING counter
+	`, credentialID, initial).Scan(&counter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		slog.Error("failed to advance otp counter", "error", err)
+		return 0, err
+	}
+	return counter, nil
+}
@@ -0,0 +1,52 @@
+package internal_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextOTPCounter(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID := uuid.New().String()
+
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO otp_counters (credential_id, counter)")).
+		WithArgs(credentialID, int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"counter"}).AddRow(int64(7)))
+
+	counter, err := store.NextOTPCounter(context.Background(), credentialID, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), counter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNextOTPCounterNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credentialID := uuid.New().String()
+
+	// Удаленная запись не возвращает строк
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO otp_counters")).
+		WithArgs(credentialID, int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"counter"}))
+
+	_, err = store.NextOTPCounter(context.Background(), credentialID, 0)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Некорректный идентификатор не доходит до базы данных
+	_, err = store.NextOTPCounter(context.Background(), "not-a-uuid", 0)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// Ограничения размера страницы при постраничной выдаче учетных данных.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// ErrInvalidPageToken - ошибка, возвращаемая при некорректном курсоре страницы.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrInvalidSort - ошибка, возвращаемая при неподдерживаемом поле сортировки.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// pageCursor - содержимое курсора страницы. Курсор указывает на последнюю
+// выданную запись и привязан к порядку сортировки, с которым был получен.
+type pageCursor struct {
+	SortBy string    `json:"s"`
+	Desc   bool      `json:"d"`
+	Value  time.Time `json:"v"`
+	ID     string    `json:"id"`
+}
+
+// encodePageToken сериализует курсор в непрозрачную строку.
+func encodePageToken(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageToken разбирает курсор и проверяет, что он соответствует
+// текущему порядку сортировки.
+func decodePageToken(token string, sortBy string, desc bool) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return c, ErrInvalidPageToken
+	}
+	if c.SortBy != sortBy || c.Desc != desc {
+		return c, ErrInvalidPageToken
+	}
+	return c, nil
+}
+
+// normalizeListOptions подставляет значения по умолчанию и проверяет параметры.
+func normalizeListOptions(opts internal.ListOptions) (internal.ListOptions, error) {
+	switch {
+	case opts.PageSize <= 0:
+		opts.PageSize = DefaultPageSize
+	case opts.PageSize > MaxPageSize:
+		opts.PageSize = MaxPageSize
+	}
+
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = internal.SortByCreatedAt
+	case internal.SortByCreatedAt, internal.SortByUpdatedAt:
+	default:
+		return opts, ErrInvalidSort
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// schema содержит SQL-выражения, приводящие базу данных к актуальному состоянию.
+// Все выражения идемпотентны, поэтому применяются при каждом подключении.
+// Новые изменения схемы добавляются в конец списка.
+var schema = []string{
+	// Таблица пользователей
+	`CREATE TABLE IF NOT EXISTS users (
+		uuid UUID PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL
+	)`,
+
+	// Таблица учетных данных пользователей
+	`CREATE TABLE IF NOT EXISTS credentials (
+		uuid UUID PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		data TEXT NOT NULL,
+		meta TEXT
+	)`,
+
+	// Тип записи и временные метки для сортировки и постраничной выдачи
+	`ALTER TABLE credentials ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'text'`,
+	`ALTER TABLE credentials ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`ALTER TABLE credentials ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`CREATE INDEX IF NOT EXISTS credentials_user_created_idx ON credentials (user_id, created_at, uuid)`,
+	`CREATE INDEX IF NOT EXISTS credentials_user_updated_idx ON credentials (user_id, updated_at, uuid)`,
+
+	// Теги учетных данных
+	`CREATE TABLE IF NOT EXISTS credential_tags (
+		credential_id UUID NOT NULL REFERENCES credentials(uuid) ON DELETE CASCADE,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (credential_id, tag)
+	)`,
+	`CREATE INDEX IF NOT EXISTS credential_tags_tag_idx ON credential_tags (tag)`,
+
+	// Слепой индекс для поиска: сервер хранит только HMAC терминов, вычисленные клиентом
+	`CREATE TABLE IF NOT EXISTS credential_search_index (
+		credential_id UUID NOT NULL REFERENCES credentials(uuid) ON DELETE CASCADE,
+		term TEXT NOT NULL,
+		PRIMARY KEY (credential_id, term)
+	)`,
+	`CREATE INDEX IF NOT EXISTS credential_search_index_term_idx ON credential_search_index (term)`,
+
+	// Дерево папок пользователя. Удаление папки удаляет все вложенные папки
+	`CREATE TABLE IF NOT EXISTS folders (
+		uuid UUID PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		parent_id UUID REFERENCES folders(uuid) ON DELETE CASCADE,
+		name TEXT NOT NULL
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS folders_user_parent_name_idx
+		ON folders (user_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), name)`,
+
+	// Размещение учетных данных в папках; при удалении папки записи переходят в корень
+	`CREATE TABLE IF NOT EXISTS credential_folders (
+		credential_id UUID PRIMARY KEY REFERENCES credentials(uuid) ON DELETE CASCADE,
+		folder_id UUID NOT NULL REFERENCES folders(uuid) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS credential_folders_folder_idx ON credential_folders (folder_id)`,
+
+	// Избранные учетные данные
+	`CREATE TABLE IF NOT EXISTS credential_favorites (
+		credential_id UUID PRIMARY KEY REFERENCES credentials(uuid) ON DELETE CASCADE
+	)`,
+
+	// Доступ к записям, выданный другим пользователям. encrypted_key - ключ записи,
+	// зашифрованный клиентом на открытом ключе получателя; сервер его не расшифровывает
+	`CREATE TABLE IF NOT EXISTS credential_shares (
+		credential_id UUID NOT NULL REFERENCES credentials(uuid) ON DELETE CASCADE,
+		grantee_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		permission TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+		encrypted_key BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (credential_id, grantee_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS credential_shares_grantee_idx ON credential_shares (grantee_id)`,
+
+	// Организации, их участники и приглашения
+	`CREATE TABLE IF NOT EXISTS organizations (
+		uuid UUID PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS org_members (
+		org_id UUID NOT NULL REFERENCES organizations(uuid) ON DELETE CASCADE,
+		user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read-only')),
+		PRIMARY KEY (org_id, user_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS org_members_user_idx ON org_members (user_id)`,
+	`CREATE TABLE IF NOT EXISTS org_invites (
+		org_id UUID NOT NULL REFERENCES organizations(uuid) ON DELETE CASCADE,
+		user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read-only')),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (org_id, user_id)
+	)`,
+
+	// Команды организации
+	`CREATE TABLE IF NOT EXISTS teams (
+		uuid UUID PRIMARY KEY,
+		org_id UUID NOT NULL REFERENCES organizations(uuid) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		UNIQUE (org_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS team_members (
+		team_id UUID NOT NULL REFERENCES teams(uuid) ON DELETE CASCADE,
+		user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+		PRIMARY KEY (team_id, user_id)
+	)`,
+
+	// Коллекции записей организации и команды, которым они доступны
+	`CREATE TABLE IF NOT EXISTS collections (
+		uuid UUID PRIMARY KEY,
+		org_id UUID NOT NULL REFERENCES organizations(uuid) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		UNIQUE (org_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS collection_teams (
+		collection_id UUID NOT NULL REFERENCES collections(uuid) ON DELETE CASCADE,
+		team_id UUID NOT NULL REFERENCES teams(uuid) ON DELETE CASCADE,
+		PRIMARY KEY (collection_id, team_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS collection_credentials (
+		credential_id UUID PRIMARY KEY REFERENCES credentials(uuid) ON DELETE CASCADE,
+		collection_id UUID NOT NULL REFERENCES collections(uuid) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS collection_credentials_collection_idx ON collection_credentials (collection_id)`,
+
+	// Записи, которые нужно сменить: к ним имел доступ удаленный участник организации.
+	// Отметка снимается при изменении данных записи
+	`CREATE TABLE IF NOT EXISTS credential_rotations (
+		credential_id UUID PRIMARY KEY REFERENCES credentials(uuid) ON DELETE CASCADE,
+		flagged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// Журнал аудита. Записи связаны хэш-цепочкой (см. пакет audit), а триггер
+	// запрещает их изменение и удаление. Идентификаторы хранятся текстом без внешних
+	// ключей, чтобы записи переживали удаление пользователей и учетных данных
+	`CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		created_at TIMESTAMPTZ NOT NULL,
+		actor_id TEXT NOT NULL DEFAULT '',
+		org_id TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		credential_id TEXT NOT NULL DEFAULT '',
+		client_ip TEXT NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		result TEXT NOT NULL,
+		prev_hash TEXT NOT NULL,
+		hash TEXT NOT NULL UNIQUE
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_id, id)`,
+	`CREATE INDEX IF NOT EXISTS audit_log_org_idx ON audit_log (org_id, id)`,
+	`CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log`,
+	`CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only()`,
+
+	// Счетчики HOTP записей. Хранятся на сервере, чтобы устройства пользователей
+	// с правом изменять запись не выдавали одинаковые коды
+	`CREATE TABLE IF NOT EXISTS otp_counters (
+		credential_id UUID PRIMARY KEY REFERENCES credentials(uuid) ON DELETE CASCADE,
+		counter BIGINT NOT NULL CHECK (counter >= 0)
+	)`,
+
+	// Открытые ключи пользователей, на которых клиенты шифруют ключи записей при выдаче
+	// доступа. Закрытые ключи хранятся только у клиентов
+	`CREATE TABLE IF NOT EXISTS user_keys (
+		user_id UUID PRIMARY KEY REFERENCES users(uuid) ON DELETE CASCADE,
+		public_key BYTEA NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// Владелец записи - пользователь или организация. Запись, помещенная в коллекцию,
+	// принадлежит организации и не зависит от членства добавившего её участника
+	`ALTER TABLE credentials ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(uuid) ON DELETE CASCADE`,
+	`ALTER TABLE credentials ALTER COLUMN user_id DROP NOT NULL`,
+	`UPDATE credentials c SET org_id = col.org_id, user_id = NULL
+		FROM collection_credentials cc JOIN collections col ON col.uuid = cc.collection_id
+		WHERE cc.credential_id = c.uuid AND c.org_id IS NULL`,
+	`ALTER TABLE credentials DROP CONSTRAINT IF EXISTS credentials_owner_check`,
+	`ALTER TABLE credentials ADD CONSTRAINT credentials_owner_check CHECK ((user_id IS NULL) <> (org_id IS NULL))`,
+	`CREATE INDEX IF NOT EXISTS credentials_org_idx ON credentials (org_id)`,
+}
+
+// migrate последовательно применяет выражения из schema.
+func (s *StorageImpl) migrate() error {
+	for i, stmt := range schema {
+		if _, err := s.DB.Exec(stmt); err != nil {
+			slog.Error("failed to apply schema statement", "error", err)
+			return fmt.Errorf("schema statement %d: %w", i, err)
+		}
+	}
+	return nil
+}
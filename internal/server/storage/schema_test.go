@@ -0,0 +1,228 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Выражения schema, из которых восстанавливается описание таблиц.
+var (
+	createTableRe   = regexp.MustCompile(`(?s)^CREATE TABLE IF NOT EXISTS (\w+) \((.*)\)$`)
+	addColumnRe     = regexp.MustCompile(`^ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (.*)$`)
+	dropNotNullRe   = regexp.MustCompile(`^ALTER TABLE (\w+) ALTER COLUMN (\w+) DROP NOT NULL$`)
+	dropCheckRe     = regexp.MustCompile(`^ALTER TABLE (\w+) DROP CONSTRAINT IF EXISTS (\w+)$`)
+	exactlyOneRe    = regexp.MustCompile(`^ALTER TABLE (\w+) ADD CONSTRAINT (\w+) CHECK \(\((\w+) IS NULL\) <> \((\w+) IS NULL\)\)$`)
+	uniqueIndexRe   = regexp.MustCompile(`(?s)^CREATE UNIQUE INDEX IF NOT EXISTS \w+\s+ON (\w+) \((.*)\)$`)
+	coalesceRe      = regexp.MustCompile(`^COALESCE\((\w+), .*\)$`)
+	tableKeyRe      = regexp.MustCompile(`^(PRIMARY KEY|UNIQUE) \(([\w, ]+)\)$`)
+	referencesRe    = regexp.MustCompile(`REFERENCES (\w+)\((\w+)\)( ON DELETE CASCADE)?`)
+	checkInRe       = regexp.MustCompile(`CHECK \((\w+) IN \(([^)]*)\)\)`)
+	checkNonNegRe   = regexp.MustCompile(`CHECK \((\w+) >= 0\)`)
+	defaultRe       = regexp.MustCompile(`DEFAULT ('[^']*'|now\(\))`)
+	quotedRe        = regexp.MustCompile(`'([^']*)'`)
+	ignoredSchemaRe = regexp.MustCompile(`^(CREATE INDEX|UPDATE|CREATE OR REPLACE FUNCTION|DROP TRIGGER|CREATE TRIGGER) `)
+)
+
+// columnTypes - типы столбцов PostgreSQL и их представление в резервной копии.
+var columnTypes = map[string]string{
+	"UUID":        internal.BackupUUID,
+	"TEXT":        internal.BackupText,
+	"TIMESTAMPTZ": internal.BackupTimestamp,
+	"BYTEA":       internal.BackupBytea,
+	"BIGINT":      internal.BackupBigint,
+	"BIGSERIAL":   internal.BackupBigint,
+}
+
+// splitTopLevel разбивает s по запятым вне скобок.
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// splitNames разбирает список имен столбцов "a, b".
+func splitNames(s string) []string {
+	names := strings.Split(s, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// schemaBuilder восстанавливает описание таблиц из выражений schema.
+type schemaBuilder struct {
+	t      *testing.T
+	tables []*internal.BackupTableSchema
+	checks map[string][]string // именованные ограничения CHECK: таблица/имя -> столбцы
+}
+
+func (b *schemaBuilder) table(name string) *internal.BackupTableSchema {
+	for _, t := range b.tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	b.t.Fatalf("schema changes unknown table %q", name)
+	return nil
+}
+
+// column разбирает определение столбца def таблицы t.
+func (b *schemaBuilder) column(t *internal.BackupTableSchema, def string) {
+	name, rest, _ := strings.Cut(def, " ")
+	typ, rest, _ := strings.Cut(rest, " ")
+	backupType, ok := columnTypes[typ]
+	if !ok {
+		b.t.Fatalf("table %s column %s: unsupported type %s", t.Name, name, typ)
+	}
+	c := internal.BackupColumn{Name: name, Type: backupType, Nullable: true}
+
+	if m := referencesRe.FindStringSubmatch(rest); m != nil {
+		t.References = append(t.References, internal.BackupReference{Column: name, Table: m[1], RefColumn: m[2]})
+		rest = strings.Replace(rest, m[0], "", 1)
+	}
+	if m := checkInRe.FindStringSubmatch(rest); m != nil && m[1] == name {
+		for _, v := range quotedRe.FindAllStringSubmatch(m[2], -1) {
+			c.Values = append(c.Values, v[1])
+		}
+		rest = strings.Replace(rest, m[0], "", 1)
+	}
+	if m := checkNonNegRe.FindStringSubmatch(rest); m != nil && m[1] == name {
+		c.NonNegative = true
+		rest = strings.Replace(rest, m[0], "", 1)
+	}
+	rest = defaultRe.ReplaceAllString(rest, "")
+	if strings.Contains(rest, "PRIMARY KEY") {
+		t.PrimaryKey = []string{name}
+		c.Nullable = false
+		rest = strings.Replace(rest, "PRIMARY KEY", "", 1)
+	}
+	if strings.Contains(rest, "NOT NULL") {
+		c.Nullable = false
+		rest = strings.Replace(rest, "NOT NULL", "", 1)
+	}
+	if strings.Contains(rest, "UNIQUE") {
+		t.Unique = append(t.Unique, []string{name})
+		rest = strings.Replace(rest, "UNIQUE", "", 1)
+	}
+	if strings.TrimSpace(rest) != "" {
+		b.t.Fatalf("table %s column %s: unsupported definition %q", t.Name, name, strings.TrimSpace(rest))
+	}
+	t.Columns = append(t.Columns, c)
+}
+
+// apply применяет к описанию таблиц выражение stmt.
+func (b *schemaBuilder) apply(stmt string) {
+	stmt = strings.Join(strings.Fields(stmt), " ")
+	stmt = strings.ReplaceAll(strings.ReplaceAll(stmt, "( ", "("), " )", ")")
+
+	switch {
+	case createTableRe.MatchString(stmt):
+		m := createTableRe.FindStringSubmatch(stmt)
+		t := &internal.BackupTableSchema{Name: m[1]}
+		for _, def := range splitTopLevel(m[2]) {
+			if k := tableKeyRe.FindStringSubmatch(def); k != nil {
+				if k[1] == "PRIMARY KEY" {
+					t.PrimaryKey = splitNames(k[2])
+				} else {
+					t.Unique = append(t.Unique, splitNames(k[2]))
+				}
+				continue
+			}
+			b.column(t, def)
+		}
+		b.tables = append(b.tables, t)
+	case addColumnRe.MatchString(stmt):
+		m := addColumnRe.FindStringSubmatch(stmt)
+		b.column(b.table(m[1]), m[2])
+	case dropNotNullRe.MatchString(stmt):
+		m := dropNotNullRe.FindStringSubmatch(stmt)
+		t := b.table(m[1])
+		for i := range t.Columns {
+			if t.Columns[i].Name == m[2] {
+				t.Columns[i].Nullable = true
+			}
+		}
+	case exactlyOneRe.MatchString(stmt):
+		m := exactlyOneRe.FindStringSubmatch(stmt)
+		b.table(m[1]).ExactlyOne = []string{m[3], m[4]}
+		b.checks[m[1]+"/"+m[2]] = []string{m[3], m[4]}
+	case dropCheckRe.MatchString(stmt):
+		m := dropCheckRe.FindStringSubmatch(stmt)
+		if _, ok := b.checks[m[1]+"/"+m[2]]; ok {
+			b.table(m[1]).ExactlyOne = nil
+			delete(b.checks, m[1]+"/"+m[2])
+		}
+	case uniqueIndexRe.MatchString(stmt):
+		m := uniqueIndexRe.FindStringSubmatch(stmt)
+		t := b.table(m[1])
+		var (
+			names    []string
+			coalesce bool
+		)
+		for _, expr := range splitTopLevel(m[2]) {
+			if c := coalesceRe.FindStringSubmatch(expr); c != nil {
+				expr, coalesce = c[1], true
+			}
+			names = append(names, expr)
+		}
+		if coalesce {
+			t.UniqueNull = append(t.UniqueNull, names)
+		} else {
+			t.Unique = append(t.Unique, names)
+		}
+	case ignoredSchemaRe.MatchString(stmt):
+	default:
+		b.t.Fatalf("unsupported schema statement, teach TestBackupSchemaMatchesSchema about it: %s", stmt)
+	}
+}
+
+// TestBackupSchemaMatchesSchema сверяет BackupSchema с таблицами, которые создают
+// выражения schema, чтобы изменение схемы базы данных без изменения описания
+// резервной копии не проходило незамеченным.
+func TestBackupSchemaMatchesSchema(t *testing.T) {
+	b := &schemaBuilder{t: t, checks: make(map[string][]string)}
+	for _, stmt := range schema {
+		b.apply(stmt)
+	}
+	require.Len(t, BackupSchema, len(b.tables), "every table of schema must be described in BackupSchema")
+
+	want := make(map[string]internal.BackupTableSchema, len(b.tables))
+	for _, table := range b.tables {
+		want[table.Name] = *table
+	}
+	loaded := make(map[string]bool, len(BackupSchema))
+	for _, table := range BackupSchema {
+		expected, ok := want[table.Name]
+		if assert.True(t, ok, "table %s is not in schema", table.Name) {
+			assert.Equal(t, expected, table, "table %s", table.Name)
+		}
+
+		// Таблицы загружаются по порядку, поэтому цели внешних ключей идут раньше
+		for _, r := range table.References {
+			assert.True(t, loaded[r.Table] || r.Table == table.Name,
+				"table %s references %s, which is restored later", table.Name, r.Table)
+		}
+		loaded[table.Name] = true
+	}
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// Ограничения на термины слепого индекса.
+const (
+	// MaxIndexTerms - максимальное количество терминов у одной записи.
+	MaxIndexTerms = 2048
+	// MaxQueryTerms - максимальное количество терминов в поисковом запросе.
+	MaxQueryTerms = 256
+	// searchTermLen - длина термина: HMAC-SHA256 в шестнадцатеричном виде.
+	searchTermLen = 64
+)
+
+// ErrInvalidSearchTerm - ошибка, возвращаемая при некорректном термине слепого индекса.
+var ErrInvalidSearchTerm = errors.New("invalid search term")
+
+// ValidateSearchTerms проверяет, что термины имеют формат HMAC-SHA256 в hex
+// и их количество не превышает limit.
+func ValidateSearchTerms(terms []string, limit int) error {
+	if len(terms) > limit {
+		return ErrInvalidSearchTerm
+	}
+	for _, term := range terms {
+		if len(term) != searchTermLen {
+			return ErrInvalidSearchTerm
+		}
+		for _, r := range term {
+			if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+				return ErrInvalidSearchTerm
+			}
+		}
+	}
+	return nil
+}
+
+// saveSearchTerms сохраняет термины слепого индекса записи внутри транзакции.
+func saveSearchTerms(ctx context.Context, tx *sql.Tx, credentialID string, terms []string) error {
+	for _, term := range terms {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO credential_search_index (credential_id, term) VALUES ($1, $2) ON CONFLICT DO NOTHING
+		`, credentialID, term)
+		if err != nil {
+			slog.Error("failed to save search term", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchCredentials возвращает учетные данные пользователя, у которых совпал хотя бы
+// один термин слепого индекса. Записи упорядочены по убыванию числа совпавших терминов,
+// затем по времени изменения.
+func (s *StorageImpl) SearchCredentials(ctx context.Context, userID string, terms []string, limit int) ([]internal.Credential, error) {
+	if len(terms) == 0 {
+		return make([]internal.Credential, 0), nil
+	}
+
+	switch {
+	case limit <= 0:
+		limit = DefaultPageSize
+	case limit > MaxPageSize:
+		limit = MaxPageSize
+	}
+
+	args := []any{userID}
+	placeholders := make([]string, 0, len(terms))
+	for _, term := range terms {
+		args = append(args, term)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	args = append(args, limit)
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+credentialColumns+`
+		FROM credentials c
+		JOIN (
+			SELECT credential_id, count(*) AS hits FROM credential_search_index
+			WHERE term IN (`+strings.Join(placeholders, ", ")+`)
+			GROUP BY credential_id
+		) m ON m.credential_id = c.uuid
+		WHERE c.user_id = $1
+		ORDER BY m.hits DESC, c.updated_at DESC, c.uuid
+		LIMIT $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		slog.Error("failed to search credentials", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := make([]internal.Credential, 0)
+	for rows.Next() {
+		cred, err := scanCredential(rows)
+		if err != nil {
+			slog.Error("failed to search credentials", "error", err)
+			return nil, err
+		}
+		credentials = append(credentials, cred)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Error("failed to search credentials", "error", err)
+		return nil, err
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+)
+
+// ErrUserNotFound - ошибка, возвращаемая, если получатель доступа не зарегистрирован.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrShareToSelf - ошибка, возвращаемая при попытке выдать доступ к записи её владельцу.
+var ErrShareToSelf = errors.New("cannot share credential with its owner")
+
+// ShareCredential выдает пользователю share.GranteeName доступ к записи владельца ownerID.
+// Повторная выдача доступа тому же пользователю заменяет уровень доступа и ключ записи.
+func (s *StorageImpl) ShareCredential(ctx context.Context, ownerID string, share internal.CredentialShare) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var granteeID string
+		err := tx.QueryRowContext(ctx, `SELECT uuid FROM users WHERE username = $1`, share.GranteeName).Scan(&granteeID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			slog.Error("failed to find grantee", "error", err)
+			return err
+		}
+		if granteeID == ownerID {
+			return ErrShareToSelf
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO credential_shares (credential_id, grantee_id, permission, encrypted_key)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (credential_id, grantee_id)
+			DO UPDATE SET permission = EXCLUDED.permission, encrypted_key = EXCLUDED.encrypted_key
+		`, share.CredentialID, granteeID, share.Permission, share.EncryptedKey)
+		if err != nil {
+			slog.Error("failed to share credential", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ErrSharesChanged - ошибка, возвращаемая, если новый ключ записи передан не всем
+// оставшимся получателям доступа или передан лишним пользователям.
+var ErrSharesChanged = errors.New("credential shares changed")
+
+// RevokeShare отзывает доступ пользователя granteeName к записи. Если передан rekey,
+// в той же транзакции данные записи заменяются данными, зашифрованными новым ключом
+// записи, а ключи оставшихся получателей - новым ключом, зашифрованным для них.
+// Набор получателей должен совпадать с rekey.EncryptedKeys, иначе возвращается
+// ErrSharesChanged.
+func (s *StorageImpl) RevokeShare(ctx context.Context, credentialID, granteeName string, rekey *internal.CredentialRekey) error {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return ErrNotFound
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM credential_shares s
+			USING users u
+			WHERE s.grantee_id = u.uuid AND s.credential_id = $1 AND u.username = $2
+		`, credentialID, granteeName)
+		if err != nil {
+			slog.Error("failed to revoke share", "error", err)
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrNotFound
+		}
+		if rekey == nil {
+			return nil
+		}
+
+		// Строка записи блокируется до замены ключей, поэтому параллельная смена
+		// ключа ждет завершения этой
+		_, err = tx.ExecContext(ctx, `
+			UPDATE credentials SET data = $2, updated_at = now() WHERE uuid = $1
+		`, credentialID, rekey.Data)
+		if err != nil {
+			slog.Error("failed to rekey credential", "error", err)
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT u.username FROM credential_shares s
+			JOIN users u ON u.uuid = s.grantee_id
+			WHERE s.credential_id = $1
+		`, credentialID)
+		if err != nil {
+			slog.Error("failed to list shares", "error", err)
+			return err
+		}
+		var remaining []string
+		for rows.Next() {
+			var name string
+			if err = rows.Scan(&name); err != nil {
+				rows.Close()
+				return err
+			}
+			remaining = append(remaining, name)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if len(remaining) != len(rekey.EncryptedKeys) {
+			return ErrSharesChanged
+		}
+
+		for _, name := range remaining {
+			key, ok := rekey.EncryptedKeys[name]
+			if !ok {
+				return ErrSharesChanged
+			}
+			_, err = tx.ExecContext(ctx, `
+				UPDATE credential_shares s SET encrypted_key = $3
+				FROM users u
+				WHERE s.grantee_id = u.uuid AND s.credential_id = $1 AND u.username = $2
+			`, credentialID, name, key)
+			if err != nil {
+				slog.Error("failed to rekey share", "error", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListShares возвращает пользователей, которым выдан доступ к записи.
+func (s *StorageImpl) ListShares(ctx context.Context, credentialID string) ([]internal.CredentialShare, error) {
+	if _, err := uuid.Parse(credentialID); err != nil {
+		return nil, ErrNotFound
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT s.credential_id, s.grantee_id, u.username, s.permission, s.encrypted_key, s.created_at
+		FROM credential_shares s
+		JOIN users u ON u.uuid = s.grantee_id
+		WHERE s.credential_id = $1
+		ORDER BY u.username
+	`, credentialID)
+	if err != nil {
+		slog.Error("failed to list shares", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := make([]internal.CredentialShare, 0)
+	for rows.Next() {
+		var share internal.CredentialShare
+		err = rows.Scan(&share.CredentialID, &share.GranteeID, &share.GranteeName, &share.Permission,
+			&share.EncryptedKey, &share.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
+
+// ListSharedWithMe возвращает записи других пользователей, к которым выдан доступ userID.
+// Папка и отметка «избранное» принадлежат владельцу записи, поэтому не возвращаются.
+func (s *StorageImpl) ListSharedWithMe(ctx context.Context, userID string) ([]internal.SharedCredential, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+credentialColumns+`, u.username, s.permission, s.encrypted_key
+		FROM credential_shares s
+		JOIN credentials c ON c.uuid = s.credential_id
+		JOIN users u ON u.uuid = c.user_id
+		WHERE s.grantee_id = $1
+		ORDER BY c.created_at, c.uuid
+	`, userID)
+	if err != nil {
+		slog.Error("failed to list shared credentials", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shared := make([]internal.SharedCredential, 0)
+	for rows.Next() {
+		var cred internal.SharedCredential
+		cred.Credential, err = scanCredential(tailScanner{
+			row:   rows,
+			extra: []any{&cred.OwnerName, &cred.Permission, &cred.EncryptedKey},
+		})
+		if err != nil {
+			return nil, err
+		}
+		cred.FolderID = ""
+		cred.Favorite = false
+		shared = append(shared, cred)
+	}
+
+	return shared, rows.Err()
+}
+
+// tailScanner дополняет список получателей Scan столбцами, выбранными после credentialColumns.
+type tailScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+// Scan считывает столбцы записи и дополнительные столбцы.
+func (t tailScanner) Scan(dest ...any) error {
+	return t.row.Scan(append(dest, t.extra...)...)
+}
@@ -0,0 +1,193 @@
+package internal_test
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	models "github.com/sol1corejz/goph-keeper/internal/server/models"
+	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestShareCredential(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	ownerID, granteeID := uuid.New().String(), uuid.New().String()
+	share := models.CredentialShare{
+		CredentialID: uuid.New().String(),
+		GranteeName:  "colleague",
+		Permission:   models.SharePermissionWrite,
+		EncryptedKey: []byte("wrapped"),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT uuid FROM users WHERE username = $1")).
+		WithArgs(share.GranteeName).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid"}).AddRow(granteeID))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_shares (credential_id, grantee_id, permission, encrypted_key)")).
+		WithArgs(share.CredentialID, granteeID, share.Permission, share.EncryptedKey).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.ShareCredential(context.Background(), ownerID, share))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareCredentialErrors(t *testing.T) {
+	ownerID := uuid.New().String()
+	share := models.CredentialShare{CredentialID: uuid.New().String(), GranteeName: "someone", Permission: models.SharePermissionRead}
+
+	tests := []struct {
+		name    string
+		grantee *sqlmock.Rows
+		want    error
+	}{
+		{name: "unknown user", grantee: sqlmock.NewRows([]string{"uuid"}), want: storage.ErrUserNotFound},
+		{name: "owner", grantee: sqlmock.NewRows([]string{"uuid"}).AddRow(ownerID), want: storage.ErrShareToSelf},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			store := &storage.StorageImpl{DB: mockDB}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT uuid FROM users WHERE username = $1")).
+				WithArgs(share.GranteeName).
+				WillReturnRows(tt.grantee)
+			mock.ExpectRollback()
+
+			assert.ErrorIs(t, store.ShareCredential(context.Background(), ownerID, share), tt.want)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRevokeShareNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credID := uuid.New().String()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_shares s")).
+		WithArgs(credID, "colleague").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.RevokeShare(context.Background(), credID, "colleague", nil), storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeShareRekey(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	credID := uuid.New().String()
+	rekey := &models.CredentialRekey{
+		Data:          "sealed:v1:new",
+		EncryptedKeys: map[string][]byte{"bob": []byte("wrapped for bob")},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_shares s")).
+		WithArgs(credID, "colleague").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET data = $2, updated_at = now() WHERE uuid = $1")).
+		WithArgs(credID, rekey.Data).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT u.username FROM credential_shares s")).
+		WithArgs(credID).
+		WillReturnRows(sqlmock.NewRows([]string{"username"}).AddRow("bob"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE credential_shares s SET encrypted_key = $3")).
+		WithArgs(credID, "bob", []byte("wrapped for bob")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.RevokeShare(context.Background(), credID, "colleague", rekey))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeShareRekeySharesChanged(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining *sqlmock.Rows
+	}{
+		{name: "new grantee", remaining: sqlmock.NewRows([]string{"username"}).AddRow("bob").AddRow("carol")},
+		{name: "other grantee", remaining: sqlmock.NewRows([]string{"username"}).AddRow("carol")},
+		{name: "grantee removed", remaining: sqlmock.NewRows([]string{"username"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockDB.Close()
+
+			store := &storage.StorageImpl{DB: mockDB}
+			credID := uuid.New().String()
+			rekey := &models.CredentialRekey{
+				Data:          "sealed:v1:new",
+				EncryptedKeys: map[string][]byte{"bob": []byte("wrapped for bob")},
+			}
+
+			// Ключ должен получить каждый оставшийся получатель и только он,
+			// иначе смена ключа отменяется вместе с отзывом доступа
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_shares s")).
+				WithArgs(credID, "colleague").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET data = $2")).
+				WithArgs(credID, rekey.Data).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT u.username FROM credential_shares s")).
+				WithArgs(credID).
+				WillReturnRows(tt.remaining)
+			mock.ExpectRollback()
+
+			assert.ErrorIs(t, store.RevokeShare(context.Background(), credID, "colleague", rekey), storage.ErrSharesChanged)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListSharedWithMe(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID, ownerID := uuid.New().String(), uuid.New().String()
+	cred := models.Credential{ID: uuid.New().String(), UserID: ownerID, Type: models.CredentialTypeLogin, Data: "db password", Tags: []string{"prod"}}
+
+	rows := sqlmock.NewRows([]string{"uuid", "user_id", "type", "data", "meta", "created_at", "updated_at", "tags",
+		"folder_id", "favorite", "username", "permission", "encrypted_key"}).
+		AddRow(cred.ID, cred.UserID, cred.Type, cred.Data, cred.Meta, cred.CreatedAt, cred.UpdatedAt,
+			strings.Join(cred.Tags, "\x1f"), uuid.New().String(), true, "owner", "read", nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credential_shares s")).
+		WithArgs(userID).
+		WillReturnRows(rows)
+
+	shared, err := store.ListSharedWithMe(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SharedCredential{{
+		Credential: cred,
+		OwnerName:  "owner",
+		Permission: models.SharePermissionRead,
+	}}, shared)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
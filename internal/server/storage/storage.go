@@ -1,27 +1,115 @@
 package internal
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	log "github.com/gofiber/fiber/v2/log"
+	"github.com/XSAM/otelsql"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/sol1corejz/goph-keeper/configs"
 	internal "github.com/sol1corejz/goph-keeper/internal/server/models"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"log/slog"
+	"strconv"
+	"strings"
 )
 
 // Storage - интерфейс хранилища данных, предоставляющий методы для работы с пользователями и учетными данными.
 // Используется для расширяемости и удобства тестирования.
 type Storage interface {
-	// CreateUser добавляет нового пользователя в хранилище.
-	CreateUser(user internal.User) error
+	// CreateUser добавляет нового пользователя и его открытый ключ в хранилище.
+	CreateUser(ctx context.Context, user internal.User, publicKey []byte) error
 	// GetUser получает данные пользователя по имени пользователя.
-	GetUser(username string) (internal.User, error)
+	GetUser(ctx context.Context, username string) (internal.User, error)
 	// SaveCredential сохраняет учетные данные пользователя.
-	SaveCredential(cred internal.Credential) error
+	SaveCredential(ctx context.Context, cred internal.Credential) error
+	// SaveCredentials сохраняет несколько записей пользователя в одной транзакции.
+	SaveCredentials(ctx context.Context, creds []internal.Credential) error
 	// EditCredential сохраняет учетные данные пользователя.
-	EditCredential(cred internal.Credential) error
+	EditCredential(ctx context.Context, cred internal.Credential) error
 	// GetCredentials возвращает все учетные данные пользователя.
-	GetCredentials(userID string) ([]internal.Credential, error)
+	GetCredentials(ctx context.Context, userID string) ([]internal.Credential, error)
+	// ListCredentials возвращает страницу учетных данных пользователя с учетом фильтров и сортировки.
+	ListCredentials(ctx context.Context, userID string, opts internal.ListOptions) (internal.CredentialsPage, error)
+	// SearchCredentials возвращает учетные данные пользователя, совпавшие по терминам слепого индекса.
+	SearchCredentials(ctx context.Context, userID string, terms []string, limit int) ([]internal.Credential, error)
+	// UpdateCredentialTags добавляет и удаляет теги записи.
+	UpdateCredentialTags(ctx context.Context, credentialID string, add, remove, searchTerms []string) error
+	// SetFavorite устанавливает или снимает отметку «избранное» у записи.
+	SetFavorite(ctx context.Context, credentialID string, favorite bool) error
+	// SetCredentialFolder помещает запись в папку.
+	SetCredentialFolder(ctx context.Context, userID, credentialID, folderID string) error
+	// CreateFolder создает папку пользователя.
+	CreateFolder(ctx context.Context, folder internal.Folder) error
+	// ListFolders возвращает все папки пользователя.
+	ListFolders(ctx context.Context, userID string) ([]internal.Folder, error)
+	// FindFolderByPath возвращает папку пользователя по пути.
+	FindFolderByPath(ctx context.Context, userID, path string) (internal.Folder, error)
+	// RenameFolder переименовывает папку.
+	RenameFolder(ctx context.Context, userID, folderID, name string) error
+	// MoveFolder перемещает папку в другую папку.
+	MoveFolder(ctx context.Context, userID, folderID, parentID string) error
+	// DeleteFolder удаляет папку вместе с вложенными папками.
+	DeleteFolder(ctx context.Context, userID, folderID string) error
+	// ShareCredential выдает другому пользователю доступ к записи.
+	ShareCredential(ctx context.Context, ownerID string, share internal.CredentialShare) error
+	// RevokeShare отзывает доступ пользователя к записи и при необходимости меняет её ключ.
+	RevokeShare(ctx context.Context, credentialID, granteeName string, rekey *internal.CredentialRekey) error
+	// ListShares возвращает выданные доступы к записи.
+	ListShares(ctx context.Context, credentialID string) ([]internal.CredentialShare, error)
+	// ListSharedWithMe возвращает записи других пользователей, доступные пользователю.
+	ListSharedWithMe(ctx context.Context, userID string) ([]internal.SharedCredential, error)
+	// SetPublicKey сохраняет открытый ключ пользователя.
+	SetPublicKey(ctx context.Context, userID string, key []byte) error
+	// GetPublicKey возвращает открытый ключ пользователя по имени.
+	GetPublicKey(ctx context.Context, username string) ([]byte, error)
+	// CreateOrganization создает организацию с владельцем ownerID.
+	CreateOrganization(ctx context.Context, org internal.Organization, ownerID string) error
+	// ListOrganizations возвращает организации пользователя.
+	ListOrganizations(ctx context.Context, userID string) ([]internal.Organization, error)
+	// GetOrgRole возвращает роль пользователя в организации.
+	GetOrgRole(ctx context.Context, orgID, userID string) (internal.OrgRole, error)
+	// GetOrgMember возвращает участника организации по имени пользователя.
+	GetOrgMember(ctx context.Context, orgID, username string) (internal.OrgMember, error)
+	// ListMembers возвращает участников организации.
+	ListMembers(ctx context.Context, orgID string) ([]internal.OrgMember, error)
+	// InviteMember приглашает пользователя в организацию.
+	InviteMember(ctx context.Context, orgID, username string, role internal.OrgRole) error
+	// ListInvites возвращает приглашения пользователя.
+	ListInvites(ctx context.Context, userID string) ([]internal.OrgInvite, error)
+	// AcceptInvite принимает приглашение в организацию.
+	AcceptInvite(ctx context.Context, userID, orgID string) error
+	// RemoveMember удаляет участника и отмечает доступные ему записи для смены.
+	RemoveMember(ctx context.Context, orgID, memberID string, allCollections bool) (int64, error)
+	// CreateTeam создает команду организации.
+	CreateTeam(ctx context.Context, team internal.Team) error
+	// AddTeamMember добавляет участника организации в команду.
+	AddTeamMember(ctx context.Context, orgID, teamName, username string) error
+	// CreateCollection создает коллекцию организации.
+	CreateCollection(ctx context.Context, collection internal.Collection) error
+	// ListCollections возвращает коллекции организации с отметкой доступа пользователя через команды.
+	ListCollections(ctx context.Context, userID, orgID string) ([]internal.Collection, error)
+	// GetCollection возвращает коллекцию организации по имени.
+	GetCollection(ctx context.Context, userID, orgID, name string) (internal.Collection, error)
+	// AddToCollection помещает запись в коллекцию организации и передает её организации.
+	AddToCollection(ctx context.Context, credentialID, orgID, collectionID, data string) error
+	// ListOrgCredentials возвращает записи организации с отметкой доступа пользователя через команды.
+	ListOrgCredentials(ctx context.Context, userID, orgID string) ([]internal.OrgCredential, error)
+	// AppendAudit добавляет событие в журнал аудита.
+	AppendAudit(ctx context.Context, entry internal.AuditEntry) error
+	// ListAudit возвращает записи журнала аудита пользователя или организации.
+	ListAudit(ctx context.Context, filter internal.AuditFilter) ([]internal.AuditEntry, error)
+	// ScanAudit возвращает записи журнала аудита по возрастанию номеров.
+	ScanAudit(ctx context.Context, afterID int64, limit int) ([]internal.AuditEntry, error)
+	// CredentialAccess возвращает отношение пользователя к записи для проверки прав.
+	CredentialAccess(ctx context.Context, userID, credentialID string) (internal.CredentialAccess, error)
+	// NextOTPCounter возвращает следующее значение счетчика HOTP записи.
+	NextOTPCounter(ctx context.Context, credentialID string, initial int64) (int64, error)
+	// DumpTables возвращает строки всех таблиц из согласованного снимка хранилища.
+	DumpTables(ctx context.Context) ([]internal.BackupTable, error)
+	// RestoreTables загружает строки таблиц в хранилище одной транзакцией.
+	RestoreTables(ctx context.Context, tables []internal.BackupTable, replace bool) error
 }
 
 // StorageImpl - реализация интерфейса Storage, использующая базу данных PostgreSQL.
@@ -44,76 +132,68 @@ func (s *StorageImpl) ConnectDB(cfg *configs.ServerConfig) error {
 		return errors.New("no connection string provided")
 	}
 
-	// Открываем соединение с базой данных PostgreSQL
-	db, err := sql.Open("pgx", cfg.Storage.ConnectionString)
+	// Открываем соединение с базой данных PostgreSQL. Каждый запрос записывается
+	// участком трассировки с текстом SQL без значений параметров.
+	db, err := otelsql.Open("pgx", cfg.Storage.ConnectionString,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			OmitConnectorConnect: true,
+		}),
+	)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to open database", "error", err)
 		return err
 	}
 
 	s.DB = db
 	DBStorage.DB = db
 
-	// Создаем таблицу пользователей, если она отсутствует
-	_, err = s.DB.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-    		uuid UUID PRIMARY KEY,
-    		username TEXT NOT NULL UNIQUE,
-    		password TEXT NOT NULL
-		)
-	`)
-	if err != nil {
-		log.Fatal("failed to create users table:", err)
-		return err
-	}
-
-	// Создаем таблицу учетных данных пользователей
-	_, err = s.DB.Exec(`
-		CREATE TABLE IF NOT EXISTS credentials (
-			uuid UUID PRIMARY KEY,
-			user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
-			data TEXT NOT NULL,
-			meta TEXT
-		)
-	`)
-	if err != nil {
-		log.Fatal("failed to create credentials table:", err)
-		return err
-	}
-
-	return nil
+	// Создаем необходимые таблицы и применяем изменения схемы
+	return s.migrate()
 }
 
-// CreateUser добавляет нового пользователя в базу данных.
-func (s *StorageImpl) CreateUser(user internal.User) error {
-	var exists bool
-	err := s.DB.QueryRow(`
-        SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)
-    `, user.Username).Scan(&exists)
-	if err != nil {
-		log.Error("failed to check user: ", err)
-		return err
-	}
-	if exists {
-		return ErrAlreadyExists
-	}
+// CreateUser добавляет нового пользователя в базу данных вместе с открытым ключом
+// publicKey, если он передан. Пользователь и ключ сохраняются одной транзакцией:
+// при ошибке сохранения ключа пользователь не создается.
+// Уникальность имени пользователя обеспечивается ограничением UNIQUE в таблице users,
+// поэтому одновременные регистрации с одинаковым именем не приводят к гонке:
+// одна из них завершается успешно, остальные получают ErrAlreadyExists.
+func (s *StorageImpl) CreateUser(ctx context.Context, user internal.User, publicKey []byte) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)
+		`, user.ID, user.Username, user.Password)
 
-	_, err = s.DB.Exec(`
-		INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)
-	`, user.ID, user.Username, user.Password)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyExists
+			}
+			slog.Error("failed to create user", "error", err)
+			return err
+		}
 
-	if err != nil {
-		log.Info("failed to create user")
-		return err
-	}
+		if publicKey == nil {
+			return nil
+		}
 
-	return nil
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2)
+		`, user.ID, publicKey)
+		if err != nil {
+			slog.Error("failed to save public key", "error", err)
+			return err
+		}
+
+		return nil
+	})
 }
 
 // GetUser получает данные пользователя по имени пользователя.
-func (s *StorageImpl) GetUser(username string) (internal.User, error) {
+func (s *StorageImpl) GetUser(ctx context.Context, username string) (internal.User, error) {
 	var user internal.User
-	err := s.DB.QueryRow(`
+	err := s.DB.QueryRowContext(ctx, `
 		SELECT * FROM users WHERE username=$1
 	`, username).Scan(&user.ID, &user.Username, &user.Password)
 
@@ -127,65 +207,297 @@ func (s *StorageImpl) GetUser(username string) (internal.User, error) {
 	return user, nil
 }
 
-// SaveCredential сохраняет учетные данные пользователя в базе данных.
-func (s *StorageImpl) SaveCredential(cred internal.Credential) error {
-	_, err := s.DB.Exec(`
-		INSERT INTO credentials (uuid, user_id, data, meta) VALUES ($1, $2, $3, $4)
-	`, cred.ID, cred.UserID, cred.Data, cred.Meta)
+// credentialColumns - список столбцов, выбираемых при чтении учетных данных.
+// Теги собираются в одну строку, разделенную символом tagSeparator. У записей
+// организации владельца-пользователя нет, user_id возвращается пустой строкой.
+const credentialColumns = `c.uuid, COALESCE(c.user_id::text, ''), c.type, c.data, c.meta, c.created_at, c.updated_at,
+	COALESCE((SELECT string_agg(t.tag, E'\x1f' ORDER BY t.tag) FROM credential_tags t WHERE t.credential_id = c.uuid), ''),
+	COALESCE((SELECT cf.folder_id::text FROM credential_folders cf WHERE cf.credential_id = c.uuid), ''),
+	EXISTS(SELECT 1 FROM credential_favorites fv WHERE fv.credential_id = c.uuid)`
+
+// tagSeparator - разделитель тегов в результате string_agg.
+const tagSeparator = "\x1f"
+
+// rowScanner - общий интерфейс для *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+// scanCredential считывает учетные данные из строки результата запроса,
+// выбранной со столбцами credentialColumns.
+func scanCredential(row rowScanner) (internal.Credential, error) {
+	var (
+		cred internal.Credential
+		meta sql.NullString
+		tags string
+	)
+	err := row.Scan(&cred.ID, &cred.UserID, &cred.Type, &cred.Data, &meta, &cred.CreatedAt, &cred.UpdatedAt, &tags,
+		&cred.FolderID, &cred.Favorite)
 	if err != nil {
-		log.Info("failed to save credential", err.Error())
-		return err
+		return internal.Credential{}, err
+	}
+	cred.Meta = meta.String
+	cred.Tags = make([]string, 0)
+	if tags != "" {
+		cred.Tags = strings.Split(tags, tagSeparator)
 	}
+	return cred, nil
+}
+
+// SaveCredential сохраняет учетные данные пользователя и их теги в базе данных.
+func (s *StorageImpl) SaveCredential(ctx context.Context, cred internal.Credential) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		return insertCredential(ctx, tx, cred)
+	})
+}
+
+// Ограничения пакетного добавления: MaxBatchSize - количество записей в одном
+// сообщении, MaxImportSize и MaxImportBytes - количество записей и размер сообщений
+// одного вызова, записи которого сохраняются одной транзакцией SaveCredentials.
+const (
+	MaxBatchSize   = 5000
+	MaxImportSize  = 100000
+	MaxImportBytes = 64 << 20
+)
 
-	return nil
+// SaveCredentials сохраняет несколько записей в одной транзакции: если не удалось
+// сохранить любую из них, не сохраняется ни одна. Записи с пустым FolderID
+// помещаются в папку FolderPath: недостающие папки создаются в той же транзакции
+// и тоже не сохраняются при откате.
+func (s *StorageImpl) SaveCredentials(ctx context.Context, creds []internal.Credential) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		folders := make(map[string]string)
+		for _, cred := range creds {
+			var err error
+			if cred.FolderID == "" && cred.FolderPath != "" {
+				cred.FolderID, err = ensureFolderPath(ctx, tx, cred.UserID, cred.FolderPath, folders)
+				if err != nil {
+					return err
+				}
+			}
+			if err = insertCredential(ctx, tx, cred); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// EditCredential обновляет учетные данные пользователя в базе данных.
-func (s *StorageImpl) EditCredential(cred internal.Credential) error {
-	_, err := s.DB.Exec(`
-		UPDATE credentials SET data = $1, meta = $2 WHERE uuid = $3
-	`, cred.Data, cred.Meta, cred.ID)
+// insertCredential добавляет запись вместе с тегами, папкой, отметкой «избранное»
+// и слепым индексом в транзакции tx.
+func insertCredential(ctx context.Context, tx *sql.Tx, cred internal.Credential) error {
+	if cred.Type == "" {
+		cred.Type = internal.DefaultCredentialType
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO credentials (uuid, user_id, type, data, meta) VALUES ($1, $2, $3, $4, $5)
+	`, cred.ID, cred.UserID, cred.Type, cred.Data, cred.Meta)
 
 	if err != nil {
-		log.Info("failed to save credential", err.Error())
+		slog.Error("failed to save credential", "error", err)
 		return err
 	}
 
-	return nil
+	for _, tag := range cred.Tags {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO credential_tags (credential_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING
+		`, cred.ID, tag)
+		if err != nil {
+			slog.Error("failed to save credential tag", "error", err)
+			return err
+		}
+	}
+
+	if cred.FolderID != "" {
+		if err = setCredentialFolder(ctx, tx, cred.UserID, cred.ID, cred.FolderID); err != nil {
+			return err
+		}
+	}
+
+	if cred.Favorite {
+		if err = setFavorite(ctx, tx, cred.ID, true); err != nil {
+			return err
+		}
+	}
+
+	return saveSearchTerms(ctx, tx, cred.ID, cred.SearchTerms)
+}
+
+// EditCredential обновляет учетные данные в базе данных; право изменять запись проверяет
+// пакет authz. Возвращает ErrNotFound, если записи нет. Изменение снимает отметку
+// о необходимости смены. Слепой индекс заменяется только владельцем, так как он
+// вычислен на ключе владельца.
+func (s *StorageImpl) EditCredential(ctx context.Context, cred internal.Credential) error {
+	if _, err := uuid.Parse(cred.ID); err != nil {
+		return ErrNotFound
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var ownerID string
+		err := tx.QueryRowContext(ctx, `
+			UPDATE credentials SET data = $1, meta = $2, updated_at = now()
+			WHERE uuid = $3
+			RETURNING COALESCE(user_id::text, '')
+		`, cred.Data, cred.Meta, cred.ID).Scan(&ownerID)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			slog.Error("failed to save credential", "error", err)
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, `DELETE FROM credential_rotations WHERE credential_id = $1`, cred.ID); err != nil {
+			slog.Error("failed to clear rotation flag", "error", err)
+			return err
+		}
+
+		if cred.SearchTerms == nil || ownerID != cred.UserID {
+			return nil
+		}
+
+		if _, err = tx.ExecContext(ctx, `DELETE FROM credential_search_index WHERE credential_id = $1`, cred.ID); err != nil {
+			slog.Error("failed to clear search index", "error", err)
+			return err
+		}
+
+		return saveSearchTerms(ctx, tx, cred.ID, cred.SearchTerms)
+	})
 }
 
 // GetCredentials получает все учетные данные, принадлежащие пользователю.
-func (s *StorageImpl) GetCredentials(userID string) ([]internal.Credential, error) {
-	rows, err := s.DB.Query(`
-		SELECT uuid, user_id, data, meta FROM credentials WHERE user_id=$1
+func (s *StorageImpl) GetCredentials(ctx context.Context, userID string) ([]internal.Credential, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+credentialColumns+` FROM credentials c WHERE c.user_id=$1 ORDER BY c.created_at, c.uuid
 	`, userID)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Info("failed to find credentials")
+			slog.Info("failed to find credentials")
 			return nil, ErrNotFound
 		}
-		log.Info("failed to retrieve credentials", err.Error())
+		slog.Error("failed to retrieve credentials", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
 
 	credentials := make([]internal.Credential, 0)
 	for rows.Next() {
-		var cred internal.Credential
-		err := rows.Scan(&cred.ID, &cred.UserID, &cred.Data, &cred.Meta)
+		cred, err := scanCredential(rows)
 		if err != nil {
-			log.Info("failed to retrieve credentials", err.Error())
+			slog.Error("failed to retrieve credentials", "error", err)
 			return nil, err
 		}
 		credentials = append(credentials, cred)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Info("failed to retrieve credentials", err.Error())
+		slog.Error("failed to retrieve credentials", "error", err)
 		return nil, err
 	}
 
 	return credentials, nil
 }
+
+// ListCredentials получает страницу учетных данных пользователя.
+// Используется курсорная (keyset) пагинация: курсор хранит значение поля сортировки
+// и идентификатор последней записи страницы, поэтому выборка не зависит от смещения
+// и не пропускает записи при вставках между запросами.
+func (s *StorageImpl) ListCredentials(ctx context.Context, userID string, opts internal.ListOptions) (internal.CredentialsPage, error) {
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return internal.CredentialsPage{}, err
+	}
+
+	var (
+		query strings.Builder
+		args  = []any{userID}
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	query.WriteString(`SELECT ` + credentialColumns + ` FROM credentials c WHERE c.user_id = $1`)
+
+	if opts.ID != "" {
+		// Некорректный идентификатор не может совпасть ни с одной записью
+		if _, err = uuid.Parse(opts.ID); err != nil {
+			return internal.CredentialsPage{Credentials: make([]internal.Credential, 0)}, nil
+		}
+		query.WriteString(` AND c.uuid = ` + arg(opts.ID))
+	}
+
+	if opts.Type != "" {
+		query.WriteString(` AND c.type = ` + arg(string(opts.Type)))
+	}
+	if opts.Tag != "" {
+		query.WriteString(` AND EXISTS (SELECT 1 FROM credential_tags t WHERE t.credential_id = c.uuid AND t.tag = ` + arg(opts.Tag) + `)`)
+	}
+	if opts.FolderID != "" {
+		query.WriteString(` AND EXISTS (SELECT 1 FROM credential_folders cf WHERE cf.credential_id = c.uuid AND cf.folder_id = ` + arg(opts.FolderID) + `)`)
+	}
+	if opts.Favorites {
+		query.WriteString(` AND EXISTS (SELECT 1 FROM credential_favorites fv WHERE fv.credential_id = c.uuid)`)
+	}
+
+	// Имя столбца берется только из фиксированного набора, проверенного normalizeListOptions
+	column := "c." + opts.SortBy
+	direction, cmp := "ASC", ">"
+	if opts.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	if opts.PageToken != "" {
+		cursor, err := decodePageToken(opts.PageToken, opts.SortBy, opts.Desc)
+		if err != nil {
+			return internal.CredentialsPage{}, err
+		}
+		query.WriteString(` AND (` + column + `, c.uuid) ` + cmp + ` (` + arg(cursor.Value) + `, ` + arg(cursor.ID) + `)`)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query.WriteString(` ORDER BY ` + column + ` ` + direction + `, c.uuid ` + direction)
+	query.WriteString(` LIMIT ` + arg(opts.PageSize+1))
+
+	rows, err := s.DB.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		slog.Error("failed to retrieve credentials", "error", err)
+		return internal.CredentialsPage{}, err
+	}
+	defer rows.Close()
+
+	page := internal.CredentialsPage{Credentials: make([]internal.Credential, 0, opts.PageSize)}
+	for rows.Next() {
+		cred, err := scanCredential(rows)
+		if err != nil {
+			slog.Error("failed to retrieve credentials", "error", err)
+			return internal.CredentialsPage{}, err
+		}
+		page.Credentials = append(page.Credentials, cred)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Error("failed to retrieve credentials", "error", err)
+		return internal.CredentialsPage{}, err
+	}
+
+	if len(page.Credentials) > opts.PageSize {
+		page.Credentials = page.Credentials[:opts.PageSize]
+		last := page.Credentials[len(page.Credentials)-1]
+		value := last.CreatedAt
+		if opts.SortBy == internal.SortByUpdatedAt {
+			value = last.UpdatedAt
+		}
+		page.NextPageToken = encodePageToken(pageCursor{
+			SortBy: opts.SortBy,
+			Desc:   opts.Desc,
+			Value:  value,
+			ID:     last.ID,
+		})
+	}
+
+	return page, nil
+}
@@ -1,14 +1,24 @@
 package internal_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sol1corejz/goph-keeper/configs"
 	models "github.com/sol1corejz/goph-keeper/internal/server/models"
 	storage "github.com/sol1corejz/goph-keeper/internal/server/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
 	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -28,19 +38,216 @@ func TestCreateUser(t *testing.T) {
 		Password: "password123",
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)")).
-		WithArgs(user.Username).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
+		WithArgs(user.ID, user.Username, user.Password).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = store.CreateUser(context.Background(), user, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserAlreadyExists(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	user := models.User{
+		ID:       uuid.New().String(),
+		Username: "testuser",
+		Password: "password123",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
+		WithArgs(user.ID, user.Username, user.Password).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_username_key"})
+	mock.ExpectRollback()
+
+	err = store.CreateUser(context.Background(), user, nil)
+	assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserDBError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	user := models.User{
+		ID:       uuid.New().String(),
+		Username: "testuser",
+		Password: "password123",
+	}
+
+	dbErr := &pgconn.PgError{Code: "08006"}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
+		WithArgs(user.ID, user.Username, user.Password).
+		WillReturnError(dbErr)
+	mock.ExpectRollback()
+
+	err = store.CreateUser(context.Background(), user, nil)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, storage.ErrAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserCommitError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	user := models.User{
+		ID:       uuid.New().String(),
+		Username: "testuser",
+		Password: "password123",
+	}
+
+	// После неудачной фиксации транзакция не откатывается повторно
+	commitErr := errors.New("connection reset")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
+		WithArgs(user.ID, user.Username, user.Password).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(commitErr)
+
+	err = store.CreateUser(context.Background(), user, nil)
+	assert.ErrorIs(t, err, commitErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserWithPublicKey(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	user := models.User{
+		ID:       uuid.New().String(),
+		Username: "testuser",
+		Password: "password123",
+	}
+	key := make([]byte, models.PublicKeySize)
 
+	// Пользователь и его ключ сохраняются одной транзакцией
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
 		WithArgs(user.ID, user.Username, user.Password).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2)")).
+		WithArgs(user.ID, key).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = store.CreateUser(user)
+	err = store.CreateUser(context.Background(), user, key)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateUserPublicKeyError(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	user := models.User{
+		ID:       uuid.New().String(),
+		Username: "testuser",
+		Password: "password123",
+	}
+	key := make([]byte, models.PublicKeySize)
+
+	// Ошибка сохранения ключа откатывает создание пользователя
+	dbErr := errors.New("connection reset")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (uuid, username, password) VALUES ($1, $2, $3)")).
+		WithArgs(user.ID, user.Username, user.Password).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO user_keys (user_id, public_key) VALUES ($1, $2)")).
+		WithArgs(user.ID, key).
+		WillReturnError(dbErr)
+	mock.ExpectRollback()
+
+	err = store.CreateUser(context.Background(), user, key)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateUserConcurrent одновременно регистрирует пользователей с одним именем
+// в настоящей базе PostgreSQL из переменной окружения GOPHKEEPER_TEST_DSN.
+// Без неё тест пропускается: гонку между проверкой и вставкой нельзя
+// воспроизвести на заглушке базы данных.
+func TestCreateUserConcurrent(t *testing.T) {
+	dsn := os.Getenv("GOPHKEEPER_TEST_DSN")
+	if dsn == "" {
+		t.Skip("GOPHKEEPER_TEST_DSN is not set")
+	}
+
+	previous := storage.DBStorage
+	defer func() { storage.DBStorage = previous }()
+
+	cfg := &configs.ServerConfig{}
+	cfg.Storage.ConnectionString = dsn
+	store := &storage.StorageImpl{}
+	require.NoError(t, store.ConnectDB(cfg))
+	defer store.DB.Close()
+
+	const workers = 32
+	store.DB.SetMaxOpenConns(workers)
+	username := "concurrent-" + uuid.New().String()
+	defer store.DB.Exec(`DELETE FROM users WHERE username = $1`, username)
+
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		success atomic.Int32
+		exists  atomic.Int32
+		errs    = make(chan error, workers)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			err := store.CreateUser(context.Background(), models.User{
+				ID:       uuid.New().String(),
+				Username: username,
+				Password: "password123",
+			}, make([]byte, models.PublicKeySize))
+			switch {
+			case err == nil:
+				success.Add(1)
+			case errors.Is(err, storage.ErrAlreadyExists):
+				exists.Add(1)
+			default:
+				errs <- err
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+	assert.Equal(t, int32(1), success.Load())
+	assert.Equal(t, int32(workers-1), exists.Load())
+
+	// Ключ сохранен только у зарегистрированного пользователя
+	var keys int
+	require.NoError(t, store.DB.QueryRow(`
+		SELECT count(*) FROM user_keys k JOIN users u ON u.uuid = k.user_id WHERE u.username = $1
+	`, username).Scan(&keys))
+	assert.Equal(t, 1, keys)
+}
+
 func TestGetUser(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -58,7 +265,7 @@ func TestGetUser(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"uuid", "username", "password"}).
 			AddRow(user.ID, user.Username, user.Password))
 
-	result, err := store.GetUser(user.Username)
+	result, err := store.GetUser(context.Background(), user.Username)
 	assert.NoError(t, err)
 	assert.Equal(t, user, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -73,16 +280,102 @@ func TestSaveCredential(t *testing.T) {
 	cred := models.Credential{
 		ID:     uuid.New().String(),
 		UserID: uuid.New().String(),
+		Type:   models.CredentialTypeLogin,
 		Data:   "secure data",
 		Meta:   "metadata",
+		Tags:   []string{"work", "db"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(cred.ID, cred.UserID, cred.Type, cred.Data, cred.Meta).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	for _, tag := range cred.Tags {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_tags")).
+			WithArgs(cred.ID, tag).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	err = store.SaveCredential(context.Background(), cred)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveCredentialDefaultType(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	cred := models.Credential{
+		ID:     uuid.New().String(),
+		UserID: uuid.New().String(),
+		Data:   "secure data",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(cred.ID, cred.UserID, models.DefaultCredentialType, cred.Data, cred.Meta).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = store.SaveCredential(context.Background(), cred)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveCredentials(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	creds := []models.Credential{
+		{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeLogin, Data: "login:a"},
+		{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeText, Data: "note", Favorite: true},
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
-		WithArgs(cred.ID, cred.UserID, cred.Data, cred.Meta).
+		WithArgs(creds[0].ID, userID, creds[0].Type, creds[0].Data, "").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(creds[1].ID, userID, creds[1].Type, creds[1].Data, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_favorites")).
+		WithArgs(creds[1].ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = store.SaveCredential(cred)
+	assert.NoError(t, store.SaveCredentials(context.Background(), creds))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveCredentialsRollsBack(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
 	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	creds := []models.Credential{
+		{ID: uuid.New().String(), UserID: userID, Data: "first"},
+		{ID: uuid.New().String(), UserID: userID, Data: "second"},
+	}
+
+	// Ошибка второй записи отменяет сохранение первой
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(creds[0].ID, userID, models.DefaultCredentialType, creds[0].Data, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credentials")).
+		WithArgs(creds[1].ID, userID, models.DefaultCredentialType, creds[1].Data, "").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	assert.Error(t, store.SaveCredentials(context.Background(), creds))
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -93,20 +386,93 @@ func TestEditCredential(t *testing.T) {
 
 	store := &storage.StorageImpl{DB: mockDB}
 	cred := models.Credential{
-		ID:   uuid.New().String(),
-		Data: "updated data",
-		Meta: "updated meta",
+		ID:     uuid.New().String(),
+		UserID: uuid.New().String(),
+		Data:   "updated data",
+		Meta:   "updated meta",
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE credentials SET data = $1, meta = $2 WHERE uuid = $3")).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE credentials SET data = $1, meta = $2, updated_at = now()")).
 		WithArgs(cred.Data, cred.Meta, cred.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(cred.UserID))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_rotations WHERE credential_id = $1")).
+		WithArgs(cred.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = store.EditCredential(context.Background(), cred)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditCredentialNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	cred := models.Credential{
+		ID:     uuid.New().String(),
+		UserID: uuid.New().String(),
+		Data:   "updated data",
+	}
+
+	// Удаленная запись не обновляется
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE credentials")).
+		WithArgs(cred.Data, cred.Meta, cred.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectRollback()
+
+	err = store.EditCredential(context.Background(), cred)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditCredentialReplacesSearchIndex(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	cred := models.Credential{
+		ID:          uuid.New().String(),
+		UserID:      uuid.New().String(),
+		Data:        "updated data",
+		Meta:        "updated meta",
+		SearchTerms: []string{strings.Repeat("a", 64)},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE credentials")).
+		WithArgs(cred.Data, cred.Meta, cred.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(cred.UserID))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_rotations WHERE credential_id = $1")).
+		WithArgs(cred.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM credential_search_index WHERE credential_id = $1")).
+		WithArgs(cred.ID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO credential_search_index")).
+		WithArgs(cred.ID, cred.SearchTerms[0]).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = store.EditCredential(cred)
+	err = store.EditCredential(context.Background(), cred)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// credentialRows возвращает набор строк в формате credentialColumns.
+func credentialRows(creds ...models.Credential) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"uuid", "user_id", "type", "data", "meta", "created_at", "updated_at", "tags", "folder_id", "favorite"})
+	for _, c := range creds {
+		rows.AddRow(c.ID, c.UserID, c.Type, c.Data, c.Meta, c.CreatedAt, c.UpdatedAt, strings.Join(c.Tags, "\x1f"), c.FolderID, c.Favorite)
+	}
+	return rows
+}
+
 func TestGetCredentials(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -114,17 +480,171 @@ func TestGetCredentials(t *testing.T) {
 
 	store := &storage.StorageImpl{DB: mockDB}
 	userID := uuid.New().String()
-	cred1 := models.Credential{ID: uuid.New().String(), UserID: userID, Data: "data1", Meta: "meta1"}
-	cred2 := models.Credential{ID: uuid.New().String(), UserID: userID, Data: "data2", Meta: "meta2"}
+	now := time.Now().UTC()
+	cred1 := models.Credential{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeText, Data: "data1", Meta: "meta1", Tags: []string{"a", "b"}, CreatedAt: now, UpdatedAt: now}
+	cred2 := models.Credential{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeLogin, Data: "data2", Meta: "meta2", Tags: []string{}, CreatedAt: now, UpdatedAt: now}
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT uuid, user_id, data, meta FROM credentials WHERE user_id=$1")).
+	mock.ExpectQuery(regexp.QuoteMeta("FROM credentials c WHERE c.user_id=$1 ORDER BY c.created_at, c.uuid")).
 		WithArgs(userID).
-		WillReturnRows(sqlmock.NewRows([]string{"uuid", "user_id", "data", "meta"}).
-			AddRow(cred1.ID, cred1.UserID, cred1.Data, cred1.Meta).
-			AddRow(cred2.ID, cred2.UserID, cred2.Data, cred2.Meta))
+		WillReturnRows(credentialRows(cred1, cred2))
 
-	result, err := store.GetCredentials(userID)
+	result, err := store.GetCredentials(context.Background(), userID)
 	assert.NoError(t, err)
 	assert.Equal(t, []models.Credential{cred1, cred2}, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestListCredentialsPagination(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	creds := make([]models.Credential, 3)
+	for i := range creds {
+		creds[i] = models.Credential{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			Type:      models.CredentialTypeText,
+			Data:      fmt.Sprintf("data%d", i),
+			Tags:      []string{},
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			UpdatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	// Первая страница: запрашивается page_size+1 записей для определения наличия следующей
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE c.user_id = $1 ORDER BY c.created_at ASC, c.uuid ASC LIMIT $2")).
+		WithArgs(userID, 3).
+		WillReturnRows(credentialRows(creds...))
+
+	page, err := store.ListCredentials(context.Background(), userID, models.ListOptions{PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, creds[:2], page.Credentials)
+	assert.NotEmpty(t, page.NextPageToken)
+
+	// Вторая страница продолжается после последней записи первой
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE c.user_id = $1 AND (c.created_at, c.uuid) > ($2, $3) ORDER BY c.created_at ASC, c.uuid ASC LIMIT $4")).
+		WithArgs(userID, creds[1].CreatedAt, creds[1].ID, 3).
+		WillReturnRows(credentialRows(creds[2]))
+
+	page, err = store.ListCredentials(context.Background(), userID, models.ListOptions{PageSize: 2, PageToken: page.NextPageToken})
+	assert.NoError(t, err)
+	assert.Equal(t, creds[2:], page.Credentials)
+	assert.Empty(t, page.NextPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListCredentialsFilterAndSort(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE c.user_id = $1 AND c.type = $2 AND EXISTS (SELECT 1 FROM credential_tags t WHERE t.credential_id = c.uuid AND t.tag = $3) ORDER BY c.updated_at DESC, c.uuid DESC LIMIT $4")).
+		WithArgs(userID, "login", "work", storage.DefaultPageSize+1).
+		WillReturnRows(credentialRows())
+
+	page, err := store.ListCredentials(context.Background(), userID, models.ListOptions{
+		Type:   models.CredentialTypeLogin,
+		Tag:    "work",
+		SortBy: models.SortByUpdatedAt,
+		Desc:   true,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Credentials)
+	assert.Empty(t, page.NextPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListCredentialsInvalidOptions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+
+	_, err = store.ListCredentials(context.Background(), userID, models.ListOptions{SortBy: "data"})
+	assert.ErrorIs(t, err, storage.ErrInvalidSort)
+
+	_, err = store.ListCredentials(context.Background(), userID, models.ListOptions{PageToken: "not-a-token"})
+	assert.ErrorIs(t, err, storage.ErrInvalidPageToken)
+
+	// Курсор, полученный при одной сортировке, не принимается при другой
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(credentialRows(
+			models.Credential{ID: uuid.New().String(), UserID: userID, Tags: []string{}},
+			models.Credential{ID: uuid.New().String(), UserID: userID, Tags: []string{}},
+		))
+	page, err := store.ListCredentials(context.Background(), userID, models.ListOptions{PageSize: 1})
+	assert.NoError(t, err)
+
+	_, err = store.ListCredentials(context.Background(), userID, models.ListOptions{PageSize: 1, PageToken: page.NextPageToken, Desc: true})
+	assert.ErrorIs(t, err, storage.ErrInvalidPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListCredentialsByID(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	cred := models.Credential{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeText, Tags: []string{}}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE c.user_id = $1 AND c.uuid = $2 ORDER BY")).
+		WithArgs(userID, cred.ID, 2).
+		WillReturnRows(credentialRows(cred))
+
+	page, err := store.ListCredentials(context.Background(), userID, models.ListOptions{ID: cred.ID, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credential{cred}, page.Credentials)
+
+	// Некорректный идентификатор не приводит к запросу в базу данных
+	page, err = store.ListCredentials(context.Background(), userID, models.ListOptions{ID: "12345"})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Credentials)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchCredentials(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	store := &storage.StorageImpl{DB: mockDB}
+	userID := uuid.New().String()
+	terms := []string{strings.Repeat("a", 64), strings.Repeat("b", 64)}
+	cred := models.Credential{ID: uuid.New().String(), UserID: userID, Type: models.CredentialTypeLogin, Tags: []string{}}
+
+	mock.ExpectQuery(`WHERE term IN \(\$2, \$3\)(.|\n)+WHERE c.user_id = \$1(.|\n)+ORDER BY m.hits DESC(.|\n)+LIMIT \$4`).
+		WithArgs(userID, terms[0], terms[1], storage.DefaultPageSize).
+		WillReturnRows(credentialRows(cred))
+
+	result, err := store.SearchCredentials(context.Background(), userID, terms, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Credential{cred}, result)
+
+	// Пустой запрос не обращается к базе данных
+	result, err = store.SearchCredentials(context.Background(), userID, nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateSearchTerms(t *testing.T) {
+	valid := strings.Repeat("0123456789abcdef", 4)
+
+	assert.NoError(t, storage.ValidateSearchTerms(nil, 1))
+	assert.NoError(t, storage.ValidateSearchTerms([]string{valid}, 1))
+	assert.ErrorIs(t, storage.ValidateSearchTerms([]string{valid, valid}, 1), storage.ErrInvalidSearchTerm)
+	assert.ErrorIs(t, storage.ValidateSearchTerms([]string{"github"}, 1), storage.ErrInvalidSearchTerm)
+	assert.ErrorIs(t, storage.ValidateSearchTerms([]string{strings.ToUpper(valid)}, 1), storage.ErrInvalidSearchTerm)
+}
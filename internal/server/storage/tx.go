@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"log/slog"
+)
+
+// uniqueViolationCode - код ошибки PostgreSQL при нарушении ограничения уникальности.
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation проверяет, вызвана ли ошибка нарушением ограничения уникальности.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// withTx выполняет fn внутри транзакции. Если fn возвращает ошибку или паникует,
+// транзакция откатывается, иначе фиксируется.
+func (s *StorageImpl) withTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	// Откат нужен, только если ошибку вернула fn: после неудачного Commit
+	// транзакция уже завершена
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			slog.Error("failed to rollback transaction", "error", rbErr)
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		slog.Error("failed to commit transaction", "error", err)
+		return err
+	}
+
+	return nil
+}
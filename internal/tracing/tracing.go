@@ -0,0 +1,119 @@
+// Package tracing настраивает трассировку OpenTelemetry для сервера и клиента.
+//
+// Setup устанавливает глобальный распространитель контекста W3C Trace Context и,
+// если выбран экспортер, провайдер трассировки, отправляющий участки (spans)
+// в OTLP-коллектор или в стандартный вывод. Без экспортера контекст трассировки
+// по-прежнему передается между клиентом и сервером, но участки не записываются.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Экспортеры участков трассировки.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// Options содержит параметры трассировки из конфигурации.
+type Options struct {
+	// ServiceName — имя сервиса в атрибутах участков.
+	ServiceName string
+
+	// ServiceVersion — версия сервиса в атрибутах участков.
+	ServiceVersion string
+
+	// Exporter — экспортер участков: none, otlp или stdout. Пустое значение означает none.
+	Exporter string
+
+	// Endpoint — адрес OTLP-коллектора (gRPC), например localhost:4317.
+	Endpoint string
+
+	// Insecure отключает TLS при подключении к OTLP-коллектору.
+	Insecure bool
+
+	// SampleRatio — доля записываемых трасс от 0 до 1. Нулевое значение означает 1.
+	SampleRatio float64
+}
+
+// Shutdown отправляет накопленные участки и останавливает провайдер трассировки.
+type Shutdown func(ctx context.Context) error
+
+// Setup устанавливает глобальные распространитель контекста и провайдер трассировки.
+// Возвращаемую функцию Shutdown необходимо вызвать при завершении программы.
+func Setup(ctx context.Context, opts Options) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := NewExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider := NewProvider(opts, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider создает провайдер трассировки с ресурсом сервиса и выборкой по opts.
+// Решение о записи трассы принимает сервер: флаг выборки из входящего контекста
+// не может заставить его записать трассу сверх заданной доли.
+func NewProvider(opts Options, extra ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	ratio := opts.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	sampler := sdktrace.TraceIDRatioBased(ratio)
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(opts.ServiceName),
+			semconv.ServiceVersion(opts.ServiceVersion),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sampler,
+			sdktrace.WithRemoteParentSampled(sampler),
+			sdktrace.WithRemoteParentNotSampled(sampler),
+		)),
+	}
+
+	return sdktrace.NewTracerProvider(append(options, extra...)...)
+}
+
+// NewExporter создает экспортер участков по opts. Для экспортера none возвращает nil.
+func NewExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(strings.TrimSpace(opts.Exporter)) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLP:
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, clientOpts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+}
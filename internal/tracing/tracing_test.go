@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewExporter(t *testing.T) {
+	ctx := context.Background()
+
+	exporter, err := NewExporter(ctx, Options{})
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	exporter, err = NewExporter(ctx, Options{Exporter: ExporterStdout})
+	require.NoError(t, err)
+	assert.NotNil(t, exporter)
+
+	exporter, err = NewExporter(ctx, Options{Exporter: ExporterOTLP, Endpoint: "localhost:4317", Insecure: true})
+	require.NoError(t, err)
+	require.NotNil(t, exporter)
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	_, err = NewExporter(ctx, Options{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestSetupPropagatesWithoutExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+
+	sc := trace.SpanContextFromContext(ctx)
+	assert.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+}
+
+func TestNewProviderRecordsSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(Options{ServiceName: "goph-keeper", ServiceVersion: "1.0.0"}, sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	_, span := provider.Tracer("test").Start(context.Background(), "work")
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "work", spans[0].Name)
+	assert.Contains(t, spans[0].Resource.Attributes(), semconv.ServiceName("goph-keeper"))
+}
+
+func TestNewProviderIgnoresRemoteSampledFlag(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(Options{SampleRatio: 1e-9}, sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+
+	_, span := provider.Tracer("test").Start(ctx, "work")
+	span.End()
+
+	assert.Empty(t, exporter.GetSpans())
+}